@@ -0,0 +1,46 @@
+package audit
+
+import (
+	"github.com/MakeNowJust/heredoc"
+	"github.com/spf13/cobra"
+
+	"github.com/hckops/hckctl/internal/command/config"
+)
+
+func NewAuditCmd(configRef *config.ConfigRef) *cobra.Command {
+
+	command := &cobra.Command{
+		Use:   "audit",
+		Short: "Show, verify and export the audit log",
+		Long: heredoc.Doc(`
+			Show, verify and export the audit log
+
+			  Every box, task and lab action is appended to "audit.filePath" with the user,
+			  the template and its git commit, the expanded arguments and targets, the vpn,
+			  the provider, the start and stop times and the exit code.
+			  Each record contains the hash of the previous one, any change, removal or
+			  reordering is detected by "verify". Keep a copy of the last hash to detect
+			  the removal of the most recent records.
+		`),
+		Example: heredoc.Doc(`
+
+			# shows the last 20 records
+			hckctl audit show --limit 20
+
+			# verifies the whole chain and prints the last hash
+			hckctl audit verify
+
+			# exports a verified copy for the engagement report
+			hckctl audit export --format csv --output audit.csv
+		`),
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.HelpFunc()(cmd, args)
+		},
+	}
+
+	command.AddCommand(NewAuditExportCmd(configRef))
+	command.AddCommand(NewAuditShowCmd(configRef))
+	command.AddCommand(NewAuditVerifyCmd(configRef))
+
+	return command
+}
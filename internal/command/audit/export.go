@@ -0,0 +1,99 @@
+package audit
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	commonFlag "github.com/hckops/hckctl/internal/command/common/flag"
+	"github.com/hckops/hckctl/internal/command/config"
+	"github.com/hckops/hckctl/pkg/audit"
+)
+
+const (
+	jsonFormat = "json"
+	csvFormat  = "csv"
+)
+
+type auditExportCmdOptions struct {
+	configRef  *config.ConfigRef
+	formatFlag string
+	outputFlag string
+}
+
+func NewAuditExportCmd(configRef *config.ConfigRef) *cobra.Command {
+
+	opts := &auditExportCmdOptions{
+		configRef: configRef,
+	}
+
+	command := &cobra.Command{
+		Use:     "export",
+		Short:   "Export all the records after verifying them",
+		Args:    cobra.NoArgs,
+		PreRunE: opts.validate,
+		RunE:    opts.run,
+	}
+
+	const (
+		formatFlagName  = "format"
+		outputFlagName  = "output"
+		outputFlagUsage = "write to a file instead of stdout"
+	)
+	formatFlagUsage := fmt.Sprintf("output format, one of %s", strings.Join([]string{jsonFormat, csvFormat}, "|"))
+	command.Flags().StringVarP(&opts.formatFlag, formatFlagName, commonFlag.NoneFlagShortHand, jsonFormat, formatFlagUsage)
+	command.Flags().StringVarP(&opts.outputFlag, outputFlagName, "o", "", outputFlagUsage)
+
+	return command
+}
+
+func (opts *auditExportCmdOptions) validate(cmd *cobra.Command, args []string) error {
+	if opts.formatFlag != jsonFormat && opts.formatFlag != csvFormat {
+		return fmt.Errorf("invalid format %s", opts.formatFlag)
+	}
+	return nil
+}
+
+func (opts *auditExportCmdOptions) run(cmd *cobra.Command, args []string) error {
+	auditLog, err := openAuditLog(opts.configRef)
+	if err != nil {
+		return err
+	}
+
+	// a broken chain is never exported
+	if _, err := verifyAudit(auditLog); err != nil {
+		return err
+	}
+	records, err := auditLog.ReadRecords()
+	if err != nil {
+		log.Warn().Err(err).Msgf("error reading audit: path=%s", auditLog.Path())
+		return errors.New("audit error")
+	}
+
+	var out io.Writer = os.Stdout
+	if opts.outputFlag != "" {
+		file, err := os.OpenFile(opts.outputFlag, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+		if err != nil {
+			log.Warn().Err(err).Msgf("error creating export: path=%s", opts.outputFlag)
+			return errors.New("export error")
+		}
+		defer file.Close()
+		out = file
+	}
+
+	if opts.formatFlag == csvFormat {
+		err = audit.ExportCsv(out, records)
+	} else {
+		err = audit.ExportJson(out, records)
+	}
+	if err != nil {
+		log.Warn().Err(err).Msgf("error exporting audit: format=%s", opts.formatFlag)
+		return errors.New("export error")
+	}
+	return nil
+}
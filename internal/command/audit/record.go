@@ -0,0 +1,70 @@
+package audit
+
+import (
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	commonCmd "github.com/hckops/hckctl/internal/command/common"
+	"github.com/hckops/hckctl/internal/command/config"
+	"github.com/hckops/hckctl/pkg/audit"
+	"github.com/hckops/hckctl/pkg/event"
+	"github.com/hckops/hckctl/pkg/scope"
+	"github.com/hckops/hckctl/pkg/template"
+)
+
+func newAuditLog(configRef *config.ConfigRef) *audit.AuditLog {
+	return audit.NewAuditLog(configRef.Config.Audit.FilePath)
+}
+
+// openAuditLog explains why the audit is not available, instead of failing to read an empty path
+func openAuditLog(configRef *config.ConfigRef) (*audit.AuditLog, error) {
+	if configRef.Config.Audit.FilePath == "" {
+		return nil, errors.New("audit disabled, set audit.filePath in the config to enable it")
+	}
+	return newAuditLog(configRef), nil
+}
+
+// Record appends an explicit record, errors are logged but never interrupt the command
+func Record(configRef *config.ConfigRef, record *audit.Record) {
+	if !configRef.Config.Audit.Enabled || configRef.Config.Audit.FilePath == "" {
+		return
+	}
+	if err := newAuditLog(configRef).Append(record); err != nil {
+		log.Warn().Err(err).Msgf("error audit record: action=%s name=%s", record.Action, record.Name)
+	}
+}
+
+// SubscribeEvents records the provider events which are relevant outside the console
+func SubscribeEvents(configRef *config.ConfigRef, eventBus *event.EventBus) {
+	eventBus.Subscribe(func(e event.Event) {
+		Record(configRef, audit.NewEventRecord(e))
+	}, audit.IsAuditable)
+}
+
+// NewTemplateRef references git templates by path and commit
+func NewTemplateRef[T template.TemplateType](info *template.TemplateInfo[T], cacheDir string) *audit.TemplateRef {
+	name := info.Path
+	if info.SourceType == template.Git {
+		name = commonCmd.PrettyPath(cacheDir, info.Path)
+	}
+	return &audit.TemplateRef{
+		Name:     name,
+		Source:   info.SourceType.String(),
+		Revision: info.Revision,
+	}
+}
+
+// Targets returns the addresses, networks and hostnames found in the arguments
+func Targets(arguments []string) []string {
+	var targets []string
+	visited := map[string]bool{}
+	for _, argument := range arguments {
+		for _, target := range scope.ExtractTargets(argument) {
+			if !visited[target.Value] {
+				visited[target.Value] = true
+				targets = append(targets, target.Value)
+			}
+		}
+	}
+	return targets
+}
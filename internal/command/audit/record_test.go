@@ -0,0 +1,83 @@
+package audit
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hckops/hckctl/internal/command/config"
+	"github.com/hckops/hckctl/pkg/audit"
+	"github.com/hckops/hckctl/pkg/event"
+)
+
+type testEvent struct {
+	kind event.EventKind
+}
+
+func (e *testEvent) Kind() event.EventKind {
+	return e.kind
+}
+
+func (e *testEvent) Source() string {
+	return "test"
+}
+
+func (e *testEvent) String() string {
+	return "my-message"
+}
+
+func newTestConfigRef(t *testing.T, enabled bool) *config.ConfigRef {
+	return &config.ConfigRef{Config: &config.ConfigV1{
+		Audit: config.AuditConfig{
+			Enabled:  enabled,
+			FilePath: filepath.Join(t.TempDir(), "audit.jsonl"),
+		},
+	}}
+}
+
+func TestSubscribeEvents(t *testing.T) {
+	configRef := newTestConfigRef(t, true)
+
+	eventBus := event.NewEventBus()
+	SubscribeEvents(configRef, eventBus)
+	eventBus.Publish(&testEvent{kind: event.LogInfo})
+	eventBus.Publish(&testEvent{kind: event.LogDebug})
+	eventBus.Publish(&testEvent{kind: event.LoaderUpdate})
+	eventBus.Publish(&testEvent{kind: event.LogError})
+	eventBus.Flush()
+
+	records, err := newAuditLog(configRef).ReadRecords()
+	assert.NoError(t, err)
+	assert.Len(t, records, 2)
+	assert.Equal(t, audit.EventAction, records[0].Action)
+	assert.Equal(t, "info", records[0].Level)
+	assert.Equal(t, "test", records[0].Source)
+	assert.Equal(t, "my-message", records[0].Message)
+	assert.Equal(t, "error", records[1].Level)
+}
+
+func TestRecordDisabled(t *testing.T) {
+	configRef := newTestConfigRef(t, false)
+	Record(configRef, &audit.Record{Action: audit.BoxCreateAction})
+
+	records, err := newAuditLog(configRef).ReadRecords()
+	assert.NoError(t, err)
+	assert.Empty(t, records)
+}
+
+func TestOpenAuditLogDisabled(t *testing.T) {
+	configRef := newTestConfigRef(t, true)
+	configRef.Config.Audit.FilePath = ""
+	// never fails
+	Record(configRef, &audit.Record{Action: audit.BoxCreateAction})
+
+	_, err := openAuditLog(configRef)
+	assert.EqualError(t, err, "audit disabled, set audit.filePath in the config to enable it")
+}
+
+func TestTargets(t *testing.T) {
+	arguments := []string{"nmap", "-sV", "10.10.10.1", "http://example.com", "10.10.10.1"}
+	assert.Equal(t, []string{"10.10.10.1", "example.com"}, Targets(arguments))
+	assert.Nil(t, Targets([]string{"ls"}))
+}
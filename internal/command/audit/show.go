@@ -0,0 +1,107 @@
+package audit
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	commonFlag "github.com/hckops/hckctl/internal/command/common/flag"
+	"github.com/hckops/hckctl/internal/command/config"
+	"github.com/hckops/hckctl/pkg/audit"
+)
+
+type auditShowCmdOptions struct {
+	configRef *config.ConfigRef
+	limitFlag int
+}
+
+func NewAuditShowCmd(configRef *config.ConfigRef) *cobra.Command {
+
+	opts := &auditShowCmdOptions{
+		configRef: configRef,
+	}
+
+	command := &cobra.Command{
+		Use:   "show",
+		Short: "Show the most recent records",
+		Args:  cobra.NoArgs,
+		RunE:  opts.run,
+	}
+
+	const (
+		limitFlagName  = "limit"
+		limitFlagUsage = "number of records, zero for all"
+	)
+	command.Flags().IntVarP(&opts.limitFlag, limitFlagName, commonFlag.NoneFlagShortHand, 50, limitFlagUsage)
+
+	return command
+}
+
+func (opts *auditShowCmdOptions) run(cmd *cobra.Command, args []string) error {
+	auditLog, err := openAuditLog(opts.configRef)
+	if err != nil {
+		return err
+	}
+
+	records, err := auditLog.ReadRecords()
+	if err != nil {
+		log.Warn().Err(err).Msgf("error reading audit: path=%s", auditLog.Path())
+		return errors.New("audit error")
+	}
+
+	total := len(records)
+	if opts.limitFlag > 0 && total > opts.limitFlag {
+		records = records[total-opts.limitFlag:]
+	}
+	for _, record := range records {
+		fmt.Println(formatRecord(record))
+	}
+	fmt.Println(fmt.Sprintf("total: %d", total))
+	return nil
+}
+
+func formatRecord(record audit.Record) string {
+	values := []string{
+		fmt.Sprintf("%d", record.Sequence),
+		record.Time.Local().Format("2006-01-02 15:04:05"),
+		record.User,
+		string(record.Action),
+	}
+	appendValue := func(key string, value string) {
+		if value != "" {
+			values = append(values, fmt.Sprintf("%s=%s", key, value))
+		}
+	}
+	appendValue("provider", record.Provider)
+	appendValue("name", record.Name)
+	if record.Template != nil {
+		appendValue("template", record.Template.Name)
+		if len(record.Template.Revision) > 7 {
+			appendValue("revision", record.Template.Revision[:7])
+		} else {
+			appendValue("revision", record.Template.Revision)
+		}
+	}
+	appendValue("vpn", record.NetworkVpn)
+	if len(record.Targets) > 0 {
+		appendValue("targets", strings.Join(record.Targets, ","))
+	}
+	if len(record.Arguments) > 0 {
+		appendValue("arguments", fmt.Sprintf("[%s]", strings.Join(record.Arguments, " ")))
+	}
+	if record.StartTime != nil && record.StopTime != nil {
+		appendValue("duration", record.StopTime.Sub(*record.StartTime).Round(time.Second).String())
+	}
+	if record.ExitCode != nil {
+		appendValue("exitCode", fmt.Sprintf("%d", *record.ExitCode))
+	}
+	appendValue("error", record.Error)
+	if record.Action == audit.EventAction {
+		values = append(values, fmt.Sprintf("[%s][%s] %s", record.Level, record.Source, record.Message))
+	}
+	return strings.Join(values, "\t")
+}
@@ -0,0 +1,59 @@
+package audit
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/hckops/hckctl/internal/command/config"
+	"github.com/hckops/hckctl/pkg/audit"
+)
+
+type auditVerifyCmdOptions struct {
+	configRef *config.ConfigRef
+}
+
+func NewAuditVerifyCmd(configRef *config.ConfigRef) *cobra.Command {
+
+	opts := &auditVerifyCmdOptions{
+		configRef: configRef,
+	}
+
+	command := &cobra.Command{
+		Use:   "verify",
+		Short: "Verify that the records were not tampered",
+		Args:  cobra.NoArgs,
+		RunE:  opts.run,
+	}
+
+	return command
+}
+
+func (opts *auditVerifyCmdOptions) run(cmd *cobra.Command, args []string) error {
+	auditLog, err := openAuditLog(opts.configRef)
+	if err != nil {
+		return err
+	}
+
+	result, err := verifyAudit(auditLog)
+	if err != nil {
+		return err
+	}
+	fmt.Println(fmt.Sprintf("valid: records=%d hash=%s", result.Records, result.HeadHash))
+	return nil
+}
+
+func verifyAudit(auditLog *audit.AuditLog) (*audit.VerifyResult, error) {
+	result, err := auditLog.Verify()
+	var chainErr *audit.ChainError
+	if errors.As(err, &chainErr) {
+		log.Warn().Err(err).Msgf("invalid audit: path=%s", auditLog.Path())
+		return nil, chainErr
+	} else if err != nil {
+		log.Warn().Err(err).Msgf("error verifying audit: path=%s", auditLog.Path())
+		return nil, errors.New("audit error")
+	}
+	return result, nil
+}
@@ -1,17 +1,21 @@
 package box
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"github.com/MakeNowJust/heredoc"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 
+	auditCmd "github.com/hckops/hckctl/internal/command/audit"
 	boxFlag "github.com/hckops/hckctl/internal/command/box/flag"
 	commonCmd "github.com/hckops/hckctl/internal/command/common"
 	commonFlag "github.com/hckops/hckctl/internal/command/common/flag"
 	"github.com/hckops/hckctl/internal/command/config"
+	"github.com/hckops/hckctl/pkg/audit"
 	boxModel "github.com/hckops/hckctl/pkg/box/model"
 	commonModel "github.com/hckops/hckctl/pkg/common/model"
 	"github.com/hckops/hckctl/pkg/template"
@@ -20,8 +24,10 @@ import (
 type boxCmdOptions struct {
 	configRef *config.ConfigRef
 	// flags
+	ignoreScopeFlag    bool
 	networkVpnFlag     string
 	providerFlag       *commonFlag.ProviderFlag
+	recordFlag         bool
 	templateSourceFlag *commonFlag.TemplateSourceFlag
 	tunnelFlag         *boxFlag.TunnelFlag
 	// internal
@@ -46,6 +52,8 @@ func NewBoxCmd(configRef *config.ConfigRef) *cobra.Command {
 
 			  Independently from the provider and the template used, it will spawn a shell
 			  that when closed will automatically delete and cleanup all the resources.
+			  Type the detach keys (default ctrl-p ctrl-q, see "box.detachKeys" config)
+			  to leave the shell and keep the box running.
 
 			  The main purpose of a Box is to provide a ready-to-go and always up-to-date
 			  environment with an uniformed experience, abstracting the actual providers
@@ -74,6 +82,9 @@ func NewBoxCmd(configRef *config.ConfigRef) *cobra.Command {
 
 			# opens a box defined locally
 			hckctl box ../megalopolis/box/base/alpine.yml --local
+
+			# records the shell session, see "hckctl session"
+			hckctl box alpine --record
 		`),
 		Args:    cobra.ExactArgs(1),
 		PreRunE: opts.validate,
@@ -86,12 +97,21 @@ func NewBoxCmd(configRef *config.ConfigRef) *cobra.Command {
 	opts.providerFlag = boxFlag.AddBoxProviderFlag(command)
 	// --revision or --local
 	opts.templateSourceFlag = commonFlag.AddTemplateSourceFlag(command)
+	// --ignore-scope
+	commonFlag.AddIgnoreScopeFlag(command, &opts.ignoreScopeFlag)
 	// --no-exec or --no-tunnel
 	opts.tunnelFlag = boxFlag.AddTunnelFlag(command)
+	// --record
+	commonFlag.AddRecordFlag(command, &opts.recordFlag)
 
+	command.AddCommand(NewBoxCopyCmd(configRef))
+	command.AddCommand(NewBoxExecCmd(configRef))
 	command.AddCommand(NewBoxInfoCmd(configRef))
 	command.AddCommand(NewBoxListCmd(configRef))
+	command.AddCommand(NewBoxLogsCmd(configRef))
 	command.AddCommand(NewBoxOpenCmd(configRef))
+	command.AddCommand(NewBoxPortForwardCmd(configRef))
+	command.AddCommand(NewBoxPortsCmd(configRef))
 	command.AddCommand(NewBoxStartCmd(configRef))
 	command.AddCommand(NewBoxStopCmd(configRef))
 
@@ -116,6 +136,8 @@ func (opts *boxCmdOptions) validate(cmd *cobra.Command, args []string) error {
 		log.Warn().Err(err).Msgf(commonFlag.ErrorFlagNotSupported)
 		return errors.New(commonFlag.ErrorFlagNotSupported)
 	}
+	// record
+	opts.recordFlag = commonFlag.ValidateRecordFlag(cmd, opts.recordFlag, opts.configRef.Config.Session.Record)
 	// tunnel
 	if err := boxFlag.ValidateTunnelFlag(opts.tunnelFlag, opts.provider); err != nil {
 		log.Warn().Err(err).Msgf("ignore validation %s", commonFlag.ErrorFlagNotSupported)
@@ -132,7 +154,7 @@ func (opts *boxCmdOptions) run(cmd *cobra.Command, args []string) error {
 		log.Debug().Msgf("temporary box from local template: path=%s", path)
 
 		sourceLoader := template.NewLocalCachedLoader[boxModel.BoxV1](path, opts.configRef.Config.Template.CacheDir)
-		return opts.temporaryBox(sourceLoader, boxModel.NewBoxLabels().AddDefaultLocal())
+		return opts.temporaryBox(cmd.Context(), sourceLoader, boxModel.NewBoxLabels().AddDefaultLocal())
 
 	} else {
 		name := args[0]
@@ -141,11 +163,16 @@ func (opts *boxCmdOptions) run(cmd *cobra.Command, args []string) error {
 		sourceOpts := commonCmd.NewGitSourceOptions(opts.configRef.Config.Template.CacheDir, opts.templateSourceFlag.Revision)
 		sourceLoader := template.NewGitLoader[boxModel.BoxV1](sourceOpts, name)
 		labels := boxModel.NewBoxLabels().AddDefaultGit(sourceOpts.RepositoryUrl, sourceOpts.DefaultRevision, sourceOpts.CacheDirName())
-		return opts.temporaryBox(sourceLoader, labels)
+		return opts.temporaryBox(cmd.Context(), sourceLoader, labels)
 	}
 }
 
-func (opts *boxCmdOptions) temporaryBox(sourceLoader template.SourceLoader[boxModel.BoxV1], labels commonModel.Labels) error {
+func (opts *boxCmdOptions) temporaryBox(ctx context.Context, sourceLoader template.SourceLoader[boxModel.BoxV1], labels commonModel.Labels) error {
+
+	// boxes don't have targets, only the engagement window is enforced
+	if _, err := commonCmd.LoadScope(opts.configRef.Config.Scope.Path, opts.ignoreScopeFlag); err != nil {
+		return err
+	}
 
 	temporaryClient := func(invokeOpts *invokeOptions) error {
 
@@ -153,13 +180,27 @@ func (opts *boxCmdOptions) temporaryBox(sourceLoader template.SourceLoader[boxMo
 		if err != nil {
 			return err
 		}
-		boxInfo, err := invokeOpts.client.Create(createOpts)
+		boxInfo, err := invokeOpts.client.Create(invokeOpts.ctx, createOpts)
 		if err != nil {
 			return err
 		}
+		startTime := auditBoxCreate(opts.configRef, invokeOpts, createOpts, boxInfo.Name)
 
 		connectOpts := opts.tunnelFlag.ToConnectOptions(&invokeOpts.template.Value.Data, boxInfo.Name, true)
-		return invokeOpts.client.Connect(connectOpts)
+		err = connectBox(invokeOpts.ctx, invokeOpts.client, connectOpts, opts.configRef, opts.recordFlag)
+
+		// a detached box is still running
+		if !connectOpts.IsDetached() {
+			stopTime := time.Now().UTC()
+			record := newBoxAuditRecord(audit.BoxDeleteAction, invokeOpts.client.Provider(), boxInfo.Name)
+			record.StartTime = &startTime
+			record.StopTime = &stopTime
+			if err != nil {
+				record.Error = err.Error()
+			}
+			auditCmd.Record(opts.configRef, record)
+		}
+		return err
 	}
-	return runBoxClient(sourceLoader, opts.provider, opts.configRef, temporaryClient)
+	return runBoxClient(ctx, sourceLoader, opts.provider, opts.configRef, temporaryClient)
 }
@@ -1,30 +1,88 @@
 package box
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 
+	auditCmd "github.com/hckops/hckctl/internal/command/audit"
 	boxFlag "github.com/hckops/hckctl/internal/command/box/flag"
 	commonCmd "github.com/hckops/hckctl/internal/command/common"
 	"github.com/hckops/hckctl/internal/command/config"
+	sessionCmd "github.com/hckops/hckctl/internal/command/session"
 	"github.com/hckops/hckctl/internal/command/version"
+	"github.com/hckops/hckctl/pkg/audit"
 	"github.com/hckops/hckctl/pkg/box"
 	boxModel "github.com/hckops/hckctl/pkg/box/model"
+	"github.com/hckops/hckctl/pkg/client/terminal"
 	commonModel "github.com/hckops/hckctl/pkg/common/model"
 	"github.com/hckops/hckctl/pkg/schema"
 	"github.com/hckops/hckctl/pkg/template"
 )
 
 type invokeOptions struct {
+	ctx      context.Context
 	client   box.BoxClient
 	template *template.TemplateInfo[boxModel.BoxV1]
 	loader   *commonCmd.Loader
 }
 
+// connectBox optionally records the shell, which can be left with the detach keys without deleting the box
+func connectBox(ctx context.Context, boxClient box.BoxClient, connectOpts *boxModel.ConnectOptions, configRef *config.ConfigRef, record bool) error {
+	if !connectOpts.DisableExec {
+		if record {
+			streamOpts, stopRecording, err := sessionCmd.RecordStreams(configRef, connectOpts.Name, connectOpts.StreamOpts)
+			if err != nil {
+				return err
+			}
+			defer stopRecording()
+			connectOpts.StreamOpts = streamOpts
+		}
+
+		detachKeys := configRef.Config.Box.DetachKeys
+		detachReader, err := terminal.NewDetachReader(connectOpts.StreamOpts.In, detachKeys)
+		if err != nil {
+			log.Warn().Err(err).Msgf("error detach keys: keys=%s", detachKeys)
+			return errors.New("invalid detach keys")
+		}
+		connectOpts.StreamOpts.In = detachReader
+	}
+
+	if err := boxClient.Connect(ctx, connectOpts); err != nil {
+		return err
+	}
+	if connectOpts.IsDetached() {
+		fmt.Println(fmt.Sprintf("detached from %s, to access it again run: %s box open %s", connectOpts.Name, commonCmd.CliName, connectOpts.Name))
+	}
+	return nil
+}
+
+// auditBoxCreate records the template and the vpn of a new box
+func auditBoxCreate(configRef *config.ConfigRef, invokeOpts *invokeOptions, createOpts *boxModel.CreateOptions, boxName string) time.Time {
+	startTime := time.Now().UTC()
+	record := newBoxAuditRecord(audit.BoxCreateAction, invokeOpts.client.Provider(), boxName)
+	record.Template = auditCmd.NewTemplateRef(invokeOpts.template, configRef.Config.Template.CacheDir)
+	record.StartTime = &startTime
+	if createOpts.CommonInfo.NetworkVpn != nil {
+		record.NetworkVpn = createOpts.CommonInfo.NetworkVpn.Name
+	}
+	auditCmd.Record(configRef, record)
+	return startTime
+}
+
+func newBoxAuditRecord(action audit.Action, provider boxModel.BoxProvider, boxName string) *audit.Record {
+	return &audit.Record{
+		Action:   action,
+		Provider: provider.String(),
+		Name:     boxName,
+	}
+}
+
 // start and temporary
-func runBoxClient(sourceLoader template.SourceLoader[boxModel.BoxV1], provider boxModel.BoxProvider, configRef *config.ConfigRef, invokeClient func(*invokeOptions) error) error {
+func runBoxClient(ctx context.Context, sourceLoader template.SourceLoader[boxModel.BoxV1], provider boxModel.BoxProvider, configRef *config.ConfigRef, invokeClient func(*invokeOptions) error) error {
 
 	boxTemplate, err := sourceLoader.Read()
 	if err != nil || boxTemplate.Value.Kind != schema.KindBoxV1 {
@@ -40,12 +98,13 @@ func runBoxClient(sourceLoader template.SourceLoader[boxModel.BoxV1], provider b
 
 	log.Info().Msgf("loading template: provider=%s name=%s\n%s", provider, templateName, boxTemplate.Value.Data.Pretty())
 
-	boxClient, err := newDefaultBoxClient(provider, configRef, loader)
+	boxClient, err := newDefaultBoxClient(ctx, provider, configRef, loader, "")
 	if err != nil {
 		return err
 	}
 
 	invokeOpts := &invokeOptions{
+		ctx:      ctx,
 		client:   boxClient,
 		template: boxTemplate,
 		loader:   loader,
@@ -58,7 +117,7 @@ func runBoxClient(sourceLoader template.SourceLoader[boxModel.BoxV1], provider b
 }
 
 // open, info and stop-one
-func attemptRunBoxClients(configRef *config.ConfigRef, boxName string, invokeClient func(*invokeOptions, *boxModel.BoxDetails) error) error {
+func attemptRunBoxClients(ctx context.Context, configRef *config.ConfigRef, boxName string, invokeClient func(*invokeOptions, *boxModel.BoxDetails) error) error {
 
 	loader := commonCmd.NewLoader()
 	loader.Start("loading %s", boxName)
@@ -75,13 +134,13 @@ func attemptRunBoxClients(configRef *config.ConfigRef, boxName string, invokeCli
 			continue
 		}
 
-		boxClient, err := newDefaultBoxClient(provider, configRef, loader)
+		boxClient, err := newDefaultBoxClient(ctx, provider, configRef, loader, boxName)
 		if err != nil {
 			log.Warn().Err(err).Msgf("ignoring error default client: provider=%s", provider)
 			continue
 		}
 
-		boxDetails, err := boxClient.Describe(boxName)
+		boxDetails, err := boxClient.Describe(ctx, boxName)
 		if err != nil {
 			log.Warn().Err(err).Msgf("ignoring error describe box: provider=%s boxName=%s", provider, boxName)
 			continue
@@ -94,6 +153,7 @@ func attemptRunBoxClients(configRef *config.ConfigRef, boxName string, invokeCli
 		}
 
 		invokeOpts := &invokeOptions{
+			ctx:      ctx,
 			client:   boxClient,
 			template: templateInfo, // TODO with lab merge boxDetails and templateInfo BoxEnv
 			loader:   loader,
@@ -118,25 +178,40 @@ func newSourceLoader(boxDetails *boxModel.BoxDetails, cacheDir string) template.
 	}
 }
 
-func newBoxClientOpts(provider boxModel.BoxProvider, configRef *config.ConfigRef) *boxModel.BoxClientOptions {
-	return &boxModel.BoxClientOptions{
+func newBoxClientOpts(provider boxModel.BoxProvider, configRef *config.ConfigRef) (*boxModel.BoxClientOptions, error) {
+	boxClientOpts := &boxModel.BoxClientOptions{
 		Provider:   provider,
 		DockerOpts: configRef.Config.Provider.Docker.ToDockerOptions(),
 		KubeOpts:   configRef.Config.Provider.Kube.ToKubeOptions(),
-		CloudOpts:  configRef.Config.Provider.Cloud.ToCloudOptions(version.ClientVersion()),
 	}
+	// unlocks the secret store only if required
+	if provider == boxModel.Cloud {
+		cloudOpts, err := configRef.Config.Provider.Cloud.ToCloudOptions(version.ClientVersion(), configRef.SecretLookup)
+		if err != nil {
+			return nil, err
+		}
+		boxClientOpts.CloudOpts = cloudOpts
+	}
+	return boxClientOpts, nil
 }
 
-func newDefaultBoxClient(provider boxModel.BoxProvider, configRef *config.ConfigRef, loader *commonCmd.Loader) (box.BoxClient, error) {
+// boxName is optional, it identifies the events of a single box in the stream
+func newDefaultBoxClient(ctx context.Context, provider boxModel.BoxProvider, configRef *config.ConfigRef, loader *commonCmd.Loader, boxName string) (box.BoxClient, error) {
 
-	boxClientOpts := newBoxClientOpts(provider, configRef)
-	boxClient, err := box.NewBoxClient(boxClientOpts)
+	boxClientOpts, err := newBoxClientOpts(provider, configRef)
+	if err != nil {
+		log.Warn().Err(err).Msgf("error box client options provider=%s", provider)
+		return nil, fmt.Errorf("error %s client", provider)
+	}
+	boxClient, err := box.NewBoxClient(ctx, boxClientOpts)
 	if err != nil {
 		log.Error().Err(err).Msgf("error box client provider=%s", provider)
 		return nil, fmt.Errorf("error %s client", provider)
 	}
 
-	boxClient.Events().Subscribe(commonCmd.EventCallback(loader))
+	commonCmd.SubscribeEvents(boxClient.Events(), loader)
+	auditCmd.SubscribeEvents(configRef, boxClient.Events())
+	configRef.EventStream.Subscribe(boxClient.Events(), provider.String(), boxName)
 	return boxClient, nil
 }
 
@@ -151,7 +226,7 @@ func newCreateOptions(info *template.TemplateInfo[boxModel.BoxV1], labels common
 	allLabels := commonCmd.AddTemplateLabels[boxModel.BoxV1](info, boxModel.AddBoxSize(labels, size))
 
 	var networkVpn *commonModel.NetworkVpnInfo
-	if networkVpnInfo, err := configRef.Config.Network.ToNetworkVpnInfo(vpnName); err != nil {
+	if networkVpnInfo, err := configRef.Config.Network.ToNetworkVpnInfo(vpnName, configRef.SecretLookup); err != nil {
 		log.Warn().Err(err).Msg("error invalid vpn config")
 		return nil, err
 	} else if networkVpnInfo != nil {
@@ -159,12 +234,19 @@ func newCreateOptions(info *template.TemplateInfo[boxModel.BoxV1], labels common
 		networkVpn = networkVpnInfo
 	}
 
+	// the store is unlocked only if the template references a secret
+	boxTemplate, err := info.Value.Data.ResolveSecrets(configRef.SecretLookup)
+	if err != nil {
+		log.Warn().Err(err).Msg("error resolving box secrets")
+		return nil, err
+	}
+
 	return &boxModel.CreateOptions{
-		Template: &info.Value.Data,
+		Template: boxTemplate,
 		Labels:   allLabels,
 		CommonInfo: commonModel.CommonInfo{
 			NetworkVpn: networkVpn,
-			ShareDir:   configRef.Config.Common.ToShareDirInfo(false),
+			ShareDir:   configRef.Config.Common.ToShareDirInfo(false, false),
 		},
 		Size: size,
 	}, nil
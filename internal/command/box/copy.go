@@ -0,0 +1,68 @@
+package box
+
+import (
+	"fmt"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/hckops/hckctl/internal/command/config"
+	boxModel "github.com/hckops/hckctl/pkg/box/model"
+)
+
+type boxCopyCmdOptions struct {
+	configRef *config.ConfigRef
+}
+
+func NewBoxCopyCmd(configRef *config.ConfigRef) *cobra.Command {
+
+	opts := &boxCopyCmdOptions{
+		configRef: configRef,
+	}
+
+	command := &cobra.Command{
+		Use:   "cp [source] [destination]",
+		Short: "Copy files and directories between a running box and the local filesystem",
+		Example: heredoc.Doc(`
+
+			# copies a local file into the "/tmp" directory of the box
+			hckctl box cp ./wordlist.txt box-kali-<RANDOM>:/tmp/
+
+			# copies a directory from the box into a local directory, preserving file modes
+			hckctl box cp box-kali-<RANDOM>:/root/loot ./loot
+		`),
+		Args: cobra.ExactArgs(2),
+		RunE: opts.run,
+	}
+
+	return command
+}
+
+func (opts *boxCopyCmdOptions) run(cmd *cobra.Command, args []string) error {
+
+	copyOpts, err := boxModel.NewCopyOptions(args[0], args[1])
+	if err != nil {
+		log.Warn().Err(err).Msgf("error copy arguments: source=%s destination=%s", args[0], args[1])
+		return errors.New("invalid arguments")
+	}
+	log.Debug().Msgf("copy box: boxName=%s direction=%s localPath=%s remotePath=%s",
+		copyOpts.Name, copyOpts.Direction, copyOpts.LocalPath, copyOpts.RemotePath)
+
+	copyClient := func(invokeOpts *invokeOptions, _ *boxModel.BoxDetails) error {
+		copyOpts.Template = &invokeOpts.template.Value.Data
+
+		if err := invokeOpts.client.Copy(invokeOpts.ctx, copyOpts); err != nil {
+			return err
+		}
+		invokeOpts.loader.Stop()
+		if copyOpts.Direction == boxModel.CopyUpload {
+			fmt.Printf("%s:%s\n", copyOpts.Name, copyOpts.RemotePath)
+		} else {
+			fmt.Println(copyOpts.LocalPath)
+		}
+		return nil
+	}
+	return attemptRunBoxClients(cmd.Context(), opts.configRef, copyOpts.Name, copyClient)
+}
@@ -0,0 +1,100 @@
+package box
+
+import (
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	auditCmd "github.com/hckops/hckctl/internal/command/audit"
+	boxFlag "github.com/hckops/hckctl/internal/command/box/flag"
+	commonCmd "github.com/hckops/hckctl/internal/command/common"
+	"github.com/hckops/hckctl/internal/command/config"
+	"github.com/hckops/hckctl/pkg/audit"
+	boxModel "github.com/hckops/hckctl/pkg/box/model"
+)
+
+type boxExecCmdOptions struct {
+	configRef *config.ConfigRef
+	execFlag  *boxFlag.ExecFlag
+}
+
+func NewBoxExecCmd(configRef *config.ConfigRef) *cobra.Command {
+
+	opts := &boxExecCmdOptions{
+		configRef: configRef,
+	}
+
+	command := &cobra.Command{
+		Use:   "exec [name] -- [command] [args...]",
+		Short: "Run a command inside a running box",
+		Example: heredoc.Doc(`
+
+			# runs a command and exits with the same exit code
+			hckctl box exec box-alpine-<RANDOM> -- cat /etc/os-release
+
+			# pipes the local stdin to the command
+			echo "hello" | hckctl box exec box-alpine-<RANDOM> --stdin -- wc -c
+
+			# runs an interactive command
+			hckctl box exec box-alpine-<RANDOM> --stdin --tty -- top
+		`),
+		Args: func(cmd *cobra.Command, args []string) error {
+			// the command must be separated by "--" to allow flags in the arguments
+			if cmd.ArgsLenAtDash() != 1 || len(args) < 2 {
+				return errors.New("requires a box name and a command separated by --")
+			}
+			return nil
+		},
+		RunE: opts.run,
+	}
+
+	// --tty and --stdin
+	opts.execFlag = boxFlag.AddExecFlag(command)
+
+	return command
+}
+
+func (opts *boxExecCmdOptions) run(cmd *cobra.Command, args []string) error {
+	boxName := args[0]
+	command := args[1:]
+	log.Debug().Msgf("exec box: boxName=%s command=%v", boxName, command)
+
+	var exitCode int
+	execClient := func(invokeOpts *invokeOptions, _ *boxModel.BoxDetails) error {
+		execOpts := opts.execFlag.ToExecOptions(&invokeOpts.template.Value.Data, boxName, command)
+
+		startTime := time.Now().UTC()
+		code, err := invokeOpts.client.Exec(invokeOpts.ctx, execOpts)
+		stopTime := time.Now().UTC()
+
+		record := newBoxAuditRecord(audit.BoxExecAction, invokeOpts.client.Provider(), boxName)
+		record.Arguments = command
+		record.Targets = auditCmd.Targets(command)
+		record.StartTime = &startTime
+		record.StopTime = &stopTime
+		if err != nil {
+			record.Error = err.Error()
+		} else {
+			record.ExitCode = &code
+		}
+		auditCmd.Record(opts.configRef, record)
+
+		if err != nil {
+			return err
+		}
+		exitCode = code
+		return nil
+	}
+	if err := attemptRunBoxClients(cmd.Context(), opts.configRef, boxName, execClient); err != nil {
+		return err
+	}
+
+	if exitCode != 0 {
+		log.Debug().Msgf("exec box exit: boxName=%s exitCode=%d", boxName, exitCode)
+		return &commonCmd.ExitCodeError{Code: exitCode}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package flag
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	commonFlag "github.com/hckops/hckctl/internal/command/common/flag"
+	boxModel "github.com/hckops/hckctl/pkg/box/model"
+	commonModel "github.com/hckops/hckctl/pkg/common/model"
+)
+
+const (
+	ttyFlagName   = "tty"
+	stdinFlagName = "stdin"
+)
+
+type ExecFlag struct {
+	Tty   bool
+	Stdin bool
+}
+
+func (f *ExecFlag) ToExecOptions(template *boxModel.BoxV1, name string, command []string) *boxModel.ExecOptions {
+	streamOpts := &commonModel.StreamOptions{
+		Out:   os.Stdout,
+		Err:   os.Stderr,
+		IsTty: f.Tty,
+	}
+	if f.Stdin {
+		streamOpts.In = os.Stdin
+	}
+	return &boxModel.ExecOptions{
+		Template:   template,
+		StreamOpts: streamOpts,
+		Name:       name,
+		Command:    command,
+	}
+}
+
+func AddExecFlag(command *cobra.Command) *ExecFlag {
+	const (
+		ttyFlagUsage   = "allocate a pseudo-tty, stdout and stderr are merged"
+		stdinFlagUsage = "keep stdin attached to the command"
+	)
+	execFlag := &ExecFlag{}
+	command.Flags().BoolVarP(&execFlag.Tty, ttyFlagName, commonFlag.NoneFlagShortHand, false, ttyFlagUsage)
+	command.Flags().BoolVarP(&execFlag.Stdin, stdinFlagName, commonFlag.NoneFlagShortHand, false, stdinFlagUsage)
+	return execFlag
+}
@@ -0,0 +1,25 @@
+package flag
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hckops/hckctl/pkg/box/model"
+)
+
+func TestToExecOptions(t *testing.T) {
+	template := &model.BoxV1{Name: "alpine"}
+	command := []string{"ls", "-la"}
+
+	opts := (&ExecFlag{}).ToExecOptions(template, "box-alpine-123", command)
+	assert.Equal(t, "box-alpine-123", opts.Name)
+	assert.Equal(t, command, opts.Command)
+	assert.Nil(t, opts.StreamOpts.In)
+	assert.False(t, opts.StreamOpts.IsTty)
+
+	interactiveOpts := (&ExecFlag{Tty: true, Stdin: true}).ToExecOptions(template, "box-alpine-123", command)
+	assert.Equal(t, os.Stdin, interactiveOpts.StreamOpts.In)
+	assert.True(t, interactiveOpts.StreamOpts.IsTty)
+}
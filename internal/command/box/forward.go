@@ -0,0 +1,94 @@
+package box
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/hckops/hckctl/pkg/util"
+)
+
+const forwardFileExtension = ".json"
+
+// portForwardEntry describes an active "box port-forward" process
+type portForwardEntry struct {
+	BoxName  string    `json:"boxName"`
+	Provider string    `json:"provider"`
+	Address  string    `json:"address"` // local host:port
+	Remote   string    `json:"remote"`
+	Pid      int       `json:"pid"`
+	Created  time.Time `json:"created"`
+}
+
+// portForwardRegistry keeps track of the local bindings, one file per process
+type portForwardRegistry struct {
+	dir         string
+	isAliveFunc func(pid int) bool
+}
+
+func newPortForwardRegistry(dir string) *portForwardRegistry {
+	return &portForwardRegistry{
+		dir:         dir,
+		isAliveFunc: util.IsProcessAlive,
+	}
+}
+
+func (registry *portForwardRegistry) entryPath(pid int) string {
+	return filepath.Join(registry.dir, fmt.Sprintf("%d%s", pid, forwardFileExtension))
+}
+
+func (registry *portForwardRegistry) add(entry *portForwardEntry) error {
+	value, err := util.EncodeJson(entry)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(registry.entryPath(entry.Pid), []byte(value), 0600); err != nil {
+		return errors.Wrapf(err, "error writing port-forward entry: pid=%d", entry.Pid)
+	}
+	return nil
+}
+
+func (registry *portForwardRegistry) remove(pid int) error {
+	return util.DeleteFile(registry.entryPath(pid))
+}
+
+// list returns the active bindings of a box, or all if the name is empty, and removes the stale entries
+func (registry *portForwardRegistry) list(boxName string) ([]portForwardEntry, error) {
+	files, err := os.ReadDir(registry.dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading port-forward dir %s", registry.dir)
+	}
+
+	var entries []portForwardEntry
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), forwardFileExtension) {
+			continue
+		}
+		filePath := filepath.Join(registry.dir, file.Name())
+
+		value, err := os.ReadFile(filePath)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error reading port-forward entry %s", filePath)
+		}
+		var entry portForwardEntry
+		if err := json.Unmarshal(value, &entry); err != nil || !registry.isAliveFunc(entry.Pid) {
+			// invalid or terminated without cleanup e.g. killed
+			_ = util.DeleteFile(filePath)
+			continue
+		}
+		if boxName == "" || entry.BoxName == boxName {
+			entries = append(entries, entry)
+		}
+	}
+
+	slices.SortFunc(entries, func(a, b portForwardEntry) int {
+		return strings.Compare(a.Address, b.Address)
+	})
+	return entries, nil
+}
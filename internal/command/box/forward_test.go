@@ -0,0 +1,41 @@
+package box
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPortForwardRegistry(t *testing.T) {
+	registry := newPortForwardRegistry(t.TempDir())
+	registry.isAliveFunc = func(pid int) bool {
+		return pid != 3
+	}
+
+	assert.NoError(t, registry.add(&portForwardEntry{BoxName: "box-alpine-1", Address: "127.0.0.1:9090", Remote: "90", Pid: 1}))
+	assert.NoError(t, registry.add(&portForwardEntry{BoxName: "box-alpine-1", Address: "127.0.0.1:8080", Remote: "80", Pid: 2}))
+	assert.NoError(t, registry.add(&portForwardEntry{BoxName: "box-alpine-1", Address: "127.0.0.1:7070", Remote: "70", Pid: 3}))
+	assert.NoError(t, registry.add(&portForwardEntry{BoxName: "box-kali-2", Address: "0.0.0.0:3000", Remote: "3000", Pid: 4}))
+
+	entries, err := registry.list("box-alpine-1")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, "127.0.0.1:8080", entries[0].Address)
+	assert.Equal(t, "127.0.0.1:9090", entries[1].Address)
+
+	// stale entry removed
+	_, err = os.Stat(filepath.Join(registry.dir, "3.json"))
+	assert.True(t, os.IsNotExist(err))
+
+	all, err := registry.list("")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(all))
+
+	assert.NoError(t, registry.remove(1))
+	entries, err = registry.list("box-alpine-1")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, 2, entries[0].Pid)
+}
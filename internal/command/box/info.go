@@ -7,6 +7,7 @@ import (
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 
+	commonFlag "github.com/hckops/hckctl/internal/command/common/flag"
 	"github.com/hckops/hckctl/internal/command/config"
 	boxModel "github.com/hckops/hckctl/pkg/box/model"
 	commonModel "github.com/hckops/hckctl/pkg/common/model"
@@ -15,7 +16,8 @@ import (
 
 // TODO add output format yaml/json
 type boxInfoCmdOptions struct {
-	configRef *config.ConfigRef
+	configRef       *config.ConfigRef
+	showSecretsFlag bool
 }
 
 func NewBoxInfoCmd(configRef *config.ConfigRef) *cobra.Command {
@@ -31,6 +33,12 @@ func NewBoxInfoCmd(configRef *config.ConfigRef) *cobra.Command {
 		RunE:  opts.run,
 	}
 
+	const (
+		showSecretsFlagName  = "show-secrets"
+		showSecretsFlagUsage = "reveal the values of the secret environment variables"
+	)
+	command.Flags().BoolVarP(&opts.showSecretsFlag, showSecretsFlagName, commonFlag.NoneFlagShortHand, false, showSecretsFlagUsage)
+
 	return command
 }
 
@@ -40,7 +48,7 @@ func (opts *boxInfoCmdOptions) run(cmd *cobra.Command, args []string) error {
 
 	describeClient := func(invokeOpts *invokeOptions, boxDetails *boxModel.BoxDetails) error {
 
-		if value, err := util.EncodeYaml(newBoxValue(&invokeOpts.template.Value.Data, boxDetails)); err != nil {
+		if value, err := util.EncodeYaml(newBoxValue(&invokeOpts.template.Value.Data, boxDetails, opts.showSecretsFlag)); err != nil {
 			return err
 		} else {
 			invokeOpts.loader.Stop()
@@ -48,7 +56,7 @@ func (opts *boxInfoCmdOptions) run(cmd *cobra.Command, args []string) error {
 		}
 		return nil
 	}
-	return attemptRunBoxClients(opts.configRef, boxName, describeClient)
+	return attemptRunBoxClients(cmd.Context(), opts.configRef, boxName, describeClient)
 }
 
 type BoxValue struct {
@@ -68,12 +76,16 @@ type ProviderValue struct {
 	KubeProvider   *commonModel.KubeProviderInfo   `yaml:"kubernetes,omitempty"`
 }
 
-func newBoxValue(template *boxModel.BoxV1, details *boxModel.BoxDetails) *BoxValue {
+func newBoxValue(template *boxModel.BoxV1, details *boxModel.BoxDetails, showSecrets bool) *BoxValue {
 
 	var envs []string
 	for _, e := range details.Env {
-		if _, exists := template.EnvironmentVariables()[e.Key]; exists {
-			envs = append(envs, fmt.Sprintf("%s=%s", e.Key, e.Value))
+		if templateEnv, exists := template.EnvironmentVariables()[e.Key]; exists {
+			value := e.Value
+			if templateEnv.Secret && !showSecrets {
+				value = util.RedactedValue
+			}
+			envs = append(envs, fmt.Sprintf("%s=%s", e.Key, value))
 		}
 	}
 	// TODO not used: match runtime port alias and local port (bound) from template
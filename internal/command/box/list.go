@@ -1,6 +1,7 @@
 package box
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/rs/zerolog/log"
@@ -40,14 +41,14 @@ func (opts *boxListCmdOptions) run(cmd *cobra.Command, args []string) error {
 
 	// silently fail attempting all the providers
 	for _, providerFlag := range boxFlag.BoxProviders() {
-		if err := listByProvider(providerFlag, opts.configRef, loader); err != nil {
+		if err := listByProvider(cmd.Context(), providerFlag, opts.configRef, loader); err != nil {
 			log.Warn().Err(err).Msgf("ignoring error list boxes: providerFlag=%v", providerFlag)
 		}
 	}
 	return nil
 }
 
-func listByProvider(providerFlag commonFlag.ProviderFlag, configRef *config.ConfigRef, loader *common.Loader) error {
+func listByProvider(ctx context.Context, providerFlag commonFlag.ProviderFlag, configRef *config.ConfigRef, loader *common.Loader) error {
 	log.Debug().Msgf("list boxes: providerFlag=%s", providerFlag)
 
 	provider, err := boxFlag.ToBoxProvider(providerFlag)
@@ -55,12 +56,12 @@ func listByProvider(providerFlag commonFlag.ProviderFlag, configRef *config.Conf
 		return fmt.Errorf("%s provider error", providerFlag)
 	}
 
-	boxClient, err := newDefaultBoxClient(provider, configRef, loader)
+	boxClient, err := newDefaultBoxClient(ctx, provider, configRef, loader, "")
 	if err != nil {
 		return err
 	}
 
-	boxes, err := boxClient.List()
+	boxes, err := boxClient.List(ctx)
 	if err != nil {
 		log.Warn().Err(err).Msgf("error listing boxes: provider=%v", boxClient.Provider())
 		return fmt.Errorf("%s list error", boxClient.Provider())
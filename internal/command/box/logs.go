@@ -0,0 +1,93 @@
+package box
+
+import (
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	commonFlag "github.com/hckops/hckctl/internal/command/common/flag"
+	"github.com/hckops/hckctl/internal/command/config"
+	boxModel "github.com/hckops/hckctl/pkg/box/model"
+	commonModel "github.com/hckops/hckctl/pkg/common/model"
+)
+
+type boxLogsCmdOptions struct {
+	configRef   *config.ConfigRef
+	followFlag  bool
+	sinceFlag   time.Duration
+	tailFlag    int
+	sidecarFlag string
+}
+
+func NewBoxLogsCmd(configRef *config.ConfigRef) *cobra.Command {
+
+	opts := &boxLogsCmdOptions{
+		configRef: configRef,
+	}
+
+	command := &cobra.Command{
+		Use:   "logs [name]",
+		Short: "Print the logs of a running box",
+		Example: heredoc.Doc(`
+
+			# prints all the logs of the main container
+			hckctl box logs box-alpine-<RANDOM>
+
+			# streams the last 100 lines of the last 10 minutes
+			hckctl box logs box-alpine-<RANDOM> --follow --since 10m --tail 100
+
+			# prints the logs of the vpn sidecar to debug the connection
+			hckctl box logs box-alpine-<RANDOM> --sidecar vpn
+		`),
+		Args:    cobra.ExactArgs(1),
+		PreRunE: opts.validate,
+		RunE:    opts.run,
+	}
+
+	const (
+		followFlagName   = "follow"
+		followFlagUsage  = "stream the logs until interrupted"
+		sinceFlagName    = "since"
+		sinceFlagUsage   = "show logs newer than a relative duration e.g. 10m"
+		tailFlagName     = "tail"
+		tailFlagUsage    = "number of lines from the end of the logs, negative for all"
+		sidecarFlagName  = "sidecar"
+		sidecarFlagUsage = "show logs of a sidecar instead of the main container e.g. vpn"
+	)
+	command.Flags().BoolVarP(&opts.followFlag, followFlagName, commonFlag.NoneFlagShortHand, false, followFlagUsage)
+	command.Flags().DurationVarP(&opts.sinceFlag, sinceFlagName, commonFlag.NoneFlagShortHand, 0, sinceFlagUsage)
+	command.Flags().IntVarP(&opts.tailFlag, tailFlagName, commonFlag.NoneFlagShortHand, -1, tailFlagUsage)
+	command.Flags().StringVarP(&opts.sidecarFlag, sidecarFlagName, commonFlag.NoneFlagShortHand, "", sidecarFlagUsage)
+
+	return command
+}
+
+func (opts *boxLogsCmdOptions) validate(cmd *cobra.Command, args []string) error {
+	if opts.sinceFlag < 0 {
+		return errors.New("invalid since duration")
+	}
+	return nil
+}
+
+func (opts *boxLogsCmdOptions) run(cmd *cobra.Command, args []string) error {
+	boxName := args[0]
+	log.Debug().Msgf("logs box: boxName=%s follow=%v since=%v tail=%d sidecar=%s",
+		boxName, opts.followFlag, opts.sinceFlag, opts.tailFlag, opts.sidecarFlag)
+
+	logsClient := func(invokeOpts *invokeOptions, _ *boxModel.BoxDetails) error {
+		logsOpts := &boxModel.LogsOptions{
+			Template:   &invokeOpts.template.Value.Data,
+			StreamOpts: commonModel.NewStdStreamOpts(false),
+			Name:       boxName,
+			Follow:     opts.followFlag,
+			Since:      opts.sinceFlag,
+			Tail:       opts.tailFlag,
+			Sidecar:    opts.sidecarFlag,
+		}
+		return invokeOpts.client.Logs(invokeOpts.ctx, logsOpts)
+	}
+	return attemptRunBoxClients(cmd.Context(), opts.configRef, boxName, logsClient)
+}
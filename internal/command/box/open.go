@@ -13,6 +13,7 @@ import (
 type boxOpenCmdOptions struct {
 	configRef  *config.ConfigRef
 	tunnelFlag *boxFlag.TunnelFlag
+	recordFlag bool
 }
 
 func NewBoxOpenCmd(configRef *config.ConfigRef) *cobra.Command {
@@ -30,6 +31,8 @@ func NewBoxOpenCmd(configRef *config.ConfigRef) *cobra.Command {
 
 	// --no-exec or --no-tunnel
 	opts.tunnelFlag = boxFlag.AddTunnelFlag(command)
+	// --record
+	commonFlag.AddRecordFlag(command, &opts.recordFlag)
 
 	return command
 }
@@ -37,6 +40,7 @@ func NewBoxOpenCmd(configRef *config.ConfigRef) *cobra.Command {
 func (opts *boxOpenCmdOptions) run(cmd *cobra.Command, args []string) error {
 	boxName := args[0]
 	log.Debug().Msgf("open box: boxName=%s", boxName)
+	record := commonFlag.ValidateRecordFlag(cmd, opts.recordFlag, opts.configRef.Config.Session.Record)
 
 	connectClient := func(invokeOpts *invokeOptions, _ *model.BoxDetails) error {
 
@@ -46,7 +50,7 @@ func (opts *boxOpenCmdOptions) run(cmd *cobra.Command, args []string) error {
 		}
 
 		connectOpts := opts.tunnelFlag.ToConnectOptions(&invokeOpts.template.Value.Data, boxName, false)
-		return invokeOpts.client.Connect(connectOpts)
+		return connectBox(invokeOpts.ctx, invokeOpts.client, connectOpts, opts.configRef, record)
 	}
-	return attemptRunBoxClients(opts.configRef, boxName, connectClient)
+	return attemptRunBoxClients(cmd.Context(), opts.configRef, boxName, connectClient)
 }
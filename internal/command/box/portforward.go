@@ -0,0 +1,121 @@
+package box
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	commonFlag "github.com/hckops/hckctl/internal/command/common/flag"
+	"github.com/hckops/hckctl/internal/command/config"
+	boxModel "github.com/hckops/hckctl/pkg/box/model"
+)
+
+type boxPortForwardCmdOptions struct {
+	configRef *config.ConfigRef
+	bindFlag  string
+	// internal
+	port boxModel.BoxPort
+}
+
+func NewBoxPortForwardCmd(configRef *config.ConfigRef) *cobra.Command {
+
+	opts := &boxPortForwardCmdOptions{
+		configRef: configRef,
+	}
+
+	command := &cobra.Command{
+		Use:   "port-forward [name] [LOCAL:REMOTE]",
+		Short: "Forward a local port to a running box until interrupted",
+		Long: heredoc.Doc(`
+			Forward a local port to a running box until interrupted
+
+			  Reach a service started inside the box after its creation, in addition to the
+			  ports defined in the template. Supported by the kube and cloud providers only,
+			  docker ports are published when the box is created.
+		`),
+		Example: heredoc.Doc(`
+
+			# forwards localhost:8080 to the port 80 of the box
+			hckctl box port-forward box-kali-<RANDOM> 8080:80
+
+			# forwards the same port on all interfaces
+			hckctl box port-forward box-kali-<RANDOM> 3000 --bind 0.0.0.0
+		`),
+		Args:    cobra.ExactArgs(2),
+		PreRunE: opts.validate,
+		RunE:    opts.run,
+	}
+
+	const (
+		bindFlagName  = "bind"
+		bindFlagUsage = "local address to listen on"
+	)
+	command.Flags().StringVarP(&opts.bindFlag, bindFlagName, commonFlag.NoneFlagShortHand, boxModel.DefaultBindAddress, bindFlagUsage)
+
+	return command
+}
+
+func (opts *boxPortForwardCmdOptions) validate(cmd *cobra.Command, args []string) error {
+	if port, err := boxModel.NewPortForward(args[1]); err != nil {
+		log.Warn().Err(err).Msgf("error port-forward argument: port=%s", args[1])
+		return errors.New("invalid port")
+	} else {
+		opts.port = port
+	}
+	if err := boxModel.ValidateBindAddress(opts.bindFlag); err != nil {
+		log.Warn().Err(err).Msgf("error port-forward flag: bind=%s", opts.bindFlag)
+		return errors.New("invalid bind address")
+	}
+	return nil
+}
+
+func (opts *boxPortForwardCmdOptions) run(cmd *cobra.Command, args []string) error {
+	boxName := args[0]
+	log.Debug().Msgf("port-forward box: boxName=%s local=%s remote=%s bind=%s",
+		boxName, opts.port.Local, opts.port.Remote, opts.bindFlag)
+
+	forwardDir, err := config.GetBoxForwardDir()
+	if err != nil {
+		return err
+	}
+	registry := newPortForwardRegistry(forwardDir)
+	pid := os.Getpid()
+
+	forwardClient := func(invokeOpts *invokeOptions, _ *boxModel.BoxDetails) error {
+		removeEntry := func() {
+			if err := registry.remove(pid); err != nil && !os.IsNotExist(errors.Cause(err)) {
+				log.Warn().Err(err).Msgf("error removing port-forward entry: pid=%d", pid)
+			}
+		}
+		// blocks until interrupted
+		defer removeEntry()
+
+		forwardOpts := &boxModel.PortForwardOptions{
+			Template:    &invokeOpts.template.Value.Data,
+			Name:        boxName,
+			Port:        opts.port,
+			BindAddress: opts.bindFlag,
+			OnPortBindCallback: func(address string) {
+				entry := &portForwardEntry{
+					BoxName:  boxName,
+					Provider: invokeOpts.client.Provider().String(),
+					Address:  address,
+					Remote:   opts.port.Remote,
+					Pid:      pid,
+					Created:  time.Now().UTC(),
+				}
+				if err := registry.add(entry); err != nil {
+					log.Warn().Err(err).Msgf("ignoring error port-forward entry: pid=%d", pid)
+				}
+				fmt.Printf("forwarding %s -> %s:%s\n", address, boxName, opts.port.Remote)
+			},
+		}
+		return invokeOpts.client.PortForward(invokeOpts.ctx, forwardOpts)
+	}
+	return attemptRunBoxClients(cmd.Context(), opts.configRef, boxName, forwardClient)
+}
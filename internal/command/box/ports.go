@@ -0,0 +1,69 @@
+package box
+
+import (
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/hckops/hckctl/internal/command/config"
+	boxModel "github.com/hckops/hckctl/pkg/box/model"
+)
+
+type boxPortsCmdOptions struct {
+	configRef *config.ConfigRef
+}
+
+func NewBoxPortsCmd(configRef *config.ConfigRef) *cobra.Command {
+
+	opts := &boxPortsCmdOptions{
+		configRef: configRef,
+	}
+
+	command := &cobra.Command{
+		Use:   "ports [name]",
+		Short: "List the active local bindings of a running box",
+		Args:  cobra.ExactArgs(1),
+		RunE:  opts.run,
+	}
+
+	return command
+}
+
+func (opts *boxPortsCmdOptions) run(cmd *cobra.Command, args []string) error {
+	boxName := args[0]
+	log.Debug().Msgf("ports box: boxName=%s", boxName)
+
+	forwardDir, err := config.GetBoxForwardDir()
+	if err != nil {
+		return err
+	}
+	entries, err := newPortForwardRegistry(forwardDir).list(boxName)
+	if err != nil {
+		return err
+	}
+
+	portsClient := func(invokeOpts *invokeOptions, boxDetails *boxModel.BoxDetails) error {
+		invokeOpts.loader.Stop()
+
+		var total int
+		// docker ports are published on the host when the box is created
+		if boxDetails.ProviderInfo.Provider == boxModel.Docker {
+			templatePorts := invokeOpts.template.Value.Data.NetworkPorts(true)
+			for _, port := range boxModel.SortPorts(boxDetails.Ports) {
+				if templatePort, ok := templatePorts[port.Remote]; ok {
+					port.Alias = templatePort.Alias
+				}
+				fmt.Println(fmt.Sprintf("%s/%s -> %s", port.Alias, port.Remote, port.Local))
+				total++
+			}
+		}
+		for _, entry := range entries {
+			fmt.Println(fmt.Sprintf("%s/%s -> %s (pid %d)", boxModel.BoxPortForwardAlias, entry.Remote, entry.Address, entry.Pid))
+			total++
+		}
+		fmt.Println(fmt.Sprintf("total: %d", total))
+		return nil
+	}
+	return attemptRunBoxClients(cmd.Context(), opts.configRef, boxName, portsClient)
+}
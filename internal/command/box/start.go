@@ -1,6 +1,7 @@
 package box
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/pkg/errors"
@@ -19,6 +20,7 @@ import (
 type boxStartCmdOptions struct {
 	configRef *config.ConfigRef
 	// flags
+	ignoreScopeFlag    bool
 	networkVpnFlag     string
 	providerFlag       *commonFlag.ProviderFlag
 	templateSourceFlag *commonFlag.TemplateSourceFlag
@@ -46,6 +48,8 @@ func NewBoxStartCmd(configRef *config.ConfigRef) *cobra.Command {
 	opts.providerFlag = boxFlag.AddBoxProviderFlag(command)
 	// --revision or --local
 	opts.templateSourceFlag = commonFlag.AddTemplateSourceFlag(command)
+	// --ignore-scope
+	commonFlag.AddIgnoreScopeFlag(command, &opts.ignoreScopeFlag)
 
 	return command
 }
@@ -78,7 +82,7 @@ func (opts *boxStartCmdOptions) run(cmd *cobra.Command, args []string) error {
 		log.Debug().Msgf("start box from local template: path=%s", path)
 
 		sourceLoader := template.NewLocalCachedLoader[boxModel.BoxV1](path, opts.configRef.Config.Template.CacheDir)
-		return opts.startBox(sourceLoader, boxModel.NewBoxLabels().AddDefaultLocal())
+		return opts.startBox(cmd.Context(), sourceLoader, boxModel.NewBoxLabels().AddDefaultLocal())
 
 	} else {
 		name := args[0]
@@ -87,11 +91,16 @@ func (opts *boxStartCmdOptions) run(cmd *cobra.Command, args []string) error {
 		sourceOpts := commonCmd.NewGitSourceOptions(opts.configRef.Config.Template.CacheDir, opts.templateSourceFlag.Revision)
 		sourceLoader := template.NewGitLoader[boxModel.BoxV1](sourceOpts, name)
 		labels := boxModel.NewBoxLabels().AddDefaultGit(sourceOpts.RepositoryUrl, sourceOpts.DefaultRevision, sourceOpts.CacheDirName())
-		return opts.startBox(sourceLoader, labels)
+		return opts.startBox(cmd.Context(), sourceLoader, labels)
 	}
 }
 
-func (opts *boxStartCmdOptions) startBox(sourceLoader template.SourceLoader[boxModel.BoxV1], labels commonModel.Labels) error {
+func (opts *boxStartCmdOptions) startBox(ctx context.Context, sourceLoader template.SourceLoader[boxModel.BoxV1], labels commonModel.Labels) error {
+
+	// boxes don't have targets, only the engagement window is enforced
+	if _, err := commonCmd.LoadScope(opts.configRef.Config.Scope.Path, opts.ignoreScopeFlag); err != nil {
+		return err
+	}
 
 	createClient := func(invokeOpts *invokeOptions) error {
 
@@ -99,13 +108,14 @@ func (opts *boxStartCmdOptions) startBox(sourceLoader template.SourceLoader[boxM
 		if err != nil {
 			return err
 		}
-		if boxInfo, err := invokeOpts.client.Create(createOpts); err != nil {
+		if boxInfo, err := invokeOpts.client.Create(invokeOpts.ctx, createOpts); err != nil {
 			return err
 		} else {
+			auditBoxCreate(opts.configRef, invokeOpts, createOpts, boxInfo.Name)
 			invokeOpts.loader.Stop()
 			fmt.Println(boxInfo.Name)
 		}
 		return nil
 	}
-	return runBoxClient(sourceLoader, opts.provider, opts.configRef, createClient)
+	return runBoxClient(ctx, sourceLoader, opts.provider, opts.configRef, createClient)
 }
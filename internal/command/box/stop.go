@@ -1,15 +1,18 @@
 package box
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 
+	auditCmd "github.com/hckops/hckctl/internal/command/audit"
 	boxFlag "github.com/hckops/hckctl/internal/command/box/flag"
 	"github.com/hckops/hckctl/internal/command/common"
 	commonFlag "github.com/hckops/hckctl/internal/command/common/flag"
 	"github.com/hckops/hckctl/internal/command/config"
+	"github.com/hckops/hckctl/pkg/audit"
 	"github.com/hckops/hckctl/pkg/box/model"
 	"github.com/hckops/hckctl/pkg/template"
 )
@@ -50,7 +53,7 @@ func (opts *boxStopCmdOptions) run(cmd *cobra.Command, args []string) error {
 
 		// silently fail attempting all the providers
 		for _, providerFlag := range boxFlag.BoxProviders() {
-			if err := stopByProvider(providerFlag, opts.configRef, loader); err != nil {
+			if err := stopByProvider(cmd.Context(), providerFlag, opts.configRef, loader); err != nil {
 				log.Warn().Err(err).Msgf("ignoring error stopping boxes: providerFlag=%s", providerFlag)
 			}
 		}
@@ -68,12 +71,13 @@ func (opts *boxStopCmdOptions) run(cmd *cobra.Command, args []string) error {
 
 		deleteClient := func(invokeOpts *invokeOptions, boxDetails *model.BoxDetails) error {
 
-			if result, err := invokeOpts.client.Delete([]string{boxName}); err != nil {
+			if result, err := invokeOpts.client.Delete(invokeOpts.ctx, []string{boxName}); err != nil {
 				return err
 			} else if len(result) == 0 {
 				// attempt next provider
 				return fmt.Errorf("box not found: boxName=%s", boxName)
 			}
+			auditCmd.Record(opts.configRef, newBoxAuditRecord(audit.BoxDeleteAction, invokeOpts.client.Provider(), boxName))
 			invokeOpts.loader.Stop()
 			fmt.Println(boxName)
 
@@ -84,7 +88,7 @@ func (opts *boxStopCmdOptions) run(cmd *cobra.Command, args []string) error {
 			}
 			return nil
 		}
-		return attemptRunBoxClients(opts.configRef, boxName, deleteClient)
+		return attemptRunBoxClients(cmd.Context(), opts.configRef, boxName, deleteClient)
 
 	} else {
 		cmd.HelpFunc()(cmd, args)
@@ -92,7 +96,7 @@ func (opts *boxStopCmdOptions) run(cmd *cobra.Command, args []string) error {
 	}
 }
 
-func stopByProvider(providerFlag commonFlag.ProviderFlag, configRef *config.ConfigRef, loader *common.Loader) error {
+func stopByProvider(ctx context.Context, providerFlag commonFlag.ProviderFlag, configRef *config.ConfigRef, loader *common.Loader) error {
 	log.Debug().Msgf("stop boxes: providerFlag=%s", providerFlag)
 
 	provider, err := boxFlag.ToBoxProvider(providerFlag)
@@ -100,15 +104,18 @@ func stopByProvider(providerFlag commonFlag.ProviderFlag, configRef *config.Conf
 		return fmt.Errorf("%s provider error", providerFlag)
 	}
 
-	boxClient, err := newDefaultBoxClient(provider, configRef, loader)
+	boxClient, err := newDefaultBoxClient(ctx, provider, configRef, loader, "")
 	if err != nil {
 		return err
 	}
 
-	names, err := boxClient.Delete([]string{})
+	names, err := boxClient.Delete(ctx, []string{})
 	if err != nil {
 		return fmt.Errorf("%s delete error", boxClient.Provider())
 	}
+	for _, name := range names {
+		auditCmd.Record(configRef, newBoxAuditRecord(audit.BoxDeleteAction, boxClient.Provider(), name))
+	}
 
 	loader.Reload()
 	fmt.Println(fmt.Sprintf("# %s", boxClient.Provider()))
@@ -0,0 +1,14 @@
+package common
+
+import (
+	"fmt"
+)
+
+// ExitCodeError propagates the exit code of a remote command without printing any message
+type ExitCodeError struct {
+	Code int
+}
+
+func (e *ExitCodeError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
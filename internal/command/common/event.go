@@ -6,26 +6,39 @@ import (
 	"github.com/rs/zerolog/log"
 
 	"github.com/hckops/hckctl/pkg/event"
+	"github.com/hckops/hckctl/pkg/util"
 )
 
-func EventCallback(loader *Loader) func(e event.Event) {
+// SubscribeEvents updates the loader and logs the events with independent subscribers
+func SubscribeEvents(eventBus *event.EventBus, loader *Loader) {
+	eventBus.Subscribe(ConsoleEventCallback(loader), event.KindFilter(event.PrintConsole, event.LoaderUpdate, event.LoaderStop))
+	eventBus.Subscribe(LogEventCallback, event.KindFilter(event.LogDebug, event.LogInfo, event.LogWarning, event.LogError))
+}
+
+// ConsoleEventCallback masks any secret before printing the events
+func ConsoleEventCallback(loader *Loader) func(e event.Event) {
 	return func(e event.Event) {
 		switch e.Kind() {
 		case event.PrintConsole:
 			loader.Refresh("loading")
-			fmt.Println(e.String())
+			fmt.Println(util.RedactSecrets(e.String()))
 		case event.LoaderUpdate:
 			loader.Refresh(e.String())
 		case event.LoaderStop:
 			loader.Stop()
-		case event.LogInfo:
-			log.Info().Msgf("[%v] %s", e.Source(), e.String())
-		case event.LogWarning:
-			log.Warn().Msgf("[%v] %s", e.Source(), e.String())
-		case event.LogError:
-			log.Error().Msgf("[%v] %s", e.Source(), e.String())
-		default:
-			log.Debug().Msgf("[%v] %s", e.Source(), e.String())
 		}
 	}
 }
+
+func LogEventCallback(e event.Event) {
+	switch e.Kind() {
+	case event.LogInfo:
+		log.Info().Msgf("[%v] %s", e.Source(), e.String())
+	case event.LogWarning:
+		log.Warn().Msgf("[%v] %s", e.Source(), e.String())
+	case event.LogError:
+		log.Error().Msgf("[%v] %s", e.Source(), e.String())
+	default:
+		log.Debug().Msgf("[%v] %s", e.Source(), e.String())
+	}
+}
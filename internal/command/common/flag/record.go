@@ -0,0 +1,25 @@
+package flag
+
+import (
+	"github.com/spf13/cobra"
+)
+
+const (
+	RecordFlagName = "record"
+)
+
+func AddRecordFlag(command *cobra.Command, value *bool) string {
+	const (
+		flagUsage = "record the session in asciicast format, defaults to session.record config"
+	)
+	command.Flags().BoolVarP(value, RecordFlagName, NoneFlagShortHand, false, flagUsage)
+	return RecordFlagName
+}
+
+// ValidateRecordFlag returns the config default unless the flag is explicitly set e.g. --record=false
+func ValidateRecordFlag(command *cobra.Command, value bool, configValue bool) bool {
+	if command.Flags().Changed(RecordFlagName) {
+		return value
+	}
+	return configValue
+}
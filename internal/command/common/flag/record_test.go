@@ -0,0 +1,20 @@
+package flag
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateRecordFlag(t *testing.T) {
+	var record bool
+	command := &cobra.Command{}
+	AddRecordFlag(command, &record)
+
+	assert.True(t, ValidateRecordFlag(command, record, true))
+	assert.False(t, ValidateRecordFlag(command, record, false))
+
+	command.Flags().Set(RecordFlagName, "false")
+	assert.False(t, ValidateRecordFlag(command, record, true))
+}
@@ -0,0 +1,17 @@
+package flag
+
+import (
+	"github.com/spf13/cobra"
+)
+
+const (
+	IgnoreScopeFlagName = "ignore-scope"
+)
+
+func AddIgnoreScopeFlag(command *cobra.Command, value *bool) string {
+	const (
+		flagUsage = "run even if the targets or the time are outside the engagement scope, the override is logged"
+	)
+	command.Flags().BoolVarP(value, IgnoreScopeFlagName, NoneFlagShortHand, false, flagUsage)
+	return IgnoreScopeFlagName
+}
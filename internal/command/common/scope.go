@@ -0,0 +1,57 @@
+package common
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/hckops/hckctl/pkg/scope"
+)
+
+const (
+	scopeOverrideHint = "use --ignore-scope to override"
+)
+
+// LoadScope returns nil if there isn't any engagement scope, when the path is empty the scope file
+// is discovered in the working directory or its parents. The time window is validated immediately
+func LoadScope(scopePath string, ignoreScope bool) (*scope.ScopeV1, error) {
+	if scopePath == "" {
+		if workingDir, err := os.Getwd(); err == nil {
+			scopePath, _ = scope.FindScopeFile(workingDir)
+		}
+	}
+	if scopePath == "" {
+		log.Debug().Msg("engagement scope not found")
+		return nil, nil
+	}
+	if ignoreScope {
+		log.Warn().Msgf("engagement scope ignored: path=%s", scopePath)
+		return nil, nil
+	}
+
+	targetScope, err := scope.LoadScope(scopePath)
+	if err != nil {
+		log.Warn().Err(err).Msg("error loading scope")
+		return nil, errors.New("invalid scope")
+	}
+	log.Info().Msgf("loading engagement scope: name=%s path=%s", targetScope.Name, scopePath)
+
+	if err := targetScope.ValidateWindow(time.Now()); err != nil {
+		log.Warn().Err(err).Msg("engagement window refused")
+		return nil, fmt.Errorf("%v, %s", err, scopeOverrideHint)
+	}
+	return targetScope, nil
+}
+
+// ScopeError adds the override hint to the targets refused by the scope
+func ScopeError(err error) error {
+	var scopeErr *scope.ScopeError
+	if errors.As(err, &scopeErr) {
+		log.Warn().Err(err).Msg("engagement scope refused")
+		return fmt.Errorf("%v, %s", err, scopeOverrideHint)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package common
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/hckops/hckctl/pkg/event"
+	"github.com/hckops/hckctl/pkg/util"
+)
+
+const (
+	EventsFormatJsonl  = "jsonl"
+	EventsOutputStderr = "stderr"
+	EventsOutputStdout = "stdout"
+	eventsOutputDash   = "-" // shorthand for stdout
+	unixSocketPrefix   = "unix://"
+	tcpSocketPrefix    = "tcp://"
+)
+
+// EventStream writes the events of all the clients of a command, it's disabled if nil
+type EventStream struct {
+	writer *event.JsonlWriter
+	closer io.Closer
+}
+
+// OpenEventStream supports "stderr", "stdout" or "-", a file path, "unix:///path/to/socket" or "tcp://host:port".
+// Stdout is used only if explicitly requested, it's shared with the console messages and the output of boxes and tasks
+func OpenEventStream(format string, output string) (*EventStream, error) {
+	if format == "" {
+		return nil, nil
+	}
+	if format != EventsFormatJsonl {
+		return nil, fmt.Errorf("invalid events format %s", format)
+	}
+
+	var writer io.Writer
+	var closer io.Closer
+	switch {
+	case output == "" || output == EventsOutputStderr:
+		writer = os.Stderr
+	case output == EventsOutputStdout || output == eventsOutputDash:
+		writer = os.Stdout
+	case strings.HasPrefix(output, unixSocketPrefix):
+		conn, err := net.Dial("unix", strings.TrimPrefix(output, unixSocketPrefix))
+		if err != nil {
+			return nil, errors.Wrapf(err, "error events socket: output=%s", output)
+		}
+		writer, closer = conn, conn
+	case strings.HasPrefix(output, tcpSocketPrefix):
+		conn, err := net.Dial("tcp", strings.TrimPrefix(output, tcpSocketPrefix))
+		if err != nil {
+			return nil, errors.Wrapf(err, "error events socket: output=%s", output)
+		}
+		writer, closer = conn, conn
+	default:
+		if err := util.CreateDir(filepath.Dir(output)); err != nil {
+			return nil, err
+		}
+		file, err := os.OpenFile(output, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error events file: output=%s", output)
+		}
+		writer, closer = file, file
+	}
+	return &EventStream{writer: event.NewJsonlWriter(writer), closer: closer}, nil
+}
+
+// Subscribe is a noop if the stream is disabled
+func (s *EventStream) Subscribe(eventBus *event.EventBus, provider string, name string) {
+	if s == nil {
+		return
+	}
+	s.writer.Subscribe(eventBus, event.JsonlAttributes{Provider: provider, Name: name})
+}
+
+func (s *EventStream) Close() error {
+	if s == nil || s.closer == nil {
+		return nil
+	}
+	return s.closer.Close()
+}
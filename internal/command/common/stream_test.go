@@ -0,0 +1,104 @@
+package common
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hckops/hckctl/pkg/event"
+)
+
+type testEvent struct{}
+
+func (e *testEvent) Kind() event.EventKind { return event.LogInfo }
+func (e *testEvent) Source() string        { return "test" }
+func (e *testEvent) String() string        { return "my-message" }
+
+func TestOpenEventStreamDisabled(t *testing.T) {
+	stream, err := OpenEventStream("", EventsOutputStderr)
+	assert.NoError(t, err)
+	assert.Nil(t, stream)
+
+	// noop
+	stream.Subscribe(event.NewEventBus(), "docker", "")
+	assert.NoError(t, stream.Close())
+
+	_, err = OpenEventStream("xml", EventsOutputStderr)
+	assert.EqualError(t, err, "invalid events format xml")
+}
+
+func TestOpenEventStreamStdout(t *testing.T) {
+	for _, output := range []string{EventsOutputStdout, "-"} {
+		reader, writer, err := os.Pipe()
+		require.NoError(t, err)
+		stdout := os.Stdout
+		os.Stdout = writer
+
+		stream, err := OpenEventStream(EventsFormatJsonl, output)
+		os.Stdout = stdout
+		require.NoError(t, err)
+
+		bus := event.NewEventBus()
+		stream.Subscribe(bus, "docker", "")
+		bus.Publish(&testEvent{})
+		bus.Flush()
+		// never closes stdout
+		assert.NoError(t, stream.Close())
+		writer.Close()
+
+		line, err := bufio.NewReader(reader).ReadString('\n')
+		assert.NoError(t, err)
+		assert.Contains(t, line, `"provider":"docker","message":"my-message"`)
+		reader.Close()
+	}
+}
+
+func TestOpenEventStreamFile(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "events", "hckctl.jsonl")
+	stream, err := OpenEventStream(EventsFormatJsonl, output)
+	require.NoError(t, err)
+
+	bus := event.NewEventBus()
+	stream.Subscribe(bus, "docker", "box-alpine-abc")
+	bus.Publish(&testEvent{})
+	bus.Flush()
+	assert.NoError(t, stream.Close())
+
+	data, err := os.ReadFile(output)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"provider":"docker","name":"box-alpine-abc","message":"my-message"`)
+}
+
+func TestOpenEventStreamSocket(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	lines := make(chan string, 1)
+	go func() {
+		if conn, err := listener.Accept(); err == nil {
+			defer conn.Close()
+			line, _ := bufio.NewReader(conn).ReadString('\n')
+			lines <- line
+		}
+	}()
+
+	stream, err := OpenEventStream(EventsFormatJsonl, "tcp://"+listener.Addr().String())
+	require.NoError(t, err)
+	defer stream.Close()
+
+	bus := event.NewEventBus()
+	stream.Subscribe(bus, "kube", "")
+	bus.Publish(&testEvent{})
+	bus.Flush()
+
+	line := <-lines
+	assert.True(t, strings.HasPrefix(line, `{"version":1,`))
+	assert.Contains(t, line, `"source":"test","provider":"kube","message":"my-message"`)
+}
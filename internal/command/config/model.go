@@ -3,13 +3,18 @@ package config
 import (
 	"fmt"
 	"net"
+	"path/filepath"
 	"strconv"
 
+	"github.com/pkg/errors"
+
 	"github.com/hckops/hckctl/internal/command/common"
 	boxModel "github.com/hckops/hckctl/pkg/box/model"
+	"github.com/hckops/hckctl/pkg/client/terminal"
 	commonModel "github.com/hckops/hckctl/pkg/common/model"
 	"github.com/hckops/hckctl/pkg/logger"
 	"github.com/hckops/hckctl/pkg/schema"
+	"github.com/hckops/hckctl/pkg/secret"
 	taskModel "github.com/hckops/hckctl/pkg/task/model"
 	"github.com/hckops/hckctl/pkg/util"
 )
@@ -18,7 +23,9 @@ import (
 // It's used to reference the config value in the commands
 // before they are actually loaded with viper in each PersistentPreRunE.
 type ConfigRef struct {
-	Config *ConfigV1
+	Config      *ConfigV1
+	EventStream *common.EventStream // nil unless enabled with --events
+	secretStore *secret.Store       // unlocked lazily
 }
 
 // TODO not used, useful for migrations
@@ -34,6 +41,10 @@ type ConfigV1 struct {
 	Network  NetworkConfig  `yaml:"network"`
 	Template TemplateConfig `yaml:"template"`
 	Common   CommonConfig   `yaml:"common"`
+	Session  SessionConfig  `yaml:"session"`
+	Scope    ScopeConfig    `yaml:"scope"`
+	Audit    AuditConfig    `yaml:"audit"`
+	Secret   SecretConfig   `yaml:"secret"`
 	Box      BoxConfig      `yaml:"box"`
 	Task     TaskConfig     `yaml:"task"`
 }
@@ -74,23 +85,45 @@ func (c *KubeConfig) ToKubeOptions() *commonModel.KubeOptions {
 }
 
 type CloudConfig struct {
-	Host     string `yaml:"host"`
-	Port     int    `yaml:"port"`
-	Username string `yaml:"username"`
-	Token    string `yaml:"token"`
+	Host           string `yaml:"host"`
+	Port           int    `yaml:"port"`
+	Username       string `yaml:"username"`
+	Token          string `yaml:"token"` // supports "secret:<name>"
+	PrivateKeyPath string `yaml:"privateKeyPath"`
+	Agent          bool   `yaml:"agent"`
+	KnownHostsPath string `yaml:"knownHostsPath"`
+	Fingerprint    string `yaml:"fingerprint"` // pinned SHA256 host key, overrides knownHostsPath
 }
 
 func (c *CloudConfig) address() string {
 	return net.JoinHostPort(c.Host, strconv.Itoa(c.Port))
 }
 
-func (c *CloudConfig) ToCloudOptions(version string) *commonModel.CloudOptions {
-	return &commonModel.CloudOptions{
-		Version:  version,
-		Address:  c.address(),
-		Username: c.Username,
-		Token:    c.Token,
+// ToCloudOptions resolves the token if it references a secret,
+// configs created before host key verification default to the known hosts in the config dir
+func (c *CloudConfig) ToCloudOptions(version string, lookup secret.Lookup) (*commonModel.CloudOptions, error) {
+	token, err := secret.Resolve(c.Token, lookup)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid cloud token")
+	}
+	knownHostsPath := c.KnownHostsPath
+	if knownHostsPath == "" {
+		configDir, err := getConfigDir()
+		if err != nil {
+			return nil, err
+		}
+		knownHostsPath = filepath.Join(configDir, knownHostsName)
 	}
+	return &commonModel.CloudOptions{
+		Version:        version,
+		Address:        c.address(),
+		Username:       c.Username,
+		Token:          token,
+		PrivateKeyPath: c.PrivateKeyPath,
+		UseAgent:       c.Agent,
+		KnownHostsPath: knownHostsPath,
+		Fingerprint:    c.Fingerprint,
+	}, nil
 }
 
 type NetworkConfig struct {
@@ -100,14 +133,22 @@ type NetworkConfig struct {
 
 type VpnConfig struct {
 	Name string `yaml:"name"`
-	Path string `yaml:"path"`
+	Path string `yaml:"path"` // supports "secret:<name>"
 }
 
+// VpnNetworks doesn't resolve the secrets, the config value is empty until ToNetworkVpnInfo
 func (c *NetworkConfig) VpnNetworks() map[string]commonModel.NetworkVpnInfo {
 	info := map[string]commonModel.NetworkVpnInfo{}
 	for _, network := range c.Vpn {
-		// ignores invalid paths
-		if configFile, err := util.ReadFile(network.Path); err == nil {
+		if _, ok := secret.ParseReference(network.Path); ok {
+			info[network.Name] = commonModel.NetworkVpnInfo{
+				Name:       network.Name,
+				LocalPath:  network.Path,
+				Privileged: c.Privileged,
+				IsSecret:   true,
+			}
+		} else if configFile, err := util.ReadFile(network.Path); err == nil {
+			// ignores invalid paths
 			info[network.Name] = commonModel.NetworkVpnInfo{
 				Name:        network.Name,
 				LocalPath:   network.Path,
@@ -119,9 +160,16 @@ func (c *NetworkConfig) VpnNetworks() map[string]commonModel.NetworkVpnInfo {
 	return info
 }
 
-func (c *NetworkConfig) ToNetworkVpnInfo(vpnName string) (*commonModel.NetworkVpnInfo, error) {
+func (c *NetworkConfig) ToNetworkVpnInfo(vpnName string, lookup secret.Lookup) (*commonModel.NetworkVpnInfo, error) {
 	if vpnName != "" {
 		if vpnNetworkInfo, ok := c.VpnNetworks()[vpnName]; ok {
+			if vpnNetworkInfo.IsSecret {
+				configValue, err := secret.Resolve(vpnNetworkInfo.LocalPath, lookup)
+				if err != nil {
+					return nil, errors.Wrapf(err, "invalid vpn config name=%s", vpnName)
+				}
+				vpnNetworkInfo.ConfigValue = configValue
+			}
 			return &vpnNetworkInfo, nil
 		} else {
 			return nil, fmt.Errorf("vpn not found name=%s", vpnName)
@@ -139,17 +187,37 @@ type CommonConfig struct {
 	ShareDir string `yaml:"shareDir"`
 }
 
-func (c *CommonConfig) ToShareDirInfo(lockDir bool) *commonModel.ShareDirInfo {
+func (c *CommonConfig) ToShareDirInfo(lockDir bool, outputDir bool) *commonModel.ShareDirInfo {
 	return &commonModel.ShareDirInfo{
 		LocalPath:  c.ShareDir,
 		RemotePath: commonModel.SidecarShareDir,
 		LockDir:    lockDir,
+		OutputDir:  outputDir,
 	}
 }
 
+type SessionConfig struct {
+	Record bool   `yaml:"record"` // default of the record flag
+	Dir    string `yaml:"dir"`
+}
+
+type ScopeConfig struct {
+	Path string `yaml:"path"` // discovered in the working directory if empty
+}
+
+type AuditConfig struct {
+	Enabled  bool   `yaml:"enabled"`
+	FilePath string `yaml:"filePath"` // hash-chained jsonl
+}
+
+type SecretConfig struct {
+	Path string `yaml:"path"` // encrypted store
+}
+
 type BoxConfig struct {
-	Provider string `yaml:"provider"`
-	Size     string `yaml:"size"`
+	Provider   string `yaml:"provider"`
+	Size       string `yaml:"size"`
+	DetachKeys string `yaml:"detachKeys"` // leaves the shell without deleting the box
 }
 
 type TaskConfig struct {
@@ -158,10 +226,14 @@ type TaskConfig struct {
 }
 
 type configOptions struct {
-	logFile    string
-	cacheDir   string
-	shareDir   string
-	taskLogDir string
+	logFile        string
+	cacheDir       string
+	shareDir       string
+	taskLogDir     string
+	sessionDir     string
+	auditFile      string
+	secretFile     string
+	knownHostsFile string
 }
 
 func newConfig(opts *configOptions) *ConfigV1 {
@@ -181,10 +253,14 @@ func newConfig(opts *configOptions) *ConfigV1 {
 				ConfigPath: "",
 			},
 			Cloud: CloudConfig{
-				Host:     "0.0.0.0",
-				Port:     2222,
-				Username: "",
-				Token:    "",
+				Host:           "0.0.0.0",
+				Port:           2222,
+				Username:       "",
+				Token:          "",
+				PrivateKeyPath: "",
+				Agent:          false,
+				KnownHostsPath: opts.knownHostsFile,
+				Fingerprint:    "",
 			},
 		},
 		Network: NetworkConfig{
@@ -200,9 +276,24 @@ func newConfig(opts *configOptions) *ConfigV1 {
 		Common: CommonConfig{
 			ShareDir: opts.shareDir,
 		},
+		Session: SessionConfig{
+			Record: false,
+			Dir:    opts.sessionDir,
+		},
+		Scope: ScopeConfig{
+			Path: "",
+		},
+		Audit: AuditConfig{
+			Enabled:  true,
+			FilePath: opts.auditFile,
+		},
+		Secret: SecretConfig{
+			Path: opts.secretFile,
+		},
 		Box: BoxConfig{
-			Provider: boxModel.Docker.String(),
-			Size:     boxModel.Small.String(),
+			Provider:   boxModel.Docker.String(),
+			Size:       boxModel.Small.String(),
+			DetachKeys: terminal.DefaultDetachKeys,
 		},
 
 		Task: TaskConfig{
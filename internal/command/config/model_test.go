@@ -1,6 +1,8 @@
 package config
 
 import (
+	"errors"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -12,10 +14,14 @@ import (
 func TestNewConfig(t *testing.T) {
 
 	configOpts := &configOptions{
-		logFile:    "/tmp/example.log",
-		cacheDir:   "/tmp/cache/",
-		shareDir:   "/tmp/share/",
-		taskLogDir: "/tmp/task/log/",
+		logFile:        "/tmp/example.log",
+		cacheDir:       "/tmp/cache/",
+		shareDir:       "/tmp/share/",
+		taskLogDir:     "/tmp/task/log/",
+		sessionDir:     "/tmp/session/",
+		auditFile:      "/tmp/audit/audit.jsonl",
+		secretFile:     "/tmp/config/secrets.json",
+		knownHostsFile: "/tmp/config/known_hosts",
 	}
 
 	expected := &ConfigV1{
@@ -34,10 +40,14 @@ func TestNewConfig(t *testing.T) {
 				ConfigPath: "",
 			},
 			Cloud: CloudConfig{
-				Host:     "0.0.0.0",
-				Port:     2222,
-				Username: "",
-				Token:    "",
+				Host:           "0.0.0.0",
+				Port:           2222,
+				Username:       "",
+				Token:          "",
+				PrivateKeyPath: "",
+				Agent:          false,
+				KnownHostsPath: "/tmp/config/known_hosts",
+				Fingerprint:    "",
 			},
 		},
 		Network: NetworkConfig{
@@ -53,9 +63,24 @@ func TestNewConfig(t *testing.T) {
 		Common: CommonConfig{
 			ShareDir: "/tmp/share/",
 		},
+		Session: SessionConfig{
+			Record: false,
+			Dir:    "/tmp/session/",
+		},
+		Scope: ScopeConfig{
+			Path: "",
+		},
+		Audit: AuditConfig{
+			Enabled:  true,
+			FilePath: "/tmp/audit/audit.jsonl",
+		},
+		Secret: SecretConfig{
+			Path: "/tmp/config/secrets.json",
+		},
 		Box: BoxConfig{
-			Provider: "docker",
-			Size:     "S",
+			Provider:   "docker",
+			Size:       "S",
+			DetachKeys: "ctrl-p,ctrl-q",
 		},
 		Task: TaskConfig{
 			Provider: "docker",
@@ -93,18 +118,60 @@ func TestToKubeOptions(t *testing.T) {
 
 func TestToCloudOptions(t *testing.T) {
 	cloudConfig := &CloudConfig{
-		Host:     "0.0.0.0",
-		Port:     2222,
-		Username: "myUsername",
-		Token:    "myToken",
+		Host:           "0.0.0.0",
+		Port:           2222,
+		Username:       "myUsername",
+		Token:          "myToken",
+		PrivateKeyPath: "/tmp/id_ed25519",
+		Agent:          true,
+		KnownHostsPath: "/tmp/known_hosts",
+		Fingerprint:    "SHA256:myFingerprint",
 	}
 	expected := &model.CloudOptions{
-		Version:  "hckctl-dev",
-		Address:  "0.0.0.0:2222",
-		Username: "myUsername",
-		Token:    "myToken",
+		Version:        "hckctl-dev",
+		Address:        "0.0.0.0:2222",
+		Username:       "myUsername",
+		Token:          "myToken",
+		PrivateKeyPath: "/tmp/id_ed25519",
+		UseAgent:       true,
+		KnownHostsPath: "/tmp/known_hosts",
+		Fingerprint:    "SHA256:myFingerprint",
+	}
+	cloudOptions, err := cloudConfig.ToCloudOptions("hckctl-dev", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, cloudOptions)
+}
+
+func TestToCloudOptionsDefaultKnownHosts(t *testing.T) {
+	configDir := t.TempDir()
+	t.Setenv(configDirEnv, configDir)
+
+	// configs created before the known hosts option
+	cloudConfig := &CloudConfig{Host: "0.0.0.0", Port: 2222}
+	cloudOptions, err := cloudConfig.ToCloudOptions("hckctl-dev", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(configDir, "known_hosts"), cloudOptions.KnownHostsPath)
+}
+
+func TestToCloudOptionsSecret(t *testing.T) {
+	cloudConfig := &CloudConfig{
+		Host:  "0.0.0.0",
+		Port:  2222,
+		Token: "secret:cloud-token",
+	}
+	lookup := func(name string) (string, error) {
+		if name == "cloud-token" {
+			return "myToken", nil
+		}
+		return "", errors.New("not found")
 	}
-	assert.Equal(t, expected, cloudConfig.ToCloudOptions("hckctl-dev"))
+	cloudOptions, err := cloudConfig.ToCloudOptions("hckctl-dev", lookup)
+	assert.NoError(t, err)
+	assert.Equal(t, "myToken", cloudOptions.Token)
+
+	cloudConfig.Token = "secret:other"
+	_, err = cloudConfig.ToCloudOptions("hckctl-dev", lookup)
+	assert.EqualError(t, err, "invalid cloud token: not found")
 }
 
 func TestVpnNetworks(t *testing.T) {
@@ -117,6 +184,37 @@ func TestVpnNetworks(t *testing.T) {
 	assert.Equal(t, 2, len(networkConfig.VpnNetworks()))
 }
 
+func TestVpnNetworksSecret(t *testing.T) {
+	networkConfig := NetworkConfig{
+		Vpn: []VpnConfig{
+			{Name: "htb", Path: "secret:htb-vpn"},
+		},
+	}
+	lookup := func(name string) (string, error) {
+		if name == "htb-vpn" {
+			return "client", nil
+		}
+		return "", errors.New("not found")
+	}
+
+	// not resolved
+	assert.Equal(t, "", networkConfig.VpnNetworks()["htb"].ConfigValue)
+
+	validVpn, validErr := networkConfig.ToNetworkVpnInfo("htb", lookup)
+	expected := &model.NetworkVpnInfo{
+		Name:        "htb",
+		LocalPath:   "secret:htb-vpn",
+		ConfigValue: "client",
+		IsSecret:    true,
+	}
+	assert.Equal(t, expected, validVpn)
+	assert.Nil(t, validErr)
+
+	networkConfig.Vpn[0].Path = "secret:other"
+	_, invalidErr := networkConfig.ToNetworkVpnInfo("htb", lookup)
+	assert.EqualError(t, invalidErr, "invalid vpn config name=htb: not found")
+}
+
 func TestToNetworkVpnInfo(t *testing.T) {
 	networkConfig := NetworkConfig{
 		Vpn: []VpnConfig{
@@ -125,11 +223,11 @@ func TestToNetworkVpnInfo(t *testing.T) {
 		},
 	}
 
-	emptyVpn, emptyErr := networkConfig.ToNetworkVpnInfo("")
+	emptyVpn, emptyErr := networkConfig.ToNetworkVpnInfo("", nil)
 	assert.Nil(t, emptyVpn)
 	assert.Nil(t, emptyErr)
 
-	validVpn, validErr := networkConfig.ToNetworkVpnInfo("readme")
+	validVpn, validErr := networkConfig.ToNetworkVpnInfo("readme", nil)
 	configFile, _ := util.ReadFile("../../../README.md")
 	expected := &model.NetworkVpnInfo{
 		Name:        "readme",
@@ -139,7 +237,7 @@ func TestToNetworkVpnInfo(t *testing.T) {
 	assert.Equal(t, expected, validVpn)
 	assert.Nil(t, validErr)
 
-	invalidVpn, invalidErr := networkConfig.ToNetworkVpnInfo("foo")
+	invalidVpn, invalidErr := networkConfig.ToNetworkVpnInfo("foo", nil)
 	assert.Nil(t, invalidVpn)
 	assert.EqualError(t, invalidErr, "vpn not found name=foo")
 }
@@ -153,6 +251,7 @@ func TestToShareDirInfo(t *testing.T) {
 		LocalPath:  "myShareDir",
 		RemotePath: "/hck/share",
 		LockDir:    true,
+		OutputDir:  true,
 	}
-	assert.Equal(t, expected, commonConfig.ToShareDirInfo(true))
+	assert.Equal(t, expected, commonConfig.ToShareDirInfo(true, true))
 }
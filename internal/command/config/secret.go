@@ -0,0 +1,63 @@
+package config
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+
+	"github.com/hckops/hckctl/pkg/client/terminal"
+	"github.com/hckops/hckctl/pkg/secret"
+	"github.com/hckops/hckctl/pkg/util"
+)
+
+const (
+	secretPassphraseEnv = "HCK_SECRET_PASSPHRASE" // unlocks the store without prompting
+)
+
+// SecretStore unlocks the store once per process, it's invoked only when a secret is actually needed
+func (ref *ConfigRef) SecretStore() (*secret.Store, error) {
+	if ref.secretStore != nil {
+		return ref.secretStore, nil
+	}
+
+	// a new store is created with the first passphrase
+	passphrase, err := readSecretPassphrase(util.PathNotExist(ref.Config.Secret.Path))
+	if err != nil {
+		return nil, err
+	}
+	store, err := secret.OpenStore(ref.Config.Secret.Path, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	ref.secretStore = store
+	return store, nil
+}
+
+// SecretLookup resolves the "secret:<name>" references in the config and templates
+func (ref *ConfigRef) SecretLookup(name string) (string, error) {
+	store, err := ref.SecretStore()
+	if err != nil {
+		return "", errors.Wrap(err, "error unlocking secret store")
+	}
+	return store.Get(name)
+}
+
+func readSecretPassphrase(confirm bool) ([]byte, error) {
+	if passphrase := os.Getenv(secretPassphraseEnv); passphrase != "" {
+		return []byte(passphrase), nil
+	}
+	passphrase, err := terminal.ReadPassword(os.Stdin, os.Stderr, "secret store passphrase: ")
+	if err != nil {
+		return nil, errors.Wrapf(err, "set %s or use an interactive terminal", secretPassphraseEnv)
+	}
+	if confirm {
+		confirmation, err := terminal.ReadPassword(os.Stdin, os.Stderr, "confirm new passphrase: ")
+		if err != nil {
+			return nil, err
+		}
+		if string(confirmation) != string(passphrase) {
+			return nil, errors.New("passphrases don't match")
+		}
+	}
+	return passphrase, nil
+}
@@ -20,9 +20,15 @@ const (
 	configDirEnv  string = "HCK_CONFIG_DIR" // overrides .config/hck
 	configEnvName string = "HCK_CONFIG"
 
-	logDirName     = "log"
-	shareDirName   = "share"
-	taskLogDirName = "task/log"
+	logDirName        = "log"
+	shareDirName      = "share"
+	taskLogDirName    = "task/log"
+	sessionDirName    = "session"
+	auditFileName     = "audit/audit.jsonl"
+	boxForwardDirName = "box/forward"
+	serverDirName     = "server"
+	knownHostsName    = "known_hosts"
+	secretFileName    = "secrets.json"
 )
 
 func InitConfig(force bool) error {
@@ -117,11 +123,30 @@ func newConfigOptions() (*configOptions, error) {
 		return nil, errors.Wrap(err, "error creating task dir")
 	}
 
+	sessionPath := filepath.Join(xdg.StateHome, common.DefaultDirName, sessionDirName)
+	if err := util.CreateDir(sessionPath); err != nil {
+		return nil, errors.Wrap(err, "error creating session dir")
+	}
+
+	auditFile := filepath.Join(xdg.StateHome, common.DefaultDirName, auditFileName)
+	if err := util.CreateBaseDir(auditFile); err != nil {
+		return nil, errors.Wrap(err, "error creating audit dir")
+	}
+
+	configDir, err := getConfigDir()
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid config dir")
+	}
+
 	return &configOptions{
-		logFile:    logFile,
-		cacheDir:   filepath.Join(xdg.CacheHome, common.DefaultDirName),
-		shareDir:   sharePath,
-		taskLogDir: taskLogPath,
+		logFile:        logFile,
+		cacheDir:       filepath.Join(xdg.CacheHome, common.DefaultDirName),
+		shareDir:       sharePath,
+		taskLogDir:     taskLogPath,
+		sessionDir:     sessionPath,
+		auditFile:      auditFile,
+		secretFile:     filepath.Join(configDir, secretFileName),
+		knownHostsFile: filepath.Join(configDir, knownHostsName),
 	}, nil
 }
 
@@ -139,6 +164,24 @@ func getLogFile() (string, error) {
 	return logFile, nil
 }
 
+// GetBoxForwardDir returns the runtime directory of the active port-forwards, it's not part of the config
+func GetBoxForwardDir() (string, error) {
+	forwardPath := filepath.Join(xdg.StateHome, common.DefaultDirName, boxForwardDirName)
+	if err := util.CreateDir(forwardPath); err != nil {
+		return "", errors.Wrap(err, "error creating box forward dir")
+	}
+	return forwardPath, nil
+}
+
+// GetServerHostKeyPath returns the path of the persistent host key, the clients can verify across restarts
+func GetServerHostKeyPath() (string, error) {
+	serverPath := filepath.Join(xdg.StateHome, common.DefaultDirName, serverDirName)
+	if err := util.CreateDir(serverPath); err != nil {
+		return "", errors.Wrap(err, "error creating server dir")
+	}
+	return filepath.Join(serverPath, "host_key"), nil
+}
+
 func LoadConfig() (*ConfigV1, error) {
 	var configV1 *ConfigV1
 	// "exact" makes sure to fail if fields are invalid
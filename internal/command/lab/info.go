@@ -0,0 +1,101 @@
+package lab
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/hckops/hckctl/internal/command/common"
+	"github.com/hckops/hckctl/internal/command/config"
+	labModel "github.com/hckops/hckctl/pkg/lab/model"
+	"github.com/hckops/hckctl/pkg/util"
+)
+
+type labInfoCmdOptions struct {
+	configRef *config.ConfigRef
+}
+
+func NewLabInfoCmd(configRef *config.ConfigRef) *cobra.Command {
+
+	opts := &labInfoCmdOptions{
+		configRef: configRef,
+	}
+
+	command := &cobra.Command{
+		Use:   "info [name]",
+		Short: "Describe a running lab",
+		Args:  cobra.ExactArgs(1),
+		RunE:  opts.run,
+	}
+
+	return command
+}
+
+func (opts *labInfoCmdOptions) run(cmd *cobra.Command, args []string) error {
+	labName := args[0]
+	log.Debug().Msgf("info lab: labName=%s", labName)
+
+	loader := common.NewLoader()
+	loader.Start("loading %s", labName)
+	defer loader.Stop()
+
+	labClient, err := newDefaultLabClient(cmd.Context(), opts.configRef, loader)
+	if err != nil {
+		return err
+	}
+
+	labDetails, err := labClient.Describe(cmd.Context(), labName)
+	if err != nil {
+		log.Warn().Err(err).Msgf("error describe lab: provider=%s labName=%s", labClient.Provider(), labName)
+		return errors.New("not found")
+	}
+
+	if value, err := util.EncodeYaml(newLabValue(labDetails, labClient.Provider())); err != nil {
+		return err
+	} else {
+		loader.Stop()
+		fmt.Print(value)
+	}
+	return nil
+}
+
+type LabValue struct {
+	Name     string
+	Created  string
+	Healthy  bool
+	Provider string
+	Template string
+	Box      LabBoxValue
+}
+
+type LabBoxValue struct {
+	Name     string
+	Alias    string `yaml:",omitempty"`
+	Template string
+	Size     string
+	Vpn      string   `yaml:",omitempty"`
+	Ports    []string `yaml:",omitempty"`
+	Dumps    []string `yaml:",omitempty"`
+}
+
+func newLabValue(details *labModel.LabDetails, provider labModel.LabProvider) *LabValue {
+	return &LabValue{
+		Name:     details.Info.Name,
+		Created:  details.Created.Format(time.RFC3339),
+		Healthy:  details.Info.Healthy,
+		Provider: provider.String(),
+		Template: details.TemplateName,
+		Box: LabBoxValue{
+			Name:     details.Box.Name,
+			Alias:    details.Box.Alias,
+			Template: details.Box.TemplateName,
+			Size:     details.Box.Size,
+			Vpn:      details.Box.Vpn,
+			Ports:    details.Box.Ports,
+			Dumps:    details.Box.Dumps,
+		},
+	}
+}
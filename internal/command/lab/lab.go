@@ -1,17 +1,22 @@
 package lab
 
 import (
+	"context"
 	"fmt"
+	"sort"
+	"time"
 
 	"github.com/MakeNowJust/heredoc"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 
+	auditCmd "github.com/hckops/hckctl/internal/command/audit"
 	commonCmd "github.com/hckops/hckctl/internal/command/common"
 	commonFlag "github.com/hckops/hckctl/internal/command/common/flag"
 	"github.com/hckops/hckctl/internal/command/config"
 	"github.com/hckops/hckctl/internal/command/version"
+	"github.com/hckops/hckctl/pkg/audit"
 	boxModel "github.com/hckops/hckctl/pkg/box/model"
 	commonModel "github.com/hckops/hckctl/pkg/common/model"
 	"github.com/hckops/hckctl/pkg/lab"
@@ -33,14 +38,28 @@ func NewLabCmd(configRef *config.ConfigRef) *cobra.Command {
 	}
 
 	command := &cobra.Command{
-		Use:     "lab [name]",
-		Short:   "Create a managed lab",
-		Long:    heredoc.Doc(`TODO long`),
-		Example: heredoc.Doc(`TODO example`),
+		Use:   "lab [name]",
+		Short: "Create a managed lab",
+		Long: heredoc.Doc(`
+			Create a managed lab
+
+			  A Lab is a Box deployed on the cloud provider, optionally connected to a vpn,
+			  with public ports and mounted dumps. All public templates are versioned
+			  under the /lab/ sub-path on GitHub at https://github.com/hckops/megalopolis
+		`),
+		Example: heredoc.Doc(`
+
+			# creates a "lab/ctf-vpn" lab overriding the default inputs
+			hckctl lab ctf-vpn --input alias=parrot --input password=changeme --input vpn=htb-eu
+
+			# lists, describes and stops the running labs
+			hckctl lab list
+			hckctl lab info <NAME>
+			hckctl lab stop <NAME>
+		`),
 		Args:    cobra.ExactArgs(1),
 		PreRunE: opts.validate,
 		RunE:    opts.run,
-		Hidden:  true,
 	}
 
 	// N --inputs
@@ -50,6 +69,10 @@ func NewLabCmd(configRef *config.ConfigRef) *cobra.Command {
 	)
 	command.Flags().StringArrayVarP(&opts.inputsFlag, inputFlagName, commonFlag.NoneFlagShortHand, []string{}, inputFlagUsage)
 
+	command.AddCommand(NewLabInfoCmd(configRef))
+	command.AddCommand(NewLabListCmd(configRef))
+	command.AddCommand(NewLabStopCmd(configRef))
+
 	return command
 }
 
@@ -70,10 +93,10 @@ func (opts *labCmdOptions) run(cmd *cobra.Command, args []string) error {
 
 	sourceOpts := commonCmd.NewGitSourceOptions(opts.configRef.Config.Template.CacheDir, revision)
 	sourceLoader := template.NewGitLoader[labModel.LabV1](sourceOpts, name)
-	return startLab(sourceLoader, opts.configRef, opts.parameters)
+	return startLab(cmd.Context(), sourceLoader, opts.configRef, opts.parameters)
 }
 
-func startLab(sourceLoader template.SourceLoader[labModel.LabV1], configRef *config.ConfigRef, parameters commonModel.Parameters) error {
+func startLab(ctx context.Context, sourceLoader template.SourceLoader[labModel.LabV1], configRef *config.ConfigRef, parameters commonModel.Parameters) error {
 
 	info, err := sourceLoader.Read()
 	if err != nil || info.Value.Kind != schema.KindLabV1 {
@@ -88,7 +111,7 @@ func startLab(sourceLoader template.SourceLoader[labModel.LabV1], configRef *con
 
 	log.Info().Msgf("loading template: name=%s\n%s", templateName, info.Value.Data.Pretty())
 
-	labClient, err := newDefaultLabClient(configRef, loader)
+	labClient, err := newDefaultLabClient(ctx, configRef, loader)
 	if err != nil {
 		return err
 	}
@@ -101,28 +124,55 @@ func startLab(sourceLoader template.SourceLoader[labModel.LabV1], configRef *con
 		Labels:        commonModel.Labels{},          // cloud only
 	}
 
-	if labInfo, err := labClient.Create(createOpts); err != nil {
+	if labInfo, err := labClient.Create(ctx, createOpts); err != nil {
 		return err
 	} else {
+		auditLabCreate(configRef, info, labClient.Provider(), labInfo.Name, parameters)
 		loader.Stop()
 		fmt.Println(labInfo.Name)
 	}
 	return nil
 }
 
-func newDefaultLabClient(configRef *config.ConfigRef, loader *commonCmd.Loader) (lab.LabClient, error) {
+// auditLabCreate records only the targets of the inputs, which might contain credentials
+func auditLabCreate(configRef *config.ConfigRef, info *template.TemplateInfo[labModel.LabV1], provider labModel.LabProvider, labName string, parameters commonModel.Parameters) {
+	var values []string
+	for _, value := range parameters {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+
+	startTime := time.Now().UTC()
+	auditCmd.Record(configRef, &audit.Record{
+		Action:    audit.LabCreateAction,
+		Provider:  provider.String(),
+		Name:      labName,
+		Template:  auditCmd.NewTemplateRef(info, configRef.Config.Template.CacheDir),
+		Targets:   auditCmd.Targets(values),
+		StartTime: &startTime,
+	})
+}
+
+func newDefaultLabClient(ctx context.Context, configRef *config.ConfigRef, loader *commonCmd.Loader) (lab.LabClient, error) {
 	provider := labModel.Cloud
+	cloudOpts, err := configRef.Config.Provider.Cloud.ToCloudOptions(version.ClientVersion(), configRef.SecretLookup)
+	if err != nil {
+		log.Warn().Err(err).Msgf("error lab client options provider=%s", provider)
+		return nil, fmt.Errorf("error %s client", provider)
+	}
 	labClientOpts := &labModel.LabClientOptions{
 		Provider:  provider,
-		CloudOpts: configRef.Config.Provider.Cloud.ToCloudOptions(version.ClientVersion()),
+		CloudOpts: cloudOpts,
 	}
 
-	labClient, err := lab.NewLabClient(labClientOpts)
+	labClient, err := lab.NewLabClient(ctx, labClientOpts)
 	if err != nil {
 		log.Error().Err(err).Msgf("error lab client provider=%s", provider)
 		return nil, fmt.Errorf("error %s client", provider)
 	}
 
-	labClient.Events().Subscribe(commonCmd.EventCallback(loader))
+	commonCmd.SubscribeEvents(labClient.Events(), loader)
+	auditCmd.SubscribeEvents(configRef, labClient.Events())
+	configRef.EventStream.Subscribe(labClient.Events(), provider.String(), "")
 	return labClient, nil
 }
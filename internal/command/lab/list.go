@@ -0,0 +1,60 @@
+package lab
+
+import (
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/hckops/hckctl/internal/command/common"
+	"github.com/hckops/hckctl/internal/command/config"
+)
+
+type labListCmdOptions struct {
+	configRef *config.ConfigRef
+}
+
+func NewLabListCmd(configRef *config.ConfigRef) *cobra.Command {
+
+	opts := &labListCmdOptions{
+		configRef: configRef,
+	}
+
+	command := &cobra.Command{
+		Use:   "list",
+		Short: "List all running labs",
+		Args:  cobra.NoArgs,
+		RunE:  opts.run,
+	}
+
+	return command
+}
+
+func (opts *labListCmdOptions) run(cmd *cobra.Command, args []string) error {
+	loader := common.NewLoader()
+	loader.Start("loading labs")
+	defer loader.Stop()
+
+	labClient, err := newDefaultLabClient(cmd.Context(), opts.configRef, loader)
+	if err != nil {
+		return err
+	}
+
+	labs, err := labClient.List(cmd.Context())
+	if err != nil {
+		log.Warn().Err(err).Msgf("error listing labs: provider=%v", labClient.Provider())
+		return fmt.Errorf("%s list error", labClient.Provider())
+	}
+
+	loader.Stop()
+	fmt.Println(fmt.Sprintf("# %s", labClient.Provider()))
+	for _, l := range labs {
+		if l.Healthy {
+			fmt.Println(l.Name)
+		} else {
+			fmt.Println(fmt.Sprintf("%s (unhealthy)", l.Name))
+		}
+	}
+	fmt.Println(fmt.Sprintf("total: %d", len(labs)))
+	return nil
+}
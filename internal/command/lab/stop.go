@@ -0,0 +1,94 @@
+package lab
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	auditCmd "github.com/hckops/hckctl/internal/command/audit"
+	"github.com/hckops/hckctl/internal/command/common"
+	commonFlag "github.com/hckops/hckctl/internal/command/common/flag"
+	"github.com/hckops/hckctl/internal/command/config"
+	"github.com/hckops/hckctl/pkg/audit"
+)
+
+type labStopCmdOptions struct {
+	configRef *config.ConfigRef
+	allFlag   bool
+}
+
+func NewLabStopCmd(configRef *config.ConfigRef) *cobra.Command {
+
+	opts := &labStopCmdOptions{
+		configRef: configRef,
+	}
+
+	command := &cobra.Command{
+		Use:   "stop [name]",
+		Short: "Stop one or more running labs",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  opts.run,
+	}
+
+	const (
+		allFlagName  = "all"
+		allFlagUsage = "stop all labs"
+	)
+	command.Flags().BoolVarP(&opts.allFlag, allFlagName, commonFlag.NoneFlagShortHand, false, allFlagUsage)
+
+	return command
+}
+
+func (opts *labStopCmdOptions) run(cmd *cobra.Command, args []string) error {
+
+	var names []string
+	if len(args) == 0 && opts.allFlag {
+		// an empty list deletes all the labs
+		names = []string{}
+	} else if len(args) == 1 && !opts.allFlag {
+		names = []string{args[0]}
+	} else {
+		cmd.HelpFunc()(cmd, args)
+		return nil
+	}
+	log.Debug().Msgf("stop labs: names=%v", names)
+
+	loader := common.NewLoader()
+	loader.Start("stopping labs")
+	defer loader.Stop()
+
+	labClient, err := newDefaultLabClient(cmd.Context(), opts.configRef, loader)
+	if err != nil {
+		return err
+	}
+
+	result, err := labClient.Delete(cmd.Context(), names)
+	if err != nil {
+		log.Warn().Err(err).Msgf("error stopping labs: provider=%s", labClient.Provider())
+		return fmt.Errorf("%s delete error", labClient.Provider())
+	} else if len(names) == 1 && len(result) == 0 {
+		return errors.New("not found")
+	}
+
+	for _, name := range result {
+		auditCmd.Record(opts.configRef, &audit.Record{
+			Action:   audit.LabDeleteAction,
+			Provider: labClient.Provider().String(),
+			Name:     name,
+		})
+	}
+
+	loader.Stop()
+	if opts.allFlag {
+		fmt.Println(fmt.Sprintf("# %s", labClient.Provider()))
+	}
+	for _, name := range result {
+		fmt.Println(name)
+	}
+	if opts.allFlag {
+		fmt.Println(fmt.Sprintf("total: %d", len(result)))
+	}
+	return nil
+}
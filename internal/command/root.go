@@ -8,10 +8,14 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
+	auditCmd "github.com/hckops/hckctl/internal/command/audit"
 	boxCmd "github.com/hckops/hckctl/internal/command/box"
 	commonCmd "github.com/hckops/hckctl/internal/command/common"
 	configCmd "github.com/hckops/hckctl/internal/command/config"
 	labCmd "github.com/hckops/hckctl/internal/command/lab"
+	secretCmd "github.com/hckops/hckctl/internal/command/secret"
+	serverCmd "github.com/hckops/hckctl/internal/command/server"
+	sessionCmd "github.com/hckops/hckctl/internal/command/session"
 	taskCmd "github.com/hckops/hckctl/internal/command/task"
 	templateCmd "github.com/hckops/hckctl/internal/command/template"
 	versionCmd "github.com/hckops/hckctl/internal/command/version"
@@ -23,6 +27,7 @@ func NewRootCmd() *cobra.Command {
 	// define pointer/reference to pass around in all commands and initialize in each PersistentPreRunE
 	configRef := &configCmd.ConfigRef{}
 	var logCallback func() error
+	var eventsFormat, eventsOutput string
 
 	rootCmd := &cobra.Command{
 		Use:   commonCmd.CliName,
@@ -45,6 +50,12 @@ func NewRootCmd() *cobra.Command {
 				logCallback = callback
 			}
 
+			if stream, err := commonCmd.OpenEventStream(eventsFormat, eventsOutput); err != nil {
+				return errors.Wrap(err, "unable to setup events")
+			} else {
+				configRef.EventStream = stream
+			}
+
 			return nil
 		},
 		Run: func(cmd *cobra.Command, args []string) {
@@ -54,6 +65,10 @@ func NewRootCmd() *cobra.Command {
 			// TODO investigate random warning due to async events
 			// "zerolog: could not write event: write /home/<REDACTED>/.local/state/hck/log/hckctl-<REDACTED>.log: file already closed"
 
+			if err := configRef.EventStream.Close(); err != nil {
+				return errors.Wrap(err, "unable to close events")
+			}
+
 			// close log file properly
 			return logCallback()
 		},
@@ -71,11 +86,26 @@ func NewRootCmd() *cobra.Command {
 	rootCmd.PersistentFlags().StringP(logLevelFlag, "l", logger.InfoLogLevel.String(), logLevelUsage)
 	viper.BindPFlag(logLevelConfigKey, rootCmd.PersistentFlags().Lookup(logLevelFlag))
 
+	const (
+		eventsFlag       = "events"
+		eventsOutputFlag = "events-output"
+	)
+	// --events
+	eventsUsage := fmt.Sprintf("stream all the events in a machine readable format, one of %s", commonCmd.EventsFormatJsonl)
+	rootCmd.PersistentFlags().StringVar(&eventsFormat, eventsFlag, "", eventsUsage)
+	// --events-output
+	eventsOutputUsage := "events destination, one of stderr, stdout, a file path, unix:///path/to/socket or tcp://host:port"
+	rootCmd.PersistentFlags().StringVar(&eventsOutput, eventsOutputFlag, commonCmd.EventsOutputStderr, eventsOutputUsage)
+
 	rootCmd.SetHelpCommand(&cobra.Command{Hidden: true})
 
+	rootCmd.AddCommand(auditCmd.NewAuditCmd(configRef))
 	rootCmd.AddCommand(boxCmd.NewBoxCmd(configRef))
 	rootCmd.AddCommand(configCmd.NewConfigCmd(configRef))
 	rootCmd.AddCommand(labCmd.NewLabCmd(configRef))
+	rootCmd.AddCommand(secretCmd.NewSecretCmd(configRef))
+	rootCmd.AddCommand(serverCmd.NewServerCmd(configRef))
+	rootCmd.AddCommand(sessionCmd.NewSessionCmd(configRef))
 	rootCmd.AddCommand(taskCmd.NewTaskCmd(configRef))
 	rootCmd.AddCommand(templateCmd.NewTemplateCmd(configRef))
 	rootCmd.AddCommand(versionCmd.NewVersionCmd())
@@ -0,0 +1,50 @@
+package secret
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/hckops/hckctl/internal/command/config"
+	"github.com/hckops/hckctl/pkg/secret"
+)
+
+type secretGetCmdOptions struct {
+	configRef *config.ConfigRef
+}
+
+func NewSecretGetCmd(configRef *config.ConfigRef) *cobra.Command {
+
+	opts := &secretGetCmdOptions{
+		configRef: configRef,
+	}
+
+	command := &cobra.Command{
+		Use:   "get [name]",
+		Short: "Print the value of a secret",
+		Args:  cobra.ExactArgs(1),
+		RunE:  opts.run,
+	}
+
+	return command
+}
+
+func (opts *secretGetCmdOptions) run(cmd *cobra.Command, args []string) error {
+	name := args[0]
+
+	store, err := openStore(opts.configRef)
+	if err != nil {
+		return err
+	}
+	value, err := store.Get(name)
+	if errors.Is(err, secret.ErrSecretNotFound) {
+		return fmt.Errorf("secret %s not found", name)
+	} else if err != nil {
+		log.Warn().Err(err).Msgf("error getting secret: name=%s", name)
+		return errors.New("error getting secret")
+	}
+	fmt.Println(value)
+	return nil
+}
@@ -0,0 +1,40 @@
+package secret
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hckops/hckctl/internal/command/config"
+)
+
+type secretListCmdOptions struct {
+	configRef *config.ConfigRef
+}
+
+func NewSecretListCmd(configRef *config.ConfigRef) *cobra.Command {
+
+	opts := &secretListCmdOptions{
+		configRef: configRef,
+	}
+
+	command := &cobra.Command{
+		Use:   "list",
+		Short: "List the secret names",
+		Args:  cobra.NoArgs,
+		RunE:  opts.run,
+	}
+
+	return command
+}
+
+func (opts *secretListCmdOptions) run(cmd *cobra.Command, args []string) error {
+	store, err := openStore(opts.configRef)
+	if err != nil {
+		return err
+	}
+	for _, name := range store.Names() {
+		fmt.Println(name)
+	}
+	return nil
+}
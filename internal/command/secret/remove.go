@@ -0,0 +1,49 @@
+package secret
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/hckops/hckctl/internal/command/config"
+	"github.com/hckops/hckctl/pkg/secret"
+)
+
+type secretRemoveCmdOptions struct {
+	configRef *config.ConfigRef
+}
+
+func NewSecretRemoveCmd(configRef *config.ConfigRef) *cobra.Command {
+
+	opts := &secretRemoveCmdOptions{
+		configRef: configRef,
+	}
+
+	command := &cobra.Command{
+		Use:   "rm [name]",
+		Short: "Remove a secret",
+		Args:  cobra.ExactArgs(1),
+		RunE:  opts.run,
+	}
+
+	return command
+}
+
+func (opts *secretRemoveCmdOptions) run(cmd *cobra.Command, args []string) error {
+	name := args[0]
+
+	store, err := openStore(opts.configRef)
+	if err != nil {
+		return err
+	}
+	if err := store.Remove(name); errors.Is(err, secret.ErrSecretNotFound) {
+		return fmt.Errorf("secret %s not found", name)
+	} else if err != nil {
+		log.Warn().Err(err).Msgf("error removing secret: name=%s", name)
+		return errors.New("error removing secret")
+	}
+	log.Info().Msgf("secret removed: name=%s", name)
+	return nil
+}
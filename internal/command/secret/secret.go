@@ -0,0 +1,62 @@
+package secret
+
+import (
+	"errors"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/hckops/hckctl/internal/command/config"
+	"github.com/hckops/hckctl/pkg/secret"
+)
+
+func NewSecretCmd(configRef *config.ConfigRef) *cobra.Command {
+
+	command := &cobra.Command{
+		Use:   "secret",
+		Short: "Manage the encrypted secrets",
+		Long: heredoc.Doc(`
+			Manage the encrypted secrets
+
+			  The secrets are stored in "secret.path", encrypted with a key derived from a passphrase.
+			  The passphrase is prompted once per command, set HCK_SECRET_PASSPHRASE to unlock
+			  the store for the whole shell session.
+			  The config values "network.vpn[].path" and "provider.cloud.token" and the box env
+			  values accept a reference "secret:<name>", which is resolved only when used.
+		`),
+		Example: heredoc.Doc(`
+
+			# stores a vpn profile, the config becomes "path: secret:htb-vpn"
+			hckctl secret set htb-vpn --file ~/Downloads/lab_user.ovpn
+
+			# stores a value from stdin
+			echo -n "my-token" | hckctl secret set cloud-token
+
+			# lists the names only
+			hckctl secret list
+		`),
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.HelpFunc()(cmd, args)
+		},
+	}
+
+	command.AddCommand(NewSecretGetCmd(configRef))
+	command.AddCommand(NewSecretListCmd(configRef))
+	command.AddCommand(NewSecretRemoveCmd(configRef))
+	command.AddCommand(NewSecretSetCmd(configRef))
+
+	return command
+}
+
+func openStore(configRef *config.ConfigRef) (*secret.Store, error) {
+	store, err := configRef.SecretStore()
+	if errors.Is(err, secret.ErrInvalidPassphrase) {
+		log.Warn().Err(err).Msgf("error unlocking secret store: path=%s", configRef.Config.Secret.Path)
+		return nil, err
+	} else if err != nil {
+		log.Warn().Err(err).Msgf("error opening secret store: path=%s", configRef.Config.Secret.Path)
+		return nil, errors.New("error secret store")
+	}
+	return store, nil
+}
@@ -0,0 +1,84 @@
+package secret
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/moby/term"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/hckops/hckctl/internal/command/config"
+	"github.com/hckops/hckctl/pkg/client/terminal"
+	"github.com/hckops/hckctl/pkg/secret"
+)
+
+type secretSetCmdOptions struct {
+	configRef *config.ConfigRef
+	filePath  string
+}
+
+func NewSecretSetCmd(configRef *config.ConfigRef) *cobra.Command {
+
+	opts := &secretSetCmdOptions{
+		configRef: configRef,
+	}
+
+	command := &cobra.Command{
+		Use:   "set [name]",
+		Short: "Add or replace a secret",
+		Long:  "Add or replace a secret, the value is read from a file, stdin or prompted. It's never accepted as argument to avoid leaking it in the shell history",
+		Args:  cobra.ExactArgs(1),
+		RunE:  opts.run,
+	}
+
+	const (
+		fileFlagName = "file"
+	)
+	command.Flags().StringVarP(&opts.filePath, fileFlagName, "f", "", "read the value from a file")
+
+	return command
+}
+
+func (opts *secretSetCmdOptions) run(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	if err := secret.ValidateName(name); err != nil {
+		return err
+	}
+
+	value, err := opts.readValue()
+	if err != nil {
+		log.Warn().Err(err).Msgf("error reading secret: name=%s", name)
+		return errors.New("error reading secret")
+	}
+	if value == "" {
+		return errors.New("empty secret")
+	}
+
+	store, err := openStore(opts.configRef)
+	if err != nil {
+		return err
+	}
+	if err := store.Set(name, value); err != nil {
+		log.Warn().Err(err).Msgf("error setting secret: name=%s", name)
+		return errors.New("error setting secret")
+	}
+	log.Info().Msgf("secret set: name=%s", name)
+	return nil
+}
+
+func (opts *secretSetCmdOptions) readValue() (string, error) {
+	if opts.filePath != "" {
+		data, err := os.ReadFile(opts.filePath)
+		return string(data), err
+	}
+	if term.IsTerminal(os.Stdin.Fd()) {
+		value, err := terminal.ReadPassword(os.Stdin, os.Stderr, "secret value: ")
+		return string(value), err
+	}
+	// preserves multiline values, only the trailing newline is removed
+	data, err := io.ReadAll(os.Stdin)
+	return strings.TrimSuffix(string(data), "\n"), err
+}
@@ -0,0 +1,215 @@
+package server
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	gossh "golang.org/x/crypto/ssh"
+
+	boxFlag "github.com/hckops/hckctl/internal/command/box/flag"
+	commonCmd "github.com/hckops/hckctl/internal/command/common"
+	commonFlag "github.com/hckops/hckctl/internal/command/common/flag"
+	"github.com/hckops/hckctl/internal/command/config"
+	"github.com/hckops/hckctl/internal/command/version"
+	"github.com/hckops/hckctl/pkg/api/v1/server"
+	"github.com/hckops/hckctl/pkg/box"
+	boxModel "github.com/hckops/hckctl/pkg/box/model"
+	commonModel "github.com/hckops/hckctl/pkg/common/model"
+	"github.com/hckops/hckctl/pkg/event"
+	"github.com/hckops/hckctl/pkg/schema"
+	"github.com/hckops/hckctl/pkg/template"
+	"github.com/hckops/hckctl/pkg/util"
+)
+
+const (
+	serverTokenEnv = "HCK_SERVER_TOKEN"
+)
+
+type serverCmdOptions struct {
+	configRef    *config.ConfigRef
+	addressFlag  string
+	usernameFlag string
+	tokenFlag    string
+	providerFlag *commonFlag.ProviderFlag
+	// internal
+	provider boxModel.BoxProvider
+}
+
+func NewServerCmd(configRef *config.ConfigRef) *cobra.Command {
+
+	opts := &serverCmdOptions{
+		configRef: configRef,
+	}
+
+	command := &cobra.Command{
+		Use:   "server",
+		Short: "Run a self-hosted cloud provider",
+		Long: heredoc.Doc(`
+			Run a self-hosted cloud provider
+
+			  Serve the api/v1 protocol over ssh, backed by a local box provider,
+			  so that a team can share boxes using "--provider cloud".
+			  Each client must configure "provider.cloud" with the same address and credentials.
+		`),
+		Example: heredoc.Doc(`
+
+			# listens on localhost only with a random token, printed on startup
+			hckctl server
+
+			# exposes the docker boxes of a shared host
+			HCK_SERVER_TOKEN=<TOKEN> hckctl server --address 0.0.0.0:2222 --username team
+		`),
+		Args:    cobra.NoArgs,
+		PreRunE: opts.validate,
+		RunE:    opts.run,
+	}
+
+	const (
+		addressFlagName   = "address"
+		addressFlagUsage  = "address to listen on"
+		usernameFlagName  = "username"
+		usernameFlagUsage = "username of the clients"
+		tokenFlagName     = "token"
+	)
+	tokenFlagUsage := fmt.Sprintf("token of the clients, defaults to %s or a random value", serverTokenEnv)
+	command.Flags().StringVarP(&opts.addressFlag, addressFlagName, commonFlag.NoneFlagShortHand, "127.0.0.1:2222", addressFlagUsage)
+	command.Flags().StringVarP(&opts.usernameFlag, usernameFlagName, commonFlag.NoneFlagShortHand, "hck", usernameFlagUsage)
+	command.Flags().StringVarP(&opts.tokenFlag, tokenFlagName, commonFlag.NoneFlagShortHand, "", tokenFlagUsage)
+	// --provider (enum)
+	opts.providerFlag = boxFlag.AddBoxProviderFlag(command)
+
+	return command
+}
+
+func (opts *serverCmdOptions) validate(cmd *cobra.Command, args []string) error {
+	if validProvider, err := boxFlag.ValidateBoxProviderFlag(opts.configRef.Config.Box.Provider, opts.providerFlag); err != nil {
+		return err
+	} else if validProvider == boxModel.Cloud {
+		return fmt.Errorf("%s: provider=%s", commonFlag.ErrorFlagNotSupported, validProvider)
+	} else {
+		opts.provider = validProvider
+	}
+	return nil
+}
+
+func (opts *serverCmdOptions) run(cmd *cobra.Command, args []string) error {
+
+	token := opts.tokenFlag
+	if token == "" {
+		token = os.Getenv(serverTokenEnv)
+	}
+	if token == "" {
+		token = util.RandomAlphanumeric(32)
+		fmt.Println(fmt.Sprintf("token: %s", token))
+	}
+
+	hostKeyPath, err := config.GetServerHostKeyPath()
+	if err != nil {
+		return err
+	}
+	hostKey, err := server.LoadHostKey(hostKeyPath)
+	if err != nil {
+		log.Warn().Err(err).Msgf("error server host key: path=%s", hostKeyPath)
+		return errors.New("invalid host key")
+	}
+	// clients can pin it in provider.cloud.fingerprint
+	fmt.Println(fmt.Sprintf("fingerprint: %s", gossh.FingerprintSHA256(hostKey.PublicKey())))
+
+	cacheDir := opts.configRef.Config.Template.CacheDir
+	serverOpts := &server.ServerOptions{
+		Address:  opts.addressFlag,
+		Origin:   version.ClientVersion(),
+		Username: opts.usernameFlag,
+		Token:    token,
+		HostKey:  hostKey,
+		NewBoxClient: func() (box.BoxClient, error) {
+			return box.NewBoxClient(cmd.Context(), &boxModel.BoxClientOptions{
+				Provider:   opts.provider,
+				DockerOpts: opts.configRef.Config.Provider.Docker.ToDockerOptions(),
+				KubeOpts:   opts.configRef.Config.Provider.Kube.ToKubeOptions(),
+			})
+		},
+		NewCreateOptions: func(templateName string, size boxModel.ResourceSize) (*boxModel.CreateOptions, error) {
+			return newCreateOptions(templateName, size, opts.configRef)
+		},
+		LoadTemplate: func(details *boxModel.BoxDetails) (*boxModel.BoxV1, error) {
+			return loadTemplate(details, cacheDir)
+		},
+	}
+	apiServer, err := server.NewServer(serverOpts)
+	if err != nil {
+		return err
+	}
+	apiServer.Events().Subscribe(eventCallback)
+	opts.configRef.EventStream.Subscribe(apiServer.Events(), opts.provider.String(), "")
+
+	log.Info().Msgf("starting server: address=%s provider=%s hostKey=%s", opts.addressFlag, opts.provider, hostKeyPath)
+	go func() {
+		<-cmd.Context().Done()
+		apiServer.Close()
+	}()
+	if err := apiServer.ListenAndServe(); err != nil {
+		log.Warn().Err(err).Msg("error server")
+		return errors.New("server error")
+	}
+	return nil
+}
+
+// only public git templates are allowed, clients can't provide custom templates or revisions
+func newCreateOptions(templateName string, size boxModel.ResourceSize, configRef *config.ConfigRef) (*boxModel.CreateOptions, error) {
+	sourceOpts := commonCmd.NewGitSourceOptions(configRef.Config.Template.CacheDir, commonCmd.TemplateSourceRevision)
+	info, err := template.NewGitLoader[boxModel.BoxV1](sourceOpts, templateName).Read()
+	if err != nil {
+		return nil, err
+	}
+	if info.Value.Kind != schema.KindBoxV1 {
+		return nil, fmt.Errorf("invalid template kind %s", info.Value.Kind)
+	}
+
+	labels := boxModel.NewBoxLabels().AddDefaultGit(sourceOpts.RepositoryUrl, sourceOpts.DefaultRevision, sourceOpts.CacheDirName())
+	return &boxModel.CreateOptions{
+		Template: &info.Value.Data,
+		Labels:   commonCmd.AddTemplateLabels[boxModel.BoxV1](info, boxModel.AddBoxSize(labels, size)),
+		CommonInfo: commonModel.CommonInfo{
+			ShareDir: configRef.Config.Common.ToShareDirInfo(false, false),
+		},
+		Size: size,
+	}, nil
+}
+
+func loadTemplate(details *boxModel.BoxDetails, cacheDir string) (*boxModel.BoxV1, error) {
+	var sourceLoader template.SourceLoader[boxModel.BoxV1]
+	if details.TemplateInfo.IsCached() {
+		sourceLoader = template.NewLocalLoader[boxModel.BoxV1](details.TemplateInfo.CachedTemplate.Path)
+	} else {
+		sourceOpts := commonCmd.NewGitSourceOptions(cacheDir, details.TemplateInfo.GitTemplate.Commit)
+		sourceLoader = template.NewGitLoader[boxModel.BoxV1](sourceOpts, details.TemplateInfo.GitTemplate.Name)
+	}
+	info, err := sourceLoader.Read()
+	if err != nil {
+		return nil, err
+	}
+	return &info.Value.Data, nil
+}
+
+// prints console events and logs everything else, there is no loader
+func eventCallback(e event.Event) {
+	switch e.Kind() {
+	case event.PrintConsole:
+		fmt.Println(e.String())
+	case event.LoaderUpdate, event.LoaderStop:
+		log.Debug().Msgf("[%v] %s", e.Source(), e.String())
+	case event.LogInfo:
+		log.Info().Msgf("[%v] %s", e.Source(), e.String())
+	case event.LogWarning:
+		log.Warn().Msgf("[%v] %s", e.Source(), e.String())
+	case event.LogError:
+		log.Error().Msgf("[%v] %s", e.Source(), e.String())
+	default:
+		log.Debug().Msgf("[%v] %s", e.Source(), e.String())
+	}
+}
@@ -0,0 +1,48 @@
+package session
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/hckops/hckctl/internal/command/config"
+	"github.com/hckops/hckctl/pkg/session"
+)
+
+type sessionListCmdOptions struct {
+	configRef *config.ConfigRef
+}
+
+func NewSessionListCmd(configRef *config.ConfigRef) *cobra.Command {
+
+	opts := &sessionListCmdOptions{
+		configRef: configRef,
+	}
+
+	command := &cobra.Command{
+		Use:   "list",
+		Short: "List all recorded sessions",
+		Args:  cobra.NoArgs,
+		RunE:  opts.run,
+	}
+
+	return command
+}
+
+func (opts *sessionListCmdOptions) run(cmd *cobra.Command, args []string) error {
+	sessionDir := opts.configRef.Config.Session.Dir
+
+	sessions, err := session.ListSessions(sessionDir)
+	if err != nil {
+		log.Warn().Err(err).Msgf("error listing sessions: dir=%s", sessionDir)
+		return errors.New("list error")
+	}
+
+	for _, s := range sessions {
+		fmt.Println(fmt.Sprintf("%s\t%s\t%dx%d\t%s", s.Id, s.Created.Format("2006-01-02 15:04:05"), s.Width, s.Height, s.Title))
+	}
+	fmt.Println(fmt.Sprintf("total: %d", len(sessions)))
+	return nil
+}
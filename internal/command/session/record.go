@@ -0,0 +1,34 @@
+package session
+
+import (
+	"errors"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/hckops/hckctl/internal/command/config"
+	commonModel "github.com/hckops/hckctl/pkg/common/model"
+	"github.com/hckops/hckctl/pkg/session"
+)
+
+// RecordStreams tees the streams into a new recording, the returned callback stops it
+func RecordStreams(configRef *config.ConfigRef, title string, streamOpts *commonModel.StreamOptions) (*commonModel.StreamOptions, func(), error) {
+	sessionDir := configRef.Config.Session.Dir
+	if sessionDir == "" {
+		log.Warn().Msg("missing session.dir config, reset the config or set it explicitly")
+		return nil, nil, errors.New("invalid session dir")
+	}
+
+	recorder, err := session.NewRecorder(sessionDir, title, streamOpts.In)
+	if err != nil {
+		log.Warn().Err(err).Msgf("error session recorder: dir=%s", sessionDir)
+		return nil, nil, errors.New("recording error")
+	}
+	log.Info().Msgf("recording session: id=%s path=%s", recorder.Id(), recorder.Path())
+
+	stopRecording := func() {
+		if err := recorder.Close(); err != nil {
+			log.Warn().Err(err).Msgf("error closing session: id=%s", recorder.Id())
+		}
+	}
+	return recorder.Record(streamOpts), stopRecording, nil
+}
@@ -0,0 +1,85 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/hckops/hckctl/internal/command/common/flag"
+	"github.com/hckops/hckctl/internal/command/config"
+	"github.com/hckops/hckctl/pkg/client/terminal"
+	"github.com/hckops/hckctl/pkg/session"
+)
+
+type sessionReplayCmdOptions struct {
+	configRef   *config.ConfigRef
+	speedFlag   float64
+	maxIdleFlag time.Duration
+}
+
+func NewSessionReplayCmd(configRef *config.ConfigRef) *cobra.Command {
+
+	opts := &sessionReplayCmdOptions{
+		configRef: configRef,
+	}
+
+	command := &cobra.Command{
+		Use:   "replay [id]",
+		Short: "Replay a recorded session in the terminal",
+		Args:  cobra.ExactArgs(1),
+		RunE:  opts.run,
+	}
+
+	const (
+		speedFlagName    = "speed"
+		speedFlagUsage   = "playback speed multiplier"
+		maxIdleFlagName  = "max-idle"
+		maxIdleFlagUsage = "limit the pauses, zero to preserve the original timing"
+	)
+	command.Flags().Float64VarP(&opts.speedFlag, speedFlagName, flag.NoneFlagShortHand, 1, speedFlagUsage)
+	command.Flags().DurationVarP(&opts.maxIdleFlag, maxIdleFlagName, flag.NoneFlagShortHand, 2*time.Second, maxIdleFlagUsage)
+
+	return command
+}
+
+func (opts *sessionReplayCmdOptions) run(cmd *cobra.Command, args []string) error {
+	sessionId := args[0]
+	if opts.speedFlag <= 0 {
+		return errors.New("invalid speed")
+	}
+
+	path, err := session.FindSession(opts.configRef.Config.Session.Dir, sessionId)
+	if err != nil {
+		log.Warn().Err(err).Msgf("error session replay: id=%s", sessionId)
+		return errors.New("not found")
+	}
+	header, err := session.ReadHeader(path)
+	if err != nil {
+		log.Warn().Err(err).Msgf("error session header: path=%s", path)
+		return errors.New("invalid session")
+	}
+	if size, ok := terminal.GetSize(os.Stdout); ok && (size.Width < header.Width || size.Height < header.Height) {
+		fmt.Fprintln(os.Stderr, fmt.Sprintf("the terminal is smaller than the recording: expected=%dx%d", header.Width, header.Height))
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		log.Warn().Err(err).Msgf("error opening session: path=%s", path)
+		return errors.New("invalid session")
+	}
+	defer file.Close()
+
+	replayOpts := &session.ReplayOptions{
+		Speed:   opts.speedFlag,
+		MaxIdle: opts.maxIdleFlag,
+	}
+	if err := session.Replay(file, os.Stdout, replayOpts); err != nil {
+		log.Warn().Err(err).Msgf("error session replay: path=%s", path)
+		return errors.New("replay error")
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package session
+
+import (
+	"github.com/MakeNowJust/heredoc"
+	"github.com/spf13/cobra"
+
+	"github.com/hckops/hckctl/internal/command/config"
+)
+
+func NewSessionCmd(configRef *config.ConfigRef) *cobra.Command {
+
+	command := &cobra.Command{
+		Use:   "session",
+		Short: "List and replay recorded sessions",
+		Long: heredoc.Doc(`
+			List and replay recorded sessions
+
+			  Sessions started with "--record" are saved in asciicast v2 format under "session.dir",
+			  including the output, what was typed and the terminal size. Recordings are compatible
+			  with asciinema e.g. "asciinema play <PATH>".
+		`),
+		Example: heredoc.Doc(`
+
+			# records a temporary box
+			hckctl box alpine --record
+
+			# lists all the recordings
+			hckctl session list
+
+			# replays a recording twice as fast, limiting the pauses to 1 second
+			hckctl session replay <ID> --speed 2 --max-idle 1s
+		`),
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.HelpFunc()(cmd, args)
+		},
+	}
+
+	command.AddCommand(NewSessionListCmd(configRef))
+	command.AddCommand(NewSessionReplayCmd(configRef))
+
+	return command
+}
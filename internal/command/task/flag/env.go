@@ -0,0 +1,78 @@
+package flag
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	commonFlag "github.com/hckops/hckctl/internal/command/common/flag"
+	"github.com/hckops/hckctl/pkg/util"
+)
+
+type EnvFlag struct {
+	Values   []string
+	FilePath string
+}
+
+func (f *EnvFlag) IsEmpty() bool {
+	return len(f.Values) == 0 && f.FilePath == ""
+}
+
+func AddEnvFlag(command *cobra.Command) *EnvFlag {
+	const (
+		envFlagName      = "env"
+		envFlagUsage     = "set environment variables KEY=VALUE, values support secret references e.g. secret:<name>"
+		envFileFlagName  = "env-file"
+		envFileFlagUsage = "read environment variables from a file, one KEY=VALUE per line"
+	)
+	envFlag := &EnvFlag{}
+	command.Flags().StringArrayVarP(&envFlag.Values, envFlagName, commonFlag.NoneFlagShortHand, []string{}, envFlagUsage)
+	command.Flags().StringVarP(&envFlag.FilePath, envFileFlagName, commonFlag.NoneFlagShortHand, "", envFileFlagUsage)
+	return envFlag
+}
+
+// ValidateEnvFlag returns the file values first, the flag values override them
+func ValidateEnvFlag(envFlag *EnvFlag) ([]string, error) {
+	var envs []string
+	if envFlag.FilePath != "" {
+		data, err := os.ReadFile(envFlag.FilePath)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid env file %s", envFlag.FilePath)
+		}
+		fileEnvs, err := parseEnvFile(string(data))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid env file %s", envFlag.FilePath)
+		}
+		envs = append(envs, fileEnvs...)
+	}
+	for index, value := range envFlag.Values {
+		// never prints the value
+		if _, _, err := util.SplitKeyValue(value); err != nil {
+			return nil, fmt.Errorf("invalid env flag at position %d", index+1)
+		}
+		envs = append(envs, value)
+	}
+	return envs, nil
+}
+
+// parseEnvFile ignores empty lines and comments, the values are not unquoted nor expanded
+func parseEnvFile(content string) ([]string, error) {
+	var envs []string
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for line := 1; scanner.Scan(); line++ {
+		value := strings.TrimSpace(scanner.Text())
+		if value == "" || strings.HasPrefix(value, "#") {
+			continue
+		}
+		value = strings.TrimPrefix(value, "export ")
+		if _, _, err := util.SplitKeyValue(value); err != nil {
+			return nil, fmt.Errorf("invalid env at line %d", line)
+		}
+		envs = append(envs, value)
+	}
+	return envs, scanner.Err()
+}
@@ -0,0 +1,41 @@
+package flag
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseEnvFile(t *testing.T) {
+	content := `
+# shodan
+SHODAN_API_KEY=secret:shodan
+export LIMIT=10
+
+QUERY=a=b
+`
+	envs, err := parseEnvFile(content)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"SHODAN_API_KEY=secret:shodan", "LIMIT=10", "QUERY=a=b"}, envs)
+
+	_, err = parseEnvFile("LIMIT=10\nINVALID\n")
+	assert.EqualError(t, err, "invalid env at line 2")
+}
+
+func TestValidateEnvFlag(t *testing.T) {
+	envFile := filepath.Join(t.TempDir(), ".env")
+	assert.NoError(t, os.WriteFile(envFile, []byte("LIMIT=10\nMODE=passive\n"), 0600))
+
+	envs, err := ValidateEnvFlag(&EnvFlag{Values: []string{"MODE=active"}, FilePath: envFile})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"LIMIT=10", "MODE=passive", "MODE=active"}, envs)
+
+	_, err = ValidateEnvFlag(&EnvFlag{Values: []string{"MODE=active", "TOKEN"}})
+	assert.EqualError(t, err, "invalid env flag at position 2")
+
+	empty, err := ValidateEnvFlag(&EnvFlag{})
+	assert.NoError(t, err)
+	assert.Empty(t, empty)
+}
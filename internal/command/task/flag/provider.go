@@ -13,6 +13,7 @@ func taskProviders() []commonFlag.ProviderFlag {
 	return []commonFlag.ProviderFlag{
 		commonFlag.DockerProviderFlag,
 		commonFlag.KubeProviderFlag,
+		commonFlag.CloudProviderFlag,
 	}
 }
 
@@ -22,6 +23,8 @@ func toTaskProvider(p commonFlag.ProviderFlag) (model.TaskProvider, error) {
 		return model.Docker, nil
 	case commonFlag.KubeProviderFlag:
 		return model.Kubernetes, nil
+	case commonFlag.CloudProviderFlag:
+		return model.Cloud, nil
 	default:
 		return commonFlag.UnknownProvider, errors.New("invalid provider")
 	}
@@ -0,0 +1,43 @@
+package flag
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hckops/hckctl/internal/command/common/flag"
+	"github.com/hckops/hckctl/pkg/task/model"
+)
+
+func TestTaskProviders(t *testing.T) {
+	assert.Equal(t, 3, len(taskProviders()))
+	assert.Equal(t, "docker", taskProviders()[0].String())
+	assert.Equal(t, "kube", taskProviders()[1].String())
+	assert.Equal(t, "cloud", taskProviders()[2].String())
+}
+
+func TestToTaskProvider(t *testing.T) {
+	docker, err := toTaskProvider(flag.DockerProviderFlag)
+	assert.NoError(t, err)
+	assert.Equal(t, model.Docker, docker)
+
+	kube, err := toTaskProvider(flag.KubeProviderFlag)
+	assert.NoError(t, err)
+	assert.Equal(t, model.Kubernetes, kube)
+
+	cloud, err := toTaskProvider(flag.CloudProviderFlag)
+	assert.NoError(t, err)
+	assert.Equal(t, model.Cloud, cloud)
+
+	_, err = toTaskProvider(flag.UnknownProviderFlag)
+	assert.EqualError(t, err, "invalid provider")
+}
+
+func TestValidateTaskProviderFlag(t *testing.T) {
+	var taskProviderFlag flag.ProviderFlag
+	taskProviderFlag = flag.CloudProviderFlag
+	taskProvider, err := ValidateTaskProviderFlag("docker", &taskProviderFlag)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "cloud", taskProvider.String())
+}
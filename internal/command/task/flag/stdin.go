@@ -0,0 +1,16 @@
+package flag
+
+import (
+	"github.com/spf13/cobra"
+
+	commonFlag "github.com/hckops/hckctl/internal/command/common/flag"
+)
+
+func AddStdinFlag(command *cobra.Command, value *bool) string {
+	const (
+		flagName  = "stdin"
+		flagUsage = "stream the standard input into the task e.g. cat urls.txt | hckctl task httpx --stdin"
+	)
+	command.Flags().BoolVarP(value, flagName, commonFlag.NoneFlagShortHand, false, flagUsage)
+	return flagName
+}
@@ -0,0 +1,26 @@
+package flag
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	commonFlag "github.com/hckops/hckctl/internal/command/common/flag"
+)
+
+func AddTimeoutFlag(command *cobra.Command, value *time.Duration) string {
+	const (
+		flagName  = "timeout"
+		flagUsage = "kill the task after a duration e.g. 30m, overrides the timeout of the command"
+	)
+	command.Flags().DurationVarP(value, flagName, commonFlag.NoneFlagShortHand, 0, flagUsage)
+	return flagName
+}
+
+func ValidateTimeoutFlag(value time.Duration) error {
+	if value < 0 {
+		return fmt.Errorf("invalid timeout %s", value)
+	}
+	return nil
+}
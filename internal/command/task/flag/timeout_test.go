@@ -0,0 +1,14 @@
+package flag
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateTimeoutFlag(t *testing.T) {
+	assert.NoError(t, ValidateTimeoutFlag(0))
+	assert.NoError(t, ValidateTimeoutFlag(30*time.Minute))
+	assert.EqualError(t, ValidateTimeoutFlag(-time.Second), "invalid timeout -1s")
+}
@@ -1,18 +1,24 @@
 package task
 
 import (
+	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/MakeNowJust/heredoc"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 
+	auditCmd "github.com/hckops/hckctl/internal/command/audit"
 	commonCmd "github.com/hckops/hckctl/internal/command/common"
 	commonFlag "github.com/hckops/hckctl/internal/command/common/flag"
 	"github.com/hckops/hckctl/internal/command/config"
+	sessionCmd "github.com/hckops/hckctl/internal/command/session"
 	taskFlag "github.com/hckops/hckctl/internal/command/task/flag"
+	"github.com/hckops/hckctl/internal/command/version"
+	"github.com/hckops/hckctl/pkg/audit"
 	commonModel "github.com/hckops/hckctl/pkg/common/model"
 	"github.com/hckops/hckctl/pkg/schema"
 	"github.com/hckops/hckctl/pkg/task"
@@ -24,12 +30,18 @@ type taskCmdOptions struct {
 	configRef *config.ConfigRef
 	// flags
 	commandFlag        *taskFlag.CommandFlag
+	envFlag            *taskFlag.EnvFlag
+	ignoreScopeFlag    bool
 	networkVpnFlag     string
 	providerFlag       *commonFlag.ProviderFlag
+	recordFlag         bool
+	stdinFlag          bool
+	timeoutFlag        time.Duration
 	templateSourceFlag *commonFlag.TemplateSourceFlag
 	// internal
 	provider   taskModel.TaskProvider
 	parameters commonModel.Parameters
+	envs       []string
 }
 
 func NewTaskCmd(configRef *config.ConfigRef) *cobra.Command {
@@ -56,6 +68,16 @@ func NewTaskCmd(configRef *config.ConfigRef) *cobra.Command {
 	opts.providerFlag = taskFlag.AddTaskProviderFlag(command)
 	// --revision or --local
 	opts.templateSourceFlag = commonFlag.AddTemplateSourceFlag(command)
+	// --record
+	commonFlag.AddRecordFlag(command, &opts.recordFlag)
+	// --ignore-scope
+	commonFlag.AddIgnoreScopeFlag(command, &opts.ignoreScopeFlag)
+	// --env or --env-file
+	opts.envFlag = taskFlag.AddEnvFlag(command)
+	// --stdin
+	taskFlag.AddStdinFlag(command, &opts.stdinFlag)
+	// --timeout
+	taskFlag.AddTimeoutFlag(command, &opts.timeoutFlag)
 
 	return command
 }
@@ -79,11 +101,31 @@ func (opts *taskCmdOptions) validate(cmd *cobra.Command, args []string) error {
 	} else if vpnNetworkInfo != nil && opts.provider == taskModel.Cloud {
 		return fmt.Errorf("%s: use flow", commonFlag.ErrorFlagNotSupported)
 	}
+	// env (after provider validation)
+	if validEnvs, err := taskFlag.ValidateEnvFlag(opts.envFlag); err != nil {
+		return err
+	} else if !opts.envFlag.IsEmpty() && opts.provider == taskModel.Cloud {
+		return fmt.Errorf("%s: env", commonFlag.ErrorFlagNotSupported)
+	} else {
+		opts.envs = validEnvs
+	}
+	// stdin (after provider validation)
+	if opts.stdinFlag && opts.provider == taskModel.Cloud {
+		return fmt.Errorf("%s: stdin", commonFlag.ErrorFlagNotSupported)
+	}
+	// timeout (after provider validation)
+	if err := taskFlag.ValidateTimeoutFlag(opts.timeoutFlag); err != nil {
+		return err
+	} else if opts.timeoutFlag > 0 && opts.provider == taskModel.Cloud {
+		return fmt.Errorf("%s: timeout", commonFlag.ErrorFlagNotSupported)
+	}
 	// source
 	if err := commonFlag.ValidateTemplateSourceFlag(opts.providerFlag, opts.templateSourceFlag); err != nil {
 		log.Warn().Err(err).Msgf(commonFlag.ErrorFlagNotSupported)
 		return errors.New(commonFlag.ErrorFlagNotSupported)
 	}
+	// record
+	opts.recordFlag = commonFlag.ValidateRecordFlag(cmd, opts.recordFlag, opts.configRef.Config.Session.Record)
 	return nil
 }
 
@@ -94,7 +136,7 @@ func (opts *taskCmdOptions) run(cmd *cobra.Command, args []string) error {
 		log.Debug().Msgf("run task from local template: path=%s", path)
 
 		sourceLoader := template.NewLocalCachedLoader[taskModel.TaskV1](path, opts.configRef.Config.Template.CacheDir)
-		return opts.runTask(sourceLoader, taskModel.NewTaskLabels().AddDefaultLocal(), args[1:])
+		return opts.runTask(cmd.Context(), sourceLoader, taskModel.NewTaskLabels().AddDefaultLocal(), args[1:])
 
 	} else {
 		name := args[0]
@@ -103,11 +145,11 @@ func (opts *taskCmdOptions) run(cmd *cobra.Command, args []string) error {
 		sourceOpts := commonCmd.NewGitSourceOptions(opts.configRef.Config.Template.CacheDir, opts.templateSourceFlag.Revision)
 		sourceLoader := template.NewGitLoader[taskModel.TaskV1](sourceOpts, name)
 		labels := taskModel.NewTaskLabels().AddDefaultGit(sourceOpts.RepositoryUrl, sourceOpts.DefaultRevision, sourceOpts.CacheDirName())
-		return opts.runTask(sourceLoader, labels, args[1:])
+		return opts.runTask(cmd.Context(), sourceLoader, labels, args[1:])
 	}
 }
 
-func (opts *taskCmdOptions) runTask(sourceLoader template.SourceLoader[taskModel.TaskV1], labels commonModel.Labels, inlineArguments []string) error {
+func (opts *taskCmdOptions) runTask(ctx context.Context, sourceLoader template.SourceLoader[taskModel.TaskV1], labels commonModel.Labels, inlineArguments []string) error {
 
 	info, err := sourceLoader.Read()
 	if err != nil || info.Value.Kind != schema.KindTaskV1 {
@@ -123,15 +165,27 @@ func (opts *taskCmdOptions) runTask(sourceLoader template.SourceLoader[taskModel
 
 	log.Info().Msgf("loading template: provider=%s name=%s\n%s", opts.provider, templateName, info.Value.Data.Pretty())
 
-	taskClient, err := newDefaultTaskClient(opts.provider, opts.configRef, loader)
+	// refuses before any container is created
+	targetScope, err := commonCmd.LoadScope(opts.configRef.Config.Scope.Path, opts.ignoreScopeFlag)
+	if err != nil {
+		return err
+	}
+
+	taskClient, err := newDefaultTaskClient(ctx, opts.provider, opts.configRef, loader, info.Value.Data.Name)
 	if err != nil {
 		return err
 	}
 
 	var arguments []string
+	timeout := opts.timeoutFlag
 	if opts.commandFlag.Inline {
 		log.Info().Msgf("run task inline arguments=[%s]", strings.Join(inlineArguments, ","))
 
+		if targetScope != nil {
+			if err := targetScope.ValidateTargets(inlineArguments); err != nil {
+				return commonCmd.ScopeError(err)
+			}
+		}
 		arguments = inlineArguments
 	} else {
 		taskCommand, err := info.Value.Data.LoadCommand(opts.commandFlag.Preset)
@@ -139,8 +193,10 @@ func (opts *taskCmdOptions) runTask(sourceLoader template.SourceLoader[taskModel
 			log.Warn().Err(err).Msg("error loading command")
 			return errors.New("invalid command")
 		}
-		expandedArguments, err := taskCommand.ExpandCommandArguments(opts.parameters)
-		if err != nil {
+		expandedArguments, err := taskCommand.ExpandCommandArguments(opts.parameters, targetScope)
+		if scopeErr := commonCmd.ScopeError(err); scopeErr != nil {
+			return scopeErr
+		} else if err != nil {
 			log.Warn().Err(err).Msg("error expanding command arguments")
 			return errors.New("invalid command arguments")
 		}
@@ -148,10 +204,37 @@ func (opts *taskCmdOptions) runTask(sourceLoader template.SourceLoader[taskModel
 			taskCommand.Name, strings.Join(taskCommand.Arguments, ","), opts.parameters, strings.Join(expandedArguments, ","))
 
 		arguments = expandedArguments
+
+		// the cloud server enforces the timeout of the command
+		if commandTimeout, err := taskCommand.ParseTimeout(); err != nil {
+			log.Warn().Err(err).Msg("error parsing command timeout")
+			return errors.New("invalid command timeout")
+		} else if timeout == 0 && opts.provider != taskModel.Cloud {
+			timeout = commandTimeout
+		}
+	}
+	if timeout > 0 {
+		log.Info().Msgf("run task timeout=%s", timeout)
+	}
+
+	// the cloud server resolves the template env
+	var envs []taskModel.TaskEnv
+	if opts.provider != taskModel.Cloud {
+		envs, err = info.Value.Data.ExpandEnv(opts.parameters, opts.envs, opts.configRef.SecretLookup)
+		if err != nil {
+			log.Warn().Err(err).Msg("error expanding env")
+			return errors.New("invalid env")
+		}
+		// never logs the values
+		var keys []string
+		for _, env := range envs {
+			keys = append(keys, env.Key)
+		}
+		log.Info().Msgf("run task env=[%s]", strings.Join(keys, ","))
 	}
 
 	var networkVpn *commonModel.NetworkVpnInfo
-	if networkVpnInfo, err := opts.configRef.Config.Network.ToNetworkVpnInfo(opts.networkVpnFlag); err != nil {
+	if networkVpnInfo, err := opts.configRef.Config.Network.ToNetworkVpnInfo(opts.networkVpnFlag, opts.configRef.SecretLookup); err != nil {
 		log.Warn().Err(err).Msg("error invalid vpn config")
 		return err
 	} else if networkVpnInfo != nil {
@@ -159,38 +242,100 @@ func (opts *taskCmdOptions) runTask(sourceLoader template.SourceLoader[taskModel
 		networkVpn = networkVpnInfo
 	}
 
+	streamOpts := commonModel.NewStdStreamOpts(false)
+	if opts.recordFlag {
+		recordOpts, stopRecording, err := sessionCmd.RecordStreams(opts.configRef, info.Value.Data.Name, streamOpts)
+		if err != nil {
+			return err
+		}
+		defer stopRecording()
+		streamOpts = recordOpts
+	}
+	if !opts.stdinFlag {
+		// the providers attach only if not nil, the recording still detects the terminal size
+		streamOpts.In = nil
+	}
+
 	runOpts := &taskModel.RunOptions{
 		Template: &info.Value.Data,
 		Labels:   commonCmd.AddTemplateLabels[taskModel.TaskV1](info, labels),
 		CommonInfo: commonModel.CommonInfo{
 			NetworkVpn: networkVpn,
-			ShareDir:   opts.configRef.Config.Common.ToShareDirInfo(true),
+			ShareDir:   opts.configRef.Config.Common.ToShareDirInfo(true, true),
 		},
-		StreamOpts: commonModel.NewStdStreamOpts(false),
+		StreamOpts: streamOpts,
 		Arguments:  arguments,
+		Env:        envs,
+		Timeout:    timeout,
 		LogDir:     opts.configRef.Config.Task.LogDir,
 	}
 
-	if err := taskClient.Run(runOpts); err != nil {
+	auditRecord := &audit.Record{
+		Provider:  opts.provider.String(),
+		Name:      info.Value.Data.Name,
+		Template:  auditCmd.NewTemplateRef(info, opts.configRef.Config.Template.CacheDir),
+		Arguments: arguments,
+		Targets:   auditCmd.Targets(arguments),
+	}
+	if networkVpn != nil {
+		auditRecord.NetworkVpn = networkVpn.Name
+	}
+	startTime := time.Now().UTC()
+	auditStart := *auditRecord
+	auditStart.Action = audit.TaskStartAction
+	auditStart.StartTime = &startTime
+	auditCmd.Record(opts.configRef, &auditStart)
+
+	exitCode, err := taskClient.Run(ctx, runOpts)
+
+	stopTime := time.Now().UTC()
+	auditStop := *auditRecord
+	auditStop.Action = audit.TaskStopAction
+	auditStop.StartTime = &startTime
+	auditStop.StopTime = &stopTime
+	if err != nil {
+		auditStop.Error = err.Error()
+	} else if exitCode >= 0 {
+		// omitted if the provider does not report it
+		auditStop.ExitCode = &exitCode
+	}
+	auditCmd.Record(opts.configRef, &auditStop)
+
+	var timeoutErr *taskModel.TimeoutError
+	if errors.As(err, &timeoutErr) {
+		log.Warn().Err(err).Msgf("timeout run task: logFileName=%s", timeoutErr.LogFileName)
+		return fmt.Errorf("%s, partial output file: %s", timeoutErr.Error(), timeoutErr.LogFileName)
+	} else if err != nil {
 		log.Warn().Err(err).Msg("error run task")
 		return errors.New("error run task")
 	}
 	return nil
 }
 
-func newDefaultTaskClient(provider taskModel.TaskProvider, configRef *config.ConfigRef, loader *commonCmd.Loader) (task.TaskClient, error) {
+func newDefaultTaskClient(ctx context.Context, provider taskModel.TaskProvider, configRef *config.ConfigRef, loader *commonCmd.Loader, templateName string) (task.TaskClient, error) {
 	taskClientOpts := &taskModel.TaskClientOptions{
 		Provider:   provider,
 		DockerOpts: configRef.Config.Provider.Docker.ToDockerOptions(),
 		KubeOpts:   configRef.Config.Provider.Kube.ToKubeOptions(),
 	}
+	// unlocks the secret store only if required
+	if provider == taskModel.Cloud {
+		cloudOpts, err := configRef.Config.Provider.Cloud.ToCloudOptions(version.ClientVersion(), configRef.SecretLookup)
+		if err != nil {
+			log.Warn().Err(err).Msgf("error task client options provider=%s", provider)
+			return nil, fmt.Errorf("error %s client", provider)
+		}
+		taskClientOpts.CloudOpts = cloudOpts
+	}
 
-	taskClient, err := task.NewTaskClient(taskClientOpts)
+	taskClient, err := task.NewTaskClient(ctx, taskClientOpts)
 	if err != nil {
 		log.Error().Err(err).Msgf("error task client provider=%s", provider)
 		return nil, fmt.Errorf("error %s client", provider)
 	}
 
-	taskClient.Events().Subscribe(commonCmd.EventCallback(loader))
+	commonCmd.SubscribeEvents(taskClient.Events(), loader)
+	auditCmd.SubscribeEvents(configRef, taskClient.Events())
+	configRef.EventStream.Subscribe(taskClient.Events(), provider.String(), templateName)
 	return taskClient, nil
 }
@@ -1,14 +1,32 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/hckops/hckctl/internal/command"
+	commonCmd "github.com/hckops/hckctl/internal/command/common"
 )
 
 func main() {
-	if err := command.NewRootCmd().Execute(); err != nil {
+	// the commands are cancelled on CTRL+C, a second interrupt terminates immediately
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	err := command.NewRootCmd().ExecuteContext(ctx)
+	stop()
+	if err != nil {
+		var exitCodeErr *commonCmd.ExitCodeError
+		if errors.As(err, &exitCodeErr) {
+			os.Exit(exitCodeErr.Code)
+		}
 		fmt.Println(err)
 		os.Exit(1)
 	}
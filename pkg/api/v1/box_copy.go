@@ -0,0 +1,21 @@
+package v1
+
+const (
+	BoxCopyUpload   = "upload"
+	BoxCopyDownload = "download"
+)
+
+// BoxCopySessionBody streams a tar archive over the session: the entries are nested under the base name of the path
+type BoxCopySessionBody struct {
+	Name      string `json:"name"`
+	Path      string `json:"path"`
+	Direction string `json:"direction"`
+}
+
+func (b BoxCopySessionBody) method() MethodName {
+	return MethodBoxCopy
+}
+
+func NewBoxCopySession(origin string, name string, path string, direction string) *Message[BoxCopySessionBody] {
+	return newMessage[BoxCopySessionBody](origin, BoxCopySessionBody{Name: name, Path: path, Direction: direction})
+}
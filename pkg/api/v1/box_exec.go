@@ -1,7 +1,8 @@
 package v1
 
 type BoxExecSessionBody struct {
-	Name string `json:"name"`
+	Name    string   `json:"name"`
+	Command []string `json:"command,omitempty"` // default shell if empty
 }
 
 func (b BoxExecSessionBody) method() MethodName {
@@ -11,3 +12,7 @@ func (b BoxExecSessionBody) method() MethodName {
 func NewBoxExecSession(origin string, name string) *Message[BoxExecSessionBody] {
 	return newMessage[BoxExecSessionBody](origin, BoxExecSessionBody{Name: name})
 }
+
+func NewBoxExecCommandSession(origin string, name string, command []string) *Message[BoxExecSessionBody] {
+	return newMessage[BoxExecSessionBody](origin, BoxExecSessionBody{Name: name, Command: command})
+}
@@ -0,0 +1,17 @@
+package v1
+
+type BoxLogsSessionBody struct {
+	Name    string `json:"name"`
+	Follow  bool   `json:"follow"`
+	Since   string `json:"since,omitempty"` // duration e.g. "10m0s", empty for all
+	Tail    int    `json:"tail"`            // negative for all
+	Sidecar string `json:"sidecar,omitempty"`
+}
+
+func (b BoxLogsSessionBody) method() MethodName {
+	return MethodBoxLogs
+}
+
+func NewBoxLogsSession(origin string, body BoxLogsSessionBody) *Message[BoxLogsSessionBody] {
+	return newMessage[BoxLogsSessionBody](origin, body)
+}
@@ -0,0 +1,121 @@
+package v1
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/hckops/hckctl/pkg/schema"
+)
+
+type ErrorCode string
+
+const (
+	ErrorInternal            ErrorCode = "internal"
+	ErrorInvalidRequest      ErrorCode = "invalid-request"
+	ErrorUnsupported         ErrorCode = "unsupported"
+	ErrorNotFound            ErrorCode = "not-found"
+	ErrorQuotaExceeded       ErrorCode = "quota-exceeded"
+	ErrorUnauthorized        ErrorCode = "unauthorized"
+	ErrorInvalidTemplate     ErrorCode = "invalid-template"
+	ErrorIncompatibleVersion ErrorCode = "incompatible-version"
+)
+
+// typed errors matched with errors.Is
+var (
+	ErrNotFound            = errors.New("not found")
+	ErrQuotaExceeded       = errors.New("quota exceeded")
+	ErrUnauthorized        = errors.New("unauthorized")
+	ErrInvalidTemplate     = errors.New("invalid template")
+	ErrIncompatibleVersion = errors.New("incompatible version")
+)
+
+var errorCodes = map[ErrorCode]error{
+	ErrorNotFound:            ErrNotFound,
+	ErrorQuotaExceeded:       ErrQuotaExceeded,
+	ErrorUnauthorized:        ErrUnauthorized,
+	ErrorInvalidTemplate:     ErrInvalidTemplate,
+	ErrorIncompatibleVersion: ErrIncompatibleVersion,
+}
+
+type ErrorBody struct {
+	Code    ErrorCode         `json:"code"`
+	Message string            `json:"message"`
+	Details map[string]string `json:"details,omitempty"`
+}
+
+// the method of an error is always the one of the failed request, see NewErrorMessage
+func (b ErrorBody) method() MethodName {
+	return -1
+}
+
+func NewErrorMessage(origin string, methodName MethodName, requestId string, body ErrorBody) *Message[ErrorBody] {
+	return &Message[ErrorBody]{
+		Kind:      schema.KindApiV1.String(),
+		Origin:    origin,
+		Method:    methodName.String(),
+		RequestId: requestId,
+		Body:      body,
+	}
+}
+
+// ApiError is the client representation of an error message
+type ApiError struct {
+	Origin    string
+	Method    string
+	RequestId string
+	Code      ErrorCode
+	Message   string
+	Details   map[string]string
+}
+
+func NewApiError(code ErrorCode, message string) *ApiError {
+	return &ApiError{Code: code, Message: message}
+}
+
+func (e *ApiError) Error() string {
+	return fmt.Sprintf("api error: code=%s message=%s requestId=%s", e.Code, e.Message, e.RequestId)
+}
+
+func (e *ApiError) Is(target error) bool {
+	if err, ok := errorCodes[e.Code]; ok {
+		return err == target
+	}
+	return false
+}
+
+func (e *ApiError) Body() ErrorBody {
+	return ErrorBody{Code: e.Code, Message: e.Message, Details: e.Details}
+}
+
+// DecodeError returns false if the value is not an error message e.g. legacy plain text responses
+func DecodeError(value string) (*ApiError, bool) {
+	message, err := Decode[ErrorBody](value)
+	if err != nil || message.Kind != schema.KindApiV1.String() || message.Body.Code == "" {
+		return nil, false
+	}
+	return &ApiError{
+		Origin:    message.Origin,
+		Method:    message.Method,
+		RequestId: message.RequestId,
+		Code:      message.Body.Code,
+		Message:   message.Body.Message,
+		Details:   message.Body.Details,
+	}, true
+}
+
+// responseError is implemented by the transport errors which carry the raw server response
+type responseError interface {
+	Response() string
+}
+
+// ToError maps a failed request to an ApiError, or returns the original error
+func ToError(err error) error {
+	var response responseError
+	if errors.As(err, &response) {
+		if apiError, ok := DecodeError(response.Response()); ok {
+			return apiError
+		}
+	}
+	return err
+}
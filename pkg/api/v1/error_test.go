@@ -0,0 +1,108 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testResponseError struct {
+	response string
+}
+
+func (e *testResponseError) Error() string {
+	return e.response
+}
+
+func (e *testResponseError) Response() string {
+	return e.response
+}
+
+func TestErrorMessage(t *testing.T) {
+	body := ErrorBody{Code: ErrorNotFound, Message: "box not found", Details: map[string]string{"name": "box-alpine-123"}}
+	message := NewErrorMessage(serverOrigin, MethodBoxDescribe, "abc123", body)
+	value := `{"kind":"api/v1","origin":"hckadm-0.0.0-info","method":"hck-box-describe","requestId":"abc123","body":{"code":"not-found","message":"box not found","details":{"name":"box-alpine-123"}}}`
+
+	testMessage[ErrorBody](t, message, value)
+}
+
+func TestDecodeError(t *testing.T) {
+	value := `{"kind":"api/v1","origin":"hckadm-0.0.0-info","method":"hck-box-create","requestId":"abc123","body":{"code":"quota-exceeded","message":"too many boxes"}}`
+	expected := &ApiError{
+		Origin:    serverOrigin,
+		Method:    "hck-box-create",
+		RequestId: "abc123",
+		Code:      ErrorQuotaExceeded,
+		Message:   "too many boxes",
+	}
+	apiError, ok := DecodeError(value)
+	assert.True(t, ok)
+	assert.Equal(t, expected, apiError)
+
+	_, ok = DecodeError("method not supported hck-box-create")
+	assert.False(t, ok)
+	_, ok = DecodeError(`{"kind":"api/v1","method":"hck-ping","body":{"value":"pong"}}`)
+	assert.False(t, ok)
+}
+
+func TestApiErrorIs(t *testing.T) {
+	assert.ErrorIs(t, NewApiError(ErrorNotFound, ""), ErrNotFound)
+	assert.ErrorIs(t, NewApiError(ErrorQuotaExceeded, ""), ErrQuotaExceeded)
+	assert.ErrorIs(t, NewApiError(ErrorUnauthorized, ""), ErrUnauthorized)
+	assert.ErrorIs(t, NewApiError(ErrorInvalidTemplate, ""), ErrInvalidTemplate)
+	assert.ErrorIs(t, NewApiError(ErrorIncompatibleVersion, ""), ErrIncompatibleVersion)
+	assert.NotErrorIs(t, NewApiError(ErrorInternal, ""), ErrNotFound)
+}
+
+func TestToError(t *testing.T) {
+	value := `{"kind":"api/v1","origin":"hckadm-0.0.0-info","method":"hck-box-create","body":{"code":"unauthorized","message":"forbidden"}}`
+	err := ToError(fmt.Errorf("wrapped: %w", &testResponseError{response: value}))
+	assert.ErrorIs(t, err, ErrUnauthorized)
+	assert.EqualError(t, err, "api error: code=unauthorized message=forbidden requestId=")
+
+	legacy := &testResponseError{response: "method not supported hck-box-create"}
+	assert.Equal(t, legacy, ToError(legacy))
+
+	plain := errors.New("error ssh dial")
+	assert.Equal(t, plain, ToError(plain))
+}
+
+func TestIsCompatibleVersion(t *testing.T) {
+	assert.True(t, IsCompatibleVersion(""))
+	assert.True(t, IsCompatibleVersion("1.0"))
+	assert.True(t, IsCompatibleVersion("1.42"))
+	assert.False(t, IsCompatibleVersion("2.0"))
+	assert.False(t, IsCompatibleVersion("invalid"))
+}
+
+func TestNegotiate(t *testing.T) {
+	var requestId string
+	send := func(apiVersion string) func(context.Context, string, string) (string, error) {
+		return func(_ context.Context, protocol string, payload string) (string, error) {
+			assert.Equal(t, "api/v1/hck-ping", protocol)
+			request, err := Decode[PingBody](payload)
+			require.NoError(t, err)
+			requestId = request.RequestId
+
+			response := NewPongMessage(serverOrigin).WithRequestId(request.RequestId)
+			response.Body.ApiVersion = apiVersion
+			return response.Encode()
+		}
+	}
+
+	response, err := Negotiate(context.Background(), clientOrigin, send(""))
+	assert.NoError(t, err)
+	assert.Equal(t, requestId, response.RequestId)
+
+	_, err = Negotiate(context.Background(), clientOrigin, send("2.0"))
+	assert.ErrorIs(t, err, ErrIncompatibleVersion)
+
+	_, err = Negotiate(context.Background(), clientOrigin, func(context.Context, string, string) (string, error) {
+		return "", &testResponseError{response: `{"kind":"api/v1","method":"hck-ping","body":{"code":"unauthorized","message":"forbidden"}}`}
+	})
+	assert.ErrorIs(t, err, ErrUnauthorized)
+}
@@ -0,0 +1,25 @@
+package v1
+
+type LabDeleteRequestBody struct {
+	Names []string `json:"names"` // empty to delete all
+}
+
+func (b LabDeleteRequestBody) method() MethodName {
+	return MethodLabDelete
+}
+
+type LabDeleteResponseBody struct {
+	Names []string `json:"names"`
+}
+
+func (b LabDeleteResponseBody) method() MethodName {
+	return MethodLabDelete
+}
+
+func NewLabDeleteRequest(origin string, names []string) *Message[LabDeleteRequestBody] {
+	return newMessage[LabDeleteRequestBody](origin, LabDeleteRequestBody{Names: names})
+}
+
+func NewLabDeleteResponse(origin string, names []string) *Message[LabDeleteResponseBody] {
+	return newMessage[LabDeleteResponseBody](origin, LabDeleteResponseBody{Names: names})
+}
@@ -0,0 +1,40 @@
+package v1
+
+type LabDescribeRequestBody struct {
+	Name string `json:"name"`
+}
+
+func (b LabDescribeRequestBody) method() MethodName {
+	return MethodLabDescribe
+}
+
+type LabDescribeResponseBody struct {
+	Id           string             `json:"id"`
+	Name         string             `json:"name"`
+	Created      string             `json:"created"`
+	Healthy      bool               `json:"healthy"`
+	TemplateName string             `json:"templateName"`
+	Box          LabDescribeBoxInfo `json:"box"`
+}
+
+type LabDescribeBoxInfo struct {
+	Name         string   `json:"name"`
+	Alias        string   `json:"alias"`
+	TemplateName string   `json:"templateName"`
+	Size         string   `json:"size"`
+	Vpn          string   `json:"vpn"`
+	Ports        []string `json:"ports"` // public
+	Dumps        []string `json:"dumps"` // mounted
+}
+
+func (b LabDescribeResponseBody) method() MethodName {
+	return MethodLabDescribe
+}
+
+func NewLabDescribeRequest(origin string, name string) *Message[LabDescribeRequestBody] {
+	return newMessage[LabDescribeRequestBody](origin, LabDescribeRequestBody{Name: name})
+}
+
+func NewLabDescribeResponse(origin string, body LabDescribeResponseBody) *Message[LabDescribeResponseBody] {
+	return newMessage[LabDescribeResponseBody](origin, body)
+}
@@ -0,0 +1,29 @@
+package v1
+
+type LabListRequestBody struct{}
+
+func (b LabListRequestBody) method() MethodName {
+	return MethodLabList
+}
+
+type LabListResponseBody struct {
+	Items []LabListItem `json:"items"`
+}
+
+type LabListItem struct {
+	Id      string `json:"id"`
+	Name    string `json:"name"`
+	Healthy bool   `json:"healthy"`
+}
+
+func (b LabListResponseBody) method() MethodName {
+	return MethodLabList
+}
+
+func NewLabListRequest(origin string) *Message[LabListRequestBody] {
+	return newMessage[LabListRequestBody](origin, LabListRequestBody{})
+}
+
+func NewLabListResponse(origin string, items []LabListItem) *Message[LabListResponseBody] {
+	return newMessage[LabListResponseBody](origin, LabListResponseBody{Items: items})
+}
@@ -12,10 +12,11 @@ import (
 )
 
 type Message[T body] struct {
-	Kind   string `json:"kind"`
-	Origin string `json:"origin"`
-	Method string `json:"method"`
-	Body   T      `json:"body"` // TODO omitempty to remove "body":{}
+	Kind      string `json:"kind"`
+	Origin    string `json:"origin"`
+	Method    string `json:"method"`
+	RequestId string `json:"requestId,omitempty"` // set by the client and returned by the server
+	Body      T      `json:"body"`                // TODO omitempty to remove "body":{}
 }
 
 type body interface {
@@ -26,6 +27,16 @@ func (req *Message[T]) Protocol() string {
 	return fmt.Sprintf("%s/%s", req.Kind, req.Method)
 }
 
+// WithRequestId correlates a response or an error with its request
+func (req *Message[T]) WithRequestId(requestId string) *Message[T] {
+	req.RequestId = requestId
+	return req
+}
+
+func NewRequestId() string {
+	return util.RandomAlphanumeric(16)
+}
+
 func (req *Message[T]) Encode() (string, error) {
 	return util.EncodeJson(req)
 }
@@ -48,17 +59,51 @@ func newMessage[T body](origin string, body T) *Message[T] {
 }
 
 func IsValidProtocol(value string) (string, error) {
+	methodName, err := ParseProtocol(value)
+	if err != nil {
+		return "", err
+	}
+	return methodName.String(), nil
+}
+
+// ParseProtocol returns the method of a global request type e.g. "api/v1/hck-ping"
+func ParseProtocol(value string) (MethodName, error) {
 	schemaPrefix := fmt.Sprintf("%s/", schema.KindApiV1.String())
 
 	if !strings.HasPrefix(value, schemaPrefix) {
-		return "", errors.New("invalid protocol")
+		return -1, errors.New("invalid protocol")
 	}
 
 	methodValue := strings.ReplaceAll(value, schemaPrefix, "")
 	methodName, err := toMethodName(methodValue)
 	if err != nil {
-		return "", errors.Wrap(err, "invalid method")
+		return -1, errors.Wrap(err, "invalid method")
 	}
 
-	return methodName.String(), nil
+	return methodName, nil
+}
+
+type Header struct {
+	Kind      string `json:"kind"`
+	Origin    string `json:"origin"`
+	Method    string `json:"method"`
+	RequestId string `json:"requestId"`
+}
+
+// DecodeHeader returns the common fields of a message without decoding the body
+func DecodeHeader(value string) (*Header, error) {
+	var header Header
+	if err := json.Unmarshal([]byte(value), &header); err != nil {
+		return nil, errors.Wrap(err, "error decoding json")
+	}
+	return &header, nil
+}
+
+// DecodeMethod returns the method of a message without decoding the body
+func DecodeMethod(value string) (MethodName, error) {
+	header, err := DecodeHeader(value)
+	if err != nil {
+		return -1, err
+	}
+	return ParseProtocol(fmt.Sprintf("%s/%s", header.Kind, header.Method))
 }
@@ -15,14 +15,22 @@ const (
 var testBoxes = []string{"box-alpine-123", "box-alpine-456"}
 
 func TestMethods(t *testing.T) {
-	assert.Equal(t, 7, len(methods))
+	assert.Equal(t, 15, len(methods))
 	assert.Equal(t, "hck-ping", methods[MethodPing])
+	assert.Equal(t, "hck-box-copy", methods[MethodBoxCopy])
 	assert.Equal(t, "hck-box-create", methods[MethodBoxCreate])
 	assert.Equal(t, "hck-box-delete", methods[MethodBoxDelete])
 	assert.Equal(t, "hck-box-describe", methods[MethodBoxDescribe])
 	assert.Equal(t, "hck-box-exec", methods[MethodBoxExec])
 	assert.Equal(t, "hck-box-list", methods[MethodBoxList])
+	assert.Equal(t, "hck-box-logs", methods[MethodBoxLogs])
 	assert.Equal(t, "hck-lab-create", methods[MethodLabCreate])
+	assert.Equal(t, "hck-lab-delete", methods[MethodLabDelete])
+	assert.Equal(t, "hck-lab-describe", methods[MethodLabDescribe])
+	assert.Equal(t, "hck-lab-list", methods[MethodLabList])
+	assert.Equal(t, "hck-task-list", methods[MethodTaskList])
+	assert.Equal(t, "hck-task-logs", methods[MethodTaskLogs])
+	assert.Equal(t, "hck-task-run", methods[MethodTaskRun])
 }
 
 func TestIsValidProtocol(t *testing.T) {
@@ -37,20 +45,55 @@ func TestIsValidProtocol(t *testing.T) {
 	assert.Equal(t, "hck-ping", method)
 }
 
+func TestParseProtocol(t *testing.T) {
+	method, err := ParseProtocol("api/v1/hck-box-list")
+	assert.NoError(t, err)
+	assert.Equal(t, MethodBoxList, method)
+
+	_, err = ParseProtocol("api/v2/hck-box-list")
+	assert.EqualError(t, err, "invalid protocol")
+}
+
+func TestDecodeMethod(t *testing.T) {
+	method, err := DecodeMethod(`{"kind":"api/v1","origin":"hckctl-0.0.0-os","method":"hck-box-exec","body":{"name":"box-alpine-123"}}`)
+	assert.NoError(t, err)
+	assert.Equal(t, MethodBoxExec, method)
+
+	_, err = DecodeMethod(`{"kind":"api/v1","method":"hck-todo"}`)
+	assert.EqualError(t, err, "invalid method: method not found hck-todo")
+
+	_, err = DecodeMethod("invalid")
+	assert.Error(t, err)
+}
+
 func TestPingRequest(t *testing.T) {
 	message := NewPingMessage(clientOrigin)
-	value := `{"kind":"api/v1","origin":"hckctl-0.0.0-os","method":"hck-ping","body":{"value":"ping"}}`
+	value := `{"kind":"api/v1","origin":"hckctl-0.0.0-os","method":"hck-ping","body":{"value":"ping","apiVersion":"1.1"}}`
 
 	testMessage[PingBody](t, message, value)
 }
 
 func TestPingResponse(t *testing.T) {
 	message := NewPongMessage(serverOrigin)
-	value := `{"kind":"api/v1","origin":"hckadm-0.0.0-info","method":"hck-ping","body":{"value":"pong"}}`
+	value := `{"kind":"api/v1","origin":"hckadm-0.0.0-info","method":"hck-ping","body":{"value":"pong","apiVersion":"1.1"}}`
 
 	testMessage[PongBody](t, message, value)
 }
 
+func TestMessageRequestId(t *testing.T) {
+	message := NewBoxDescribeRequest(clientOrigin, testBoxes[0]).WithRequestId("abc123")
+	value := `{"kind":"api/v1","origin":"hckctl-0.0.0-os","method":"hck-box-describe","requestId":"abc123","body":{"name":"box-alpine-123"}}`
+
+	testMessage[BoxDescribeRequestBody](t, message, value)
+
+	header, err := DecodeHeader(value)
+	assert.NoError(t, err)
+	assert.Equal(t, &Header{Kind: "api/v1", Origin: clientOrigin, Method: "hck-box-describe", RequestId: "abc123"}, header)
+
+	assert.Equal(t, 16, len(NewRequestId()))
+	assert.NotEqual(t, NewRequestId(), NewRequestId())
+}
+
 func TestBoxCreateRequest(t *testing.T) {
 	message := NewBoxCreateRequest(clientOrigin, "alpine", "s")
 	value := `{"kind":"api/v1","origin":"hckctl-0.0.0-os","method":"hck-box-create","body":{"templateName":"alpine","size":"s"}}`
@@ -126,6 +169,13 @@ func TestBoxListResponse(t *testing.T) {
 	testMessage[BoxListResponseBody](t, message, value)
 }
 
+func TestBoxCopySession(t *testing.T) {
+	message := NewBoxCopySession(clientOrigin, "alpine", "/tmp/foo", BoxCopyUpload)
+	value := `{"kind":"api/v1","origin":"hckctl-0.0.0-os","method":"hck-box-copy","body":{"name":"alpine","path":"/tmp/foo","direction":"upload"}}`
+
+	testMessage[BoxCopySessionBody](t, message, value)
+}
+
 func TestBoxExecSession(t *testing.T) {
 	message := NewBoxExecSession(clientOrigin, "alpine")
 	value := `{"kind":"api/v1","origin":"hckctl-0.0.0-os","method":"hck-box-exec","body":{"name":"alpine"}}`
@@ -133,6 +183,20 @@ func TestBoxExecSession(t *testing.T) {
 	testMessage[BoxExecSessionBody](t, message, value)
 }
 
+func TestBoxExecCommandSession(t *testing.T) {
+	message := NewBoxExecCommandSession(clientOrigin, "alpine", []string{"ls", "-la"})
+	value := `{"kind":"api/v1","origin":"hckctl-0.0.0-os","method":"hck-box-exec","body":{"name":"alpine","command":["ls","-la"]}}`
+
+	testMessage[BoxExecSessionBody](t, message, value)
+}
+
+func TestBoxLogsSession(t *testing.T) {
+	message := NewBoxLogsSession(clientOrigin, BoxLogsSessionBody{Name: "alpine", Follow: true, Since: "10m0s", Tail: -1, Sidecar: "vpn"})
+	value := `{"kind":"api/v1","origin":"hckctl-0.0.0-os","method":"hck-box-logs","body":{"name":"alpine","follow":true,"since":"10m0s","tail":-1,"sidecar":"vpn"}}`
+
+	testMessage[BoxLogsSessionBody](t, message, value)
+}
+
 func TestLabCreateRequest(t *testing.T) {
 	parameters := map[string]string{
 		"password":        "changeme",
@@ -151,6 +215,98 @@ func TestLabCreateResponse(t *testing.T) {
 	testMessage[LabCreateResponseBody](t, message, value)
 }
 
+func TestLabListRequest(t *testing.T) {
+	message := NewLabListRequest(clientOrigin)
+	value := `{"kind":"api/v1","origin":"hckctl-0.0.0-os","method":"hck-lab-list","body":{}}`
+
+	testMessage[LabListRequestBody](t, message, value)
+}
+
+func TestLabListResponse(t *testing.T) {
+	message := NewLabListResponse(serverOrigin, []LabListItem{{Id: "123", Name: "lab-ctf-vpn-123", Healthy: true}})
+	value := `{"kind":"api/v1","origin":"hckadm-0.0.0-info","method":"hck-lab-list","body":{"items":[{"id":"123","name":"lab-ctf-vpn-123","healthy":true}]}}`
+
+	testMessage[LabListResponseBody](t, message, value)
+}
+
+func TestLabDescribeRequest(t *testing.T) {
+	message := NewLabDescribeRequest(clientOrigin, "lab-ctf-vpn-123")
+	value := `{"kind":"api/v1","origin":"hckctl-0.0.0-os","method":"hck-lab-describe","body":{"name":"lab-ctf-vpn-123"}}`
+
+	testMessage[LabDescribeRequestBody](t, message, value)
+}
+
+func TestLabDescribeResponse(t *testing.T) {
+	message := NewLabDescribeResponse(serverOrigin, LabDescribeResponseBody{
+		Id:           "myId",
+		Name:         "lab-ctf-vpn-123",
+		Created:      "myCreated",
+		Healthy:      true,
+		TemplateName: "ctf-vpn",
+		Box: LabDescribeBoxInfo{
+			Name:         "box-parrot-123",
+			Alias:        "myAlias",
+			TemplateName: "parrot",
+			Size:         "M",
+			Vpn:          "htb",
+			Ports:        []string{"tty/https://tty.example.com"},
+			Dumps:        []string{"dump-1"},
+		},
+	})
+	value := `{"kind":"api/v1","origin":"hckadm-0.0.0-info","method":"hck-lab-describe","body":{"id":"myId","name":"lab-ctf-vpn-123","created":"myCreated","healthy":true,"templateName":"ctf-vpn","box":{"name":"box-parrot-123","alias":"myAlias","templateName":"parrot","size":"M","vpn":"htb","ports":["tty/https://tty.example.com"],"dumps":["dump-1"]}}}`
+
+	testMessage[LabDescribeResponseBody](t, message, value)
+}
+
+func TestLabDeleteRequest(t *testing.T) {
+	message := NewLabDeleteRequest(clientOrigin, []string{"lab-ctf-vpn-123"})
+	value := `{"kind":"api/v1","origin":"hckctl-0.0.0-os","method":"hck-lab-delete","body":{"names":["lab-ctf-vpn-123"]}}`
+
+	testMessage[LabDeleteRequestBody](t, message, value)
+}
+
+func TestLabDeleteResponse(t *testing.T) {
+	message := NewLabDeleteResponse(serverOrigin, []string{"lab-ctf-vpn-123"})
+	value := `{"kind":"api/v1","origin":"hckadm-0.0.0-info","method":"hck-lab-delete","body":{"names":["lab-ctf-vpn-123"]}}`
+
+	testMessage[LabDeleteResponseBody](t, message, value)
+}
+
+func TestTaskRunRequest(t *testing.T) {
+	message := NewTaskRunRequest(clientOrigin, "nmap", []string{"-sV", "scanme.nmap.org"})
+	value := `{"kind":"api/v1","origin":"hckctl-0.0.0-os","method":"hck-task-run","body":{"templateName":"nmap","arguments":["-sV","scanme.nmap.org"]}}`
+
+	testMessage[TaskRunRequestBody](t, message, value)
+}
+
+func TestTaskRunResponse(t *testing.T) {
+	message := NewTaskRunResponse(serverOrigin, "task-nmap-123")
+	value := `{"kind":"api/v1","origin":"hckadm-0.0.0-info","method":"hck-task-run","body":{"name":"task-nmap-123"}}`
+
+	testMessage[TaskRunResponseBody](t, message, value)
+}
+
+func TestTaskLogsSession(t *testing.T) {
+	message := NewTaskLogsSession(clientOrigin, "task-nmap-123")
+	value := `{"kind":"api/v1","origin":"hckctl-0.0.0-os","method":"hck-task-logs","body":{"name":"task-nmap-123"}}`
+
+	testMessage[TaskLogsSessionBody](t, message, value)
+}
+
+func TestTaskListRequest(t *testing.T) {
+	message := NewTaskListRequest(clientOrigin)
+	value := `{"kind":"api/v1","origin":"hckctl-0.0.0-os","method":"hck-task-list","body":{}}`
+
+	testMessage[TaskListRequestBody](t, message, value)
+}
+
+func TestTaskListResponse(t *testing.T) {
+	message := NewTaskListResponse(serverOrigin, []TaskListItem{{Name: "task-nmap-123", Status: "running"}})
+	value := `{"kind":"api/v1","origin":"hckadm-0.0.0-info","method":"hck-task-list","body":{"items":[{"name":"task-nmap-123","status":"running"}]}}`
+
+	testMessage[TaskListResponseBody](t, message, value)
+}
+
 func testMessage[T body](t *testing.T, message *Message[T], value string) {
 	jsonString, err := message.Encode()
 	assert.NoError(t, err)
@@ -8,22 +8,38 @@ type MethodName int
 
 const (
 	MethodPing MethodName = iota
+	MethodBoxCopy
 	MethodBoxCreate
 	MethodBoxDelete
 	MethodBoxDescribe
 	MethodBoxExec
 	MethodBoxList
+	MethodBoxLogs
 	MethodLabCreate
+	MethodLabDelete
+	MethodLabDescribe
+	MethodLabList
+	MethodTaskList
+	MethodTaskLogs
+	MethodTaskRun
 )
 
 var methods = map[MethodName]string{
 	MethodPing:        "hck-ping",
+	MethodBoxCopy:     "hck-box-copy",
 	MethodBoxCreate:   "hck-box-create",
 	MethodBoxDelete:   "hck-box-delete",
 	MethodBoxDescribe: "hck-box-describe",
 	MethodBoxExec:     "hck-box-exec",
 	MethodBoxList:     "hck-box-list",
+	MethodBoxLogs:     "hck-box-logs",
 	MethodLabCreate:   "hck-lab-create",
+	MethodLabDelete:   "hck-lab-delete",
+	MethodLabDescribe: "hck-lab-describe",
+	MethodLabList:     "hck-lab-list",
+	MethodTaskList:    "hck-task-list",
+	MethodTaskLogs:    "hck-task-logs",
+	MethodTaskRun:     "hck-task-run",
 }
 
 func (c MethodName) String() string {
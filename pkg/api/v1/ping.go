@@ -1,12 +1,25 @@
 package v1
 
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
 const (
 	PingValue = "ping"
 	PongValue = "pong"
+	// ApiVersion is negotiated with hck-ping, only the major version must match
+	ApiVersion = "1.1"
+	// legacyApiVersion is assumed if the peer doesn't send any version
+	legacyApiVersion = "1.0"
 )
 
 type PingBody struct {
-	Value string `json:"value"`
+	Value      string `json:"value"`
+	ApiVersion string `json:"apiVersion,omitempty"`
 }
 
 func (b PingBody) method() MethodName {
@@ -14,7 +27,8 @@ func (b PingBody) method() MethodName {
 }
 
 type PongBody struct {
-	Value string `json:"value"`
+	Value      string `json:"value"`
+	ApiVersion string `json:"apiVersion,omitempty"`
 }
 
 func (b PongBody) method() MethodName {
@@ -22,9 +36,52 @@ func (b PongBody) method() MethodName {
 }
 
 func NewPingMessage(origin string) *Message[PingBody] {
-	return newMessage[PingBody](origin, PingBody{PingValue})
+	return newMessage[PingBody](origin, PingBody{Value: PingValue, ApiVersion: ApiVersion})
 }
 
 func NewPongMessage(origin string) *Message[PongBody] {
-	return newMessage[PongBody](origin, PongBody{PongValue})
+	return newMessage[PongBody](origin, PongBody{Value: PongValue, ApiVersion: ApiVersion})
+}
+
+func IsCompatibleVersion(value string) bool {
+	if value == "" {
+		value = legacyApiVersion
+	}
+	major, _, _ := strings.Cut(value, ".")
+	currentMajor, _, _ := strings.Cut(ApiVersion, ".")
+	return major == currentMajor
+}
+
+func NewIncompatibleVersionError(value string) *ApiError {
+	return &ApiError{
+		Code:    ErrorIncompatibleVersion,
+		Message: fmt.Sprintf("api version %s not compatible with %s", value, ApiVersion),
+		Details: map[string]string{"apiVersion": ApiVersion},
+	}
+}
+
+// Negotiate pings the server and fails early if the api versions are not compatible
+func Negotiate(ctx context.Context, origin string, send func(ctx context.Context, protocol string, payload string) (string, error)) (*Message[PongBody], error) {
+	request := NewPingMessage(origin).WithRequestId(NewRequestId())
+	payload, err := request.Encode()
+	if err != nil {
+		return nil, errors.Wrap(err, "error ping request")
+	}
+
+	value, err := send(ctx, request.Protocol(), payload)
+	if err != nil {
+		return nil, ToError(err)
+	}
+
+	response, err := Decode[PongBody](value)
+	if err != nil {
+		return nil, errors.Wrap(err, "error pong response")
+	}
+	if !IsCompatibleVersion(response.Body.ApiVersion) {
+		apiError := NewIncompatibleVersionError(response.Body.ApiVersion)
+		apiError.Origin = response.Origin
+		apiError.RequestId = request.RequestId
+		return nil, apiError
+	}
+	return response, nil
 }
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/hckops/hckctl/pkg/event"
+)
+
+const serverEventSource = "server"
+
+type serverEvent struct {
+	kind  event.EventKind
+	value string
+}
+
+func (e *serverEvent) Source() string {
+	return serverEventSource
+}
+
+func (e *serverEvent) Kind() event.EventKind {
+	return e.kind
+}
+
+func (e *serverEvent) String() string {
+	return e.value
+}
+
+func newServerListenEvent(address string) *serverEvent {
+	return &serverEvent{kind: event.PrintConsole, value: fmt.Sprintf("listening on %s", address)}
+}
+
+func newConnectionOpenServerEvent(remoteAddress string, user string) *serverEvent {
+	return &serverEvent{kind: event.LogInfo, value: fmt.Sprintf("server connection open: remoteAddress=%s user=%s", remoteAddress, user)}
+}
+
+func newConnectionCloseServerEvent(remoteAddress string) *serverEvent {
+	return &serverEvent{kind: event.LogInfo, value: fmt.Sprintf("server connection close: remoteAddress=%s", remoteAddress)}
+}
+
+func newConnectionErrorServerEvent(remoteAddress string, err error) *serverEvent {
+	return &serverEvent{kind: event.LogWarning, value: fmt.Sprintf("server connection error: remoteAddress=%s error=%v", remoteAddress, err)}
+}
+
+func newRequestServerEvent(protocol string, requestId string) *serverEvent {
+	return &serverEvent{kind: event.LogDebug, value: fmt.Sprintf("server request: protocol=%s requestId=%s", protocol, requestId)}
+}
+
+func newRequestErrorServerEvent(protocol string, requestId string, err error) *serverEvent {
+	return &serverEvent{kind: event.LogError, value: fmt.Sprintf("server request error: protocol=%s requestId=%s error=%v", protocol, requestId, err)}
+}
+
+func newSessionServerEvent(method string) *serverEvent {
+	return &serverEvent{kind: event.LogInfo, value: fmt.Sprintf("server session: method=%s", method)}
+}
+
+func newSessionErrorServerEvent(err error) *serverEvent {
+	return &serverEvent{kind: event.LogError, value: fmt.Sprintf("server session error: error=%v", err)}
+}
+
+func newTunnelServerEvent(boxName string, address string) *serverEvent {
+	return &serverEvent{kind: event.LogInfo, value: fmt.Sprintf("server tunnel: boxName=%s address=%s", boxName, address)}
+}
+
+func newTunnelErrorServerEvent(boxName string, err error) *serverEvent {
+	return &serverEvent{kind: event.LogError, value: fmt.Sprintf("server tunnel error: boxName=%s error=%v", boxName, err)}
+}
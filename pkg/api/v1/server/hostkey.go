@@ -0,0 +1,51 @@
+package server
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"os"
+
+	"github.com/pkg/errors"
+	gossh "golang.org/x/crypto/ssh"
+
+	"github.com/hckops/hckctl/pkg/util"
+)
+
+// LoadHostKey reads a private key in openssh format, or generates a new ed25519 key if the file doesn't exist
+func LoadHostKey(path string) (gossh.Signer, error) {
+	if util.PathNotExist(path) {
+		if err := generateHostKey(path); err != nil {
+			return nil, err
+		}
+	}
+
+	value, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading host key %s", path)
+	}
+	signer, err := gossh.ParsePrivateKey(value)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error parsing host key %s", path)
+	}
+	return signer, nil
+}
+
+func generateHostKey(path string) error {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return errors.Wrap(err, "error generating host key")
+	}
+	block, err := gossh.MarshalPrivateKey(privateKey, "")
+	if err != nil {
+		return errors.Wrap(err, "error encoding host key")
+	}
+
+	if err := util.CreateBaseDir(path); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, pem.EncodeToMemory(block), 0600); err != nil {
+		return errors.Wrapf(err, "error writing host key %s", path)
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	gossh "golang.org/x/crypto/ssh"
+
+	v1 "github.com/hckops/hckctl/pkg/api/v1"
+	boxModel "github.com/hckops/hckctl/pkg/box/model"
+)
+
+func (server *Server) handleRequests(ctx context.Context, requests <-chan *gossh.Request) {
+	for request := range requests {
+		// clients probe half-open connections
+		if request.Type == keepAliveType {
+			request.Reply(true, nil)
+			continue
+		}
+
+		// the request id is optional, a message is decoded anyway by each method
+		var requestId string
+		if header, err := v1.DecodeHeader(string(request.Payload)); err == nil {
+			requestId = header.RequestId
+		}
+		server.eventBus.Publish(newRequestServerEvent(request.Type, requestId))
+
+		response, err := server.dispatchRequest(ctx, request.Type, string(request.Payload), requestId)
+		if err != nil {
+			server.eventBus.Publish(newRequestErrorServerEvent(request.Type, requestId, err))
+			// the client decodes the payload of a failed request
+			request.Reply(false, []byte(server.encodeError(request.Type, requestId, err)))
+		} else {
+			request.Reply(true, []byte(response))
+		}
+	}
+}
+
+// encodeError returns an error message, unexpected errors are internal
+func (server *Server) encodeError(protocol string, requestId string, err error) string {
+	apiError := v1.NewApiError(v1.ErrorInternal, err.Error())
+	errors.As(err, &apiError)
+
+	// unknown methods are reported with an empty method
+	methodName, _ := v1.ParseProtocol(protocol)
+	value, encodeErr := v1.NewErrorMessage(server.opts.Origin, methodName, requestId, apiError.Body()).Encode()
+	if encodeErr != nil {
+		return err.Error()
+	}
+	return value
+}
+
+func (server *Server) dispatchRequest(ctx context.Context, protocol string, payload string, requestId string) (string, error) {
+	methodName, err := v1.ParseProtocol(protocol)
+	if err != nil {
+		return "", v1.NewApiError(v1.ErrorUnsupported, err.Error())
+	}
+
+	switch methodName {
+	case v1.MethodPing:
+		return server.ping(payload, requestId)
+	case v1.MethodBoxCreate:
+		return server.createBox(ctx, payload, requestId)
+	case v1.MethodBoxDelete:
+		return server.deleteBoxes(ctx, payload, requestId)
+	case v1.MethodBoxDescribe:
+		return server.describeBoxResponse(ctx, payload, requestId)
+	case v1.MethodBoxList:
+		return server.listBoxes(ctx, payload, requestId)
+	default:
+		return "", v1.NewApiError(v1.ErrorUnsupported, fmt.Sprintf("method not supported %s", methodName.String()))
+	}
+}
+
+func invalidRequest(err error) error {
+	return v1.NewApiError(v1.ErrorInvalidRequest, err.Error())
+}
+
+func (server *Server) ping(payload string, requestId string) (string, error) {
+	request, err := v1.Decode[v1.PingBody](payload)
+	if err != nil {
+		return "", invalidRequest(err)
+	}
+	if !v1.IsCompatibleVersion(request.Body.ApiVersion) {
+		return "", v1.NewIncompatibleVersionError(request.Body.ApiVersion)
+	}
+	return v1.NewPongMessage(server.opts.Origin).WithRequestId(requestId).Encode()
+}
+
+func (server *Server) createBox(ctx context.Context, payload string, requestId string) (string, error) {
+	request, err := v1.Decode[v1.BoxCreateRequestBody](payload)
+	if err != nil {
+		return "", invalidRequest(err)
+	}
+	size, err := boxModel.ExistResourceSize(request.Body.Size)
+	if err != nil {
+		return "", invalidRequest(errors.Wrap(err, "invalid size"))
+	}
+	createOpts, err := server.opts.NewCreateOptions(request.Body.TemplateName, size)
+	if err != nil {
+		return "", v1.NewApiError(v1.ErrorInvalidTemplate, errors.Wrapf(err, "invalid template %s", request.Body.TemplateName).Error())
+	}
+
+	boxClient, err := server.newBoxClient()
+	if err != nil {
+		return "", err
+	}
+	info, err := boxClient.Create(ctx, createOpts)
+	if err != nil {
+		return "", err
+	}
+	return v1.NewBoxCreateResponse(server.opts.Origin, info.Name, size.String()).WithRequestId(requestId).Encode()
+}
+
+func (server *Server) deleteBoxes(ctx context.Context, payload string, requestId string) (string, error) {
+	request, err := v1.Decode[v1.BoxDeleteRequestBody](payload)
+	if err != nil {
+		return "", invalidRequest(err)
+	}
+
+	boxClient, err := server.newBoxClient()
+	if err != nil {
+		return "", err
+	}
+	names, err := boxClient.Delete(ctx, request.Body.Names)
+	if err != nil {
+		return "", err
+	}
+	return v1.NewBoxDeleteResponse(server.opts.Origin, names).WithRequestId(requestId).Encode()
+}
+
+func (server *Server) describeBoxResponse(ctx context.Context, payload string, requestId string) (string, error) {
+	request, err := v1.Decode[v1.BoxDescribeRequestBody](payload)
+	if err != nil {
+		return "", invalidRequest(err)
+	}
+
+	details, template, err := server.describeBox(ctx, request.Body.Name)
+	if errors.Is(err, boxModel.ErrBoxNotFound) {
+		return "", v1.NewApiError(v1.ErrorNotFound, fmt.Sprintf("box not found %s", request.Body.Name))
+	} else if err != nil {
+		// e.g. provider unavailable or timeout
+		return "", err
+	}
+	return v1.NewBoxDescribeResponse(server.opts.Origin, newBoxDescribeResponseBody(details, template)).WithRequestId(requestId).Encode()
+}
+
+func newBoxDescribeResponseBody(details *boxModel.BoxDetails, template *boxModel.BoxV1) v1.BoxDescribeResponseBody {
+
+	var envs []string
+	for _, env := range details.Env {
+		envs = append(envs, fmt.Sprintf("%s=%s", env.Key, env.Value))
+	}
+	// runtime ports are local to the server, the client binds its own
+	var ports []string
+	for _, port := range template.NetworkPortValues(true) {
+		ports = append(ports, fmt.Sprintf("%s/%s", port.Alias, port.Remote))
+	}
+
+	// the client always expects a template
+	templateInfo := &v1.BoxDescribeTemplateInfo{}
+	if details.TemplateInfo != nil && details.TemplateInfo.GitTemplate != nil {
+		templateInfo = &v1.BoxDescribeTemplateInfo{
+			Public:   true,
+			Url:      details.TemplateInfo.GitTemplate.Url,
+			Revision: details.TemplateInfo.GitTemplate.Revision,
+			Commit:   details.TemplateInfo.GitTemplate.Commit,
+			Name:     details.TemplateInfo.GitTemplate.Name,
+		}
+	}
+
+	return v1.BoxDescribeResponseBody{
+		Id:       details.Info.Id,
+		Name:     details.Info.Name,
+		Created:  details.Created.Format(time.RFC3339),
+		Healthy:  details.Info.Healthy,
+		Size:     details.Size.String(),
+		Template: templateInfo,
+		Env:      envs,
+		Ports:    ports,
+	}
+}
+
+func (server *Server) listBoxes(ctx context.Context, payload string, requestId string) (string, error) {
+	if _, err := v1.Decode[v1.BoxListRequestBody](payload); err != nil {
+		return "", invalidRequest(err)
+	}
+
+	boxClient, err := server.newBoxClient()
+	if err != nil {
+		return "", err
+	}
+	boxes, err := boxClient.List(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	var items []v1.BoxListItem
+	for _, info := range boxes {
+		items = append(items, v1.BoxListItem{Id: info.Id, Name: info.Name, Healthy: info.Healthy})
+	}
+	return v1.NewBoxListResponse(server.opts.Origin, items).WithRequestId(requestId).Encode()
+}
@@ -0,0 +1,190 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/pkg/errors"
+	gossh "golang.org/x/crypto/ssh"
+
+	"github.com/hckops/hckctl/pkg/box"
+	boxModel "github.com/hckops/hckctl/pkg/box/model"
+	"github.com/hckops/hckctl/pkg/event"
+)
+
+const (
+	sessionChannelType = "session"
+	tunnelChannelType  = "direct-tcpip"
+	keepAliveType      = "keepalive@openssh.com"
+)
+
+type ServerOptions struct {
+	Address  string
+	Origin   string // server version sent in each response
+	Username string
+	Token    string
+	HostKey  gossh.Signer
+	// returns a new client for each invocation, box clients are closed after every method
+	NewBoxClient func() (box.BoxClient, error)
+	// resolves the template name and size of a "hck-box-create" request
+	NewCreateOptions func(templateName string, size boxModel.ResourceSize) (*boxModel.CreateOptions, error)
+	// resolves the template of a running box
+	LoadTemplate func(details *boxModel.BoxDetails) (*boxModel.BoxV1, error)
+}
+
+// Server implements the api/v1 protocol over ssh, events must be subscribed or drained
+type Server struct {
+	opts        *ServerOptions
+	config      *gossh.ServerConfig
+	eventBus    *event.EventBus
+	listener    net.Listener
+	connections map[*gossh.ServerConn]struct{}
+	mutex       sync.Mutex
+}
+
+func NewServer(opts *ServerOptions) (*Server, error) {
+	if opts.HostKey == nil {
+		return nil, errors.New("missing host key")
+	}
+	if opts.NewBoxClient == nil || opts.NewCreateOptions == nil || opts.LoadTemplate == nil {
+		return nil, errors.New("missing box callbacks")
+	}
+
+	config := &gossh.ServerConfig{
+		PasswordCallback: func(conn gossh.ConnMetadata, password []byte) (*gossh.Permissions, error) {
+			validUsername := subtle.ConstantTimeCompare([]byte(conn.User()), []byte(opts.Username)) == 1
+			validToken := subtle.ConstantTimeCompare(password, []byte(opts.Token)) == 1
+			if validUsername && validToken {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("invalid credentials: user=%s", conn.User())
+		},
+	}
+	config.AddHostKey(opts.HostKey)
+
+	return &Server{
+		opts:        opts,
+		config:      config,
+		eventBus:    event.NewEventBus(),
+		connections: map[*gossh.ServerConn]struct{}{},
+	}, nil
+}
+
+func (server *Server) Events() *event.EventBus {
+	return server.eventBus
+}
+
+func (server *Server) ListenAndServe() error {
+	listener, err := net.Listen("tcp", server.opts.Address)
+	if err != nil {
+		return errors.Wrap(err, "error server listen")
+	}
+	return server.Serve(listener)
+}
+
+// Serve blocks accepting connections until the server is closed
+func (server *Server) Serve(listener net.Listener) error {
+	server.mutex.Lock()
+	server.listener = listener
+	server.mutex.Unlock()
+	server.eventBus.Publish(newServerListenEvent(listener.Addr().String()))
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return errors.Wrap(err, "error server accept")
+		}
+		go server.handleConnection(conn)
+	}
+}
+
+// Close stops accepting connections and closes the active ones
+func (server *Server) Close() error {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	for sshConn := range server.connections {
+		sshConn.Close()
+	}
+	if server.listener == nil {
+		return nil
+	}
+	return server.listener.Close()
+}
+
+func (server *Server) trackConnection(sshConn *gossh.ServerConn) func() {
+	server.mutex.Lock()
+	server.connections[sshConn] = struct{}{}
+	server.mutex.Unlock()
+
+	return func() {
+		server.mutex.Lock()
+		delete(server.connections, sshConn)
+		server.mutex.Unlock()
+	}
+}
+
+func (server *Server) handleConnection(conn net.Conn) {
+	sshConn, channels, requests, err := gossh.NewServerConn(conn, server.config)
+	if err != nil {
+		server.eventBus.Publish(newConnectionErrorServerEvent(conn.RemoteAddr().String(), err))
+		conn.Close()
+		return
+	}
+	defer sshConn.Close()
+	defer server.trackConnection(sshConn)()
+	server.eventBus.Publish(newConnectionOpenServerEvent(sshConn.RemoteAddr().String(), sshConn.User()))
+
+	// all the pending box operations are cancelled when the client disconnects
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go server.handleRequests(ctx, requests)
+
+	for newChannel := range channels {
+		switch newChannel.ChannelType() {
+		case sessionChannelType:
+			go server.handleSession(ctx, newChannel)
+		case tunnelChannelType:
+			go server.handleTunnel(ctx, newChannel)
+		default:
+			newChannel.Reject(gossh.UnknownChannelType, "unsupported channel type")
+		}
+	}
+	server.eventBus.Publish(newConnectionCloseServerEvent(sshConn.RemoteAddr().String()))
+}
+
+// newBoxClient forwards all the client events to the server
+func (server *Server) newBoxClient() (box.BoxClient, error) {
+	boxClient, err := server.opts.NewBoxClient()
+	if err != nil {
+		return nil, errors.Wrap(err, "error server box client")
+	}
+	boxClient.Events().Subscribe(func(e event.Event) {
+		server.eventBus.Publish(e)
+	})
+	return boxClient, nil
+}
+
+// describeBox returns the details and the template of a running box
+func (server *Server) describeBox(ctx context.Context, name string) (*boxModel.BoxDetails, *boxModel.BoxV1, error) {
+	boxClient, err := server.newBoxClient()
+	if err != nil {
+		return nil, nil, err
+	}
+	details, err := boxClient.Describe(ctx, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	template, err := server.opts.LoadTemplate(details)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "error server template")
+	}
+	return details, template, nil
+}
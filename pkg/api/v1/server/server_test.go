@@ -0,0 +1,531 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gossh "golang.org/x/crypto/ssh"
+
+	v1 "github.com/hckops/hckctl/pkg/api/v1"
+	"github.com/hckops/hckctl/pkg/box"
+	boxModel "github.com/hckops/hckctl/pkg/box/model"
+	"github.com/hckops/hckctl/pkg/client/ssh"
+	"github.com/hckops/hckctl/pkg/client/terminal"
+	commonModel "github.com/hckops/hckctl/pkg/common/model"
+	"github.com/hckops/hckctl/pkg/event"
+	"github.com/hckops/hckctl/pkg/util"
+)
+
+const (
+	testOrigin   = "hckctl-test"
+	testUsername = "user"
+	testToken    = "token"
+	testBoxName  = "box-alpine-123"
+	// fails with an unexpected error
+	testBrokenBoxName = "box-broken-123"
+)
+
+type fakeBoxClient struct {
+	eventBus *event.EventBus
+	tunnelIp string
+	uploaded map[string]string
+}
+
+func (f *fakeBoxClient) Provider() boxModel.BoxProvider { return boxModel.Docker }
+func (f *fakeBoxClient) Events() *event.EventBus        { return f.eventBus }
+func (f *fakeBoxClient) Create(ctx context.Context, opts *boxModel.CreateOptions) (*boxModel.BoxInfo, error) {
+	return &boxModel.BoxInfo{Id: "id-123", Name: testBoxName}, nil
+}
+func (f *fakeBoxClient) Connect(ctx context.Context, opts *boxModel.ConnectOptions) error {
+	return errors.New("todo")
+}
+func (f *fakeBoxClient) Copy(ctx context.Context, opts *boxModel.CopyOptions) error {
+	if opts.Direction == boxModel.CopyUpload {
+		value, err := os.ReadFile(opts.LocalPath)
+		f.uploaded[opts.RemotePath] = string(value)
+		return err
+	}
+	return os.WriteFile(opts.LocalPath, []byte("remote-content"), 0600)
+}
+func (f *fakeBoxClient) Exec(ctx context.Context, opts *boxModel.ExecOptions) (int, error) {
+	if opts.StreamOpts.Resize != nil {
+		size := <-opts.StreamOpts.Resize
+		fmt.Fprintf(opts.StreamOpts.Out, "%dx%d ", size.Width, size.Height)
+	}
+	io.WriteString(opts.StreamOpts.Out, strings.Join(opts.Command, " "))
+	return 3, nil
+}
+func (f *fakeBoxClient) Logs(ctx context.Context, opts *boxModel.LogsOptions) error {
+	_, err := io.WriteString(opts.StreamOpts.Out, "line-1\nline-2\n")
+	return err
+}
+func (f *fakeBoxClient) PortForward(ctx context.Context, opts *boxModel.PortForwardOptions) error {
+	return errors.New("todo")
+}
+func (f *fakeBoxClient) Describe(ctx context.Context, name string) (*boxModel.BoxDetails, error) {
+	if name == testBrokenBoxName {
+		return nil, errors.New("error docker connection")
+	} else if name != testBoxName {
+		return nil, boxModel.ErrBoxNotFound
+	}
+	return &boxModel.BoxDetails{
+		Info: boxModel.BoxInfo{Id: "id-123", Name: testBoxName, Healthy: true},
+		TemplateInfo: &boxModel.BoxTemplateInfo{
+			GitTemplate: &commonModel.GitTemplateInfo{Url: "https://github.com/hckops/megalopolis", Revision: "main", Commit: "abc", Name: "alpine"},
+		},
+		ProviderInfo: &boxModel.BoxProviderInfo{
+			Provider:       boxModel.Docker,
+			DockerProvider: &commonModel.DockerProviderInfo{Ip: f.tunnelIp},
+		},
+		Size:    boxModel.Small,
+		Created: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}, nil
+}
+func (f *fakeBoxClient) List(ctx context.Context) ([]boxModel.BoxInfo, error) {
+	return []boxModel.BoxInfo{{Id: "id-123", Name: testBoxName, Healthy: true}}, nil
+}
+func (f *fakeBoxClient) Delete(ctx context.Context, names []string) ([]string, error) {
+	return names, nil
+}
+func (f *fakeBoxClient) Clean(ctx context.Context) error             { return errors.New("todo") }
+func (f *fakeBoxClient) Version(ctx context.Context) (string, error) { return "", errors.New("todo") }
+
+func startTestServer(t *testing.T, hostKey gossh.Signer, address string, uploaded map[string]string) (*Server, string) {
+	server, err := NewServer(&ServerOptions{
+		Origin:   testOrigin,
+		Username: testUsername,
+		Token:    testToken,
+		HostKey:  hostKey,
+		NewBoxClient: func() (box.BoxClient, error) {
+			return &fakeBoxClient{eventBus: event.NewEventBus(), tunnelIp: "127.0.0.1", uploaded: uploaded}, nil
+		},
+		NewCreateOptions: func(templateName string, size boxModel.ResourceSize) (*boxModel.CreateOptions, error) {
+			return &boxModel.CreateOptions{Template: &boxModel.BoxV1{Name: templateName}, Size: size}, nil
+		},
+		LoadTemplate: func(details *boxModel.BoxDetails) (*boxModel.BoxV1, error) {
+			template := &boxModel.BoxV1{Name: "alpine", Shell: "/bin/sh"}
+			template.Network.Ports = []string{"tty:7681"}
+			return template, nil
+		},
+	})
+	require.NoError(t, err)
+	server.Events().Drain()
+
+	listener, err := net.Listen("tcp", address)
+	require.NoError(t, err)
+	go server.Serve(listener)
+	t.Cleanup(func() { server.Close() })
+	return server, listener.Addr().String()
+}
+
+func newTestServer(t *testing.T) (*ssh.SshClient, map[string]string) {
+	hostKey, err := LoadHostKey(filepath.Join(t.TempDir(), "host_key"))
+	require.NoError(t, err)
+
+	uploaded := map[string]string{}
+	_, address := startTestServer(t, hostKey, "127.0.0.1:0", uploaded)
+
+	client, err := ssh.NewSshClient(context.Background(), &ssh.SshClientConfig{
+		Address:        address,
+		Username:       testUsername,
+		Token:          testToken,
+		KnownHostsPath: filepath.Join(t.TempDir(), "known_hosts"),
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+	return client, uploaded
+}
+
+func TestServerInvalidCredentials(t *testing.T) {
+	hostKey, err := LoadHostKey(filepath.Join(t.TempDir(), "host_key"))
+	require.NoError(t, err)
+	server, err := NewServer(&ServerOptions{
+		Username:         testUsername,
+		Token:            testToken,
+		HostKey:          hostKey,
+		NewBoxClient:     func() (box.BoxClient, error) { return nil, errors.New("todo") },
+		NewCreateOptions: func(string, boxModel.ResourceSize) (*boxModel.CreateOptions, error) { return nil, nil },
+		LoadTemplate:     func(*boxModel.BoxDetails) (*boxModel.BoxV1, error) { return nil, nil },
+	})
+	require.NoError(t, err)
+	server.Events().Drain()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go server.Serve(listener)
+	defer server.Close()
+
+	_, err = ssh.NewSshClient(context.Background(), &ssh.SshClientConfig{
+		Address:     listener.Addr().String(),
+		Username:    testUsername,
+		Token:       "invalid",
+		Fingerprint: gossh.FingerprintSHA256(hostKey.PublicKey()),
+	})
+	assert.Error(t, err)
+}
+
+func TestServerPing(t *testing.T) {
+	client, _ := newTestServer(t)
+
+	request := v1.NewPingMessage("hckctl-0.0.0-os").WithRequestId("abc123")
+	payload, err := request.Encode()
+	require.NoError(t, err)
+
+	value, err := client.SendRequest(context.Background(), request.Protocol(), payload)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"kind":"api/v1","origin":"hckctl-test","method":"hck-ping","requestId":"abc123","body":{"value":"pong","apiVersion":"1.1"}}`, value)
+
+	response, err := v1.Negotiate(context.Background(), "hckctl-0.0.0-os", client.SendRequest)
+	assert.NoError(t, err)
+	assert.Equal(t, "hckctl-test", response.Origin)
+}
+
+func TestServerPingIncompatibleVersion(t *testing.T) {
+	client, _ := newTestServer(t)
+
+	request := v1.NewPingMessage("hckctl-0.0.0-os").WithRequestId("abc123")
+	request.Body.ApiVersion = "2.0"
+	payload, _ := request.Encode()
+
+	_, err := client.SendRequest(context.Background(), request.Protocol(), payload)
+	err = v1.ToError(err)
+	assert.ErrorIs(t, err, v1.ErrIncompatibleVersion)
+	assert.EqualError(t, err, "api error: code=incompatible-version message=api version 2.0 not compatible with 1.1 requestId=abc123")
+}
+
+func TestServerBoxRequests(t *testing.T) {
+	client, _ := newTestServer(t)
+
+	create := v1.NewBoxCreateRequest("hckctl-0.0.0-os", "alpine", "s")
+	payload, _ := create.Encode()
+	value, err := client.SendRequest(context.Background(), create.Protocol(), payload)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"kind":"api/v1","origin":"hckctl-test","method":"hck-box-create","body":{"name":"box-alpine-123","size":"S"}}`, value)
+
+	describe := v1.NewBoxDescribeRequest("hckctl-0.0.0-os", testBoxName)
+	payload, _ = describe.Encode()
+	value, err = client.SendRequest(context.Background(), describe.Protocol(), payload)
+	assert.NoError(t, err)
+	response, err := v1.Decode[v1.BoxDescribeResponseBody](value)
+	assert.NoError(t, err)
+	assert.Equal(t, "2024-01-01T00:00:00Z", response.Body.Created)
+	assert.Equal(t, []string{"tty/7681"}, response.Body.Ports)
+	assert.Equal(t, "alpine", response.Body.Template.Name)
+
+	list := v1.NewBoxListRequest("hckctl-0.0.0-os")
+	payload, _ = list.Encode()
+	value, err = client.SendRequest(context.Background(), list.Protocol(), payload)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"kind":"api/v1","origin":"hckctl-test","method":"hck-box-list","body":{"items":[{"Id":"id-123","Name":"box-alpine-123","Healthy":true}]}}`, value)
+
+	missing := v1.NewBoxDescribeRequest("hckctl-0.0.0-os", "box-missing-123")
+	payload, _ = missing.Encode()
+	_, err = client.SendRequest(context.Background(), missing.Protocol(), payload)
+	assert.ErrorIs(t, v1.ToError(err), v1.ErrNotFound)
+
+	broken := v1.NewBoxDescribeRequest("hckctl-0.0.0-os", testBrokenBoxName)
+	payload, _ = broken.Encode()
+	_, err = client.SendRequest(context.Background(), broken.Protocol(), payload)
+	var internalError *v1.ApiError
+	require.ErrorAs(t, v1.ToError(err), &internalError)
+	assert.Equal(t, v1.ErrorInternal, internalError.Code)
+
+	unsupported := v1.NewLabCreateRequest("hckctl-0.0.0-os", "ctf", map[string]string{})
+	payload, _ = unsupported.Encode()
+	_, err = client.SendRequest(context.Background(), unsupported.Protocol(), payload)
+	var apiError *v1.ApiError
+	require.ErrorAs(t, v1.ToError(err), &apiError)
+	assert.Equal(t, v1.ErrorUnsupported, apiError.Code)
+	assert.Equal(t, "method not supported hck-lab-create", apiError.Message)
+	assert.Equal(t, "hck-lab-create", apiError.Method)
+
+	notFound := v1.NewBoxDescribeRequest("hckctl-0.0.0-os", "box-unknown").WithRequestId("abc123")
+	payload, _ = notFound.Encode()
+	_, err = client.SendRequest(context.Background(), notFound.Protocol(), payload)
+	err = v1.ToError(err)
+	assert.ErrorIs(t, err, v1.ErrNotFound)
+	require.ErrorAs(t, err, &apiError)
+	assert.Equal(t, "abc123", apiError.RequestId)
+}
+
+func TestServerExecSession(t *testing.T) {
+	client, _ := newTestServer(t)
+
+	session := v1.NewBoxExecCommandSession("hckctl-0.0.0-os", testBoxName, []string{"echo", "hello"})
+	payload, _ := session.Encode()
+
+	out := new(bytes.Buffer)
+	exitCode, err := client.ExecCommand(context.Background(), &ssh.SshCommandOpts{
+		Payload:               payload,
+		OutStream:             out,
+		ErrStream:             io.Discard,
+		OnStreamStartCallback: func() {},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, exitCode)
+	assert.Equal(t, "echo hello", out.String())
+}
+
+func TestServerExecSessionTty(t *testing.T) {
+	client, _ := newTestServer(t)
+
+	session := v1.NewBoxExecCommandSession("hckctl-0.0.0-os", testBoxName, []string{"top"})
+	payload, _ := session.Encode()
+
+	// stdin is not a terminal, the default size is requested
+	out := new(bytes.Buffer)
+	exitCode, err := client.ExecCommand(context.Background(), &ssh.SshCommandOpts{
+		Payload:               payload,
+		OutStream:             out,
+		ErrStream:             io.Discard,
+		IsTty:                 true,
+		OnStreamStartCallback: func() {},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, exitCode)
+	assert.Equal(t, "80x24 top", out.String())
+}
+
+func TestSendSize(t *testing.T) {
+	resize := make(chan terminal.Size, 1)
+
+	sendSize(resize, 80, 24)
+	// replaces the pending size without blocking
+	sendSize(resize, 120, 40)
+	// ignores invalid sizes
+	sendSize(resize, 0, 40)
+
+	assert.Equal(t, terminal.Size{Width: 120, Height: 40}, <-resize)
+	assert.Len(t, resize, 0)
+}
+
+func TestServerLogsSessionNotFound(t *testing.T) {
+	client, _ := newTestServer(t)
+
+	session := v1.NewBoxLogsSession("hckctl-0.0.0-os", v1.BoxLogsSessionBody{Name: "box-invalid", Tail: -1})
+	payload, _ := session.Encode()
+
+	errOut := new(bytes.Buffer)
+	exitCode, err := client.ExecCommand(context.Background(), &ssh.SshCommandOpts{
+		Payload:               payload,
+		OutStream:             io.Discard,
+		ErrStream:             errOut,
+		OnStreamStartCallback: func() {},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, exitCode)
+	assert.Equal(t, "box not found\n", errOut.String())
+}
+
+func TestServerCopySession(t *testing.T) {
+	client, uploaded := newTestServer(t)
+
+	localDir := t.TempDir()
+	localFile := filepath.Join(localDir, "wordlist.txt")
+	require.NoError(t, os.WriteFile(localFile, []byte("local-content"), 0600))
+
+	reader, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(util.CreateTar(localFile, "wordlist.txt", writer))
+	}()
+	upload := v1.NewBoxCopySession("hckctl-0.0.0-os", testBoxName, "/tmp/wordlist.txt", v1.BoxCopyUpload)
+	payload, _ := upload.Encode()
+	assert.NoError(t, client.Stream(context.Background(), &ssh.SshStreamOpts{Payload: payload, InStream: reader, OutStream: io.Discard}))
+	assert.Equal(t, "local-content", uploaded["/tmp/wordlist.txt"])
+
+	archive := new(bytes.Buffer)
+	download := v1.NewBoxCopySession("hckctl-0.0.0-os", testBoxName, "/root/loot.txt", v1.BoxCopyDownload)
+	payload, _ = download.Encode()
+	assert.NoError(t, client.Stream(context.Background(), &ssh.SshStreamOpts{Payload: payload, InStream: strings.NewReader(""), OutStream: archive}))
+
+	downloadPath := filepath.Join(localDir, "loot.txt")
+	assert.NoError(t, util.ExtractTar(archive, "loot.txt", downloadPath))
+	value, err := os.ReadFile(downloadPath)
+	assert.NoError(t, err)
+	assert.Equal(t, "remote-content", string(value))
+}
+
+func TestServerTunnel(t *testing.T) {
+	client, _ := newTestServer(t)
+
+	// echo server reachable by the fake box ip
+	echoListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer echoListener.Close()
+	go func() {
+		if conn, err := echoListener.Accept(); err == nil {
+			io.Copy(conn, conn)
+			conn.Close()
+		}
+	}()
+	_, echoPort, _ := net.SplitHostPort(echoListener.Addr().String())
+
+	localListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	_, localPort, _ := net.SplitHostPort(localListener.Addr().String())
+	// release the port for the tunnel
+	localListener.Close()
+
+	listenChannel := make(chan string, 1)
+	go client.Tunnel(context.Background(), &ssh.SshTunnelOpts{
+		LocalHost:  "127.0.0.1",
+		LocalPort:  localPort,
+		RemoteHost: testBoxName,
+		RemotePort: echoPort,
+		OnTunnelListenCallback: func(address string) {
+			listenChannel <- address
+		},
+		OnTunnelStartCallback: func(string) {},
+		OnTunnelStopCallback:  func(string) {},
+		OnTunnelErrorCallback: func(error) {},
+	})
+
+	var address string
+	select {
+	case address = <-listenChannel:
+	case <-time.After(2 * time.Second):
+		t.Fatal("tunnel not listening")
+	}
+	assert.Equal(t, net.JoinHostPort("127.0.0.1", localPort), address)
+
+	// the listener is bound before the callback is invoked
+	conn, err := net.Dial("tcp", address)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("ping"))
+	assert.NoError(t, err)
+	buffer := make([]byte, 4)
+	_, err = io.ReadFull(conn, buffer)
+	assert.NoError(t, err)
+	assert.Equal(t, "ping", string(buffer))
+}
+
+func TestServerKeepAlive(t *testing.T) {
+	client, _ := newTestServer(t)
+
+	response, err := client.SendRequest(context.Background(), "keepalive@openssh.com", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "", response)
+}
+
+func TestServerReconnect(t *testing.T) {
+	hostKey, err := LoadHostKey(filepath.Join(t.TempDir(), "host_key"))
+	require.NoError(t, err)
+	server, address := startTestServer(t, hostKey, "127.0.0.1:0", map[string]string{})
+
+	var reconnected atomic.Bool
+	client, err := ssh.NewSshClient(context.Background(), &ssh.SshClientConfig{
+		Address:        address,
+		Username:       testUsername,
+		Token:          testToken,
+		KnownHostsPath: filepath.Join(t.TempDir(), "known_hosts"),
+		OnReconnectCallback: func(attempt int, err error) {
+			if err == nil {
+				reconnected.Store(true)
+			}
+		},
+	})
+	require.NoError(t, err)
+	defer client.Close()
+
+	// drops the connection and restarts on the same address
+	require.NoError(t, server.Close())
+	startTestServer(t, hostKey, address, map[string]string{})
+
+	request := v1.NewPingMessage(testOrigin)
+	payload, _ := request.Encode()
+	assert.Eventually(t, func() bool {
+		_, err := client.SendRequest(context.Background(), request.Protocol(), payload)
+		return err == nil
+	}, 5*time.Second, 50*time.Millisecond)
+	assert.True(t, reconnected.Load())
+}
+
+func TestServerTunnelClose(t *testing.T) {
+	client, _ := newTestServer(t)
+
+	busyListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer busyListener.Close()
+	_, busyPort, _ := net.SplitHostPort(busyListener.Addr().String())
+
+	tunnelOpts := &ssh.SshTunnelOpts{
+		LocalHost:             "127.0.0.1",
+		RemoteHost:            testBoxName,
+		RemotePort:            "8080",
+		OnTunnelStartCallback: func(string) {},
+		OnTunnelStopCallback:  func(string) {},
+		OnTunnelErrorCallback: func(error) {},
+	}
+
+	// fails instead of accepting forever
+	tunnelOpts.LocalPort = busyPort
+	assert.ErrorContains(t, client.Tunnel(context.Background(), tunnelOpts), "error ssh creating local tunnel")
+
+	tunnelOpts.LocalPort = "0"
+	errorChannel := make(chan error, 1)
+	go func() {
+		errorChannel <- client.Tunnel(context.Background(), tunnelOpts)
+	}()
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, client.Close())
+
+	select {
+	case err := <-errorChannel:
+		assert.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("tunnel not stopped")
+	}
+}
+
+func TestServerTunnelCancel(t *testing.T) {
+	client, _ := newTestServer(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errorChannel := make(chan error, 1)
+	go func() {
+		errorChannel <- client.Tunnel(ctx, &ssh.SshTunnelOpts{
+			LocalHost:             "127.0.0.1",
+			LocalPort:             "0",
+			RemoteHost:            testBoxName,
+			RemotePort:            "8080",
+			OnTunnelStartCallback: func(string) {},
+			OnTunnelStopCallback:  func(string) {},
+			OnTunnelErrorCallback: func(error) {},
+		})
+	}()
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-errorChannel:
+		assert.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("tunnel not cancelled")
+	}
+	// the client is still usable
+	_, err := client.SendRequest(context.Background(), "keepalive@openssh.com", "")
+	assert.NoError(t, err)
+}
+
+func TestServerRequestCancel(t *testing.T) {
+	client, _ := newTestServer(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := client.SendRequest(ctx, "keepalive@openssh.com", "")
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,282 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	gossh "golang.org/x/crypto/ssh"
+
+	v1 "github.com/hckops/hckctl/pkg/api/v1"
+	boxModel "github.com/hckops/hckctl/pkg/box/model"
+	"github.com/hckops/hckctl/pkg/client/terminal"
+	commonModel "github.com/hckops/hckctl/pkg/common/model"
+	"github.com/hckops/hckctl/pkg/util"
+)
+
+const (
+	sessionErrorExitCode = 1
+)
+
+// see https://datatracker.ietf.org/doc/html/rfc4254#section-6.2
+type ptyRequestPayload struct {
+	Term     string
+	Columns  uint32
+	Rows     uint32
+	WidthPx  uint32
+	HeightPx uint32
+	Modes    string
+}
+
+// see https://datatracker.ietf.org/doc/html/rfc4254#section-6.5
+type execRequestPayload struct {
+	Command string
+}
+
+// see https://datatracker.ietf.org/doc/html/rfc4254#section-6.7
+type windowChangePayload struct {
+	Columns  uint32
+	Rows     uint32
+	WidthPx  uint32
+	HeightPx uint32
+}
+
+// see https://datatracker.ietf.org/doc/html/rfc4254#section-6.10
+type exitStatusPayload struct {
+	Status uint32
+}
+
+func (server *Server) handleSession(ctx context.Context, newChannel gossh.NewChannel) {
+	channel, requests, err := newChannel.Accept()
+	if err != nil {
+		server.eventBus.Publish(newSessionErrorServerEvent(err))
+		return
+	}
+
+	// the session is cancelled when the channel is closed
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var isTty bool
+	// the latest terminal size is forwarded to the box, closed with the channel
+	var resize chan terminal.Size
+	defer func() {
+		if resize != nil {
+			close(resize)
+		}
+	}()
+
+	for request := range requests {
+		switch request.Type {
+		case "pty-req":
+			var ptyPayload ptyRequestPayload
+			if err := gossh.Unmarshal(request.Payload, &ptyPayload); err != nil || resize != nil {
+				request.Reply(false, nil)
+				continue
+			}
+			isTty = true
+			resize = make(chan terminal.Size, 1)
+			sendSize(resize, ptyPayload.Columns, ptyPayload.Rows)
+			request.Reply(true, nil)
+		case "window-change":
+			// no reply, see https://datatracker.ietf.org/doc/html/rfc4254#section-6.7
+			var windowPayload windowChangePayload
+			if err := gossh.Unmarshal(request.Payload, &windowPayload); err == nil && resize != nil {
+				sendSize(resize, windowPayload.Columns, windowPayload.Rows)
+			}
+		case "exec":
+			var execPayload execRequestPayload
+			if err := gossh.Unmarshal(request.Payload, &execPayload); err != nil {
+				request.Reply(false, nil)
+				continue
+			}
+			request.Reply(true, nil)
+
+			go func(payload string, isTty bool, resize <-chan terminal.Size) {
+				exitCode := server.runSession(ctx, channel, payload, isTty, resize)
+				channel.SendRequest("exit-status", false, gossh.Marshal(&exitStatusPayload{Status: uint32(exitCode)}))
+				channel.Close()
+			}(execPayload.Command, isTty, resize)
+		default:
+			// e.g. "env" and "shell"
+			request.Reply(false, nil)
+		}
+	}
+}
+
+// sendSize never blocks the requests, a pending size not consumed yet is replaced
+func sendSize(resize chan terminal.Size, columns uint32, rows uint32) {
+	if columns == 0 || rows == 0 {
+		return
+	}
+	size := terminal.Size{Width: uint16(columns), Height: uint16(rows)}
+	for {
+		select {
+		case resize <- size:
+			return
+		default:
+			select {
+			case <-resize:
+			default:
+			}
+		}
+	}
+}
+
+// runSession returns the exit code and prints any error on stderr
+func (server *Server) runSession(ctx context.Context, channel gossh.Channel, payload string, isTty bool, resize <-chan terminal.Size) int {
+	exitCode, err := server.dispatchSession(ctx, channel, payload, isTty, resize)
+	if err != nil {
+		server.eventBus.Publish(newSessionErrorServerEvent(err))
+		fmt.Fprintln(channel.Stderr(), err.Error())
+		return sessionErrorExitCode
+	}
+	return exitCode
+}
+
+func (server *Server) dispatchSession(ctx context.Context, channel gossh.Channel, payload string, isTty bool, resize <-chan terminal.Size) (int, error) {
+	methodName, err := v1.DecodeMethod(payload)
+	if err != nil {
+		return -1, err
+	}
+	server.eventBus.Publish(newSessionServerEvent(methodName.String()))
+
+	switch methodName {
+	case v1.MethodBoxExec:
+		return server.execBox(ctx, channel, payload, isTty, resize)
+	case v1.MethodBoxCopy:
+		return 0, server.copyBox(ctx, channel, payload)
+	case v1.MethodBoxLogs:
+		return 0, server.logsBox(ctx, channel, payload)
+	default:
+		return -1, fmt.Errorf("session not supported %s", methodName.String())
+	}
+}
+
+func (server *Server) execBox(ctx context.Context, channel gossh.Channel, payload string, isTty bool, resize <-chan terminal.Size) (int, error) {
+	session, err := v1.Decode[v1.BoxExecSessionBody](payload)
+	if err != nil {
+		return -1, err
+	}
+	_, template, err := server.describeBox(ctx, session.Body.Name)
+	if err != nil {
+		return -1, err
+	}
+
+	command := session.Body.Command
+	if len(command) == 0 {
+		if template.Shell == boxModel.BoxShellNone {
+			return -1, errors.New("shell not available")
+		}
+		// interactive shell
+		command = []string{template.Shell}
+		isTty = true
+	}
+
+	boxClient, err := server.newBoxClient()
+	if err != nil {
+		return -1, err
+	}
+	streamOpts := newChannelStreamOpts(channel, isTty)
+	streamOpts.Resize = resize
+	execOpts := &boxModel.ExecOptions{
+		Template:   template,
+		StreamOpts: streamOpts,
+		Name:       session.Body.Name,
+		Command:    command,
+	}
+	return boxClient.Exec(ctx, execOpts)
+}
+
+func (server *Server) copyBox(ctx context.Context, channel gossh.Channel, payload string) error {
+	session, err := v1.Decode[v1.BoxCopySessionBody](payload)
+	if err != nil {
+		return err
+	}
+	_, template, err := server.describeBox(ctx, session.Body.Name)
+	if err != nil {
+		return err
+	}
+
+	// the archive is staged on the server, the box clients copy only from the local filesystem
+	tmpDir, err := os.MkdirTemp("", "hck-server-copy-")
+	if err != nil {
+		return errors.Wrap(err, "error server copy dir")
+	}
+	defer os.RemoveAll(tmpDir)
+
+	prefix := path.Base(session.Body.Path)
+	copyOpts := &boxModel.CopyOptions{
+		Template:   template,
+		Name:       session.Body.Name,
+		LocalPath:  filepath.Join(tmpDir, prefix),
+		RemotePath: session.Body.Path,
+	}
+
+	boxClient, err := server.newBoxClient()
+	if err != nil {
+		return err
+	}
+	switch session.Body.Direction {
+	case v1.BoxCopyUpload:
+		if err := util.ExtractTar(channel, prefix, copyOpts.LocalPath); err != nil {
+			return errors.Wrap(err, "error server copy upload")
+		}
+		copyOpts.Direction = boxModel.CopyUpload
+		return boxClient.Copy(ctx, copyOpts)
+	case v1.BoxCopyDownload:
+		copyOpts.Direction = boxModel.CopyDownload
+		if err := boxClient.Copy(ctx, copyOpts); err != nil {
+			return err
+		}
+		return util.CreateTar(copyOpts.LocalPath, prefix, channel)
+	default:
+		return fmt.Errorf("invalid copy direction %s", session.Body.Direction)
+	}
+}
+
+func (server *Server) logsBox(ctx context.Context, channel gossh.Channel, payload string) error {
+	session, err := v1.Decode[v1.BoxLogsSessionBody](payload)
+	if err != nil {
+		return err
+	}
+	_, template, err := server.describeBox(ctx, session.Body.Name)
+	if err != nil {
+		return err
+	}
+
+	var since time.Duration
+	if session.Body.Since != "" {
+		if since, err = time.ParseDuration(session.Body.Since); err != nil {
+			return errors.Wrap(err, "invalid since duration")
+		}
+	}
+
+	boxClient, err := server.newBoxClient()
+	if err != nil {
+		return err
+	}
+	logsOpts := &boxModel.LogsOptions{
+		Template:   template,
+		StreamOpts: newChannelStreamOpts(channel, false),
+		Name:       session.Body.Name,
+		Follow:     session.Body.Follow,
+		Since:      since,
+		Tail:       session.Body.Tail,
+		Sidecar:    session.Body.Sidecar,
+	}
+	return boxClient.Logs(ctx, logsOpts)
+}
+
+func newChannelStreamOpts(channel gossh.Channel, isTty bool) *commonModel.StreamOptions {
+	return &commonModel.StreamOptions{
+		In:    channel,
+		Out:   channel,
+		Err:   channel.Stderr(),
+		IsTty: isTty,
+	}
+}
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// see https://datatracker.ietf.org/doc/html/rfc4254#section-7.2
+type tunnelChannelPayload struct {
+	Host       string
+	Port       uint32
+	OriginHost string
+	OriginPort uint32
+}
+
+func (server *Server) handleTunnel(ctx context.Context, newChannel gossh.NewChannel) {
+	var payload tunnelChannelPayload
+	if err := gossh.Unmarshal(newChannel.ExtraData(), &payload); err != nil {
+		newChannel.Reject(gossh.ConnectionFailed, "invalid tunnel payload")
+		return
+	}
+
+	// the host is always the name of a box, arbitrary destinations are not allowed
+	address, err := server.resolveBoxAddress(ctx, payload.Host, payload.Port)
+	if err != nil {
+		server.eventBus.Publish(newTunnelErrorServerEvent(payload.Host, err))
+		newChannel.Reject(gossh.ConnectionFailed, err.Error())
+		return
+	}
+	remoteConnection, err := (&net.Dialer{}).DialContext(ctx, "tcp", address)
+	if err != nil {
+		server.eventBus.Publish(newTunnelErrorServerEvent(payload.Host, err))
+		newChannel.Reject(gossh.ConnectionFailed, err.Error())
+		return
+	}
+	defer remoteConnection.Close()
+
+	channel, requests, err := newChannel.Accept()
+	if err != nil {
+		server.eventBus.Publish(newTunnelErrorServerEvent(payload.Host, err))
+		return
+	}
+	defer channel.Close()
+	go gossh.DiscardRequests(requests)
+	server.eventBus.Publish(newTunnelServerEvent(payload.Host, address))
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		io.Copy(channel, remoteConnection)
+		channel.CloseWrite()
+	}()
+	go func() {
+		defer wg.Done()
+		io.Copy(remoteConnection, channel)
+		if tcpConnection, ok := remoteConnection.(*net.TCPConn); ok {
+			tcpConnection.CloseWrite()
+		}
+	}()
+	wg.Wait()
+}
+
+func (server *Server) resolveBoxAddress(ctx context.Context, name string, port uint32) (string, error) {
+	boxClient, err := server.newBoxClient()
+	if err != nil {
+		return "", err
+	}
+	details, err := boxClient.Describe(ctx, name)
+	if err != nil {
+		return "", err
+	}
+
+	// the server must be able to reach the box network directly e.g. docker on linux
+	if details.ProviderInfo == nil || details.ProviderInfo.DockerProvider == nil || details.ProviderInfo.DockerProvider.Ip == "" {
+		return "", fmt.Errorf("tunnel not supported: box=%s", name)
+	}
+	return net.JoinHostPort(details.ProviderInfo.DockerProvider.Ip, strconv.FormatUint(uint64(port), 10)), nil
+}
@@ -0,0 +1,28 @@
+package v1
+
+type TaskListRequestBody struct{}
+
+func (b TaskListRequestBody) method() MethodName {
+	return MethodTaskList
+}
+
+type TaskListResponseBody struct {
+	Items []TaskListItem `json:"items"`
+}
+
+type TaskListItem struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
+func (b TaskListResponseBody) method() MethodName {
+	return MethodTaskList
+}
+
+func NewTaskListRequest(origin string) *Message[TaskListRequestBody] {
+	return newMessage[TaskListRequestBody](origin, TaskListRequestBody{})
+}
+
+func NewTaskListResponse(origin string, items []TaskListItem) *Message[TaskListResponseBody] {
+	return newMessage[TaskListResponseBody](origin, TaskListResponseBody{Items: items})
+}
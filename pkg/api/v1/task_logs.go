@@ -0,0 +1,14 @@
+package v1
+
+type TaskLogsSessionBody struct {
+	Name string `json:"name"`
+}
+
+func (b TaskLogsSessionBody) method() MethodName {
+	return MethodTaskLogs
+}
+
+// NewTaskLogsSession streams the output until the task completes
+func NewTaskLogsSession(origin string, name string) *Message[TaskLogsSessionBody] {
+	return newMessage[TaskLogsSessionBody](origin, TaskLogsSessionBody{Name: name})
+}
@@ -0,0 +1,26 @@
+package v1
+
+type TaskRunRequestBody struct {
+	TemplateName string   `json:"templateName"`
+	Arguments    []string `json:"arguments"`
+}
+
+func (b TaskRunRequestBody) method() MethodName {
+	return MethodTaskRun
+}
+
+type TaskRunResponseBody struct {
+	Name string `json:"name"`
+}
+
+func (b TaskRunResponseBody) method() MethodName {
+	return MethodTaskRun
+}
+
+func NewTaskRunRequest(origin string, templateName string, arguments []string) *Message[TaskRunRequestBody] {
+	return newMessage[TaskRunRequestBody](origin, TaskRunRequestBody{TemplateName: templateName, Arguments: arguments})
+}
+
+func NewTaskRunResponse(origin string, name string) *Message[TaskRunResponseBody] {
+	return newMessage[TaskRunResponseBody](origin, TaskRunResponseBody{Name: name})
+}
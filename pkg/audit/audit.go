@@ -0,0 +1,244 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/hckops/hckctl/pkg/util"
+)
+
+const (
+	lockSuffix        = ".lock"
+	lockTimeout       = 5 * time.Second
+	lockRetryInterval = 50 * time.Millisecond
+	staleLockTimeout  = 30 * time.Second
+	readChunkSize     = 4096
+)
+
+// AuditLog is a jsonl file where each record is chained to the previous one with its hash,
+// any change, removal or reordering of the records is detected by Verify
+type AuditLog struct {
+	path string
+}
+
+func NewAuditLog(path string) *AuditLog {
+	return &AuditLog{path: path}
+}
+
+func (l *AuditLog) Path() string {
+	return l.path
+}
+
+// Append sets sequence, time, user and hashes before writing the record, concurrent processes are serialized with a lock file
+func (l *AuditLog) Append(record *Record) error {
+	if err := util.CreateBaseDir(l.path); err != nil {
+		return errors.Wrapf(err, "error creating audit dir: path=%s", l.path)
+	}
+
+	unlock, err := lockFile(l.path + lockSuffix)
+	if err != nil {
+		return errors.Wrapf(err, "error locking audit: path=%s", l.path)
+	}
+	defer unlock()
+
+	last, err := readLastRecord(l.path)
+	if err != nil {
+		return errors.Wrapf(err, "error reading last audit record: path=%s", l.path)
+	}
+	if last == nil {
+		record.Sequence = 1
+		record.PreviousHash = genesisHash
+	} else {
+		record.Sequence = last.Sequence + 1
+		record.PreviousHash = last.Hash
+	}
+	if record.Time.IsZero() {
+		record.Time = time.Now().UTC()
+	}
+	if record.User == "" {
+		record.User = currentUser()
+	}
+	if record.Hash, err = record.computeHash(); err != nil {
+		return errors.Wrap(err, "error hashing audit record")
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return errors.Wrap(err, "error encoding audit record")
+	}
+	file, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.Wrapf(err, "error opening audit: path=%s", l.path)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return errors.Wrapf(err, "error writing audit: path=%s", l.path)
+	}
+	return nil
+}
+
+// ReadRecords returns all the records without verifying them
+func (l *AuditLog) ReadRecords() ([]Record, error) {
+	var records []Record
+	err := l.scan(func(line int, data []byte) error {
+		var record Record
+		if err := json.Unmarshal(data, &record); err != nil {
+			return &ChainError{Line: line, Reason: fmt.Sprintf("invalid record: %v", err)}
+		}
+		records = append(records, record)
+		return nil
+	})
+	return records, err
+}
+
+type VerifyResult struct {
+	Records  int
+	HeadHash string // pin it to detect the truncation of the last records
+}
+
+// ChainError references the first record which breaks the chain
+type ChainError struct {
+	Line   int
+	Reason string
+}
+
+func (e *ChainError) Error() string {
+	return fmt.Sprintf("audit chain broken at line %d: %s", e.Line, e.Reason)
+}
+
+// Verify returns a ChainError if any record was modified, removed, inserted or reordered
+func (l *AuditLog) Verify() (*VerifyResult, error) {
+	result := &VerifyResult{HeadHash: genesisHash}
+	var sequence uint64
+
+	err := l.scan(func(line int, data []byte) error {
+		var record Record
+		if err := json.Unmarshal(data, &record); err != nil {
+			return &ChainError{Line: line, Reason: fmt.Sprintf("invalid record: %v", err)}
+		}
+		if record.Sequence != sequence+1 {
+			return &ChainError{Line: line, Reason: fmt.Sprintf("expected sequence %d, found %d", sequence+1, record.Sequence)}
+		}
+		if record.PreviousHash != result.HeadHash {
+			return &ChainError{Line: line, Reason: "previous hash mismatch"}
+		}
+		if hash, err := record.computeHash(); err != nil || hash != record.Hash {
+			return &ChainError{Line: line, Reason: "hash mismatch"}
+		}
+		sequence = record.Sequence
+		result.Records++
+		result.HeadHash = record.Hash
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// scan ignores empty lines, a missing file is an empty audit
+func (l *AuditLog) scan(callback func(line int, data []byte) error) error {
+	file, err := os.Open(l.path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return errors.Wrapf(err, "error opening audit: path=%s", l.path)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 {
+			if callbackErr := callback(line, trimmed); callbackErr != nil {
+				return callbackErr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return errors.Wrapf(err, "error reading audit: path=%s", l.path)
+		}
+	}
+}
+
+// readLastRecord reads the file backwards, it returns nil if empty
+func readLastRecord(path string) (*Record, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	var buffer []byte
+	var last []byte
+	for offset := info.Size(); offset > 0 && last == nil; {
+		size := min(readChunkSize, offset)
+		offset -= size
+		chunk := make([]byte, size)
+		if _, err := file.ReadAt(chunk, offset); err != nil {
+			return nil, err
+		}
+		buffer = bytes.TrimRight(append(chunk, buffer...), "\r\n ")
+		if index := bytes.LastIndexByte(buffer, '\n'); index >= 0 {
+			last = buffer[index+1:]
+		} else if offset == 0 {
+			last = buffer
+		}
+	}
+	if len(last) == 0 {
+		return nil, nil
+	}
+
+	var record Record
+	if err := json.Unmarshal(last, &record); err != nil {
+		return nil, errors.Wrap(err, "invalid last record")
+	}
+	return &record, nil
+}
+
+// lockFile waits until any other process releases the lock, a lock left by a crashed process expires
+func lockFile(path string) (func(), error) {
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			file.Close()
+			return func() { os.Remove(path) }, nil
+		} else if !os.IsExist(err) {
+			return nil, err
+		}
+
+		if info, statErr := os.Stat(path); statErr == nil && time.Since(info.ModTime()) > staleLockTimeout {
+			os.Remove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timeout waiting for lock %s", path)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+func currentUser() string {
+	if usr, err := user.Current(); err == nil {
+		return usr.Username
+	}
+	return "unknown"
+}
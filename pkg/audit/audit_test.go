@@ -0,0 +1,189 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestAuditLog(t *testing.T) *AuditLog {
+	return NewAuditLog(filepath.Join(t.TempDir(), "audit", "audit.jsonl"))
+}
+
+func appendTestRecords(t *testing.T, auditLog *AuditLog, count int) {
+	for i := 0; i < count; i++ {
+		assert.NoError(t, auditLog.Append(&Record{
+			Action:    TaskStartAction,
+			Name:      "task-nmap",
+			Arguments: []string{"nmap", "10.10.10.1"},
+			Targets:   []string{"10.10.10.1"},
+		}))
+	}
+}
+
+func readLines(t *testing.T, auditLog *AuditLog) []string {
+	data, err := os.ReadFile(auditLog.Path())
+	assert.NoError(t, err)
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
+
+func writeLines(t *testing.T, auditLog *AuditLog, lines []string) {
+	assert.NoError(t, os.WriteFile(auditLog.Path(), []byte(strings.Join(lines, "\n")+"\n"), 0600))
+}
+
+func TestAppend(t *testing.T) {
+	auditLog := newTestAuditLog(t)
+	appendTestRecords(t, auditLog, 3)
+
+	records, err := auditLog.ReadRecords()
+	assert.NoError(t, err)
+	assert.Len(t, records, 3)
+
+	assert.Equal(t, uint64(1), records[0].Sequence)
+	assert.Equal(t, genesisHash, records[0].PreviousHash)
+	assert.NotEmpty(t, records[0].User)
+	assert.False(t, records[0].Time.IsZero())
+	for i := 1; i < len(records); i++ {
+		assert.Equal(t, uint64(i+1), records[i].Sequence)
+		assert.Equal(t, records[i-1].Hash, records[i].PreviousHash)
+	}
+	_, err = os.Stat(auditLog.Path() + lockSuffix)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestAppendConcurrent(t *testing.T) {
+	auditLog := newTestAuditLog(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			appendTestRecords(t, auditLog, 1)
+		}()
+	}
+	wg.Wait()
+
+	result, err := auditLog.Verify()
+	assert.NoError(t, err)
+	assert.Equal(t, 10, result.Records)
+}
+
+func TestVerify(t *testing.T) {
+	auditLog := newTestAuditLog(t)
+
+	result, err := auditLog.Verify()
+	assert.NoError(t, err)
+	assert.Equal(t, &VerifyResult{Records: 0, HeadHash: genesisHash}, result)
+
+	appendTestRecords(t, auditLog, 3)
+	records, _ := auditLog.ReadRecords()
+
+	result, err = auditLog.Verify()
+	assert.NoError(t, err)
+	assert.Equal(t, &VerifyResult{Records: 3, HeadHash: records[2].Hash}, result)
+}
+
+func TestVerifyTampered(t *testing.T) {
+	auditLog := newTestAuditLog(t)
+	appendTestRecords(t, auditLog, 3)
+	lines := readLines(t, auditLog)
+
+	// modified
+	writeLines(t, auditLog, []string{lines[0], strings.Replace(lines[1], "10.10.10.1", "10.10.10.2", -1), lines[2]})
+	_, err := auditLog.Verify()
+	assert.EqualError(t, err, "audit chain broken at line 2: hash mismatch")
+
+	// removed
+	writeLines(t, auditLog, []string{lines[0], lines[2]})
+	_, err = auditLog.Verify()
+	assert.EqualError(t, err, "audit chain broken at line 2: expected sequence 2, found 3")
+
+	// reordered
+	writeLines(t, auditLog, []string{lines[1], lines[0], lines[2]})
+	_, err = auditLog.Verify()
+	assert.EqualError(t, err, "audit chain broken at line 1: expected sequence 1, found 2")
+
+	// rewritten with a valid hash
+	var record Record
+	assert.NoError(t, json.Unmarshal([]byte(lines[1]), &record))
+	record.Targets = []string{"10.10.10.2"}
+	record.Hash, _ = record.computeHash()
+	rewritten, _ := json.Marshal(record)
+	writeLines(t, auditLog, []string{lines[0], string(rewritten), lines[2]})
+	_, err = auditLog.Verify()
+	assert.EqualError(t, err, "audit chain broken at line 3: previous hash mismatch")
+
+	// invalid
+	writeLines(t, auditLog, []string{lines[0], "{"})
+	_, err = auditLog.Verify()
+	var chainErr *ChainError
+	assert.ErrorAs(t, err, &chainErr)
+	assert.Equal(t, 2, chainErr.Line)
+}
+
+func TestReadLastRecord(t *testing.T) {
+	auditLog := newTestAuditLog(t)
+
+	record, err := readLastRecord(auditLog.Path())
+	assert.NoError(t, err)
+	assert.Nil(t, record)
+
+	// larger than a chunk
+	assert.NoError(t, auditLog.Append(&Record{Action: EventAction, Message: strings.Repeat("a", readChunkSize*2)}))
+	assert.NoError(t, auditLog.Append(&Record{Action: EventAction, Message: strings.Repeat("b", readChunkSize)}))
+
+	record, err = readLastRecord(auditLog.Path())
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), record.Sequence)
+	assert.Equal(t, strings.Repeat("b", readChunkSize), record.Message)
+}
+
+func TestLockFileStale(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl.lock")
+	assert.NoError(t, os.WriteFile(path, []byte{}, 0600))
+	stale := time.Now().Add(-2 * staleLockTimeout)
+	assert.NoError(t, os.Chtimes(path, stale, stale))
+
+	unlock, err := lockFile(path)
+	assert.NoError(t, err)
+	unlock()
+}
+
+func TestExportCsv(t *testing.T) {
+	exitCode := 1
+	start := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	records := []Record{{
+		Sequence:     1,
+		Time:         start,
+		User:         "my-user",
+		Action:       TaskStopAction,
+		Provider:     "docker",
+		Name:         "task-nmap",
+		Template:     &TemplateRef{Name: "scanner/nmap", Source: "git", Revision: "abc"},
+		Arguments:    []string{"nmap", "10.10.10.1"},
+		StartTime:    &start,
+		ExitCode:     &exitCode,
+		PreviousHash: "000",
+		Hash:         "123",
+	}}
+
+	var out bytes.Buffer
+	assert.NoError(t, ExportCsv(&out, records))
+	assert.Equal(t, strings.Join(csvHeader, ",")+"\n"+
+		"1,2024-01-01T09:00:00Z,my-user,task-stop,docker,task-nmap,scanner/nmap,abc,nmap 10.10.10.1,,,2024-01-01T09:00:00Z,,1,,,,,000,123\n",
+		out.String())
+}
+
+func TestExportJson(t *testing.T) {
+	var out bytes.Buffer
+	assert.NoError(t, ExportJson(&out, nil))
+	assert.Equal(t, "[]\n", out.String())
+}
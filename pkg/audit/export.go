@@ -0,0 +1,78 @@
+package audit
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var csvHeader = []string{
+	"seq", "time", "user", "action", "provider", "name", "template", "revision", "arguments", "targets",
+	"networkVpn", "startTime", "stopTime", "exitCode", "error", "level", "source", "message", "previousHash", "hash",
+}
+
+// ExportJson writes all the records as an indented json array
+func ExportJson(out io.Writer, records []Record) error {
+	if records == nil {
+		records = []Record{}
+	}
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(records)
+}
+
+// ExportCsv writes one row per record, lists are space separated
+func ExportCsv(out io.Writer, records []Record) error {
+	writer := csv.NewWriter(out)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, record := range records {
+		var templateName, templateRevision string
+		if record.Template != nil {
+			templateName = record.Template.Name
+			templateRevision = record.Template.Revision
+		}
+		var exitCode string
+		if record.ExitCode != nil {
+			exitCode = strconv.Itoa(*record.ExitCode)
+		}
+		row := []string{
+			strconv.FormatUint(record.Sequence, 10),
+			record.Time.Format(time.RFC3339Nano),
+			record.User,
+			string(record.Action),
+			record.Provider,
+			record.Name,
+			templateName,
+			templateRevision,
+			strings.Join(record.Arguments, " "),
+			strings.Join(record.Targets, " "),
+			record.NetworkVpn,
+			formatTime(record.StartTime),
+			formatTime(record.StopTime),
+			exitCode,
+			record.Error,
+			record.Level,
+			record.Source,
+			record.Message,
+			record.PreviousHash,
+			record.Hash,
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func formatTime(value *time.Time) string {
+	if value == nil {
+		return ""
+	}
+	return value.Format(time.RFC3339Nano)
+}
@@ -0,0 +1,87 @@
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/hckops/hckctl/pkg/event"
+	"github.com/hckops/hckctl/pkg/util"
+)
+
+type Action string
+
+const (
+	BoxCreateAction Action = "box-create"
+	BoxDeleteAction Action = "box-delete"
+	BoxExecAction   Action = "box-exec"
+	TaskStartAction Action = "task-start"
+	TaskStopAction  Action = "task-stop"
+	LabCreateAction Action = "lab-create"
+	LabDeleteAction Action = "lab-delete"
+	EventAction     Action = "event"
+)
+
+// the previous hash of the first record
+var genesisHash = strings.Repeat("0", sha256.Size*2)
+
+type TemplateRef struct {
+	Name     string `json:"name"`
+	Source   string `json:"source"`             // local or git
+	Revision string `json:"revision,omitempty"` // git commit
+}
+
+// Record is an append-only entry, the hash covers all the other fields including the hash of the previous record
+type Record struct {
+	Sequence     uint64       `json:"seq"`
+	Time         time.Time    `json:"time"`
+	User         string       `json:"user"`
+	Action       Action       `json:"action"`
+	Provider     string       `json:"provider,omitempty"`
+	Name         string       `json:"name,omitempty"` // box, task or lab
+	Template     *TemplateRef `json:"template,omitempty"`
+	Arguments    []string     `json:"arguments,omitempty"`
+	Targets      []string     `json:"targets,omitempty"`
+	NetworkVpn   string       `json:"networkVpn,omitempty"`
+	StartTime    *time.Time   `json:"startTime,omitempty"`
+	StopTime     *time.Time   `json:"stopTime,omitempty"`
+	ExitCode     *int         `json:"exitCode,omitempty"`
+	Error        string       `json:"error,omitempty"`
+	Level        string       `json:"level,omitempty"` // event only
+	Source       string       `json:"source,omitempty"`
+	Message      string       `json:"message,omitempty"`
+	PreviousHash string       `json:"previousHash"`
+	Hash         string       `json:"hash"`
+}
+
+func NewEventRecord(e event.Event) *Record {
+	return &Record{
+		Action:  EventAction,
+		Level:   e.Kind().String(),
+		Source:  e.Source(),
+		Message: util.RedactSecrets(e.String()),
+	}
+}
+
+// IsAuditable excludes the events which are only relevant for the console
+func IsAuditable(e event.Event) bool {
+	switch e.Kind() {
+	case event.LogInfo, event.LogWarning, event.LogError:
+		return true
+	default:
+		return false
+	}
+}
+
+func (r *Record) computeHash() (string, error) {
+	unsigned := *r
+	unsigned.Hash = ""
+	data, err := json.Marshal(unsigned)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -1,6 +1,8 @@
 package box
 
 import (
+	"context"
+
 	"github.com/pkg/errors"
 
 	"github.com/hckops/hckctl/pkg/box/cloud"
@@ -13,16 +15,20 @@ import (
 type BoxClient interface {
 	Provider() model.BoxProvider // TODO replace with generics
 	Events() *event.EventBus
-	Create(opts *model.CreateOptions) (*model.BoxInfo, error)
-	Connect(opts *model.ConnectOptions) error
-	Describe(name string) (*model.BoxDetails, error)
-	List() ([]model.BoxInfo, error)
-	Delete(names []string) ([]string, error) // empty "names" means all boxes
-	Clean() error                            // TODO delete source in params: remove local and git cache
-	Version() (string, error)                // TODO replace string with BoxVersion interface, return both client and server version
+	Create(ctx context.Context, opts *model.CreateOptions) (*model.BoxInfo, error)
+	Connect(ctx context.Context, opts *model.ConnectOptions) error
+	Copy(ctx context.Context, opts *model.CopyOptions) error
+	Exec(ctx context.Context, opts *model.ExecOptions) (int, error) // returns the exit code of the command
+	Logs(ctx context.Context, opts *model.LogsOptions) error
+	PortForward(ctx context.Context, opts *model.PortForwardOptions) error // blocks until the context is cancelled
+	Describe(ctx context.Context, name string) (*model.BoxDetails, error)
+	List(ctx context.Context) ([]model.BoxInfo, error)
+	Delete(ctx context.Context, names []string) ([]string, error) // empty "names" means all boxes
+	Clean(ctx context.Context) error                              // TODO delete source in params: remove local and git cache
+	Version(ctx context.Context) (string, error)                  // TODO replace string with BoxVersion interface, return both client and server version
 }
 
-func NewBoxClient(opts *model.BoxClientOptions) (BoxClient, error) {
+func NewBoxClient(ctx context.Context, opts *model.BoxClientOptions) (BoxClient, error) {
 	commonOpts := model.NewCommonBoxOpts()
 	switch opts.Provider {
 	case model.Docker:
@@ -30,7 +36,7 @@ func NewBoxClient(opts *model.BoxClientOptions) (BoxClient, error) {
 	case model.Kubernetes:
 		return kubernetes.NewKubeBoxClient(commonOpts, opts.KubeOpts)
 	case model.Cloud:
-		return cloud.NewCloudBoxClient(commonOpts, opts.CloudOpts)
+		return cloud.NewCloudBoxClient(ctx, commonOpts, opts.CloudOpts)
 	default:
 		return nil, errors.New("invalid provider")
 	}
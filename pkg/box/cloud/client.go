@@ -1,6 +1,7 @@
 package cloud
 
 import (
+	"context"
 	"github.com/pkg/errors"
 
 	boxModel "github.com/hckops/hckctl/pkg/box/model"
@@ -15,8 +16,8 @@ type CloudBoxClient struct {
 	eventBus   *event.EventBus
 }
 
-func NewCloudBoxClient(commonOpts *boxModel.CommonBoxOptions, cloudOpts *commonModel.CloudOptions) (*CloudBoxClient, error) {
-	return newCloudBoxClient(commonOpts, cloudOpts)
+func NewCloudBoxClient(ctx context.Context, commonOpts *boxModel.CommonBoxOptions, cloudOpts *commonModel.CloudOptions) (*CloudBoxClient, error) {
+	return newCloudBoxClient(ctx, commonOpts, cloudOpts)
 }
 
 func (box *CloudBoxClient) Provider() boxModel.BoxProvider {
@@ -27,37 +28,57 @@ func (box *CloudBoxClient) Events() *event.EventBus {
 	return box.eventBus
 }
 
-func (box *CloudBoxClient) Create(opts *boxModel.CreateOptions) (*boxModel.BoxInfo, error) {
+func (box *CloudBoxClient) Create(ctx context.Context, opts *boxModel.CreateOptions) (*boxModel.BoxInfo, error) {
 	//defer box.close()
-	return box.createBox(opts)
+	return box.createBox(ctx, opts)
 }
 
-func (box *CloudBoxClient) Connect(opts *boxModel.ConnectOptions) error {
+func (box *CloudBoxClient) Connect(ctx context.Context, opts *boxModel.ConnectOptions) error {
 	//defer box.close()
-	return box.connectBox(opts)
+	return box.connectBox(ctx, opts)
 }
 
-func (box *CloudBoxClient) Describe(name string) (*boxModel.BoxDetails, error) {
+func (box *CloudBoxClient) Copy(ctx context.Context, opts *boxModel.CopyOptions) error {
+	defer box.close()
+	return box.copyBox(ctx, opts)
+}
+
+func (box *CloudBoxClient) Exec(ctx context.Context, opts *boxModel.ExecOptions) (int, error) {
+	defer box.close()
+	return box.execCommandBox(ctx, opts)
+}
+
+func (box *CloudBoxClient) Logs(ctx context.Context, opts *boxModel.LogsOptions) error {
+	defer box.close()
+	return box.filterLogsBox(ctx, opts)
+}
+
+func (box *CloudBoxClient) PortForward(ctx context.Context, opts *boxModel.PortForwardOptions) error {
+	defer box.close()
+	return box.portForwardBox(ctx, opts)
+}
+
+func (box *CloudBoxClient) Describe(ctx context.Context, name string) (*boxModel.BoxDetails, error) {
 	//defer box.close()
-	return box.describeBox(name)
+	return box.describeBox(ctx, name)
 }
 
-func (box *CloudBoxClient) List() ([]boxModel.BoxInfo, error) {
+func (box *CloudBoxClient) List(ctx context.Context) ([]boxModel.BoxInfo, error) {
 	defer box.close()
-	return box.listBoxes()
+	return box.listBoxes(ctx)
 }
 
-func (box *CloudBoxClient) Delete(names []string) ([]string, error) {
+func (box *CloudBoxClient) Delete(ctx context.Context, names []string) ([]string, error) {
 	defer box.close()
-	return box.deleteBoxes(names)
+	return box.deleteBoxes(ctx, names)
 }
 
-func (box *CloudBoxClient) Clean() error {
+func (box *CloudBoxClient) Clean(ctx context.Context) error {
 	defer box.close()
 	return errors.New("not implemented")
 }
 
-func (box *CloudBoxClient) Version() (string, error) {
+func (box *CloudBoxClient) Version(ctx context.Context) (string, error) {
 	defer box.close()
-	return box.version()
+	return box.version(ctx)
 }
@@ -1,7 +1,12 @@
 package cloud
 
 import (
+	"context"
+	"fmt"
+	"io"
+	"path"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -13,18 +18,37 @@ import (
 	"github.com/hckops/hckctl/pkg/util"
 )
 
-func newCloudBoxClient(commonOpts *boxModel.CommonBoxOptions, cloudOpts *commonModel.CloudOptions) (*CloudBoxClient, error) {
+// creating a box could require to pull the image
+const createRequestTimeout = 10 * time.Minute
+
+func newCloudBoxClient(ctx context.Context, commonOpts *boxModel.CommonBoxOptions, cloudOpts *commonModel.CloudOptions) (*CloudBoxClient, error) {
 	commonOpts.EventBus.Publish(newInitCloudClientEvent())
 
 	clientConfig := &ssh.SshClientConfig{
-		Address:  cloudOpts.Address,
-		Username: cloudOpts.Username,
-		Token:    cloudOpts.Token,
+		Address:        cloudOpts.Address,
+		Username:       cloudOpts.Username,
+		Token:          cloudOpts.Token,
+		PrivateKeyPath: cloudOpts.PrivateKeyPath,
+		UseAgent:       cloudOpts.UseAgent,
+		KnownHostsPath: cloudOpts.KnownHostsPath,
+		Fingerprint:    cloudOpts.Fingerprint,
+		OnReconnectCallback: func(attempt int, err error) {
+			commonOpts.EventBus.Publish(newReconnectCloudClientEvent(attempt, err))
+		},
+		OnTrustHostCallback: func(host string, fingerprint string) {
+			commonOpts.EventBus.Publish(newTrustHostCloudClientEvent(host, fingerprint))
+			commonOpts.EventBus.Publish(newTrustHostCloudClientConsoleEvent(host, fingerprint))
+		},
 	}
-	sshClient, err := ssh.NewSshClient(clientConfig)
+	sshClient, err := ssh.NewSshClient(ctx, clientConfig)
 	if err != nil {
 		return nil, errors.Wrap(err, "error cloud box")
 	}
+	// detects incompatible servers before any other request
+	if _, err := v1.Negotiate(ctx, cloudOpts.Version, sshClient.SendRequest); err != nil {
+		sshClient.Close()
+		return nil, errors.Wrap(err, "error cloud box version")
+	}
 
 	return &CloudBoxClient{
 		client:     sshClient,
@@ -36,21 +60,21 @@ func newCloudBoxClient(commonOpts *boxModel.CommonBoxOptions, cloudOpts *commonM
 // TODO issue "use of closed network connection" after multiple invocation e.g. info + open
 func (box *CloudBoxClient) close() error {
 	box.eventBus.Publish(newCloseCloudClientEvent())
-	box.eventBus.Close()
+	box.eventBus.Flush()
 	return box.client.Close()
 }
 
-func (box *CloudBoxClient) createBox(opts *boxModel.CreateOptions) (*boxModel.BoxInfo, error) {
+func (box *CloudBoxClient) createBox(ctx context.Context, opts *boxModel.CreateOptions) (*boxModel.BoxInfo, error) {
 	box.eventBus.Publish(newApiCreateCloudLoaderEvent(box.clientOpts.Address, opts.Template.Name))
 
-	request := v1.NewBoxCreateRequest(box.clientOpts.Version, opts.Template.Name, opts.Size.String())
+	request := v1.NewBoxCreateRequest(box.clientOpts.Version, opts.Template.Name, opts.Size.String()).WithRequestId(v1.NewRequestId())
 	payload, err := request.Encode()
 	if err != nil {
 		return nil, errors.Wrap(err, "error cloud create request")
 	}
-	value, err := box.client.SendRequest(request.Protocol(), payload)
+	value, err := box.client.SendRequestTimeout(ctx, request.Protocol(), payload, createRequestTimeout)
 	if err != nil {
-		return nil, errors.Wrap(err, "error cloud create")
+		return nil, errors.Wrap(v1.ToError(err), "error cloud create")
 	}
 
 	response, err := v1.Decode[v1.BoxCreateResponseBody](value)
@@ -64,7 +88,7 @@ func (box *CloudBoxClient) createBox(opts *boxModel.CreateOptions) (*boxModel.Bo
 	return &boxModel.BoxInfo{Id: boxName, Name: boxName}, nil
 }
 
-func (box *CloudBoxClient) connectBox(opts *boxModel.ConnectOptions) error {
+func (box *CloudBoxClient) connectBox(ctx context.Context, opts *boxModel.ConnectOptions) error {
 
 	if opts.DisableExec && opts.DisableTunnel {
 		return errors.New("invalid connection options")
@@ -77,35 +101,41 @@ func (box *CloudBoxClient) connectBox(opts *boxModel.ConnectOptions) error {
 
 	// tunnel only
 	if opts.DisableExec {
-		return box.tunnelBox(opts.Template, opts.Name, true)
+		return box.tunnelBox(ctx, opts.Template, opts.Name, true)
 	}
 
 	if !opts.DisableTunnel {
-		if err := box.tunnelBox(opts.Template, opts.Name, false); err != nil {
+		if err := box.tunnelBox(ctx, opts.Template, opts.Name, false); err != nil {
 			return err
 		}
 	}
 
-	return box.execBox(opts)
+	return box.execBox(ctx, opts)
 }
 
-func (box *CloudBoxClient) execBox(opts *boxModel.ConnectOptions) error {
+func (box *CloudBoxClient) execBox(ctx context.Context, opts *boxModel.ConnectOptions) error {
 	box.eventBus.Publish(newApiExecCloudEvent(opts.Name))
 
-	session := v1.NewBoxExecSession(box.clientOpts.Version, opts.Name)
+	session := v1.NewBoxExecSession(box.clientOpts.Version, opts.Name).WithRequestId(v1.NewRequestId())
 	payload, err := session.Encode()
 	if err != nil {
 		return errors.Wrap(err, "error cloud exec session")
 	}
 
-	// TODO close streams on opts.OnInterruptCallback
-
-	if opts.DeleteOnExit {
-		defer box.deleteBoxes([]string{opts.Name})
-	}
+	defer func() {
+		if opts.IsDetached() {
+			box.eventBus.Publish(newApiExecDetachCloudEvent(opts.Name))
+		} else if opts.DeleteOnExit {
+			// the box is deleted even if the session was interrupted
+			box.deleteBoxes(context.WithoutCancel(ctx), []string{opts.Name})
+		}
+	}()
 
 	execOpts := &ssh.SshExecOpts{
-		Payload: payload,
+		Payload:   payload,
+		InStream:  opts.StreamOpts.In,
+		OutStream: opts.StreamOpts.Out,
+		ErrStream: opts.StreamOpts.Err,
 		OnStreamStartCallback: func() {
 			// stop loader
 			box.eventBus.Publish(newApiStopCloudLoaderEvent())
@@ -113,11 +143,78 @@ func (box *CloudBoxClient) execBox(opts *boxModel.ConnectOptions) error {
 		OnStreamErrorCallback: func(err error) {
 			box.eventBus.Publish(newApiExecErrorCloudEvent(opts.Name, err))
 		},
+		OnPtyErrorCallback: func(err error) {
+			box.eventBus.Publish(newApiExecPtyErrorCloudEvent(opts.Name, err))
+		},
+	}
+	return box.client.Exec(ctx, execOpts)
+}
+
+func (box *CloudBoxClient) execCommandBox(ctx context.Context, opts *boxModel.ExecOptions) (int, error) {
+	box.eventBus.Publish(newApiExecCommandCloudEvent(opts.Name, opts.Command))
+
+	session := v1.NewBoxExecCommandSession(box.clientOpts.Version, opts.Name, opts.Command).WithRequestId(v1.NewRequestId())
+	payload, err := session.Encode()
+	if err != nil {
+		return -1, errors.Wrap(err, "error cloud exec session")
+	}
+
+	commandOpts := &ssh.SshCommandOpts{
+		Payload:   payload,
+		InStream:  opts.StreamOpts.In,
+		OutStream: opts.StreamOpts.Out,
+		ErrStream: opts.StreamOpts.Err,
+		IsTty:     opts.StreamOpts.IsTty,
+		OnStreamStartCallback: func() {
+			// stop loader
+			box.eventBus.Publish(newApiStopCloudLoaderEvent())
+		},
+		OnPtyErrorCallback: func(err error) {
+			box.eventBus.Publish(newApiExecPtyErrorCloudEvent(opts.Name, err))
+		},
+	}
+	exitCode, err := box.client.ExecCommand(ctx, commandOpts)
+	if err != nil {
+		return -1, err
+	}
+	box.eventBus.Publish(newApiExecExitCodeCloudEvent(opts.Name, exitCode))
+	return exitCode, nil
+}
+
+func (box *CloudBoxClient) filterLogsBox(ctx context.Context, opts *boxModel.LogsOptions) error {
+	box.eventBus.Publish(newApiLogsCloudEvent(opts.Name, opts.Sidecar))
+
+	body := v1.BoxLogsSessionBody{
+		Name:    opts.Name,
+		Follow:  opts.Follow,
+		Tail:    opts.Tail,
+		Sidecar: opts.Sidecar,
+	}
+	if opts.Since > 0 {
+		body.Since = opts.Since.String()
+	}
+	session := v1.NewBoxLogsSession(box.clientOpts.Version, body).WithRequestId(v1.NewRequestId())
+	payload, err := session.Encode()
+	if err != nil {
+		return errors.Wrap(err, "error cloud logs session")
+	}
+
+	commandOpts := &ssh.SshCommandOpts{
+		Payload:   payload,
+		OutStream: opts.StreamOpts.Out,
+		ErrStream: opts.StreamOpts.Err,
+		IsTty:     false,
+		OnStreamStartCallback: func() {
+			// stop loader
+			box.eventBus.Publish(newApiStopCloudLoaderEvent())
+		},
 	}
-	return box.client.Exec(execOpts)
+	// ignore exit code
+	_, err = box.client.ExecCommand(ctx, commandOpts)
+	return err
 }
 
-func (box *CloudBoxClient) tunnelBox(template *boxModel.BoxV1, name string, isWait bool) error {
+func (box *CloudBoxClient) tunnelBox(ctx context.Context, template *boxModel.BoxV1, name string, isWait bool) error {
 
 	if !template.HasPorts() {
 		box.eventBus.Publish(newApiTunnelIgnoreCloudEvent(name))
@@ -128,7 +225,9 @@ func (box *CloudBoxClient) tunnelBox(template *boxModel.BoxV1, name string, isWa
 	networkPorts := template.NetworkPortValues(true)
 	portPadding := boxModel.PortFormatPadding(networkPorts)
 
-	errorChannel := make(chan struct{})
+	// closed when the first tunnel stops
+	stopChannel := make(chan struct{})
+	var stopOnce sync.Once
 
 	for _, p := range networkPorts {
 		port, err := bindPort(p)
@@ -154,23 +253,75 @@ func (box *CloudBoxClient) tunnelBox(template *boxModel.BoxV1, name string, isWa
 				box.eventBus.Publish(newApiTunnelStopCloudEvent(name, port, connection))
 			},
 			OnTunnelErrorCallback: func(err error) {
+				// connection errors are not fatal, the tunnel keeps listening
 				box.eventBus.Publish(newApiTunnelErrorCloudEvent(name, err))
-				close(errorChannel)
 			},
 		}
-		go box.client.Tunnel(sshTunnelOpts)
+		go func() {
+			// returns only if the local listener fails or the client is closed
+			if err := box.client.Tunnel(ctx, sshTunnelOpts); err != nil {
+				box.eventBus.Publish(newApiTunnelErrorCloudEvent(name, err))
+			}
+			stopOnce.Do(func() { close(stopChannel) })
+		}()
 	}
 
 	if isWait {
 		// waits until it's interrupted
-		select {
-		case <-errorChannel:
-		}
+		<-stopChannel
 	}
 
 	return nil
 }
 
+func (box *CloudBoxClient) portForwardBox(ctx context.Context, opts *boxModel.PortForwardOptions) error {
+
+	// fails if the requested port is already in use, instead of binding the next available
+	if err := util.CheckLocalAddress(opts.BindHost(), opts.Port.Local); err != nil {
+		return errors.Wrapf(err, "error bind local port %s", opts.Port.Local)
+	}
+	box.eventBus.Publish(newApiTunnelBindingCloudEvent(opts.Name, opts.Port))
+	box.eventBus.Publish(newApiTunnelBindingCloudConsoleEvent(opts.Name, opts.Port, len(opts.Port.Alias)))
+	box.eventBus.Publish(newApiTunnelListenCloudLoaderEvent())
+
+	sshTunnelOpts := &ssh.SshTunnelOpts{
+		LocalHost:  opts.BindHost(),
+		LocalPort:  opts.Port.Local,
+		RemoteHost: opts.Name,
+		RemotePort: opts.Port.Remote,
+		OnTunnelListenCallback: func(string) {
+			// stop loader
+			box.eventBus.Publish(newApiStopCloudLoaderEvent())
+			opts.OnPortBindCallback(opts.LocalAddress())
+		},
+		OnTunnelStartCallback: func(connection string) {
+			box.eventBus.Publish(newApiTunnelStartCloudEvent(opts.Name, opts.Port, connection))
+		},
+		OnTunnelStopCallback: func(connection string) {
+			box.eventBus.Publish(newApiTunnelStopCloudEvent(opts.Name, opts.Port, connection))
+		},
+		OnTunnelErrorCallback: func(err error) {
+			// connection errors are not fatal, the tunnel keeps listening
+			box.eventBus.Publish(newApiTunnelErrorCloudEvent(opts.Name, err))
+		},
+	}
+
+	errorChannel := make(chan error, 1)
+	go func() {
+		// returns only if the local listener fails or the client is closed
+		errorChannel <- box.client.Tunnel(ctx, sshTunnelOpts)
+	}()
+
+	// waits until it's interrupted
+	if err := <-errorChannel; err != nil {
+		return errors.Wrapf(err, "error cloud tunnel stopped: address=%s", opts.LocalAddress())
+	} else if ctx.Err() != nil {
+		// stopped explicitly
+		return nil
+	}
+	return fmt.Errorf("error cloud tunnel stopped: address=%s", opts.LocalAddress())
+}
+
 func bindPort(port boxModel.BoxPort) (boxModel.BoxPort, error) {
 	localPort, err := util.FindOpenPort(port.Local)
 	if err != nil {
@@ -182,17 +333,65 @@ func bindPort(port boxModel.BoxPort) (boxModel.BoxPort, error) {
 	return port, nil
 }
 
-func (box *CloudBoxClient) describeBox(name string) (*boxModel.BoxDetails, error) {
+func (box *CloudBoxClient) copyBox(ctx context.Context, opts *boxModel.CopyOptions) error {
+	box.eventBus.Publish(newApiCopyCloudEvent(opts))
+
+	session := v1.NewBoxCopySession(box.clientOpts.Version, opts.Name, opts.RemotePath, opts.Direction.String()).WithRequestId(v1.NewRequestId())
+	payload, err := session.Encode()
+	if err != nil {
+		return errors.Wrap(err, "error cloud copy session")
+	}
+
+	onProgressCallback := func(size int64) {
+		box.eventBus.Publish(newApiCopyProgressCloudLoaderEvent(opts.Direction, size))
+	}
+	// the archive entries are nested under the base name of the remote path
+	prefix := path.Base(opts.RemotePath)
+
+	reader, writer := io.Pipe()
+	defer reader.Close()
+
+	if opts.Direction == boxModel.CopyUpload {
+		if util.PathNotExist(opts.LocalPath) {
+			return fmt.Errorf("error cloud copy invalid localPath=%s", opts.LocalPath)
+		}
+		go func() {
+			writer.CloseWithError(util.CreateTar(opts.LocalPath, prefix, writer))
+		}()
+
+		streamOpts := &ssh.SshStreamOpts{
+			Payload:   payload,
+			InStream:  util.NewProgressReader(reader, onProgressCallback),
+			OutStream: io.Discard,
+		}
+		return box.client.Stream(ctx, streamOpts)
+	}
+
+	go func() {
+		streamOpts := &ssh.SshStreamOpts{
+			Payload:   payload,
+			InStream:  strings.NewReader(""),
+			OutStream: writer,
+		}
+		writer.CloseWithError(box.client.Stream(ctx, streamOpts))
+	}()
+	if err := util.ExtractTar(util.NewProgressReader(reader, onProgressCallback), prefix, opts.LocalPath); err != nil {
+		return errors.Wrap(err, "error cloud copy")
+	}
+	return nil
+}
+
+func (box *CloudBoxClient) describeBox(ctx context.Context, name string) (*boxModel.BoxDetails, error) {
 	box.eventBus.Publish(newApiDescribeCloudEvent(name))
 
-	request := v1.NewBoxDescribeRequest(box.clientOpts.Version, name)
+	request := v1.NewBoxDescribeRequest(box.clientOpts.Version, name).WithRequestId(v1.NewRequestId())
 	payload, err := request.Encode()
 	if err != nil {
 		return nil, errors.Wrap(err, "error cloud describe request")
 	}
-	value, err := box.client.SendRequest(request.Protocol(), payload)
+	value, err := box.client.SendRequest(ctx, request.Protocol(), payload)
 	if err != nil {
-		return nil, errors.Wrap(err, "error cloud describe")
+		return nil, errors.Wrap(v1.ToError(err), "error cloud describe")
 	}
 
 	response, err := v1.Decode[v1.BoxDescribeResponseBody](value)
@@ -265,16 +464,16 @@ func toBoxDetails(response *v1.Message[v1.BoxDescribeResponseBody]) (*boxModel.B
 	}, nil
 }
 
-func (box *CloudBoxClient) listBoxes() ([]boxModel.BoxInfo, error) {
+func (box *CloudBoxClient) listBoxes(ctx context.Context) ([]boxModel.BoxInfo, error) {
 
-	request := v1.NewBoxListRequest(box.clientOpts.Version)
+	request := v1.NewBoxListRequest(box.clientOpts.Version).WithRequestId(v1.NewRequestId())
 	payload, err := request.Encode()
 	if err != nil {
 		return nil, errors.Wrap(err, "error cloud list request")
 	}
-	value, err := box.client.SendRequest(request.Protocol(), payload)
+	value, err := box.client.SendRequest(ctx, request.Protocol(), payload)
 	if err != nil {
-		return nil, errors.Wrap(err, "error cloud list")
+		return nil, errors.Wrap(v1.ToError(err), "error cloud list")
 	}
 
 	response, err := v1.Decode[v1.BoxListResponseBody](value)
@@ -290,16 +489,16 @@ func (box *CloudBoxClient) listBoxes() ([]boxModel.BoxInfo, error) {
 	return result, nil
 }
 
-func (box *CloudBoxClient) deleteBoxes(names []string) ([]string, error) {
+func (box *CloudBoxClient) deleteBoxes(ctx context.Context, names []string) ([]string, error) {
 
-	request := v1.NewBoxDeleteRequest(box.clientOpts.Version, names)
+	request := v1.NewBoxDeleteRequest(box.clientOpts.Version, names).WithRequestId(v1.NewRequestId())
 	payload, err := request.Encode()
 	if err != nil {
 		return nil, errors.Wrap(err, "error cloud delete request")
 	}
-	value, err := box.client.SendRequest(request.Protocol(), payload)
+	value, err := box.client.SendRequest(ctx, request.Protocol(), payload)
 	if err != nil {
-		return nil, errors.Wrap(err, "error cloud delete")
+		return nil, errors.Wrap(v1.ToError(err), "error cloud delete")
 	}
 
 	response, err := v1.Decode[v1.BoxDeleteResponseBody](value)
@@ -315,18 +514,18 @@ func (box *CloudBoxClient) deleteBoxes(names []string) ([]string, error) {
 	return result, nil
 }
 
-func (box *CloudBoxClient) version() (string, error) {
+func (box *CloudBoxClient) version(ctx context.Context) (string, error) {
 
-	request := v1.NewPingMessage(box.clientOpts.Version)
+	request := v1.NewPingMessage(box.clientOpts.Version).WithRequestId(v1.NewRequestId())
 	payload, err := request.Encode()
 	box.eventBus.Publish(newApiRawCloudEvent(payload))
 	if err != nil {
 		return "", errors.Wrap(err, "error cloud ping request")
 	}
 
-	value, err := box.client.SendRequest(request.Protocol(), payload)
+	value, err := box.client.SendRequest(ctx, request.Protocol(), payload)
 	if err != nil {
-		return "", errors.Wrap(err, "error cloud ping")
+		return "", errors.Wrap(v1.ToError(err), "error cloud ping")
 	}
 
 	response, err := v1.Decode[v1.PongBody](value)
@@ -2,9 +2,11 @@ package cloud
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/hckops/hckctl/pkg/box/model"
 	"github.com/hckops/hckctl/pkg/event"
+	"github.com/hckops/hckctl/pkg/util"
 )
 
 type cloudBoxEvent struct {
@@ -32,6 +34,21 @@ func newCloseCloudClientEvent() *cloudBoxEvent {
 	return &cloudBoxEvent{kind: event.LogDebug, value: "close cloud client"}
 }
 
+func newReconnectCloudClientEvent(attempt int, err error) *cloudBoxEvent {
+	if err != nil {
+		return &cloudBoxEvent{kind: event.LogWarning, value: fmt.Sprintf("reconnect cloud client: attempt=%d error=%v", attempt, err)}
+	}
+	return &cloudBoxEvent{kind: event.LogInfo, value: fmt.Sprintf("reconnect cloud client: attempt=%d", attempt)}
+}
+
+func newTrustHostCloudClientEvent(host string, fingerprint string) *cloudBoxEvent {
+	return &cloudBoxEvent{kind: event.LogWarning, value: fmt.Sprintf("trust new host: host=%s fingerprint=%s", host, fingerprint)}
+}
+
+func newTrustHostCloudClientConsoleEvent(host string, fingerprint string) *cloudBoxEvent {
+	return &cloudBoxEvent{kind: event.PrintConsole, value: fmt.Sprintf("trusting new host %s with fingerprint %s, verify it with the server administrator", host, fingerprint)}
+}
+
 func newApiRawCloudEvent(value string) *cloudBoxEvent {
 	return &cloudBoxEvent{kind: event.LogDebug, value: value}
 }
@@ -48,20 +65,40 @@ func newApiExecCloudEvent(boxName string) *cloudBoxEvent {
 	return &cloudBoxEvent{kind: event.LogInfo, value: fmt.Sprintf("api exec: boxName=%s", boxName)}
 }
 
+func newApiExecCommandCloudEvent(boxName string, command []string) *cloudBoxEvent {
+	return &cloudBoxEvent{kind: event.LogInfo, value: fmt.Sprintf("api exec command: boxName=%s command=%s", boxName, strings.Join(command, " "))}
+}
+
+func newApiExecExitCodeCloudEvent(boxName string, exitCode int) *cloudBoxEvent {
+	return &cloudBoxEvent{kind: event.LogInfo, value: fmt.Sprintf("api exec exit code: boxName=%s exitCode=%d", boxName, exitCode)}
+}
+
+func newApiLogsCloudEvent(boxName string, sidecar string) *cloudBoxEvent {
+	return &cloudBoxEvent{kind: event.LogInfo, value: fmt.Sprintf("api logs: boxName=%s sidecar=%s", boxName, sidecar)}
+}
+
+func newApiExecDetachCloudEvent(boxName string) *cloudBoxEvent {
+	return &cloudBoxEvent{kind: event.LogInfo, value: fmt.Sprintf("api exec detach: boxName=%s", boxName)}
+}
+
 func newApiExecErrorCloudEvent(boxName string, err error) *cloudBoxEvent {
 	return &cloudBoxEvent{kind: event.LogError, value: fmt.Sprintf("api exec error: boxName=%s error=%v", boxName, err)}
 }
 
+func newApiExecPtyErrorCloudEvent(boxName string, err error) *cloudBoxEvent {
+	return &cloudBoxEvent{kind: event.LogWarning, value: fmt.Sprintf("api exec without terminal: boxName=%s error=%v", boxName, err)}
+}
+
 func newApiStopCloudLoaderEvent() *cloudBoxEvent {
 	return &cloudBoxEvent{kind: event.LoaderStop, value: "waiting"}
 }
 
 func newApiEnvCloudEvent(boxName string, env model.BoxEnv) *cloudBoxEvent {
-	return &cloudBoxEvent{kind: event.LogInfo, value: fmt.Sprintf("api env: boxName=%s key=%s value=%s", boxName, env.Key, env.Value)}
+	return &cloudBoxEvent{kind: event.LogInfo, value: fmt.Sprintf("api env: boxName=%s key=%s value=%s", boxName, env.Key, env.RedactedValue())}
 }
 
 func newApiEnvCloudConsoleEvent(boxName string, env model.BoxEnv) *cloudBoxEvent {
-	return &cloudBoxEvent{kind: event.PrintConsole, value: fmt.Sprintf("[%s] %s=%s", boxName, env.Key, env.Value)}
+	return &cloudBoxEvent{kind: event.PrintConsole, value: fmt.Sprintf("[%s] %s=%s", boxName, env.Key, env.RedactedValue())}
 }
 
 func newApiTunnelIgnoreCloudEvent(boxName string) *cloudBoxEvent {
@@ -96,6 +133,14 @@ func newApiTunnelListenCloudLoaderEvent() *cloudBoxEvent {
 	return &cloudBoxEvent{kind: event.LoaderUpdate, value: "listening"}
 }
 
+func newApiCopyCloudEvent(opts *model.CopyOptions) *cloudBoxEvent {
+	return &cloudBoxEvent{kind: event.LogInfo, value: fmt.Sprintf("api copy: boxName=%s direction=%s localPath=%s remotePath=%s", opts.Name, opts.Direction, opts.LocalPath, opts.RemotePath)}
+}
+
+func newApiCopyProgressCloudLoaderEvent(direction model.CopyDirection, size int64) *cloudBoxEvent {
+	return &cloudBoxEvent{kind: event.LoaderUpdate, value: fmt.Sprintf("%s %s", direction, util.FormatBytes(size))}
+}
+
 func newApiDescribeCloudEvent(boxName string) *cloudBoxEvent {
 	return &cloudBoxEvent{kind: event.LogInfo, value: fmt.Sprintf("api describe: boxName=%s", boxName)}
 }
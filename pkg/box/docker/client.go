@@ -1,6 +1,7 @@
 package docker
 
 import (
+	"context"
 	"github.com/pkg/errors"
 
 	boxModel "github.com/hckops/hckctl/pkg/box/model"
@@ -29,37 +30,57 @@ func (box *DockerBoxClient) Events() *event.EventBus {
 	return box.eventBus
 }
 
-func (box *DockerBoxClient) Create(opts *boxModel.CreateOptions) (*boxModel.BoxInfo, error) {
+func (box *DockerBoxClient) Create(ctx context.Context, opts *boxModel.CreateOptions) (*boxModel.BoxInfo, error) {
 	defer box.close()
-	return box.createBox(opts)
+	return box.createBox(ctx, opts)
 }
 
-func (box *DockerBoxClient) Connect(opts *boxModel.ConnectOptions) error {
+func (box *DockerBoxClient) Connect(ctx context.Context, opts *boxModel.ConnectOptions) error {
 	defer box.close()
-	return box.connectBox(opts)
+	return box.connectBox(ctx, opts)
 }
 
-func (box *DockerBoxClient) Describe(name string) (*boxModel.BoxDetails, error) {
+func (box *DockerBoxClient) Copy(ctx context.Context, opts *boxModel.CopyOptions) error {
 	defer box.close()
-	return box.describeBox(name)
+	return box.copyBox(ctx, opts)
 }
 
-func (box *DockerBoxClient) List() ([]boxModel.BoxInfo, error) {
+func (box *DockerBoxClient) Exec(ctx context.Context, opts *boxModel.ExecOptions) (int, error) {
 	defer box.close()
-	return box.listBoxes()
+	return box.execCommandBox(ctx, opts)
 }
 
-func (box *DockerBoxClient) Delete(names []string) ([]string, error) {
+func (box *DockerBoxClient) Logs(ctx context.Context, opts *boxModel.LogsOptions) error {
 	defer box.close()
-	return box.deleteBoxes(names)
+	return box.filterLogsBox(ctx, opts)
 }
 
-func (box *DockerBoxClient) Clean() error {
+func (box *DockerBoxClient) PortForward(ctx context.Context, opts *boxModel.PortForwardOptions) error {
+	defer box.close()
+	return box.portForwardBox(ctx, opts)
+}
+
+func (box *DockerBoxClient) Describe(ctx context.Context, name string) (*boxModel.BoxDetails, error) {
+	defer box.close()
+	return box.describeBox(ctx, name)
+}
+
+func (box *DockerBoxClient) List(ctx context.Context) ([]boxModel.BoxInfo, error) {
+	defer box.close()
+	return box.listBoxes(ctx)
+}
+
+func (box *DockerBoxClient) Delete(ctx context.Context, names []string) ([]string, error) {
+	defer box.close()
+	return box.deleteBoxes(ctx, names)
+}
+
+func (box *DockerBoxClient) Clean(ctx context.Context) error {
 	defer box.close()
 	// TODO remove network and volumes
 	return errors.New("not implemented")
 }
 
-func (box *DockerBoxClient) Version() (string, error) {
+func (box *DockerBoxClient) Version(ctx context.Context) (string, error) {
 	return "", errors.New("not implemented")
 }
@@ -1,7 +1,10 @@
 package docker
 
 import (
+	"context"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/pkg/errors"
 	"golang.org/x/exp/maps"
@@ -13,6 +16,7 @@ import (
 	commonDocker "github.com/hckops/hckctl/pkg/common/docker"
 	commonModel "github.com/hckops/hckctl/pkg/common/model"
 	"github.com/hckops/hckctl/pkg/schema"
+	"github.com/hckops/hckctl/pkg/util"
 )
 
 func newDockerBoxClient(commonOpts *boxModel.CommonBoxOptions, dockerOpts *commonModel.DockerOptions) (*DockerBoxClient, error) {
@@ -35,11 +39,14 @@ func (box *DockerBoxClient) close() error {
 }
 
 // TODO limit resources by size?
-func (box *DockerBoxClient) createBox(opts *boxModel.CreateOptions) (*boxModel.BoxInfo, error) {
+func (box *DockerBoxClient) createBox(ctx context.Context, opts *boxModel.CreateOptions) (*boxModel.BoxInfo, error) {
+	// removes the sidecar and the container in reverse order on failure
+	rollback := util.NewRollback()
+	defer box.dockerCommon.Rollback(ctx, rollback)
 
 	// pull image
 	imageName := opts.Template.Image.Name()
-	if err := box.dockerCommon.PullImageOffline(imageName, func() {
+	if err := box.dockerCommon.PullImageOffline(ctx, imageName, func() {
 		box.eventBus.Publish(newImagePullDockerLoaderEvent(imageName))
 	}); err != nil {
 		return nil, err
@@ -70,9 +77,13 @@ func (box *DockerBoxClient) createBox(opts *boxModel.CreateOptions) (*boxModel.B
 			Name:       containerName,
 			NetworkVpn: opts.CommonInfo.NetworkVpn,
 		}
-		if sidecarContainerId, err := box.dockerCommon.SidecarVpnInject(sidecarOpts, portConfig); err != nil {
+		if sidecarContainerId, err := box.dockerCommon.SidecarVpnInject(ctx, sidecarOpts, portConfig); err != nil {
 			return nil, err
 		} else {
+			rollback.Add("sidecar-vpn", func(ctx context.Context) error {
+				return box.dockerCommon.SidecarRemove(ctx, sidecarContainerId)
+			})
+
 			// fix conflicting options: hostname and the network mode
 			hostname = ""
 
@@ -125,23 +136,28 @@ func (box *DockerBoxClient) createBox(opts *boxModel.CreateOptions) (*boxModel.B
 	}
 
 	networkName := box.clientOpts.NetworkName
-	networkId, err := box.client.NetworkUpsert(networkName)
+	networkId, err := box.client.NetworkUpsert(ctx, networkName)
 	if err != nil {
 		return nil, err
 	}
 	box.eventBus.Publish(newNetworkUpsertDockerEvent(networkName, networkId))
 
 	containerOpts := &docker.ContainerCreateOpts{
-		ContainerName:                containerName,
-		ContainerConfig:              containerConfig,
-		HostConfig:                   hostConfig,
-		NetworkingConfig:             docker.BuildNetworkingConfig(networkName, networkId), // all on the same network
-		Platform:                     docker.DefaultPlatform(),
-		WaitStatus:                   false,
-		CaptureInterrupt:             false,
-		OnContainerInterruptCallback: func(string) {},
-		OnContainerCreateCallback:    func(string) error { return nil },
-		OnContainerWaitCallback:      func(string) error { return nil },
+		ContainerName:    containerName,
+		ContainerConfig:  containerConfig,
+		HostConfig:       hostConfig,
+		NetworkingConfig: docker.BuildNetworkingConfig(networkName, networkId), // all on the same network
+		Platform:         docker.DefaultPlatform(),
+		WaitStatus:       false,
+		OnContainerCreateCallback: func(containerId string) error {
+			// the container is removed also if it fails to start
+			rollback.Add("container", func(ctx context.Context) error {
+				box.eventBus.Publish(newContainerRemoveDockerEvent(containerName, containerId))
+				return box.client.ContainerRemove(ctx, containerId)
+			})
+			return nil
+		},
+		OnContainerWaitCallback: func(string) error { return nil },
 		OnContainerStatusCallback: func(status string) {
 			box.eventBus.Publish(newContainerCreateStatusDockerEvent(status))
 		},
@@ -153,28 +169,29 @@ func (box *DockerBoxClient) createBox(opts *boxModel.CreateOptions) (*boxModel.B
 		},
 	}
 	// boxId
-	containerId, err := box.client.ContainerCreate(containerOpts)
+	containerId, err := box.client.ContainerCreate(ctx, containerOpts)
 	if err != nil {
 		return nil, err
 	}
 	box.eventBus.Publish(newContainerCreateDockerEvent(opts.Template.Name, containerName, containerId))
 
+	rollback.Commit()
 	return &boxModel.BoxInfo{Id: containerId, Name: containerName, Healthy: true}, nil
 }
 
-func (box *DockerBoxClient) connectBox(opts *boxModel.ConnectOptions) error {
-	if info, err := box.searchBox(opts.Name); err != nil {
+func (box *DockerBoxClient) connectBox(ctx context.Context, opts *boxModel.ConnectOptions) error {
+	if info, err := box.searchBox(ctx, opts.Name); err != nil {
 		return err
 	} else {
 		if opts.DisableExec || opts.DisableTunnel {
 			box.eventBus.Publish(newContainerExecIgnoreDockerEvent(info.Id))
 		}
-		return box.execBox(opts, info)
+		return box.execBox(ctx, opts, info)
 	}
 }
 
-func (box *DockerBoxClient) searchBox(name string) (*boxModel.BoxInfo, error) {
-	boxes, err := box.listBoxes()
+func (box *DockerBoxClient) searchBox(ctx context.Context, name string) (*boxModel.BoxInfo, error) {
+	boxes, err := box.listBoxes(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -183,13 +200,13 @@ func (box *DockerBoxClient) searchBox(name string) (*boxModel.BoxInfo, error) {
 			return &boxInfo, nil
 		}
 	}
-	return nil, errors.New("box not found")
+	return nil, boxModel.ErrBoxNotFound
 }
 
-func (box *DockerBoxClient) execBox(opts *boxModel.ConnectOptions, info *boxModel.BoxInfo) error {
+func (box *DockerBoxClient) execBox(ctx context.Context, opts *boxModel.ConnectOptions, info *boxModel.BoxInfo) error {
 
 	// attempt to restart all associated sidecars
-	sidecars, err := box.dockerCommon.SidecarList(info.Name)
+	sidecars, err := box.dockerCommon.SidecarList(ctx, info.Name)
 	if err != nil {
 		return err
 	}
@@ -200,7 +217,7 @@ func (box *DockerBoxClient) execBox(opts *boxModel.ConnectOptions, info *boxMode
 				box.eventBus.Publish(newContainerRestartDockerEvent(sidecar.Id, status))
 			},
 		}
-		if err := box.client.ContainerRestart(restartsOpts); err != nil {
+		if err := box.client.ContainerRestart(ctx, restartsOpts); err != nil {
 			return err
 		}
 	}
@@ -212,7 +229,7 @@ func (box *DockerBoxClient) execBox(opts *boxModel.ConnectOptions, info *boxMode
 			box.eventBus.Publish(newContainerRestartDockerEvent(info.Id, status))
 		},
 	}
-	if err := box.client.ContainerRestart(restartsOpts); err != nil {
+	if err := box.client.ContainerRestart(ctx, restartsOpts); err != nil {
 		return err
 	}
 
@@ -220,20 +237,20 @@ func (box *DockerBoxClient) execBox(opts *boxModel.ConnectOptions, info *boxMode
 		// stop loader
 		box.eventBus.Publish(newContainerExecDockerLoaderEvent())
 
-		return box.logsBox(opts, info)
+		return box.logsBox(ctx, opts, info)
 	}
 
 	// already printed for temporary box
 	if !opts.DeleteOnExit {
-		containerDetails, err := box.client.ContainerInspect(info.Id)
+		containerDetails, err := box.client.ContainerInspect(ctx, info.Id)
 		if err != nil {
 			return err
 		}
 		// print environment variables
 		for _, e := range containerDetails.Env {
 			// ignore internal variables e.g. PATH
-			if _, exists := opts.Template.EnvironmentVariables()[e.Key]; exists {
-				env := boxModel.BoxEnv{Key: e.Key, Value: e.Value}
+			if templateEnv, exists := opts.Template.EnvironmentVariables()[e.Key]; exists {
+				env := boxModel.BoxEnv{Key: e.Key, Value: e.Value, Secret: templateEnv.Secret}
 				box.eventBus.Publish(newContainerCreateEnvDockerEvent(containerDetails.Info.ContainerName, env))
 				box.eventBus.Publish(newContainerCreateEnvDockerConsoleEvent(containerDetails.Info.ContainerName, env))
 			}
@@ -244,7 +261,7 @@ func (box *DockerBoxClient) execBox(opts *boxModel.ConnectOptions, info *boxMode
 		}
 		// print sidecar ports
 		for _, sidecar := range sidecars {
-			sidecarDetails, err := box.client.ContainerInspect(sidecar.Id)
+			sidecarDetails, err := box.client.ContainerInspect(ctx, sidecar.Id)
 			if err != nil {
 				return err
 			}
@@ -261,27 +278,66 @@ func (box *DockerBoxClient) execBox(opts *boxModel.ConnectOptions, info *boxMode
 		OutStream:   opts.StreamOpts.Out,
 		ErrStream:   opts.StreamOpts.Err,
 		IsTty:       opts.StreamOpts.IsTty,
+		Resize:      opts.StreamOpts.Resize,
 		OnContainerExecCallback: func() {
 			// stop loader
 			box.eventBus.Publish(newContainerExecDockerLoaderEvent())
 		},
 		OnStreamCloseCallback: func() {
+			if opts.IsDetached() {
+				box.eventBus.Publish(newContainerExecDetachDockerEvent(info.Id))
+				return
+			}
 			box.eventBus.Publish(newContainerExecExitDockerEvent(info.Id))
 			if opts.DeleteOnExit {
-				// ignore error
-				box.deleteBox(*info)
+				// ignore error, the box is deleted even if the session was interrupted
+				box.deleteBox(context.WithoutCancel(ctx), *info)
 			}
 		},
 		OnStreamErrorCallback: func(err error) {
 			box.eventBus.Publish(newContainerExecErrorDockerEvent(info.Id, err))
-			if opts.DeleteOnExit {
+			if opts.DeleteOnExit && !opts.IsDetached() {
 				// ignore error
-				box.deleteBox(*info)
+				box.deleteBox(context.WithoutCancel(ctx), *info)
 			}
 		},
 	}
 	box.eventBus.Publish(newContainerExecDockerEvent(info.Name, info.Id, opts.Template.Shell))
-	return box.client.ContainerExec(execOpts)
+	return box.client.ContainerExec(ctx, execOpts)
+}
+
+func (box *DockerBoxClient) execCommandBox(ctx context.Context, opts *boxModel.ExecOptions) (int, error) {
+	info, err := box.searchBox(ctx, opts.Name)
+	if err != nil {
+		return -1, err
+	}
+
+	execOpts := &docker.ContainerExecOpts{
+		ContainerId: info.Id,
+		Commands:    opts.Command,
+		InStream:    opts.StreamOpts.In,
+		OutStream:   opts.StreamOpts.Out,
+		ErrStream:   opts.StreamOpts.Err,
+		IsTty:       opts.StreamOpts.IsTty,
+		Resize:      opts.StreamOpts.Resize,
+		OnContainerExecCallback: func() {
+			// stop loader
+			box.eventBus.Publish(newContainerExecDockerLoaderEvent())
+		},
+		OnStreamCloseCallback: func() {
+			box.eventBus.Publish(newContainerExecExitDockerEvent(info.Id))
+		},
+		OnStreamErrorCallback: func(err error) {
+			box.eventBus.Publish(newContainerExecErrorDockerEvent(info.Id, err))
+		},
+	}
+	box.eventBus.Publish(newContainerExecDockerEvent(info.Name, info.Id, strings.Join(opts.Command, " ")))
+	exitCode, err := box.client.ContainerExecCommand(ctx, execOpts)
+	if err != nil {
+		return -1, err
+	}
+	box.eventBus.Publish(newContainerExecExitCodeDockerEvent(info.Id, exitCode))
+	return exitCode, nil
 }
 
 func (box *DockerBoxClient) publishPortInfo(networkMap map[string]boxModel.BoxPort, containerName string, containerPort docker.ContainerPort) {
@@ -295,13 +351,7 @@ func (box *DockerBoxClient) publishPortInfo(networkMap map[string]boxModel.BoxPo
 	box.eventBus.Publish(newContainerCreatePortBindDockerConsoleEvent(containerName, networkPort, portPadding))
 }
 
-func (box *DockerBoxClient) logsBox(opts *boxModel.ConnectOptions, info *boxModel.BoxInfo) error {
-
-	if opts.DeleteOnExit {
-		opts.OnInterruptCallback(func() {
-			box.deleteBox(*info)
-		})
-	}
+func (box *DockerBoxClient) logsBox(ctx context.Context, opts *boxModel.ConnectOptions, info *boxModel.BoxInfo) error {
 
 	logsOpts := &docker.ContainerLogsOpts{
 		ContainerId: info.Id,
@@ -314,17 +364,99 @@ func (box *DockerBoxClient) logsBox(opts *boxModel.ConnectOptions, info *boxMode
 		},
 	}
 	box.eventBus.Publish(newContainerLogsDockerEvent(info.Id))
-	return box.client.ContainerLogs(logsOpts)
+	err := box.client.ContainerLogs(ctx, logsOpts)
+	if opts.DeleteOnExit && ctx.Err() != nil {
+		// ignore error, blocks until interrupted
+		box.deleteBox(context.WithoutCancel(ctx), *info)
+	}
+	return err
+}
+
+func (box *DockerBoxClient) copyBox(ctx context.Context, opts *boxModel.CopyOptions) error {
+	info, err := box.searchBox(ctx, opts.Name)
+	if err != nil {
+		return err
+	}
+
+	copyOpts := &docker.ContainerCopyOpts{
+		ContainerId:   info.Id,
+		LocalPath:     opts.LocalPath,
+		ContainerPath: opts.RemotePath,
+		OnProgressCallback: func(size int64) {
+			box.eventBus.Publish(newContainerCopyProgressDockerLoaderEvent(opts.Direction, size))
+		},
+	}
+	box.eventBus.Publish(newContainerCopyDockerEvent(info.Id, opts))
+	if opts.Direction == boxModel.CopyUpload {
+		return box.client.CopyToContainer(ctx, copyOpts)
+	}
+	return box.client.CopyFromContainer(ctx, copyOpts)
+}
+
+func (box *DockerBoxClient) filterLogsBox(ctx context.Context, opts *boxModel.LogsOptions) error {
+	info, err := box.searchBox(ctx, opts.Name)
+	if err != nil {
+		return err
+	}
+
+	containerId := info.Id
+	if opts.Sidecar != "" {
+		if sidecarId, err := box.searchSidecar(ctx, info.Name, opts.Sidecar); err != nil {
+			return err
+		} else {
+			containerId = sidecarId
+		}
+	}
+
+	logsOpts := &docker.ContainerLogsFilterOpts{
+		ContainerId: containerId,
+		OutStream:   opts.StreamOpts.Out,
+		ErrStream:   opts.StreamOpts.Err,
+		Follow:      opts.Follow,
+		Tail:        "all",
+	}
+	if opts.Since > 0 {
+		logsOpts.Since = opts.Since.String()
+	}
+	if opts.Tail >= 0 {
+		logsOpts.Tail = strconv.Itoa(opts.Tail)
+	}
+
+	box.eventBus.Publish(newContainerLogsDockerEvent(containerId))
+	// stop loader
+	box.eventBus.Publish(newContainerExecDockerLoaderEvent())
+	return box.client.ContainerLogsFilter(ctx, logsOpts)
+}
+
+func (box *DockerBoxClient) searchSidecar(ctx context.Context, containerName string, sidecarName string) (string, error) {
+	sidecars, err := box.dockerCommon.SidecarList(ctx, containerName)
+	if err != nil {
+		return "", err
+	}
+	// e.g. "sidecar-vpn-<RANDOM>"
+	sidecarPrefix := fmt.Sprintf("%s%s-", commonModel.SidecarPrefixName, sidecarName)
+	for _, sidecar := range sidecars {
+		if strings.HasPrefix(sidecar.Name, sidecarPrefix) {
+			return sidecar.Id, nil
+		}
+	}
+	return "", fmt.Errorf("sidecar not found: name=%s", sidecarName)
+}
+
+func (box *DockerBoxClient) portForwardBox(ctx context.Context, opts *boxModel.PortForwardOptions) error {
+	box.eventBus.Publish(newContainerPortForwardIgnoreDockerEvent(opts.Name))
+	// ports are published when the container is created and can't be added afterwards
+	return errors.New("port-forward not supported by docker provider")
 }
 
-func (box *DockerBoxClient) describeBox(name string) (*boxModel.BoxDetails, error) {
-	info, err := box.searchBox(name)
+func (box *DockerBoxClient) describeBox(ctx context.Context, name string) (*boxModel.BoxDetails, error) {
+	info, err := box.searchBox(ctx, name)
 	if err != nil {
 		return nil, err
 	}
 
 	box.eventBus.Publish(newContainerInspectDockerEvent(info.Id))
-	containerInfo, err := box.client.ContainerInspect(info.Id)
+	containerInfo, err := box.client.ContainerInspect(ctx, info.Id)
 	if err != nil {
 		return nil, err
 	}
@@ -391,9 +523,9 @@ func boxLabel() string {
 	return fmt.Sprintf("%s=%s", commonModel.LabelSchemaKind, schema.KindBoxV1.String())
 }
 
-func (box *DockerBoxClient) listBoxes() ([]boxModel.BoxInfo, error) {
+func (box *DockerBoxClient) listBoxes(ctx context.Context) ([]boxModel.BoxInfo, error) {
 
-	containers, err := box.client.ContainerList(boxModel.BoxPrefixName, boxLabel())
+	containers, err := box.client.ContainerList(ctx, boxModel.BoxPrefixName, boxLabel())
 	if err != nil {
 		return nil, err
 	}
@@ -406,9 +538,9 @@ func (box *DockerBoxClient) listBoxes() ([]boxModel.BoxInfo, error) {
 	return boxes, nil
 }
 
-func (box *DockerBoxClient) deleteBoxes(names []string) ([]string, error) {
+func (box *DockerBoxClient) deleteBoxes(ctx context.Context, names []string) ([]string, error) {
 
-	boxes, err := box.listBoxes()
+	boxes, err := box.listBoxes(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -419,7 +551,7 @@ func (box *DockerBoxClient) deleteBoxes(names []string) ([]string, error) {
 		// all or filter
 		if len(names) == 0 || slices.Contains(names, boxInfo.Name) {
 
-			if err := box.deleteBox(boxInfo); err == nil {
+			if err := box.deleteBox(ctx, boxInfo); err == nil {
 				deleted = append(deleted, boxInfo.Name)
 			}
 		}
@@ -427,12 +559,12 @@ func (box *DockerBoxClient) deleteBoxes(names []string) ([]string, error) {
 	return deleted, nil
 }
 
-func (box *DockerBoxClient) deleteBox(boxInfo boxModel.BoxInfo) error {
+func (box *DockerBoxClient) deleteBox(ctx context.Context, boxInfo boxModel.BoxInfo) error {
 
 	// delete all sidecars
-	sidecars, _ := box.dockerCommon.SidecarList(boxInfo.Name)
+	sidecars, _ := box.dockerCommon.SidecarList(ctx, boxInfo.Name)
 	for _, sidecar := range sidecars {
-		if err := box.client.ContainerRemove(sidecar.Id); err != nil {
+		if err := box.client.ContainerRemove(ctx, sidecar.Id); err != nil {
 			// silently ignore
 			box.eventBus.Publish(newContainerRemoveIgnoreDockerEvent(sidecar.Name, sidecar.Id, err))
 		} else {
@@ -440,7 +572,7 @@ func (box *DockerBoxClient) deleteBox(boxInfo boxModel.BoxInfo) error {
 		}
 	}
 
-	if err := box.client.ContainerRemove(boxInfo.Id); err != nil {
+	if err := box.client.ContainerRemove(ctx, boxInfo.Id); err != nil {
 		box.eventBus.Publish(newContainerRemoveIgnoreDockerEvent(boxInfo.Name, boxInfo.Id, err))
 		return err
 	}
@@ -5,11 +5,13 @@ import (
 
 	"github.com/hckops/hckctl/pkg/box/model"
 	"github.com/hckops/hckctl/pkg/event"
+	"github.com/hckops/hckctl/pkg/util"
 )
 
 type dockerBoxEvent struct {
-	kind  event.EventKind
-	value string
+	kind    event.EventKind
+	value   string
+	payload any
 }
 
 func (e *dockerBoxEvent) Source() string {
@@ -24,6 +26,10 @@ func (e *dockerBoxEvent) String() string {
 	return e.value
 }
 
+func (e *dockerBoxEvent) Payload() any {
+	return e.payload
+}
+
 func newImagePullDockerLoaderEvent(imageName string) *dockerBoxEvent {
 	return &dockerBoxEvent{kind: event.LoaderUpdate, value: fmt.Sprintf("pulling image %s", imageName)}
 }
@@ -45,11 +51,11 @@ func newContainerCreatePortBindDockerConsoleEvent(containerName string, port mod
 }
 
 func newContainerCreateEnvDockerEvent(containerName string, env model.BoxEnv) *dockerBoxEvent {
-	return &dockerBoxEvent{kind: event.LogInfo, value: fmt.Sprintf("container create env: containerName=%s key=%s value=%s", containerName, env.Key, env.Value)}
+	return &dockerBoxEvent{kind: event.LogInfo, value: fmt.Sprintf("container create env: containerName=%s key=%s value=%s", containerName, env.Key, env.RedactedValue())}
 }
 
 func newContainerCreateEnvDockerConsoleEvent(containerName string, env model.BoxEnv) *dockerBoxEvent {
-	return &dockerBoxEvent{kind: event.PrintConsole, value: fmt.Sprintf("[%s] %s=%s", containerName, env.Key, env.Value)}
+	return &dockerBoxEvent{kind: event.PrintConsole, value: fmt.Sprintf("[%s] %s=%s", containerName, env.Key, env.RedactedValue())}
 }
 
 func newContainerCreateStatusDockerEvent(status string) *dockerBoxEvent {
@@ -57,7 +63,8 @@ func newContainerCreateStatusDockerEvent(status string) *dockerBoxEvent {
 }
 
 func newContainerCreateDockerEvent(templateName string, containerName string, containerId string) *dockerBoxEvent {
-	return &dockerBoxEvent{kind: event.LogInfo, value: fmt.Sprintf("container create: templateName=%s containerName=%s containerId=%s", templateName, containerName, containerId)}
+	return &dockerBoxEvent{kind: event.LogInfo, value: fmt.Sprintf("container create: templateName=%s containerName=%s containerId=%s", templateName, containerName, containerId),
+		payload: event.ResourcePayload{Action: event.ResourceCreate, Type: "container", Name: containerName, Id: containerId}}
 }
 
 func newContainerRestartDockerEvent(containerId string, status string) *dockerBoxEvent {
@@ -80,6 +87,14 @@ func newContainerExecExitDockerEvent(containerId string) *dockerBoxEvent {
 	return &dockerBoxEvent{kind: event.LogDebug, value: fmt.Sprintf("container exec exit: containerId=%s", containerId)}
 }
 
+func newContainerExecDetachDockerEvent(containerId string) *dockerBoxEvent {
+	return &dockerBoxEvent{kind: event.LogInfo, value: fmt.Sprintf("container exec detach: containerId=%s", containerId)}
+}
+
+func newContainerExecExitCodeDockerEvent(containerId string, exitCode int) *dockerBoxEvent {
+	return &dockerBoxEvent{kind: event.LogInfo, value: fmt.Sprintf("container exec exit code: containerId=%s exitCode=%d", containerId, exitCode)}
+}
+
 func newContainerExecErrorDockerEvent(containerId string, err error) *dockerBoxEvent {
 	return &dockerBoxEvent{kind: event.LogError, value: fmt.Sprintf("container exec error: containerId=%s error=%v", containerId, err)}
 }
@@ -96,12 +111,25 @@ func newContainerLogsErrorDockerEvent(containerId string, err error) *dockerBoxE
 	return &dockerBoxEvent{kind: event.LogError, value: fmt.Sprintf("container logs error: containerId=%s error=%v", containerId, err)}
 }
 
+func newContainerCopyDockerEvent(containerId string, opts *model.CopyOptions) *dockerBoxEvent {
+	return &dockerBoxEvent{kind: event.LogInfo, value: fmt.Sprintf("container copy: containerId=%s direction=%s localPath=%s remotePath=%s", containerId, opts.Direction, opts.LocalPath, opts.RemotePath)}
+}
+
+func newContainerCopyProgressDockerLoaderEvent(direction model.CopyDirection, size int64) *dockerBoxEvent {
+	return &dockerBoxEvent{kind: event.LoaderUpdate, value: fmt.Sprintf("%s %s", direction, util.FormatBytes(size))}
+}
+
+func newContainerPortForwardIgnoreDockerEvent(containerName string) *dockerBoxEvent {
+	return &dockerBoxEvent{kind: event.LogWarning, value: fmt.Sprintf("container port-forward ignored: containerName=%s", containerName)}
+}
+
 func newContainerListDockerEvent(index int, containerName string, containerId string, healthy bool) *dockerBoxEvent {
 	return &dockerBoxEvent{kind: event.LogDebug, value: fmt.Sprintf("container list: (%d) containerName=%s containerId=%s healthy=%v", index, containerName, containerId, healthy)}
 }
 
 func newContainerRemoveDockerEvent(containerName string, containerId string) *dockerBoxEvent {
-	return &dockerBoxEvent{kind: event.LogInfo, value: fmt.Sprintf("container remove: containerName=%s containerId=%s", containerName, containerId)}
+	return &dockerBoxEvent{kind: event.LogInfo, value: fmt.Sprintf("container remove: containerName=%s containerId=%s", containerName, containerId),
+		payload: event.ResourcePayload{Action: event.ResourceDelete, Type: "container", Name: containerName, Id: containerId}}
 }
 
 func newContainerRemoveIgnoreDockerEvent(containerName string, containerId string, err error) *dockerBoxEvent {
@@ -1,6 +1,7 @@
 package kubernetes
 
 import (
+	"context"
 	"github.com/pkg/errors"
 
 	boxModel "github.com/hckops/hckctl/pkg/box/model"
@@ -29,36 +30,56 @@ func (box *KubeBoxClient) Events() *event.EventBus {
 	return box.eventBus
 }
 
-func (box *KubeBoxClient) Create(opts *boxModel.CreateOptions) (*boxModel.BoxInfo, error) {
+func (box *KubeBoxClient) Create(ctx context.Context, opts *boxModel.CreateOptions) (*boxModel.BoxInfo, error) {
 	defer box.close()
-	return box.createBox(opts)
+	return box.createBox(ctx, opts)
 }
 
-func (box *KubeBoxClient) Connect(opts *boxModel.ConnectOptions) error {
+func (box *KubeBoxClient) Connect(ctx context.Context, opts *boxModel.ConnectOptions) error {
 	defer box.close()
-	return box.connectBox(opts)
+	return box.connectBox(ctx, opts)
 }
 
-func (box *KubeBoxClient) Describe(name string) (*boxModel.BoxDetails, error) {
+func (box *KubeBoxClient) Copy(ctx context.Context, opts *boxModel.CopyOptions) error {
 	defer box.close()
-	return box.describeBox(name)
+	return box.copyBox(ctx, opts)
 }
 
-func (box *KubeBoxClient) List() ([]boxModel.BoxInfo, error) {
+func (box *KubeBoxClient) Exec(ctx context.Context, opts *boxModel.ExecOptions) (int, error) {
 	defer box.close()
-	return box.listBoxes()
+	return box.execCommandBox(ctx, opts)
 }
 
-func (box *KubeBoxClient) Delete(names []string) ([]string, error) {
+func (box *KubeBoxClient) Logs(ctx context.Context, opts *boxModel.LogsOptions) error {
 	defer box.close()
-	return box.deleteBoxes(names)
+	return box.filterLogsBox(ctx, opts)
 }
 
-func (box *KubeBoxClient) Clean() error {
+func (box *KubeBoxClient) PortForward(ctx context.Context, opts *boxModel.PortForwardOptions) error {
 	defer box.close()
-	return box.clean()
+	return box.portForwardBox(ctx, opts)
 }
 
-func (box *KubeBoxClient) Version() (string, error) {
+func (box *KubeBoxClient) Describe(ctx context.Context, name string) (*boxModel.BoxDetails, error) {
+	defer box.close()
+	return box.describeBox(ctx, name)
+}
+
+func (box *KubeBoxClient) List(ctx context.Context) ([]boxModel.BoxInfo, error) {
+	defer box.close()
+	return box.listBoxes(ctx)
+}
+
+func (box *KubeBoxClient) Delete(ctx context.Context, names []string) ([]string, error) {
+	defer box.close()
+	return box.deleteBoxes(ctx, names)
+}
+
+func (box *KubeBoxClient) Clean(ctx context.Context) error {
+	defer box.close()
+	return box.clean(ctx)
+}
+
+func (box *KubeBoxClient) Version(ctx context.Context) (string, error) {
 	return "", errors.New("not implemented")
 }
@@ -5,11 +5,13 @@ import (
 
 	"github.com/hckops/hckctl/pkg/box/model"
 	"github.com/hckops/hckctl/pkg/event"
+	"github.com/hckops/hckctl/pkg/util"
 )
 
 type kubeBoxEvent struct {
-	kind  event.EventKind
-	value string
+	kind    event.EventKind
+	value   string
+	payload any
 }
 
 func (e *kubeBoxEvent) Source() string {
@@ -24,6 +26,10 @@ func (e *kubeBoxEvent) String() string {
 	return e.value
 }
 
+func (e *kubeBoxEvent) Payload() any {
+	return e.payload
+}
+
 func newNamespaceApplyKubeEvent(namespace string) *kubeBoxEvent {
 	return &kubeBoxEvent{kind: event.LogInfo, value: fmt.Sprintf("namespace apply: namespace=%s", namespace)}
 }
@@ -41,7 +47,8 @@ func newResourcesDeleteIgnoreKubeEvent(namespace string, name string) *kubeBoxEv
 }
 
 func newServiceCreateKubeEvent(namespace string, name string) *kubeBoxEvent {
-	return &kubeBoxEvent{kind: event.LogInfo, value: fmt.Sprintf("service create: namespace=%s name=%s", namespace, name)}
+	return &kubeBoxEvent{kind: event.LogInfo, value: fmt.Sprintf("service create: namespace=%s name=%s", namespace, name),
+		payload: event.ResourcePayload{Action: event.ResourceCreate, Type: "service", Name: name, Namespace: namespace}}
 }
 
 func newServiceCreateIgnoreKubeEvent(namespace string, name string) *kubeBoxEvent {
@@ -53,11 +60,13 @@ func newServiceDescribeKubeEvent(namespace string, name string) *kubeBoxEvent {
 }
 
 func newServiceDeleteKubeEvent(namespace string, name string) *kubeBoxEvent {
-	return &kubeBoxEvent{kind: event.LogInfo, value: fmt.Sprintf("service delete: namespace=%s name=%s", namespace, name)}
+	return &kubeBoxEvent{kind: event.LogInfo, value: fmt.Sprintf("service delete: namespace=%s name=%s", namespace, name),
+		payload: event.ResourcePayload{Action: event.ResourceDelete, Type: "service", Name: name, Namespace: namespace}}
 }
 
 func newDeploymentCreateKubeEvent(namespace string, name string) *kubeBoxEvent {
-	return &kubeBoxEvent{kind: event.LogInfo, value: fmt.Sprintf("deployment create: namespace=%s name=%s", namespace, name)}
+	return &kubeBoxEvent{kind: event.LogInfo, value: fmt.Sprintf("deployment create: namespace=%s name=%s", namespace, name),
+		payload: event.ResourcePayload{Action: event.ResourceCreate, Type: "deployment", Name: name, Namespace: namespace}}
 }
 
 func newDeploymentCreateStatusKubeEvent(status string) *kubeBoxEvent {
@@ -77,7 +86,8 @@ func newDeploymentDescribeKubeEvent(namespace string, name string) *kubeBoxEvent
 }
 
 func newDeploymentDeleteKubeEvent(namespace string, name string) *kubeBoxEvent {
-	return &kubeBoxEvent{kind: event.LogInfo, value: fmt.Sprintf("deployment delete: namespace=%s name=%s", namespace, name)}
+	return &kubeBoxEvent{kind: event.LogInfo, value: fmt.Sprintf("deployment delete: namespace=%s name=%s", namespace, name),
+		payload: event.ResourcePayload{Action: event.ResourceDelete, Type: "deployment", Name: name, Namespace: namespace}}
 }
 
 func newPodNameKubeEvent(namespace string, name string, containerName string) *kubeBoxEvent {
@@ -88,6 +98,14 @@ func newPodExecKubeEvent(templateName string, namespace string, name string, com
 	return &kubeBoxEvent{kind: event.LogInfo, value: fmt.Sprintf("pod attach: templateName=%s namespace=%s name=%s command=%s", templateName, namespace, name, command)}
 }
 
+func newPodExecDetachKubeEvent(namespace string, name string) *kubeBoxEvent {
+	return &kubeBoxEvent{kind: event.LogInfo, value: fmt.Sprintf("pod exec detach: namespace=%s name=%s", namespace, name)}
+}
+
+func newPodExecExitCodeKubeEvent(namespace string, name string, exitCode int) *kubeBoxEvent {
+	return &kubeBoxEvent{kind: event.LogInfo, value: fmt.Sprintf("pod exec exit code: namespace=%s name=%s exitCode=%d", namespace, name, exitCode)}
+}
+
 func newPodExecKubeLoaderEvent() *kubeBoxEvent {
 	return &kubeBoxEvent{kind: event.LoaderStop, value: "waiting"}
 }
@@ -125,9 +143,17 @@ func newPodPortForwardErrorKubeEvent(namespace string, name string, err error) *
 }
 
 func newPodEnvKubeEvent(namespace string, name string, env model.BoxEnv) *kubeBoxEvent {
-	return &kubeBoxEvent{kind: event.LogInfo, value: fmt.Sprintf("pod env: namespace=%s name=%s key=%s value=%s", namespace, name, env.Key, env.Value)}
+	return &kubeBoxEvent{kind: event.LogInfo, value: fmt.Sprintf("pod env: namespace=%s name=%s key=%s value=%s", namespace, name, env.Key, env.RedactedValue())}
 }
 
 func newPodEnvKubeConsoleEvent(namespace string, containerName string, env model.BoxEnv) *kubeBoxEvent {
-	return &kubeBoxEvent{kind: event.PrintConsole, value: fmt.Sprintf("[%s/%s] %s=%s", namespace, containerName, env.Key, env.Value)}
+	return &kubeBoxEvent{kind: event.PrintConsole, value: fmt.Sprintf("[%s/%s] %s=%s", namespace, containerName, env.Key, env.RedactedValue())}
+}
+
+func newPodCopyKubeEvent(namespace string, podName string, opts *model.CopyOptions) *kubeBoxEvent {
+	return &kubeBoxEvent{kind: event.LogInfo, value: fmt.Sprintf("pod copy: namespace=%s podName=%s direction=%s localPath=%s remotePath=%s", namespace, podName, opts.Direction, opts.LocalPath, opts.RemotePath)}
+}
+
+func newPodCopyProgressKubeLoaderEvent(direction model.CopyDirection, size int64) *kubeBoxEvent {
+	return &kubeBoxEvent{kind: event.LoaderUpdate, value: fmt.Sprintf("%s %s", direction, util.FormatBytes(size))}
 }
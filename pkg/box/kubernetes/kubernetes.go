@@ -1,7 +1,9 @@
 package kubernetes
 
 import (
+	"context"
 	"fmt"
+	"strings"
 
 	"github.com/pkg/errors"
 	"golang.org/x/exp/slices"
@@ -34,7 +36,7 @@ func (box *KubeBoxClient) close() error {
 	return box.kubeCommon.Close()
 }
 
-func (box *KubeBoxClient) createBox(opts *boxModel.CreateOptions) (*boxModel.BoxInfo, error) {
+func (box *KubeBoxClient) createBox(ctx context.Context, opts *boxModel.CreateOptions) (*boxModel.BoxInfo, error) {
 	namespace := box.clientOpts.Namespace
 
 	boxName := opts.Template.GenerateName()
@@ -43,18 +45,26 @@ func (box *KubeBoxClient) createBox(opts *boxModel.CreateOptions) (*boxModel.Box
 		return nil, err
 	}
 
+	// deletes the deployment, the secret and the service in reverse order on failure, the namespace is shared
+	rollback := util.NewRollback()
+	defer box.kubeCommon.Rollback(ctx, rollback)
+
 	// create namespace
-	if err := box.client.NamespaceApply(namespace); err != nil {
+	if err := box.client.NamespaceApply(ctx, namespace); err != nil {
 		return nil, err
 	}
 	box.eventBus.Publish(newNamespaceApplyKubeEvent(namespace))
 
 	// create service
 	if opts.Template.HasPorts() {
-		if err := box.client.ServiceCreate(namespace, service); err != nil {
+		if err := box.client.ServiceCreate(ctx, namespace, service); err != nil {
 			return nil, err
 		}
 		box.eventBus.Publish(newServiceCreateKubeEvent(namespace, service.Name))
+		rollback.Add("service", func(ctx context.Context) error {
+			box.eventBus.Publish(newServiceDeleteKubeEvent(namespace, service.Name))
+			return box.client.ServiceDelete(ctx, namespace, service.Name)
+		})
 	} else {
 		box.eventBus.Publish(newServiceCreateIgnoreKubeEvent(namespace, service.Name))
 	}
@@ -76,9 +86,12 @@ func (box *KubeBoxClient) createBox(opts *boxModel.CreateOptions) (*boxModel.Box
 			Name:       boxName,
 			NetworkVpn: opts.CommonInfo.NetworkVpn,
 		}
-		if err := box.kubeCommon.SidecarVpnInject(namespace, sidecarOpts, &deployment.Spec.Template.Spec); err != nil {
+		if err := box.kubeCommon.SidecarVpnInject(ctx, namespace, sidecarOpts, &deployment.Spec.Template.Spec); err != nil {
 			return nil, err
 		}
+		rollback.Add("sidecar-vpn", func(ctx context.Context) error {
+			return box.kubeCommon.SidecarVpnDelete(ctx, namespace, boxName)
+		})
 	}
 
 	// create deployment
@@ -86,16 +99,23 @@ func (box *KubeBoxClient) createBox(opts *boxModel.CreateOptions) (*boxModel.Box
 	deploymentOpts := &kubernetes.DeploymentCreateOpts{
 		Namespace: namespace,
 		Spec:      deployment,
+		OnCreateCallback: func() {
+			// the deployment is deleted also if it never becomes available
+			rollback.Add("deployment", func(ctx context.Context) error {
+				box.eventBus.Publish(newDeploymentDeleteKubeEvent(namespace, deployment.Name))
+				return box.client.DeploymentDelete(ctx, namespace, deployment.Name)
+			})
+		},
 		OnStatusEventCallback: func(event string) {
 			box.eventBus.Publish(newDeploymentCreateStatusKubeEvent(event))
 		},
 	}
-	if err := box.client.DeploymentCreate(deploymentOpts); err != nil {
+	if err := box.client.DeploymentCreate(ctx, deploymentOpts); err != nil {
 		return nil, err
 	}
 	box.eventBus.Publish(newDeploymentCreateKubeEvent(namespace, deployment.Name))
 
-	podInfo, err := box.client.PodDescribeFromDeployment(deployment)
+	podInfo, err := box.client.PodDescribeFromDeployment(ctx, deployment)
 	if err != nil {
 		return nil, err
 	}
@@ -108,11 +128,12 @@ func (box *KubeBoxClient) createBox(opts *boxModel.CreateOptions) (*boxModel.Box
 			PodName:   podInfo.PodName,
 			ShareDir:  opts.CommonInfo.ShareDir,
 		}
-		if err := box.kubeCommon.SidecarShareUpload(sidecarOpts); err != nil {
+		if err := box.kubeCommon.SidecarShareUpload(ctx, sidecarOpts); err != nil {
 			return nil, err
 		}
 	}
 
+	rollback.Commit()
 	// TODO always healthy unused? otherwise use DeploymentDescribe instead of PodDescribe
 	return &boxModel.BoxInfo{Id: podInfo.PodName, Name: boxName, Healthy: true}, nil
 }
@@ -147,8 +168,8 @@ func newResources(namespace string, name string, opts *boxModel.CreateOptions) *
 	}
 }
 
-func (box *KubeBoxClient) connectBox(opts *boxModel.ConnectOptions) error {
-	if info, err := box.searchBox(opts.Name); err != nil {
+func (box *KubeBoxClient) connectBox(ctx context.Context, opts *boxModel.ConnectOptions) error {
+	if info, err := box.searchBox(ctx, opts.Name); err != nil {
 		return err
 	} else {
 		if opts.DisableExec && opts.DisableTunnel {
@@ -164,17 +185,17 @@ func (box *KubeBoxClient) connectBox(opts *boxModel.ConnectOptions) error {
 		// tunnel only
 		if opts.DisableExec {
 			// tunnel and block to exit, wait until killed
-			return box.podPortForward(opts.Template, info, true)
+			return box.podPortForward(ctx, opts.Template, info, true)
 		}
 
 		if !opts.DisableTunnel {
 			// tunnel and exec after, do not block
-			if err := box.podPortForward(opts.Template, info, false); err != nil {
+			if err := box.podPortForward(ctx, opts.Template, info, false); err != nil {
 				return err
 			}
 		}
 
-		return box.execBox(opts, info)
+		return box.execBox(ctx, opts, info)
 	}
 }
 
@@ -182,18 +203,18 @@ func boxNameLabelSelector(name string) string {
 	return fmt.Sprintf("%s,%s=%s", boxModel.BoxLabelSelector(), kubernetes.LabelKubeName, name)
 }
 
-func (box *KubeBoxClient) searchBox(name string) (*boxModel.BoxInfo, error) {
+func (box *KubeBoxClient) searchBox(ctx context.Context, name string) (*boxModel.BoxInfo, error) {
 	namespace := box.clientOpts.Namespace
 	box.eventBus.Publish(newDeploymentSearchKubeEvent(namespace, name))
 
-	deployments, err := box.client.DeploymentList(namespace, boxModel.BoxPrefixName, boxNameLabelSelector(name))
+	deployments, err := box.client.DeploymentList(ctx, namespace, boxModel.BoxPrefixName, boxNameLabelSelector(name))
 	if err != nil {
 		return nil, err
 	}
 
 	switch len(deployments) {
 	case 0:
-		return nil, errors.New("box not found")
+		return nil, boxModel.ErrBoxNotFound
 	case 1:
 		info := newBoxInfo(deployments[0])
 		return &info, nil
@@ -203,7 +224,7 @@ func (box *KubeBoxClient) searchBox(name string) (*boxModel.BoxInfo, error) {
 	}
 }
 
-func (box *KubeBoxClient) execBox(opts *boxModel.ConnectOptions, info *boxModel.BoxInfo) error {
+func (box *KubeBoxClient) execBox(ctx context.Context, opts *boxModel.ConnectOptions, info *boxModel.BoxInfo) error {
 
 	// TODO if BoxInfo not Healthy attempt scale 1
 
@@ -211,12 +232,17 @@ func (box *KubeBoxClient) execBox(opts *boxModel.ConnectOptions, info *boxModel.
 		// stop loader
 		box.eventBus.Publish(newPodExecKubeLoaderEvent())
 
-		return box.logsBox(opts, info)
+		return box.logsBox(ctx, opts, info)
 	}
 
-	if opts.DeleteOnExit {
-		defer box.deleteBox(info.Name)
-	}
+	defer func() {
+		if opts.IsDetached() {
+			box.eventBus.Publish(newPodExecDetachKubeEvent(box.clientOpts.Namespace, info.Id))
+		} else if opts.DeleteOnExit {
+			// the box is deleted even if the session was interrupted
+			box.deleteBox(context.WithoutCancel(ctx), info.Name)
+		}
+	}()
 
 	// exec
 	execOpts := &kubernetes.PodExecOpts{
@@ -228,26 +254,51 @@ func (box *KubeBoxClient) execBox(opts *boxModel.ConnectOptions, info *boxModel.
 		OutStream:     opts.StreamOpts.Out,
 		ErrStream:     opts.StreamOpts.Err,
 		IsTty:         opts.StreamOpts.IsTty,
+		Resize:        opts.StreamOpts.Resize,
 		OnExecCallback: func() {
 			// stop loader
 			box.eventBus.Publish(newPodExecKubeLoaderEvent())
 		},
 	}
 	box.eventBus.Publish(newPodExecKubeEvent(opts.Template.Name, box.clientOpts.Namespace, info.Id, opts.Template.Shell))
-	return box.client.PodExecShell(execOpts)
+	return box.client.PodExecShell(ctx, execOpts)
 }
 
-func (box *KubeBoxClient) logsBox(opts *boxModel.ConnectOptions, info *boxModel.BoxInfo) error {
+func (box *KubeBoxClient) execCommandBox(ctx context.Context, opts *boxModel.ExecOptions) (int, error) {
 	namespace := box.clientOpts.Namespace
 
-	if opts.DeleteOnExit {
-		opts.OnInterruptCallback(func() {
-			box.eventBus.Publish(newPodLogsExitKubeEvent(namespace, info.Id))
-			box.eventBus.Publish(newPodLogsExitKubeConsoleEvent())
-			box.deleteBox(info.Name)
-		})
+	info, err := box.searchBox(ctx, opts.Name)
+	if err != nil {
+		return -1, err
 	}
 
+	execOpts := &kubernetes.PodExecOpts{
+		Namespace:     namespace,
+		PodName:       info.Id,
+		ContainerName: opts.Template.MainContainerName(),
+		Commands:      opts.Command,
+		InStream:      opts.StreamOpts.In,
+		OutStream:     opts.StreamOpts.Out,
+		ErrStream:     opts.StreamOpts.Err,
+		IsTty:         opts.StreamOpts.IsTty,
+		Resize:        opts.StreamOpts.Resize,
+		OnExecCallback: func() {
+			// stop loader
+			box.eventBus.Publish(newPodExecKubeLoaderEvent())
+		},
+	}
+	box.eventBus.Publish(newPodExecKubeEvent(opts.Template.Name, namespace, info.Id, strings.Join(opts.Command, " ")))
+	exitCode, err := box.client.PodExec(ctx, execOpts)
+	if err != nil {
+		return -1, err
+	}
+	box.eventBus.Publish(newPodExecExitCodeKubeEvent(namespace, info.Id, exitCode))
+	return exitCode, nil
+}
+
+func (box *KubeBoxClient) logsBox(ctx context.Context, opts *boxModel.ConnectOptions, info *boxModel.BoxInfo) error {
+	namespace := box.clientOpts.Namespace
+
 	logsOpts := &kubernetes.PodLogsOpts{
 		Namespace:     namespace,
 		PodName:       info.Id,
@@ -255,10 +306,53 @@ func (box *KubeBoxClient) logsBox(opts *boxModel.ConnectOptions, info *boxModel.
 		OutStream:     opts.StreamOpts.Out,
 	}
 	box.eventBus.Publish(newPodLogsKubeEvent(namespace, info.Id))
-	return box.client.PodLogs(logsOpts)
+	err := box.client.PodLogs(ctx, logsOpts)
+	if opts.DeleteOnExit && ctx.Err() != nil {
+		// blocks until interrupted
+		box.eventBus.Publish(newPodLogsExitKubeEvent(namespace, info.Id))
+		box.eventBus.Publish(newPodLogsExitKubeConsoleEvent())
+		box.deleteBox(context.WithoutCancel(ctx), info.Name)
+	}
+	return err
 }
 
-func (box *KubeBoxClient) podPortForward(template *boxModel.BoxV1, boxInfo *boxModel.BoxInfo, isWait bool) error {
+func (box *KubeBoxClient) filterLogsBox(ctx context.Context, opts *boxModel.LogsOptions) error {
+	namespace := box.clientOpts.Namespace
+
+	info, err := box.searchBox(ctx, opts.Name)
+	if err != nil {
+		return err
+	}
+
+	containerName := opts.Template.MainContainerName()
+	if opts.Sidecar != "" {
+		// e.g. "sidecar-vpn"
+		containerName = fmt.Sprintf("%s%s", commonModel.SidecarPrefixName, opts.Sidecar)
+	}
+
+	logsOpts := &kubernetes.PodLogsFilterOpts{
+		Namespace:     namespace,
+		PodName:       info.Id,
+		ContainerName: containerName,
+		OutStream:     opts.StreamOpts.Out,
+		Follow:        opts.Follow,
+	}
+	if opts.Since > 0 {
+		sinceSeconds := int64(opts.Since.Seconds())
+		logsOpts.SinceSeconds = &sinceSeconds
+	}
+	if opts.Tail >= 0 {
+		tailLines := int64(opts.Tail)
+		logsOpts.TailLines = &tailLines
+	}
+
+	box.eventBus.Publish(newPodLogsKubeEvent(namespace, info.Id))
+	// stop loader
+	box.eventBus.Publish(newPodExecKubeLoaderEvent())
+	return box.client.PodLogsFilter(ctx, logsOpts)
+}
+
+func (box *KubeBoxClient) podPortForward(ctx context.Context, template *boxModel.BoxV1, boxInfo *boxModel.BoxInfo, isWait bool) error {
 	namespace := box.clientOpts.Namespace
 
 	if !template.HasPorts() {
@@ -290,13 +384,46 @@ func (box *KubeBoxClient) podPortForward(template *boxModel.BoxV1, boxInfo *boxM
 			box.eventBus.Publish(newPodPortForwardErrorKubeEvent(namespace, boxInfo.Id, err))
 		},
 	}
-	if err := box.client.PodPortForward(opts); err != nil {
+	if err := box.client.PodPortForward(ctx, opts); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+func (box *KubeBoxClient) portForwardBox(ctx context.Context, opts *boxModel.PortForwardOptions) error {
+	namespace := box.clientOpts.Namespace
+
+	info, err := box.searchBox(ctx, opts.Name)
+	if err != nil {
+		return err
+	}
+
+	// fails if the requested port is already in use, instead of binding the next available
+	if err := util.CheckLocalAddress(opts.BindHost(), opts.Port.Local); err != nil {
+		return errors.Wrapf(err, "error kube local port %s", opts.Port.Local)
+	}
+	box.eventBus.Publish(newPodPortForwardBindingKubeEvent(namespace, info.Id, opts.Port))
+	box.eventBus.Publish(newPodPortForwardBindingKubeConsoleEvent(namespace, info.Name, opts.Port, len(opts.Port.Alias)))
+
+	forwardOpts := &kubernetes.PodPortForwardOpts{
+		Namespace: namespace,
+		PodName:   info.Id,
+		Addresses: []string{opts.BindHost()},
+		Ports:     []string{fmt.Sprintf("%s:%s", opts.Port.Local, opts.Port.Remote)},
+		IsWait:    true,
+		OnTunnelStartCallback: func() {
+			// stop loader
+			box.eventBus.Publish(newPodExecKubeLoaderEvent())
+			opts.OnPortBindCallback(opts.LocalAddress())
+		},
+		OnTunnelErrorCallback: func(err error) {
+			box.eventBus.Publish(newPodPortForwardErrorKubeEvent(namespace, info.Id, err))
+		},
+	}
+	return box.client.PodPortForward(ctx, forwardOpts)
+}
+
 func ToPortBindings(ports []boxModel.BoxPort, onPortBindCallback func(port boxModel.BoxPort)) ([]string, error) {
 
 	var portBindings []string
@@ -319,22 +446,47 @@ func ToPortBindings(ports []boxModel.BoxPort, onPortBindCallback func(port boxMo
 	return portBindings, nil
 }
 
-func (box *KubeBoxClient) describeBox(name string) (*boxModel.BoxDetails, error) {
+func (box *KubeBoxClient) copyBox(ctx context.Context, opts *boxModel.CopyOptions) error {
+	namespace := box.clientOpts.Namespace
+
+	info, err := box.searchBox(ctx, opts.Name)
+	if err != nil {
+		return err
+	}
+
+	copyOpts := &kubernetes.CopyPodOpts{
+		Namespace:     namespace,
+		PodName:       info.Id,
+		ContainerName: opts.Template.MainContainerName(),
+		LocalPath:     opts.LocalPath,
+		RemotePath:    opts.RemotePath,
+		OnProgressCallback: func(size int64) {
+			box.eventBus.Publish(newPodCopyProgressKubeLoaderEvent(opts.Direction, size))
+		},
+	}
+	box.eventBus.Publish(newPodCopyKubeEvent(namespace, info.Id, opts))
+	if opts.Direction == boxModel.CopyUpload {
+		return box.client.CopyToPod(ctx, copyOpts)
+	}
+	return box.client.CopyFromPod(ctx, copyOpts)
+}
+
+func (box *KubeBoxClient) describeBox(ctx context.Context, name string) (*boxModel.BoxDetails, error) {
 	namespace := box.clientOpts.Namespace
 
-	boxInfo, err := box.searchBox(name)
+	boxInfo, err := box.searchBox(ctx, name)
 	if err != nil {
 		return nil, err
 	}
 
 	box.eventBus.Publish(newDeploymentDescribeKubeEvent(namespace, name))
-	deployment, err := box.client.DeploymentDescribe(namespace, boxInfo.Name)
+	deployment, err := box.client.DeploymentDescribe(ctx, namespace, boxInfo.Name)
 	if err != nil {
 		return nil, err
 	}
 
 	box.eventBus.Publish(newServiceDescribeKubeEvent(namespace, name))
-	service, err := box.client.ServiceDescribe(namespace, boxInfo.Name)
+	service, err := box.client.ServiceDescribe(ctx, namespace, boxInfo.Name)
 	if err != nil {
 		return nil, err
 	}
@@ -388,10 +540,10 @@ func ToBoxDetails(deployment *kubernetes.DeploymentDetails, serviceInfo *kuberne
 	}, nil
 }
 
-func (box *KubeBoxClient) listBoxes() ([]boxModel.BoxInfo, error) {
+func (box *KubeBoxClient) listBoxes(ctx context.Context) ([]boxModel.BoxInfo, error) {
 	namespace := box.clientOpts.Namespace
 
-	deployments, err := box.client.DeploymentList(namespace, boxModel.BoxPrefixName, boxModel.BoxLabelSelector())
+	deployments, err := box.client.DeploymentList(ctx, namespace, boxModel.BoxPrefixName, boxModel.BoxLabelSelector())
 	if err != nil {
 		return nil, err
 	}
@@ -412,19 +564,19 @@ func newBoxInfo(deployment kubernetes.DeploymentInfo) boxModel.BoxInfo {
 	}
 }
 
-func (box *KubeBoxClient) deleteBoxes(names []string) ([]string, error) {
+func (box *KubeBoxClient) deleteBoxes(ctx context.Context, names []string) ([]string, error) {
 	namespace := box.clientOpts.Namespace
 
 	// optimize delete
 	if len(names) == 1 {
-		boxInfo, err := box.searchBox(names[0])
+		boxInfo, err := box.searchBox(ctx, names[0])
 		if err != nil {
 			return nil, err
 		}
-		return []string{boxInfo.Name}, box.deleteBox(boxInfo.Name)
+		return []string{boxInfo.Name}, box.deleteBox(ctx, boxInfo.Name)
 	}
 
-	boxes, err := box.listBoxes()
+	boxes, err := box.listBoxes(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -435,7 +587,7 @@ func (box *KubeBoxClient) deleteBoxes(names []string) ([]string, error) {
 		// all or filter
 		if len(names) == 0 || slices.Contains(names, boxInfo.Name) {
 
-			if err := box.deleteBox(boxInfo.Name); err == nil {
+			if err := box.deleteBox(ctx, boxInfo.Name); err == nil {
 				deleted = append(deleted, boxInfo.Name)
 			} else {
 				// silently ignore
@@ -446,28 +598,28 @@ func (box *KubeBoxClient) deleteBoxes(names []string) ([]string, error) {
 	return deleted, nil
 }
 
-func (box *KubeBoxClient) deleteBox(name string) error {
+func (box *KubeBoxClient) deleteBox(ctx context.Context, name string) error {
 	namespace := box.clientOpts.Namespace
 
 	box.eventBus.Publish(newDeploymentDeleteKubeEvent(namespace, name))
-	if err := box.client.DeploymentDelete(namespace, name); err != nil {
+	if err := box.client.DeploymentDelete(ctx, namespace, name); err != nil {
 		return err
 	}
 
 	box.eventBus.Publish(newServiceDeleteKubeEvent(namespace, name))
-	if err := box.client.ServiceDelete(namespace, name); err != nil {
+	if err := box.client.ServiceDelete(ctx, namespace, name); err != nil {
 		return err
 	}
 
-	if err := box.kubeCommon.SidecarVpnDelete(namespace, name); err != nil {
+	if err := box.kubeCommon.SidecarVpnDelete(ctx, namespace, name); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (box *KubeBoxClient) clean() error {
+func (box *KubeBoxClient) clean(ctx context.Context) error {
 	namespace := box.clientOpts.Namespace
 
 	box.eventBus.Publish(newNamespaceDeleteKubeEvent(namespace))
-	return box.client.NamespaceDelete(namespace)
+	return box.client.NamespaceDelete(ctx, namespace)
 }
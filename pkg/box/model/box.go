@@ -9,6 +9,7 @@ import (
 	"golang.org/x/exp/slices"
 
 	commonModel "github.com/hckops/hckctl/pkg/common/model"
+	"github.com/hckops/hckctl/pkg/secret"
 	"github.com/hckops/hckctl/pkg/util"
 )
 
@@ -26,6 +27,7 @@ type BoxV1 struct {
 	Image   commonModel.Image
 	Shell   string
 	Env     []string
+	Secrets []string `json:"Secrets,omitempty" yaml:",omitempty"` // env keys to redact, in addition to the naming convention e.g. *_PASSWORD
 	Network struct {
 		Ports []string
 	}
@@ -48,8 +50,17 @@ func SortPorts(ports []BoxPort) []BoxPort {
 }
 
 type BoxEnv struct {
-	Key   string
-	Value string
+	Key    string
+	Value  string
+	Secret bool
+}
+
+// RedactedValue masks secrets, the actual value should be revealed only explicitly
+func (env BoxEnv) RedactedValue() string {
+	if env.Secret {
+		return util.RedactedValue
+	}
+	return env.Value
 }
 
 func SortEnv(env []BoxEnv) []BoxEnv {
@@ -148,7 +159,14 @@ func PortFormatPadding(ports []BoxPort) int {
 
 func (box *BoxV1) EnvironmentVariables() map[string]BoxEnv {
 	// TODO return error validation?
-	return ToEnvironmentVariables(box.Env)
+	envs := ToEnvironmentVariables(box.Env)
+	for _, key := range box.Secrets {
+		if env, ok := envs[key]; ok {
+			env.Secret = true
+			envs[key] = env
+		}
+	}
+	return envs
 }
 
 func ToEnvironmentVariables(values []string) map[string]BoxEnv {
@@ -157,20 +175,60 @@ func ToEnvironmentVariables(values []string) map[string]BoxEnv {
 
 		// silently ignore errors
 		if key, value, err := util.SplitKeyValue(e); err == nil {
+			// a "secret:<name>" reference is always secret, regardless of the key
+			_, isReference := secret.ParseReference(value)
 			envs[key] = BoxEnv{
-				Key:   key,
-				Value: value,
+				Key:    key,
+				Value:  value,
+				Secret: util.IsSecretKey(key) || isReference,
 			}
 		}
 	}
 	return envs
 }
 
+// ResolveSecrets returns a copy with the "secret:<name>" env values resolved and redacted
+func (box *BoxV1) ResolveSecrets(lookup secret.Lookup) (*BoxV1, error) {
+	resolved := *box
+	resolved.Env = nil
+	resolved.Secrets = slices.Clone(box.Secrets)
+	for _, e := range box.Env {
+		key, value, err := util.SplitKeyValue(e)
+		if err != nil {
+			resolved.Env = append(resolved.Env, e)
+			continue
+		}
+		if _, ok := secret.ParseReference(value); !ok {
+			resolved.Env = append(resolved.Env, e)
+			continue
+		}
+		secretValue, err := secret.Resolve(value, lookup)
+		if err != nil {
+			return nil, fmt.Errorf("invalid secret env %s: %w", key, err)
+		}
+		resolved.Env = append(resolved.Env, fmt.Sprintf("%s=%s", key, secretValue))
+		if !slices.Contains(resolved.Secrets, key) {
+			resolved.Secrets = append(resolved.Secrets, key)
+		}
+	}
+	return &resolved, nil
+}
+
 func (box *BoxV1) EnvironmentVariableValues() []BoxEnv {
 	return SortEnv(maps.Values(box.EnvironmentVariables()))
 }
 
+// Pretty redacts the secrets
 func (box *BoxV1) Pretty() string {
-	value, _ := util.EncodeJsonIndent(box)
+	redacted := *box
+	redacted.Env = nil
+	for _, e := range box.Env {
+		if key, _, err := util.SplitKeyValue(e); err == nil && box.EnvironmentVariables()[key].Secret {
+			redacted.Env = append(redacted.Env, fmt.Sprintf("%s=%s", key, util.RedactedValue))
+		} else {
+			redacted.Env = append(redacted.Env, e)
+		}
+	}
+	value, _ := util.EncodeJsonIndent(redacted)
 	return value
 }
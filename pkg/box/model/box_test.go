@@ -1,6 +1,7 @@
 package model
 
 import (
+	"errors"
 	"strings"
 	"testing"
 
@@ -120,11 +121,73 @@ func TestPortFormatPadding(t *testing.T) {
 func TestEnvironmentVariables(t *testing.T) {
 	env := map[string]BoxEnv{
 		"TTYD_USERNAME": {Key: "TTYD_USERNAME", Value: "username"},
-		"TTYD_PASSWORD": {Key: "TTYD_PASSWORD", Value: "password"},
+		"TTYD_PASSWORD": {Key: "TTYD_PASSWORD", Value: "password", Secret: true},
 	}
 	assert.Equal(t, env, testBox.EnvironmentVariables())
 }
 
+func TestEnvironmentVariablesSecrets(t *testing.T) {
+	var testBox = &BoxV1{
+		Env: []string{
+			"MY_LICENSE=abc",
+			"MY_USER=root",
+		},
+		Secrets: []string{"MY_LICENSE", "MY_MISSING"},
+	}
+	env := map[string]BoxEnv{
+		"MY_LICENSE": {Key: "MY_LICENSE", Value: "abc", Secret: true},
+		"MY_USER":    {Key: "MY_USER", Value: "root"},
+	}
+	assert.Equal(t, env, testBox.EnvironmentVariables())
+}
+
+func TestEnvironmentVariablesSecretReference(t *testing.T) {
+	var testBox = &BoxV1{
+		Env: []string{
+			"FOO=secret:license",
+			"MY_USER=root",
+		},
+	}
+	env := map[string]BoxEnv{
+		"FOO":     {Key: "FOO", Value: "secret:license", Secret: true},
+		"MY_USER": {Key: "MY_USER", Value: "root"},
+	}
+	assert.Equal(t, env, testBox.EnvironmentVariables())
+}
+
+func TestResolveSecrets(t *testing.T) {
+	var testBox = &BoxV1{
+		Env: []string{
+			"MY_LICENSE=secret:license",
+			"MY_USER=root",
+		},
+	}
+	lookup := func(name string) (string, error) {
+		if name == "license" {
+			return "abc", nil
+		}
+		return "", errors.New("not found")
+	}
+
+	resolved, err := testBox.ResolveSecrets(lookup)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"MY_LICENSE=abc", "MY_USER=root"}, resolved.Env)
+	assert.Equal(t, []string{"MY_LICENSE"}, resolved.Secrets)
+	assert.True(t, resolved.EnvironmentVariables()["MY_LICENSE"].Secret)
+	// unchanged
+	assert.Equal(t, []string{"MY_LICENSE=secret:license", "MY_USER=root"}, testBox.Env)
+	assert.Empty(t, testBox.Secrets)
+
+	testBox.Env = append(testBox.Env, "MY_TOKEN=secret:missing")
+	_, err = testBox.ResolveSecrets(lookup)
+	assert.EqualError(t, err, "invalid secret env MY_TOKEN: not found")
+}
+
+func TestRedactedValue(t *testing.T) {
+	assert.Equal(t, "root", BoxEnv{Key: "MY_USER", Value: "root"}.RedactedValue())
+	assert.Equal(t, "********", BoxEnv{Key: "MY_LICENSE", Value: "abc", Secret: true}.RedactedValue())
+}
+
 func TestEnvironmentVariablesInvalid(t *testing.T) {
 	var testBox = &BoxV1{
 		Env: []string{
@@ -155,7 +218,7 @@ func TestEnvironmentVariablesUnique(t *testing.T) {
 
 func TestEnvironmentVariableValues(t *testing.T) {
 	ports := []BoxEnv{
-		{Key: "TTYD_PASSWORD", Value: "password"},
+		{Key: "TTYD_PASSWORD", Value: "password", Secret: true},
 		{Key: "TTYD_USERNAME", Value: "username"},
 	}
 	assert.Equal(t, ports, testBox.EnvironmentVariableValues())
@@ -175,7 +238,7 @@ func TestPretty(t *testing.T) {
   "Shell": "/bin/bash",
   "Env": [
     "TTYD_USERNAME=username",
-    "TTYD_PASSWORD=password"
+    "TTYD_PASSWORD=********"
   ],
   "Network": {
     "Ports": [
@@ -0,0 +1,63 @@
+package model
+
+import (
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+type CopyDirection string
+
+const (
+	CopyUpload   CopyDirection = "upload"   // from local to box
+	CopyDownload CopyDirection = "download" // from box to local
+)
+
+func (d CopyDirection) String() string {
+	return string(d)
+}
+
+// splitRemotePath parses the "box-name:/path" syntax
+func splitRemotePath(value string) (string, string, bool) {
+	name, remotePath, found := strings.Cut(value, ":")
+	if !found || name == "" || strings.ContainsAny(name, `/\`) {
+		return "", "", false
+	}
+	return name, remotePath, true
+}
+
+// NewCopyOptions expects exactly one of source or destination with the "box-name:/path" syntax.
+// A trailing separator or an existing local directory as destination, copies the source inside it
+func NewCopyOptions(source string, destination string) (*CopyOptions, error) {
+	sourceName, sourcePath, isSourceRemote := splitRemotePath(source)
+	destinationName, destinationPath, isDestinationRemote := splitRemotePath(destination)
+
+	switch {
+	case isSourceRemote && isDestinationRemote:
+		return nil, errors.New("copy between boxes not supported")
+	case isSourceRemote:
+		if sourcePath == "" {
+			return nil, errors.New("invalid remote source path")
+		}
+		remotePath := path.Clean(sourcePath)
+		localPath := destination
+		if info, err := os.Stat(localPath); strings.HasSuffix(localPath, string(os.PathSeparator)) || (err == nil && info.IsDir()) {
+			localPath = filepath.Join(localPath, path.Base(remotePath))
+		}
+		return &CopyOptions{Name: sourceName, LocalPath: filepath.Clean(localPath), RemotePath: remotePath, Direction: CopyDownload}, nil
+	case isDestinationRemote:
+		if destinationPath == "" {
+			return nil, errors.New("invalid remote destination path")
+		}
+		localPath := filepath.Clean(source)
+		remotePath := destinationPath
+		if strings.HasSuffix(remotePath, "/") {
+			remotePath = path.Join(remotePath, filepath.Base(localPath))
+		}
+		return &CopyOptions{Name: destinationName, LocalPath: localPath, RemotePath: path.Clean(remotePath), Direction: CopyUpload}, nil
+	default:
+		return nil, errors.New("missing box name")
+	}
+}
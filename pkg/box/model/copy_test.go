@@ -0,0 +1,40 @@
+package model
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCopyOptionsUpload(t *testing.T) {
+	opts, err := NewCopyOptions("./foo/bar.txt", "box-alpine-123:/tmp/")
+	assert.NoError(t, err)
+	assert.Equal(t, &CopyOptions{Name: "box-alpine-123", LocalPath: "foo/bar.txt", RemotePath: "/tmp/bar.txt", Direction: CopyUpload}, opts)
+
+	opts, err = NewCopyOptions("foo", "box-alpine-123:/tmp/my-foo")
+	assert.NoError(t, err)
+	assert.Equal(t, &CopyOptions{Name: "box-alpine-123", LocalPath: "foo", RemotePath: "/tmp/my-foo", Direction: CopyUpload}, opts)
+}
+
+func TestNewCopyOptionsDownload(t *testing.T) {
+	opts, err := NewCopyOptions("box-alpine-123:/etc/hosts", "my-hosts")
+	assert.NoError(t, err)
+	assert.Equal(t, &CopyOptions{Name: "box-alpine-123", LocalPath: "my-hosts", RemotePath: "/etc/hosts", Direction: CopyDownload}, opts)
+
+	localDir := t.TempDir()
+	opts, err = NewCopyOptions("box-alpine-123:/etc/hosts", localDir)
+	assert.NoError(t, err)
+	assert.Equal(t, &CopyOptions{Name: "box-alpine-123", LocalPath: filepath.Join(localDir, "hosts"), RemotePath: "/etc/hosts", Direction: CopyDownload}, opts)
+}
+
+func TestNewCopyOptionsInvalid(t *testing.T) {
+	_, errLocal := NewCopyOptions("foo", "bar")
+	assert.EqualError(t, errLocal, "missing box name")
+
+	_, errRemote := NewCopyOptions("box-a:/foo", "box-b:/bar")
+	assert.EqualError(t, errRemote, "copy between boxes not supported")
+
+	_, errPath := NewCopyOptions("./foo:bar", "box-b:")
+	assert.EqualError(t, errPath, "invalid remote destination path")
+}
@@ -0,0 +1,76 @@
+package model
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+const (
+	BoxPortForwardAlias   = "forward"
+	DefaultBindAddress    = "127.0.0.1"
+	portForwardMinNumber  = 1
+	portForwardMaxNumber  = 65535
+	portForwardSeparator  = ":"
+	portForwardSpecFormat = "LOCAL:REMOTE"
+)
+
+// NewPortForward parses the "LOCAL:REMOTE" syntax, local defaults to remote if omitted
+func NewPortForward(value string) (BoxPort, error) {
+
+	values := strings.Split(strings.TrimSpace(value), portForwardSeparator)
+
+	var local, remote string
+	switch len(values) {
+	case 1:
+		local, remote = values[0], values[0]
+	case 2:
+		local, remote = values[0], values[1]
+	default:
+		return BoxPort{}, fmt.Errorf("invalid port %s, expected format %s", value, portForwardSpecFormat)
+	}
+
+	if err := validatePortNumber(local); err != nil {
+		return BoxPort{}, fmt.Errorf("invalid local port: %v", err)
+	}
+	if err := validatePortNumber(remote); err != nil {
+		return BoxPort{}, fmt.Errorf("invalid remote port: %v", err)
+	}
+
+	return BoxPort{
+		Alias:  BoxPortForwardAlias,
+		Local:  local,
+		Remote: remote,
+		Public: false,
+	}, nil
+}
+
+func validatePortNumber(value string) error {
+	port, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("%s is not a number", value)
+	}
+	if port < portForwardMinNumber || port > portForwardMaxNumber {
+		return fmt.Errorf("%d out of range", port)
+	}
+	return nil
+}
+
+func ValidateBindAddress(address string) error {
+	if address == "localhost" || net.ParseIP(address) != nil {
+		return nil
+	}
+	return fmt.Errorf("invalid bind address %s", address)
+}
+
+func (opts *PortForwardOptions) BindHost() string {
+	if strings.TrimSpace(opts.BindAddress) == "" {
+		return DefaultBindAddress
+	}
+	return opts.BindAddress
+}
+
+func (opts *PortForwardOptions) LocalAddress() string {
+	return net.JoinHostPort(opts.BindHost(), opts.Port.Local)
+}
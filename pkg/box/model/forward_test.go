@@ -0,0 +1,49 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPortForward(t *testing.T) {
+	port, err := NewPortForward("8080:80")
+	assert.NoError(t, err)
+	assert.Equal(t, BoxPort{Alias: "forward", Local: "8080", Remote: "80"}, port)
+}
+
+func TestNewPortForwardSame(t *testing.T) {
+	port, err := NewPortForward("3000")
+	assert.NoError(t, err)
+	assert.Equal(t, BoxPort{Alias: "forward", Local: "3000", Remote: "3000"}, port)
+}
+
+func TestNewPortForwardError(t *testing.T) {
+	_, err := NewPortForward("1:2:3")
+	assert.EqualError(t, err, "invalid port 1:2:3, expected format LOCAL:REMOTE")
+
+	_, err = NewPortForward("abc:80")
+	assert.EqualError(t, err, "invalid local port: abc is not a number")
+
+	_, err = NewPortForward("8080:0")
+	assert.EqualError(t, err, "invalid remote port: 0 out of range")
+
+	_, err = NewPortForward("65536:80")
+	assert.EqualError(t, err, "invalid local port: 65536 out of range")
+}
+
+func TestValidateBindAddress(t *testing.T) {
+	assert.NoError(t, ValidateBindAddress("0.0.0.0"))
+	assert.NoError(t, ValidateBindAddress("localhost"))
+	assert.NoError(t, ValidateBindAddress("::1"))
+	assert.EqualError(t, ValidateBindAddress("example"), "invalid bind address example")
+}
+
+func TestPortForwardOptionsLocalAddress(t *testing.T) {
+	opts := &PortForwardOptions{Port: BoxPort{Local: "8080", Remote: "80"}}
+	assert.Equal(t, "127.0.0.1", opts.BindHost())
+	assert.Equal(t, "127.0.0.1:8080", opts.LocalAddress())
+
+	opts.BindAddress = "0.0.0.0"
+	assert.Equal(t, "0.0.0.0:8080", opts.LocalAddress())
+}
@@ -1,6 +1,9 @@
 package model
 
 import (
+	"time"
+
+	"github.com/hckops/hckctl/pkg/client/terminal"
 	commonModel "github.com/hckops/hckctl/pkg/common/model"
 	"github.com/hckops/hckctl/pkg/event"
 )
@@ -30,11 +33,48 @@ type CreateOptions struct {
 }
 
 type ConnectOptions struct {
-	Template            *BoxV1
-	StreamOpts          *commonModel.StreamOptions
-	Name                string
-	DisableExec         bool
-	DisableTunnel       bool
-	DeleteOnExit        bool
-	OnInterruptCallback func(func())
+	Template      *BoxV1
+	StreamOpts    *commonModel.StreamOptions
+	Name          string
+	DisableExec   bool
+	DisableTunnel bool
+	DeleteOnExit  bool
+}
+
+// IsDetached returns true if the session was left with the detach keys, the box must not be deleted
+func (opts *ConnectOptions) IsDetached() bool {
+	return opts.StreamOpts != nil && terminal.IsDetached(opts.StreamOpts.In)
+}
+
+type ExecOptions struct {
+	Template   *BoxV1
+	StreamOpts *commonModel.StreamOptions // stdin is nil when not attached
+	Name       string
+	Command    []string
+}
+
+type LogsOptions struct {
+	Template   *BoxV1
+	StreamOpts *commonModel.StreamOptions
+	Name       string
+	Follow     bool
+	Since      time.Duration // zero for all
+	Tail       int           // negative for all
+	Sidecar    string        // main container if empty
+}
+
+type CopyOptions struct {
+	Template   *BoxV1
+	Name       string
+	LocalPath  string
+	RemotePath string
+	Direction  CopyDirection
+}
+
+type PortForwardOptions struct {
+	Template           *BoxV1
+	Name               string
+	Port               BoxPort
+	BindAddress        string               // defaults to localhost
+	OnPortBindCallback func(address string) // invoked once the local port is listening
 }
@@ -1,11 +1,15 @@
 package model
 
 import (
+	"errors"
 	"time"
 
 	commonModel "github.com/hckops/hckctl/pkg/common/model"
 )
 
+// ErrBoxNotFound is returned when no box matches the name
+var ErrBoxNotFound = errors.New("box not found")
+
 type BoxInfo struct {
 	Id      string
 	Name    string
@@ -1,10 +1,13 @@
 package docker
 
 import (
+	"archive/tar"
+	"bytes"
 	"context"
 	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 	"sync"
@@ -32,7 +35,6 @@ func NewDockerClient() (*DockerClient, error) {
 	}
 
 	return &DockerClient{
-		ctx:    context.Background(),
 		docker: dockerClient,
 	}, nil
 }
@@ -41,9 +43,9 @@ func (client *DockerClient) Close() error {
 	return client.docker.Close()
 }
 
-func (client *DockerClient) ImagePull(opts *ImagePullOpts) error {
+func (client *DockerClient) ImagePull(ctx context.Context, opts *ImagePullOpts) error {
 
-	reader, err := client.docker.ImagePull(client.ctx, opts.ImageName, types.ImagePullOptions{Platform: opts.PlatformString()})
+	reader, err := client.docker.ImagePull(ctx, opts.ImageName, types.ImagePullOptions{Platform: opts.PlatformString()})
 	if err != nil {
 		return errors.Wrap(err, "error image pull")
 	}
@@ -59,10 +61,10 @@ func (client *DockerClient) ImagePull(opts *ImagePullOpts) error {
 	return nil
 }
 
-func (client *DockerClient) ImageRemoveDangling(opts *ImageRemoveOpts) error {
+func (client *DockerClient) ImageRemoveDangling(ctx context.Context, opts *ImageRemoveOpts) error {
 
 	// dangling images have no tags <none>
-	images, err := client.docker.ImageList(client.ctx, types.ImageListOptions{
+	images, err := client.docker.ImageList(ctx, types.ImageListOptions{
 		Filters: filters.NewArgs(filters.KeyValuePair{
 			Key: "dangling", Value: "true",
 		}),
@@ -73,7 +75,7 @@ func (client *DockerClient) ImageRemoveDangling(opts *ImageRemoveOpts) error {
 	for _, image := range images {
 		opts.OnImageRemoveCallback(image.ID)
 
-		_, err := client.docker.ImageRemove(client.ctx, image.ID, types.ImageRemoveOptions{})
+		_, err := client.docker.ImageRemove(ctx, image.ID, types.ImageRemoveOptions{})
 		if err != nil {
 			// ignore failures: there might be running containers with old images
 			opts.OnImageRemoveErrorCallback(image.ID, err)
@@ -83,10 +85,10 @@ func (client *DockerClient) ImageRemoveDangling(opts *ImageRemoveOpts) error {
 	return nil
 }
 
-func (client *DockerClient) ContainerCreate(opts *ContainerCreateOpts) (string, error) {
+func (client *DockerClient) ContainerCreate(ctx context.Context, opts *ContainerCreateOpts) (string, error) {
 
 	newContainer, err := client.docker.ContainerCreate(
-		client.ctx,
+		ctx,
 		opts.ContainerConfig,
 		opts.HostConfig,
 		opts.NetworkingConfig,
@@ -96,18 +98,11 @@ func (client *DockerClient) ContainerCreate(opts *ContainerCreateOpts) (string,
 		return "", errors.Wrap(err, "error container create")
 	}
 
-	// TODO move in the commands
-	if opts.CaptureInterrupt {
-		util.InterruptHandler(func() {
-			opts.OnContainerInterruptCallback(newContainer.ID)
-		})
-	}
-
 	if err := opts.OnContainerCreateCallback(newContainer.ID); err != nil {
 		return "", errors.Wrap(err, "error container create callback")
 	}
 
-	if err := client.docker.ContainerStart(client.ctx, newContainer.ID, types.ContainerStartOptions{}); err != nil {
+	if err := client.docker.ContainerStart(ctx, newContainer.ID, types.ContainerStartOptions{}); err != nil {
 		return "", errors.Wrap(err, "error container start")
 	}
 
@@ -116,7 +111,7 @@ func (client *DockerClient) ContainerCreate(opts *ContainerCreateOpts) (string,
 			return "", errors.Wrap(err, "error container wait callback")
 		}
 
-		statusCh, errCh := client.docker.ContainerWait(client.ctx, newContainer.ID, container.WaitConditionNotRunning)
+		statusCh, errCh := client.docker.ContainerWait(ctx, newContainer.ID, container.WaitConditionNotRunning)
 		select {
 		case err := <-errCh:
 			if err != nil {
@@ -124,6 +119,7 @@ func (client *DockerClient) ContainerCreate(opts *ContainerCreateOpts) (string,
 			}
 		case status := <-statusCh:
 			opts.OnContainerStatusCallback(fmt.Sprintf("wait status: containerId=%s code=%d", newContainer.ID, status.StatusCode))
+			opts.OnContainerExitCallback(int(status.StatusCode))
 		}
 	}
 
@@ -132,9 +128,34 @@ func (client *DockerClient) ContainerCreate(opts *ContainerCreateOpts) (string,
 	return newContainer.ID, nil
 }
 
-func (client *DockerClient) ContainerRestart(opts *ContainerRestartOpts) error {
+// ContainerAttachStdin streams the input into a created container, it must be invoked before starting it.
+// The container receives EOF when the input is consumed, which requires "StdinOnce"
+func (client *DockerClient) ContainerAttachStdin(ctx context.Context, containerId string, inStream io.Reader, onStreamErrorCallback func(error)) error {
+
+	attachResponse, err := client.docker.ContainerAttach(ctx, containerId, types.ContainerAttachOptions{
+		Stream: true,
+		Stdin:  true,
+	})
+	if err != nil {
+		return errors.Wrap(err, "error container attach")
+	}
+
+	go func() {
+		defer attachResponse.Close()
+
+		if _, err := io.Copy(attachResponse.Conn, inStream); err != nil {
+			onStreamErrorCallback(errors.Wrap(err, "error container attach copy"))
+		}
+		if err := attachResponse.CloseWrite(); err != nil {
+			onStreamErrorCallback(errors.Wrap(err, "error container attach close"))
+		}
+	}()
+	return nil
+}
+
+func (client *DockerClient) ContainerRestart(ctx context.Context, opts *ContainerRestartOpts) error {
 
-	containerJson, err := client.docker.ContainerInspect(client.ctx, opts.ContainerId)
+	containerJson, err := client.docker.ContainerInspect(ctx, opts.ContainerId)
 	if err != nil {
 		return errors.Wrap(err, "error container inspect")
 	}
@@ -143,16 +164,16 @@ func (client *DockerClient) ContainerRestart(opts *ContainerRestartOpts) error {
 	if containerJson.State.Status != ContainerStatusRunning {
 		opts.OnRestartCallback(containerJson.State.Status)
 
-		if err := client.docker.ContainerRestart(client.ctx, opts.ContainerId, container.StopOptions{}); err != nil {
+		if err := client.docker.ContainerRestart(ctx, opts.ContainerId, container.StopOptions{}); err != nil {
 			return errors.Wrap(err, "error docker restart")
 		}
 	}
 	return nil
 }
 
-func (client *DockerClient) ContainerExec(opts *ContainerExecOpts) error {
+func (client *DockerClient) ContainerExec(ctx context.Context, opts *ContainerExecOpts) error {
 
-	execCreateResponse, err := client.docker.ContainerExecCreate(client.ctx, opts.ContainerId, types.ExecConfig{
+	execCreateResponse, err := client.docker.ContainerExecCreate(ctx, opts.ContainerId, types.ExecConfig{
 		AttachStdin:  true,
 		AttachStdout: true,
 		AttachStderr: true,
@@ -164,7 +185,7 @@ func (client *DockerClient) ContainerExec(opts *ContainerExecOpts) error {
 		return errors.Wrap(err, "error container exec create")
 	}
 
-	execAttachResponse, err := client.docker.ContainerExecAttach(client.ctx, execCreateResponse.ID, types.ExecStartCheck{
+	execAttachResponse, err := client.docker.ContainerExecAttach(ctx, execCreateResponse.ID, types.ExecStartCheck{
 		Tty: opts.IsTty,
 	})
 	if err != nil {
@@ -178,6 +199,11 @@ func (client *DockerClient) ContainerExec(opts *ContainerExecOpts) error {
 		return errors.Wrap(err, "error container exec terminal")
 	}
 
+	if opts.IsTty {
+		stopResize := client.resizeExec(ctx, execCreateResponse.ID, opts)
+		defer stopResize()
+	}
+
 	doneChan := make(chan struct{}, 1)
 	onStreamCloseCallback := func() {
 		rawTerminal.Restore()
@@ -191,13 +217,96 @@ func (client *DockerClient) ContainerExec(opts *ContainerExecOpts) error {
 
 	// waits for interrupt signals, alternative ContainerExecKill https://github.com/moby/moby/pull/41548
 	select {
-	case <-client.ctx.Done():
-		return client.ctx.Err()
+	case <-ctx.Done():
+		return ctx.Err()
 	case <-doneChan:
 		return nil
 	}
 }
 
+// ContainerExecCommand runs a command without spawning a shell, blocks until the output is closed and returns the exit code
+func (client *DockerClient) ContainerExecCommand(ctx context.Context, opts *ContainerExecOpts) (int, error) {
+
+	execCreateResponse, err := client.docker.ContainerExecCreate(ctx, opts.ContainerId, types.ExecConfig{
+		AttachStdin:  opts.InStream != nil,
+		AttachStdout: true,
+		AttachStderr: true,
+		Detach:       false,
+		Tty:          opts.IsTty,
+		Cmd:          opts.Commands,
+	})
+	if err != nil {
+		return -1, errors.Wrap(err, "error container exec create")
+	}
+
+	execAttachResponse, err := client.docker.ContainerExecAttach(ctx, execCreateResponse.ID, types.ExecStartCheck{
+		Tty: opts.IsTty,
+	})
+	if err != nil {
+		return -1, errors.Wrap(err, "error container exec attach")
+	}
+	defer execAttachResponse.Close()
+
+	if opts.InStream != nil {
+		if opts.IsTty {
+			// ignore error if stdin is not a terminal e.g. pipe
+			if rawTerminal, err := terminal.NewRawTerminal(opts.InStream); err == nil {
+				defer rawTerminal.Restore()
+			}
+			stopResize := client.resizeExec(ctx, execCreateResponse.ID, opts)
+			defer stopResize()
+		}
+		go func() {
+			if _, err := io.Copy(execAttachResponse.Conn, opts.InStream); err != nil {
+				opts.OnStreamErrorCallback(errors.Wrap(err, "error copy stdin local->docker"))
+			}
+			// signal EOF to the remote command
+			execAttachResponse.CloseWrite()
+		}()
+	}
+
+	opts.OnContainerExecCallback()
+
+	// blocks until the command exits
+	if opts.IsTty {
+		_, err = io.Copy(opts.OutStream, execAttachResponse.Reader)
+	} else {
+		_, err = stdcopy.StdCopy(opts.OutStream, opts.ErrStream, execAttachResponse.Reader)
+	}
+	if err != nil {
+		return -1, errors.Wrap(err, "error container exec stream")
+	}
+	opts.OnStreamCloseCallback()
+
+	execInspect, err := client.docker.ContainerExecInspect(ctx, execCreateResponse.ID)
+	if err != nil {
+		return -1, errors.Wrap(err, "error container exec inspect")
+	}
+	return execInspect.ExitCode, nil
+}
+
+// resizeExec propagates the terminal size to the exec process until stopped
+func (client *DockerClient) resizeExec(ctx context.Context, execId string, opts *ContainerExecOpts) func() {
+	ctx, cancel := context.WithCancel(ctx)
+	sizeChannel := terminal.ResizeSource(ctx, opts.InStream, opts.Resize)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case size, ok := <-sizeChannel:
+				if !ok {
+					return
+				}
+				resizeOpts := types.ResizeOptions{Height: uint(size.Height), Width: uint(size.Width)}
+				// ignore error e.g. the process already exited, the stream error callback would delete the box
+				client.docker.ContainerExecResize(ctx, execId, resizeOpts)
+			}
+		}
+	}()
+	return cancel
+}
+
 func handleStreams(
 	opts *ContainerExecOpts,
 	execAttachResponse *types.HijackedResponse,
@@ -228,16 +337,24 @@ func handleStreams(
 	}()
 }
 
-func (client *DockerClient) ContainerRemove(containerId string) error {
-	if err := client.docker.ContainerRemove(client.ctx, containerId, types.ContainerRemoveOptions{Force: true}); err != nil {
+// ContainerKill stops a running container immediately, the logs are preserved until it's removed
+func (client *DockerClient) ContainerKill(ctx context.Context, containerId string) error {
+	if err := client.docker.ContainerKill(ctx, containerId, "SIGKILL"); err != nil {
+		return errors.Wrap(err, "error docker kill")
+	}
+	return nil
+}
+
+func (client *DockerClient) ContainerRemove(ctx context.Context, containerId string) error {
+	if err := client.docker.ContainerRemove(ctx, containerId, types.ContainerRemoveOptions{Force: true}); err != nil {
 		return errors.Wrap(err, "error docker remove")
 	}
 	return nil
 }
 
-func (client *DockerClient) ContainerInspect(containerId string) (ContainerDetails, error) {
+func (client *DockerClient) ContainerInspect(ctx context.Context, containerId string) (ContainerDetails, error) {
 
-	containerJson, err := client.docker.ContainerInspect(client.ctx, containerId)
+	containerJson, err := client.docker.ContainerInspect(ctx, containerId)
 	if err != nil {
 		return ContainerDetails{}, errors.Wrap(err, "error container inspect")
 	}
@@ -293,9 +410,9 @@ func newContainerDetails(container types.ContainerJSON) (ContainerDetails, error
 	}, nil
 }
 
-func (client *DockerClient) ContainerList(namePrefix string, label string) ([]ContainerInfo, error) {
+func (client *DockerClient) ContainerList(ctx context.Context, namePrefix string, label string) ([]ContainerInfo, error) {
 
-	containers, err := client.docker.ContainerList(client.ctx, types.ContainerListOptions{
+	containers, err := client.docker.ContainerList(ctx, types.ContainerListOptions{
 		All: true, // include exited
 		Filters: filters.NewArgs(
 			filters.KeyValuePair{Key: "name", Value: namePrefix},
@@ -328,9 +445,9 @@ func newContainerInfo(id, name, status string) ContainerInfo {
 	}
 }
 
-func (client *DockerClient) ContainerLogs(opts *ContainerLogsOpts) error {
+func (client *DockerClient) ContainerLogs(ctx context.Context, opts *ContainerLogsOpts) error {
 
-	outStream, err := client.docker.ContainerLogs(client.ctx, opts.ContainerId, types.ContainerLogsOptions{
+	outStream, err := client.docker.ContainerLogs(ctx, opts.ContainerId, types.ContainerLogsOptions{
 		ShowStdout: true,
 		ShowStderr: true,
 		Timestamps: true,
@@ -358,15 +475,47 @@ func (client *DockerClient) ContainerLogs(opts *ContainerLogsOpts) error {
 	}()
 
 	select {
-	case <-client.ctx.Done():
-		return client.ctx.Err()
+	case <-ctx.Done():
+		return ctx.Err()
 	case <-doneChan:
 		return nil
 	}
 }
 
-func (client *DockerClient) containerLogsStream(containerId string) (io.ReadCloser, error) {
-	outStream, err := client.docker.ContainerLogs(client.ctx, containerId, types.ContainerLogsOptions{
+// ContainerLogsFilter blocks until the stream is finished, or interrupted if it follows the logs
+func (client *DockerClient) ContainerLogsFilter(ctx context.Context, opts *ContainerLogsFilterOpts) error {
+
+	// multiplexed stdout and stderr are available only without tty
+	containerJson, err := client.docker.ContainerInspect(ctx, opts.ContainerId)
+	if err != nil {
+		return errors.Wrap(err, "error container logs inspect")
+	}
+
+	outStream, err := client.docker.ContainerLogs(ctx, opts.ContainerId, types.ContainerLogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Follow:     opts.Follow,
+		Since:      opts.Since,
+		Tail:       opts.Tail,
+	})
+	if err != nil {
+		return errors.Wrap(err, "error container logs")
+	}
+	defer outStream.Close()
+
+	if containerJson.Config != nil && containerJson.Config.Tty {
+		_, err = io.Copy(opts.OutStream, outStream)
+	} else {
+		_, err = stdcopy.StdCopy(opts.OutStream, opts.ErrStream, outStream)
+	}
+	if err != nil {
+		return errors.Wrap(err, "error container logs copy")
+	}
+	return nil
+}
+
+func (client *DockerClient) containerLogsStream(ctx context.Context, containerId string) (io.ReadCloser, error) {
+	outStream, err := client.docker.ContainerLogs(ctx, containerId, types.ContainerLogsOptions{
 		ShowStdout: true,
 		ShowStderr: true,
 		Follow:     true,
@@ -377,9 +526,9 @@ func (client *DockerClient) containerLogsStream(containerId string) (io.ReadClos
 	return outStream, nil
 }
 
-func (client *DockerClient) ContainerLogsStd(containerId string) error {
+func (client *DockerClient) ContainerLogsStd(ctx context.Context, containerId string) error {
 
-	outStream, err := client.containerLogsStream(containerId)
+	outStream, err := client.containerLogsStream(ctx, containerId)
 	if err != nil {
 		return err
 	}
@@ -392,9 +541,9 @@ func (client *DockerClient) ContainerLogsStd(containerId string) error {
 	return nil
 }
 
-func (client *DockerClient) ContainerLogsTee(opts *ContainerLogsOpts, logFileName string) error {
+func (client *DockerClient) ContainerLogsTee(ctx context.Context, opts *ContainerLogsOpts, logFileName string) error {
 
-	outStream, err := client.containerLogsStream(opts.ContainerId)
+	outStream, err := client.containerLogsStream(ctx, opts.ContainerId)
 	if err != nil {
 		return err
 	}
@@ -414,9 +563,9 @@ func (client *DockerClient) ContainerLogsTee(opts *ContainerLogsOpts, logFileNam
 	return err
 }
 
-func (client *DockerClient) NetworkUpsert(networkName string) (string, error) {
+func (client *DockerClient) NetworkUpsert(ctx context.Context, networkName string) (string, error) {
 
-	networks, err := client.docker.NetworkList(client.ctx, types.NetworkListOptions{})
+	networks, err := client.docker.NetworkList(ctx, types.NetworkListOptions{})
 	if err != nil {
 		return "", errors.Wrap(err, "error docker network list")
 	}
@@ -426,25 +575,63 @@ func (client *DockerClient) NetworkUpsert(networkName string) (string, error) {
 		}
 	}
 
-	if newNetwork, err := client.docker.NetworkCreate(client.ctx, networkName, types.NetworkCreate{CheckDuplicate: true}); err != nil {
+	if newNetwork, err := client.docker.NetworkCreate(ctx, networkName, types.NetworkCreate{CheckDuplicate: true}); err != nil {
 		return "", errors.Wrap(err, "error docker network create")
 	} else {
 		return newNetwork.ID, nil
 	}
 }
 
-func (client *DockerClient) CopyFileToContainer(containerId string, localPath string, containerPath string) error {
+func (client *DockerClient) CopyFileToContainer(ctx context.Context, containerId string, localPath string, containerPath string) error {
+	return client.CopyToContainer(ctx, &ContainerCopyOpts{
+		ContainerId:        containerId,
+		LocalPath:          localPath,
+		ContainerPath:      containerPath,
+		OnProgressCallback: func(int64) {},
+	})
+}
+
+// CopyContentToContainer writes the content as a single file without creating it locally e.g. decrypted secrets
+func (client *DockerClient) CopyContentToContainer(ctx context.Context, containerId string, content []byte, containerPath string) error {
+	var buffer bytes.Buffer
+	writer := tar.NewWriter(&buffer)
+	header := &tar.Header{
+		Name:    path.Base(containerPath),
+		Mode:    0600,
+		Size:    int64(len(content)),
+		ModTime: time.Now(),
+	}
+	if err := writer.WriteHeader(header); err != nil {
+		return errors.Wrap(err, "error copy content to container: tar header")
+	}
+	if _, err := writer.Write(content); err != nil {
+		return errors.Wrap(err, "error copy content to container: tar content")
+	}
+	if err := writer.Close(); err != nil {
+		return errors.Wrap(err, "error copy content to container: tar archive")
+	}
+
+	// container paths are always unix
+	if err := client.docker.CopyToContainer(ctx, containerId, path.Dir(containerPath), &buffer, types.CopyToContainerOptions{
+		AllowOverwriteDirWithFile: true,
+	}); err != nil {
+		return errors.Wrap(err, "error copy content to container")
+	}
+	return nil
+}
+
+func (client *DockerClient) CopyToContainer(ctx context.Context, opts *ContainerCopyOpts) error {
 	// see https://github.com/docker/cli/blob/b1d27091e50595fecd8a2a4429557b70681395b2/cli/command/container/cp.go#L182-L282
 
 	// get an absolute source path
-	srcPath, err := resolveLocalPath(localPath)
+	srcPath, err := resolveLocalPath(opts.LocalPath)
 	if err != nil {
 		return errors.Wrap(err, "error copy file to container: resolve local path")
 	}
 
 	// prepare destination copy info by stat-ing the container path
-	dstInfo := archive.CopyInfo{Path: containerPath}
-	dstStat, err := client.docker.ContainerStatPath(client.ctx, containerId, containerPath)
+	dstInfo := archive.CopyInfo{Path: opts.ContainerPath}
+	dstStat, err := client.docker.ContainerStatPath(ctx, opts.ContainerId, opts.ContainerPath)
 	if err != nil {
 		// ignore any error and assume that the parent directory of the destination
 		// path exists, in which case the copy may still succeed
@@ -452,7 +639,7 @@ func (client *DockerClient) CopyFileToContainer(containerId string, localPath st
 
 	// validate the destination path
 	if err := validateOutputPathFileMode(dstStat.Mode); err != nil {
-		return errors.Wrapf(err, `error copy file to container: destination "%s:%s" must be a directory or a regular file`, containerId, containerPath)
+		return errors.Wrapf(err, `error copy file to container: destination "%s:%s" must be a directory or a regular file`, opts.ContainerId, opts.ContainerPath)
 	}
 
 	// assume it's a valid directory
@@ -476,7 +663,7 @@ func (client *DockerClient) CopyFileToContainer(containerId string, localPath st
 	}
 	defer preparedArchive.Close()
 
-	if err := client.docker.CopyToContainer(client.ctx, containerId, dstDir, preparedArchive, types.CopyToContainerOptions{
+	if err := client.docker.CopyToContainer(ctx, opts.ContainerId, dstDir, util.NewProgressReader(preparedArchive, opts.OnProgressCallback), types.CopyToContainerOptions{
 		AllowOverwriteDirWithFile: true,
 	}); err != nil {
 		return errors.Wrap(err, "error copy file to container")
@@ -484,6 +671,22 @@ func (client *DockerClient) CopyFileToContainer(containerId string, localPath st
 	return nil
 }
 
+func (client *DockerClient) CopyFromContainer(ctx context.Context, opts *ContainerCopyOpts) error {
+
+	content, stat, err := client.docker.CopyFromContainer(ctx, opts.ContainerId, opts.ContainerPath)
+	if err != nil {
+		return errors.Wrap(err, "error copy from container")
+	}
+	defer content.Close()
+
+	// the archive entries are nested under the base name of the container path, either a file or a directory
+	progressReader := util.NewProgressReader(content, opts.OnProgressCallback)
+	if err := util.ExtractTar(progressReader, stat.Name, opts.LocalPath); err != nil {
+		return errors.Wrap(err, "error copy from container: extract archive")
+	}
+	return nil
+}
+
 func resolveLocalPath(localPath string) (absPath string, err error) {
 	if absPath, err = filepath.Abs(localPath); err != nil {
 		return
@@ -8,6 +8,7 @@ import (
 	"github.com/docker/docker/api/types/network"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 
+	"github.com/hckops/hckctl/pkg/client/terminal"
 	commonModel "github.com/hckops/hckctl/pkg/common/model"
 )
 
@@ -49,18 +50,17 @@ type ImageRemoveOpts struct {
 }
 
 type ContainerCreateOpts struct {
-	ContainerName                string
-	ContainerConfig              *container.Config
-	HostConfig                   *container.HostConfig
-	NetworkingConfig             *network.NetworkingConfig
-	Platform                     *ocispec.Platform
-	WaitStatus                   bool
-	CaptureInterrupt             bool
-	OnContainerInterruptCallback func(containerId string)
-	OnContainerCreateCallback    func(containerId string) error
-	OnContainerWaitCallback      func(containerId string) error
-	OnContainerStatusCallback    func(status string)
-	OnContainerStartCallback     func()
+	ContainerName             string
+	ContainerConfig           *container.Config
+	HostConfig                *container.HostConfig
+	NetworkingConfig          *network.NetworkingConfig
+	Platform                  *ocispec.Platform
+	WaitStatus                bool
+	OnContainerCreateCallback func(containerId string) error
+	OnContainerWaitCallback   func(containerId string) error
+	OnContainerStatusCallback func(status string)
+	OnContainerExitCallback   func(exitCode int) // required only with WaitStatus
+	OnContainerStartCallback  func()
 }
 
 type ContainerRestartOpts struct {
@@ -68,6 +68,13 @@ type ContainerRestartOpts struct {
 	OnRestartCallback func(string)
 }
 
+type ContainerCopyOpts struct {
+	ContainerId        string
+	LocalPath          string
+	ContainerPath      string
+	OnProgressCallback func(int64)
+}
+
 type ContainerExecOpts struct {
 	ContainerId             string
 	Commands                []string
@@ -75,6 +82,7 @@ type ContainerExecOpts struct {
 	OutStream               io.Writer
 	ErrStream               io.Writer
 	IsTty                   bool
+	Resize                  <-chan terminal.Size // optional, defaults to the size of InStream
 	OnContainerExecCallback func()
 	OnStreamCloseCallback   func()
 	OnStreamErrorCallback   func(error)
@@ -86,3 +94,12 @@ type ContainerLogsOpts struct {
 	OnStreamCloseCallback func()
 	OnStreamErrorCallback func(error)
 }
+
+type ContainerLogsFilterOpts struct {
+	ContainerId string
+	OutStream   io.Writer
+	ErrStream   io.Writer
+	Follow      bool
+	Since       string // duration relative to now e.g. "10m", empty for all
+	Tail        string // number of lines or "all"
+}
@@ -1,7 +1,6 @@
 package docker
 
 import (
-	"context"
 	"time"
 
 	"github.com/docker/docker/client"
@@ -12,7 +11,6 @@ const (
 )
 
 type DockerClient struct {
-	ctx    context.Context
 	docker *client.Client
 }
 
@@ -2,7 +2,9 @@ package kubernetes
 
 import (
 	"fmt"
+	"math"
 	"strconv"
+	"time"
 
 	"github.com/pkg/errors"
 	"golang.org/x/exp/maps"
@@ -116,6 +118,15 @@ func buildContainerPorts(ports []KubePort) ([]corev1.ContainerPort, error) {
 
 func int32Ptr(i int32) *int32 { return &i }
 
+// rounds up to the next second, nil if not set
+func activeDeadlineSeconds(timeout time.Duration) *int64 {
+	if timeout <= 0 {
+		return nil
+	}
+	seconds := int64(math.Ceil(timeout.Seconds()))
+	return &seconds
+}
+
 func buildDeployment(objectMeta metav1.ObjectMeta, pod *corev1.Pod) *appsv1.Deployment {
 
 	return &appsv1.Deployment{
@@ -193,12 +204,15 @@ func BuildJob(opts *JobOpts) *batchv1.Job {
 							Command:         []string{}, // override entrypoint
 							Args:            opts.PodInfo.Arguments,
 							Env:             buildEnvVars(opts.PodInfo.Env),
+							Stdin:           opts.Stdin,
+							StdinOnce:       opts.Stdin,
 						},
 					},
 					RestartPolicy: corev1.RestartPolicyNever,
 				},
 			},
-			BackoffLimit: int32Ptr(0), // attempt only once
+			BackoffLimit:          int32Ptr(0), // attempt only once
+			ActiveDeadlineSeconds: activeDeadlineSeconds(opts.Timeout),
 		},
 	}
 }
@@ -2,6 +2,7 @@ package kubernetes
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -226,3 +227,39 @@ status: {}
 
 	assert.YAMLEqf(t, expectedJob, ObjectToYaml(actualJob), "unexpected job")
 }
+
+func TestBuildJobStdin(t *testing.T) {
+	jobOpts := &JobOpts{
+		Namespace: "my-namespace",
+		Name:      "my-job",
+		PodInfo: &PodInfo{
+			ContainerName: "my-container",
+			ImageName:     "my-image",
+			Env:           []KubeEnv{},
+			Resource:      &KubeResource{},
+		},
+		Stdin: true,
+	}
+
+	container := BuildJob(jobOpts).Spec.Template.Spec.Containers[0]
+	assert.True(t, container.Stdin)
+	assert.True(t, container.StdinOnce)
+	assert.False(t, container.TTY)
+}
+
+func TestBuildJobTimeout(t *testing.T) {
+	jobOpts := &JobOpts{
+		Namespace: "my-namespace",
+		Name:      "my-job",
+		PodInfo: &PodInfo{
+			ContainerName: "my-container",
+			ImageName:     "my-image",
+			Resource:      &KubeResource{},
+		},
+		Timeout: 1500 * time.Millisecond,
+	}
+	assert.Equal(t, int64(2), *BuildJob(jobOpts).Spec.ActiveDeadlineSeconds)
+
+	jobOpts.Timeout = 0
+	assert.Nil(t, BuildJob(jobOpts).Spec.ActiveDeadlineSeconds)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"path"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -29,29 +30,30 @@ import (
 	"k8s.io/client-go/tools/portforward"
 	"k8s.io/client-go/tools/remotecommand"
 	"k8s.io/client-go/transport/spdy"
+	utilexec "k8s.io/client-go/util/exec"
 	"k8s.io/client-go/util/homedir"
 	"k8s.io/kubectl/pkg/cmd/exec"
 	"k8s.io/kubectl/pkg/scheme"
+	"k8s.io/kubectl/pkg/util/term"
 
+	"github.com/hckops/hckctl/pkg/client/terminal"
 	"github.com/hckops/hckctl/pkg/util"
 )
 
 func NewKubeClient(inCluster bool, configPath string) (*KubeClient, error) {
-	ctx := context.Background()
 	if inCluster {
-		return newInClusterKubeClient(ctx)
+		return newInClusterKubeClient()
 	} else {
-		return newOutOfClusterKubeClient(ctx, configPath)
+		return newOutOfClusterKubeClient(configPath)
 	}
 }
 
-func newOutOfClusterKubeClient(ctx context.Context, configPath string) (*KubeClient, error) {
+func newOutOfClusterKubeClient(configPath string) (*KubeClient, error) {
 	restConfig, clientSet, err := NewOutOfClusterKubeConfig(configPath)
 	if err != nil {
 		return nil, err
 	}
 	return &KubeClient{
-		ctx:            ctx,
 		kubeRestConfig: restConfig,
 		kubeClientSet:  clientSet,
 	}, nil
@@ -83,13 +85,12 @@ func NormalizeKubeConfig(configPath string) string {
 	}
 }
 
-func newInClusterKubeClient(ctx context.Context) (*KubeClient, error) {
+func newInClusterKubeClient() (*KubeClient, error) {
 	restConfig, clientSet, err := NewInClusterKubeConfig()
 	if err != nil {
 		return nil, err
 	}
 	return &KubeClient{
-		ctx:            ctx,
 		kubeRestConfig: restConfig,
 		kubeClientSet:  clientSet,
 	}, nil
@@ -130,33 +131,34 @@ func (client *KubeClient) BatchApi() batch.BatchV1Interface {
 	return client.kubeClientSet.BatchV1()
 }
 
-func (client *KubeClient) NamespaceApply(name string) error {
+func (client *KubeClient) NamespaceApply(ctx context.Context, name string) error {
 
 	// https://github.com/kubernetes/client-go/issues/1036
-	_, err := client.CoreApi().Namespaces().Apply(client.ctx, applyv1.Namespace(name), metav1.ApplyOptions{FieldManager: "application/apply-patch"})
+	_, err := client.CoreApi().Namespaces().Apply(ctx, applyv1.Namespace(name), metav1.ApplyOptions{FieldManager: "application/apply-patch"})
 	if err != nil {
 		return errors.Wrapf(err, "error namespace apply: name=%s", name)
 	}
 	return nil
 }
 
-func (client *KubeClient) NamespaceDelete(name string) error {
+func (client *KubeClient) NamespaceDelete(ctx context.Context, name string) error {
 
-	if err := client.CoreApi().Namespaces().Delete(client.ctx, name, metav1.DeleteOptions{}); err != nil {
+	if err := client.CoreApi().Namespaces().Delete(ctx, name, metav1.DeleteOptions{}); err != nil {
 		return errors.Wrapf(err, "error namespace delete: name=%s", name)
 	}
 	return nil
 }
 
-func (client *KubeClient) DeploymentCreate(opts *DeploymentCreateOpts) error {
+func (client *KubeClient) DeploymentCreate(ctx context.Context, opts *DeploymentCreateOpts) error {
 
-	deployment, err := client.AppApi().Deployments(opts.Namespace).Create(client.ctx, opts.Spec, metav1.CreateOptions{})
+	deployment, err := client.AppApi().Deployments(opts.Namespace).Create(ctx, opts.Spec, metav1.CreateOptions{})
 	if err != nil {
 		return errors.Wrapf(err, "error deployment create: namespace=%s name=%s", opts.Namespace, opts.Spec.Name)
 	}
+	opts.OnCreateCallback()
 
 	// blocks until the deployment is available, then stop watching
-	watcher, err := client.AppApi().Deployments(opts.Namespace).Watch(client.ctx, metav1.SingleObject(deployment.ObjectMeta))
+	watcher, err := client.AppApi().Deployments(opts.Namespace).Watch(ctx, metav1.SingleObject(deployment.ObjectMeta))
 	if err != nil {
 		return errors.Wrapf(err, "error deployment watch: namespace=%s name=%s", opts.Namespace, deployment.Name)
 	}
@@ -178,12 +180,16 @@ func (client *KubeClient) DeploymentCreate(opts *DeploymentCreateOpts) error {
 			return errors.Wrapf(err, "error deployment event: type=%v", event.Type)
 		}
 	}
+	// the watcher is closed also when the context is cancelled
+	if err := ctx.Err(); err != nil {
+		return errors.Wrapf(err, "error deployment watch: namespace=%s name=%s", opts.Namespace, deployment.Name)
+	}
 	return nil
 }
 
-func (client *KubeClient) DeploymentList(namespace string, namePrefix string, labelSelector string) ([]DeploymentInfo, error) {
+func (client *KubeClient) DeploymentList(ctx context.Context, namespace string, namePrefix string, labelSelector string) ([]DeploymentInfo, error) {
 
-	deployments, err := client.AppApi().Deployments(namespace).List(client.ctx, metav1.ListOptions{
+	deployments, err := client.AppApi().Deployments(namespace).List(ctx, metav1.ListOptions{
 		// comma separated values with format <LABEL_KEY>=<SANITIZED_LABEL_VALUE>
 		LabelSelector: labelSelector,
 	})
@@ -199,7 +205,7 @@ func (client *KubeClient) DeploymentList(namespace string, namePrefix string, la
 			continue
 		}
 
-		podInfo, err := client.PodDescribeFromDeployment(&deployment)
+		podInfo, err := client.PodDescribeFromDeployment(ctx, &deployment)
 		if err != nil {
 			// skip invalid pod
 			continue
@@ -234,14 +240,14 @@ func isDeploymentHealthy(status appsv1.DeploymentStatus) bool {
 	return healthy
 }
 
-func (client *KubeClient) DeploymentDescribe(namespace string, name string) (*DeploymentDetails, error) {
+func (client *KubeClient) DeploymentDescribe(ctx context.Context, namespace string, name string) (*DeploymentDetails, error) {
 
-	deployment, err := client.AppApi().Deployments(namespace).Get(client.ctx, name, metav1.GetOptions{})
+	deployment, err := client.AppApi().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
 		return nil, errors.Wrapf(err, "error deployment describe: namespace=%s name=%s", namespace, name)
 	}
 
-	podInfo, err := client.PodDescribeFromDeployment(deployment)
+	podInfo, err := client.PodDescribeFromDeployment(ctx, deployment)
 	if err != nil {
 		return nil, err
 	}
@@ -258,27 +264,27 @@ func newDeploymentDetails(deployment *appsv1.Deployment, podInfo *PodInfo) *Depl
 	}
 }
 
-func (client *KubeClient) DeploymentDelete(namespace string, name string) error {
+func (client *KubeClient) DeploymentDelete(ctx context.Context, namespace string, name string) error {
 
-	err := client.AppApi().Deployments(namespace).Delete(client.ctx, name, metav1.DeleteOptions{})
+	err := client.AppApi().Deployments(namespace).Delete(ctx, name, metav1.DeleteOptions{})
 	if err != nil {
 		return errors.Wrapf(err, "error deployment delete: namespace=%s name=%s", namespace, name)
 	}
 	return nil
 }
 
-func (client *KubeClient) ServiceCreate(namespace string, spec *corev1.Service) error {
+func (client *KubeClient) ServiceCreate(ctx context.Context, namespace string, spec *corev1.Service) error {
 
-	_, err := client.CoreApi().Services(namespace).Create(client.ctx, spec, metav1.CreateOptions{})
+	_, err := client.CoreApi().Services(namespace).Create(ctx, spec, metav1.CreateOptions{})
 	if err != nil {
 		return errors.Wrapf(err, "error service create: namespace=%s name=%s", namespace, spec.Name)
 	}
 	return nil
 }
 
-func (client *KubeClient) ServiceDescribe(namespace string, name string) (*ServiceInfo, error) {
+func (client *KubeClient) ServiceDescribe(ctx context.Context, namespace string, name string) (*ServiceInfo, error) {
 
-	service, err := client.CoreApi().Services(namespace).Get(client.ctx, name, metav1.GetOptions{})
+	service, err := client.CoreApi().Services(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
 		return nil, errors.Wrapf(err, "error service describe: namespace=%s name=%s", namespace, name)
 	}
@@ -299,26 +305,26 @@ func newServiceInfo(service *corev1.Service) *ServiceInfo {
 	}
 }
 
-func (client *KubeClient) ServiceDelete(namespace string, name string) error {
+func (client *KubeClient) ServiceDelete(ctx context.Context, namespace string, name string) error {
 
-	if err := client.CoreApi().Services(namespace).Delete(client.ctx, name, metav1.DeleteOptions{}); err != nil {
+	if err := client.CoreApi().Services(namespace).Delete(ctx, name, metav1.DeleteOptions{}); err != nil {
 		return errors.Wrapf(err, "error service delete: namespace=%s name=%s", namespace, name)
 	}
 	return nil
 }
 
-func (client *KubeClient) PodDescribeFromDeployment(deployment *appsv1.Deployment) (*PodInfo, error) {
+func (client *KubeClient) PodDescribeFromDeployment(ctx context.Context, deployment *appsv1.Deployment) (*PodInfo, error) {
 	labelSet := labels.Set(deployment.Spec.Selector.MatchLabels)
 	listOptions := metav1.ListOptions{
 		LabelSelector: labelSet.AsSelector().String(),
 	}
 
-	return client.podDescribe(deployment.Namespace, listOptions)
+	return client.podDescribe(ctx, deployment.Namespace, listOptions)
 }
 
-func (client *KubeClient) podDescribe(namespace string, listOptions metav1.ListOptions) (*PodInfo, error) {
+func (client *KubeClient) podDescribe(ctx context.Context, namespace string, listOptions metav1.ListOptions) (*PodInfo, error) {
 
-	pods, err := client.CoreApi().Pods(namespace).List(client.ctx, listOptions)
+	pods, err := client.CoreApi().Pods(namespace).List(ctx, listOptions)
 	if err != nil {
 		return nil, errors.Wrapf(err, "error pod describe: namespace=%s labels=%v", namespace, listOptions.LabelSelector)
 	}
@@ -364,7 +370,7 @@ func newPodInfo(namespace string, pods *corev1.PodList) (*PodInfo, error) {
 	}, nil
 }
 
-func (client *KubeClient) PodPortForward(opts *PodPortForwardOpts) error {
+func (client *KubeClient) PodPortForward(ctx context.Context, opts *PodPortForwardOpts) error {
 
 	restRequest := client.CoreApi().RESTClient().
 		Post().
@@ -379,12 +385,16 @@ func (client *KubeClient) PodPortForward(opts *PodPortForwardOpts) error {
 	}
 	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, restRequest.URL())
 
-	stopChannel := client.ctx.Done()
+	stopChannel := ctx.Done()
 	readyChannel := make(chan struct{}, 1)
 	out := new(bytes.Buffer)
 	errOut := new(bytes.Buffer)
 
-	forwarder, err := portforward.New(dialer, opts.Ports, stopChannel, readyChannel, out, errOut)
+	addresses := opts.Addresses
+	if len(addresses) == 0 {
+		addresses = []string{"localhost"}
+	}
+	forwarder, err := portforward.NewOnAddresses(dialer, addresses, opts.Ports, stopChannel, readyChannel, out, errOut)
 	if err != nil {
 		return errors.Wrap(err, "error kube new portforward")
 	}
@@ -425,55 +435,167 @@ func (client *KubeClient) newRestRequestExec(opts *PodExecOpts, isTty bool) *res
 		VersionedParams(&corev1.PodExecOptions{
 			Container: opts.ContainerName,
 			Command:   opts.Commands,
-			Stdin:     true,
+			Stdin:     opts.InStream != nil,
 			Stdout:    true,
 			Stderr:    true,
 			TTY:       isTty,
 		}, scheme.ParameterCodec)
 }
 
-func (client *KubeClient) PodExecShell(opts *PodExecOpts) error {
+func (client *KubeClient) PodExecShell(ctx context.Context, opts *PodExecOpts) error {
 
 	streamOptions := exec.StreamOptions{
 		Stdin: true,
 		TTY:   opts.IsTty,
 		IOStreams: genericclioptions.IOStreams{
-			In:     opts.InStream,
-			Out:    opts.OutStream,
+			// detects the terminal of the wrapped streams e.g. a recorder
+			In:     terminal.Unwrap(opts.InStream),
+			Out:    terminal.Unwrap(opts.OutStream),
 			ErrOut: opts.ErrStream,
 		},
 	}
-	tty := streamOptions.SetupTTY()
 
+	var tty term.TTY
 	var sizeQueue remotecommand.TerminalSizeQueue
-	if tty.Raw {
-		sizeQueue = tty.MonitorSize(tty.GetSize())
+	var isTty bool
+	if opts.Resize != nil {
+		// the input is not a local terminal e.g. forwarded by a remote client, the size is provided explicitly
+		tty = term.TTY{In: opts.InStream, Out: opts.OutStream}
+		sizeQueue = &terminalSizeQueue{sizeChannel: opts.Resize}
+		isTty = opts.IsTty
+	} else {
+		tty = streamOptions.SetupTTY()
+		if tty.Raw {
+			sizeQueue = tty.MonitorSize(tty.GetSize())
+		}
+		isTty = tty.Raw && opts.IsTty
+	}
+	// SetupTTY replaces the streams with the standard ones, the wrappers are preserved
+	if _, ok := opts.InStream.(terminal.FileStream); ok {
+		streamOptions.In = opts.InStream
+	}
+	if _, ok := opts.OutStream.(terminal.FileStream); ok {
+		streamOptions.Out = opts.OutStream
+	}
+	if isTty {
+		// stderr is merged into stdout by the terminal
 		streamOptions.ErrOut = nil
 	}
 
 	// exec remote shell
 	execUrl := client.newRestRequestExec(opts, true).URL()
-	executor := exec.DefaultRemoteExecutor{}
+	executor, err := remotecommand.NewSPDYExecutor(client.RestApi(), http.MethodPost, execUrl)
+	if err != nil {
+		return errors.Wrap(err, "error pod exec executor")
+	}
+
+	// leaves the remote shell without waiting for it to exit
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if detached := terminal.DetachChannel(opts.InStream); detached != nil {
+		go func() {
+			select {
+			case <-detached:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+	}
 
 	opts.OnExecCallback()
 
 	fn := func() error {
-		isTty := tty.Raw && opts.IsTty
-		return executor.Execute(http.MethodPost, execUrl, client.RestApi(), streamOptions.In, streamOptions.Out, streamOptions.ErrOut, isTty, sizeQueue)
+		return executor.StreamWithContext(ctx, remotecommand.StreamOptions{
+			Stdin:             streamOptions.In,
+			Stdout:            streamOptions.Out,
+			Stderr:            streamOptions.ErrOut,
+			Tty:               isTty,
+			TerminalSizeQueue: sizeQueue,
+		})
 	}
-	if err := tty.Safe(fn); err != nil {
+	if err := tty.Safe(fn); err != nil && !terminal.IsDetached(opts.InStream) {
 		return errors.Wrap(err, "terminal session closed")
 	}
 	return nil
 }
 
-func (client *KubeClient) PodExecCommand(opts *PodExecOpts) error {
+// terminalSizeQueue adapts the terminal size changes to remotecommand
+type terminalSizeQueue struct {
+	sizeChannel <-chan terminal.Size
+}
+
+// Next blocks until the size changes and returns nil when the channel is closed
+func (queue *terminalSizeQueue) Next() *remotecommand.TerminalSize {
+	size, ok := <-queue.sizeChannel
+	if !ok {
+		return nil
+	}
+	return &remotecommand.TerminalSize{Width: size.Width, Height: size.Height}
+}
+
+func (client *KubeClient) PodExecCommand(ctx context.Context, opts *PodExecOpts) error {
 	isTty := false
 	execUrl := client.newRestRequestExec(opts, isTty).URL()
-	return (&exec.DefaultRemoteExecutor{}).Execute(http.MethodPost, execUrl, client.RestApi(), opts.InStream, opts.OutStream, opts.ErrStream, isTty, nil)
+	executor, err := remotecommand.NewSPDYExecutor(client.RestApi(), http.MethodPost, execUrl)
+	if err != nil {
+		return errors.Wrap(err, "error pod exec executor")
+	}
+	// the remote command is interrupted when the context is cancelled
+	return executor.StreamWithContext(ctx, remotecommand.StreamOptions{
+		Stdin:  opts.InStream,
+		Stdout: opts.OutStream,
+		Stderr: opts.ErrStream,
+		Tty:    isTty,
+	})
 }
 
-func (client *KubeClient) podLogsStream(opts *PodLogsOpts) (io.ReadCloser, error) {
+// PodExec runs a command without spawning a shell and returns the exit code
+func (client *KubeClient) PodExec(ctx context.Context, opts *PodExecOpts) (int, error) {
+	var err error
+	if opts.IsTty && opts.InStream != nil {
+		err = client.PodExecShell(ctx, opts)
+	} else {
+		opts.OnExecCallback()
+		err = client.PodExecCommand(ctx, opts)
+	}
+
+	var exitError utilexec.ExitError
+	if errors.As(err, &exitError) {
+		return exitError.ExitStatus(), nil
+	} else if err != nil {
+		return -1, errors.Wrap(err, "error pod exec")
+	}
+	return 0, nil
+}
+
+// PodAttachStdin blocks until the input is consumed, the container receives EOF only if "stdinOnce" is set
+func (client *KubeClient) PodAttachStdin(ctx context.Context, opts *PodAttachOpts) error {
+	attachUrl := client.CoreApi().RESTClient().
+		Post().
+		Namespace(opts.Namespace).
+		Resource("pods").
+		Name(opts.PodName).
+		SubResource("attach").
+		VersionedParams(&corev1.PodAttachOptions{
+			Container: opts.ContainerName,
+			Stdin:     true,
+			Stdout:    false,
+			Stderr:    false,
+			TTY:       false,
+		}, scheme.ParameterCodec).
+		URL()
+
+	executor, err := remotecommand.NewSPDYExecutor(client.RestApi(), http.MethodPost, attachUrl)
+	if err != nil {
+		return errors.Wrap(err, "error pod attach executor")
+	}
+	if err := executor.StreamWithContext(ctx, remotecommand.StreamOptions{Stdin: opts.InStream}); err != nil {
+		return errors.Wrap(err, "error pod attach")
+	}
+	return nil
+}
+
+func (client *KubeClient) podLogsStream(ctx context.Context, opts *PodLogsOpts) (io.ReadCloser, error) {
 
 	logOptions := &corev1.PodLogOptions{
 		Container: opts.ContainerName,
@@ -482,16 +604,16 @@ func (client *KubeClient) podLogsStream(opts *PodLogsOpts) (io.ReadCloser, error
 	outStream, err := client.CoreApi().
 		Pods(opts.Namespace).
 		GetLogs(opts.PodName, logOptions).
-		Stream(client.ctx)
+		Stream(ctx)
 	if err != nil {
 		return nil, errors.Wrapf(err, "error pod logs stream")
 	}
 	return outStream, nil
 }
 
-func (client *KubeClient) PodLogs(opts *PodLogsOpts) error {
+func (client *KubeClient) PodLogs(ctx context.Context, opts *PodLogsOpts) error {
 
-	outStream, err := client.podLogsStream(opts)
+	outStream, err := client.podLogsStream(ctx, opts)
 	if err != nil {
 		return err
 	}
@@ -504,9 +626,33 @@ func (client *KubeClient) PodLogs(opts *PodLogsOpts) error {
 	return nil
 }
 
-func (client *KubeClient) PodLogsTee(opts *PodLogsOpts, logFileName string) error {
+// PodLogsFilter blocks until the stream is finished, or interrupted if it follows the logs
+func (client *KubeClient) PodLogsFilter(ctx context.Context, opts *PodLogsFilterOpts) error {
+
+	logOptions := &corev1.PodLogOptions{
+		Container:    opts.ContainerName,
+		Follow:       opts.Follow,
+		SinceSeconds: opts.SinceSeconds,
+		TailLines:    opts.TailLines,
+	}
+	outStream, err := client.CoreApi().
+		Pods(opts.Namespace).
+		GetLogs(opts.PodName, logOptions).
+		Stream(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "error pod logs stream")
+	}
+	defer outStream.Close()
+
+	if _, err = io.Copy(opts.OutStream, outStream); err != nil {
+		return errors.Wrapf(err, "error pod logs copy")
+	}
+	return nil
+}
+
+func (client *KubeClient) PodLogsTee(ctx context.Context, opts *PodLogsOpts, logFileName string) error {
 
-	outStream, err := client.podLogsStream(opts)
+	outStream, err := client.podLogsStream(ctx, opts)
 	if err != nil {
 		return err
 	}
@@ -525,7 +671,7 @@ func (client *KubeClient) PodLogsTee(opts *PodLogsOpts, logFileName string) erro
 	return nil
 }
 
-func (client *KubeClient) CopyToPod(opts *CopyPodOpts) error {
+func (client *KubeClient) CopyToPod(ctx context.Context, opts *CopyPodOpts) error {
 
 	if util.PathNotExist(opts.LocalPath) {
 		return fmt.Errorf("error copy invalid localPath=%s", opts.LocalPath)
@@ -547,19 +693,52 @@ func (client *KubeClient) CopyToPod(opts *CopyPodOpts) error {
 		Namespace:      opts.Namespace,
 		PodName:        opts.PodName,
 		ContainerName:  opts.ContainerName,
-		Commands:       []string{"tar", "-xmf", "-", "-C", filepath.Dir(opts.RemotePath)},
-		InStream:       reader, // input stream reader
+		Commands:       []string{"tar", "-xmf", "-", "-C", path.Dir(opts.RemotePath)},
+		InStream:       io.NopCloser(util.NewProgressReader(reader, opts.OnProgressCallback)), // input stream reader
 		OutStream:      io.Discard,
 		ErrStream:      io.Discard,
 		IsTty:          false,
 		OnExecCallback: func() {},
 	}
-	if err := client.PodExecCommand(execArchive); err != nil {
+	if err := client.PodExecCommand(ctx, execArchive); err != nil {
 		return errors.Wrapf(err, "error copy archive")
 	}
 	return nil
 }
 
+func (client *KubeClient) CopyFromPod(ctx context.Context, opts *CopyPodOpts) error {
+
+	reader, writer := io.Pipe()
+	defer reader.Close()
+
+	// create and download archive: the entries are nested under the remote base name, either a file or a directory
+	go func() {
+		execArchive := &PodExecOpts{
+			Namespace:      opts.Namespace,
+			PodName:        opts.PodName,
+			ContainerName:  opts.ContainerName,
+			Commands:       []string{"tar", "-cf", "-", "-C", path.Dir(opts.RemotePath), path.Base(opts.RemotePath)},
+			InStream:       io.NopCloser(strings.NewReader("")),
+			OutStream:      writer, // output stream writer
+			ErrStream:      io.Discard,
+			IsTty:          false,
+			OnExecCallback: func() {},
+		}
+		if err := client.PodExecCommand(ctx, execArchive); err != nil {
+			writer.CloseWithError(errors.Wrapf(err, "error copy archive"))
+			return
+		}
+		writer.Close()
+	}()
+
+	// extract archive
+	progressReader := util.NewProgressReader(reader, opts.OnProgressCallback)
+	if err := util.ExtractTar(progressReader, path.Base(opts.RemotePath), opts.LocalPath); err != nil {
+		return errors.Wrapf(err, "error copy extract")
+	}
+	return nil
+}
+
 func buildJobLabelSelector(job *batchv1.Job) (metav1.ListOptions, error) {
 	labelMap, err := metav1.LabelSelectorAsMap(job.Spec.Selector)
 	if err != nil {
@@ -571,26 +750,20 @@ func buildJobLabelSelector(job *batchv1.Job) (metav1.ListOptions, error) {
 	return listOptions, nil
 }
 
-func (client *KubeClient) JobCreate(opts *JobCreateOpts) error {
+func (client *KubeClient) JobCreate(ctx context.Context, opts *JobCreateOpts) error {
 
-	job, err := client.BatchApi().Jobs(opts.Namespace).Create(client.ctx, opts.Spec, metav1.CreateOptions{})
+	job, err := client.BatchApi().Jobs(opts.Namespace).Create(ctx, opts.Spec, metav1.CreateOptions{})
 	if err != nil {
 		return errors.Wrapf(err, "error job create: namespace=%s name=%s", opts.Namespace, opts.Spec.Name)
 	}
-
-	// TODO move in the commands
-	if opts.CaptureInterrupt {
-		util.InterruptHandler(func() {
-			opts.OnContainerInterruptCallback(job.Name)
-		})
-	}
+	opts.OnCreateCallback()
 
 	// blocks until the job is ready, then stop watching
 	listOptions, err := buildJobLabelSelector(job)
 	if err != nil {
 		return err
 	}
-	watcher, err := client.CoreApi().Pods(opts.Namespace).Watch(client.ctx, listOptions)
+	watcher, err := client.CoreApi().Pods(opts.Namespace).Watch(ctx, listOptions)
 	if err != nil {
 		return errors.Wrapf(err, "error job watch: namespace=%s name=%s", opts.Namespace, job.Name)
 	}
@@ -608,12 +781,16 @@ func (client *KubeClient) JobCreate(opts *JobCreateOpts) error {
 			watcher.Stop()
 		}
 	}
+	// the watcher is closed also when the context is cancelled
+	if err := ctx.Err(); err != nil {
+		return errors.Wrapf(err, "error job watch: namespace=%s name=%s", opts.Namespace, job.Name)
+	}
 	return nil
 }
 
-func (client *KubeClient) JobDescribe(namespace string, name string) (*PodInfo, error) {
+func (client *KubeClient) JobDescribe(ctx context.Context, namespace string, name string) (*PodInfo, error) {
 
-	job, err := client.BatchApi().Jobs(namespace).Get(client.ctx, name, metav1.GetOptions{})
+	job, err := client.BatchApi().Jobs(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
 		return nil, errors.Wrapf(err, "error job describe: namespace=%s name=%s", namespace, name)
 	}
@@ -623,14 +800,64 @@ func (client *KubeClient) JobDescribe(namespace string, name string) (*PodInfo,
 		return nil, err
 	}
 
-	return client.podDescribe(job.Namespace, listOptions)
+	return client.podDescribe(ctx, job.Namespace, listOptions)
+}
+
+// PodExitCode returns the exit code of a terminated container, it fails if the container is still running
+func (client *KubeClient) PodExitCode(ctx context.Context, namespace string, podName string, containerName string) (int, error) {
+
+	pod, err := client.CoreApi().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+	if err != nil {
+		return -1, errors.Wrapf(err, "error pod status: namespace=%s name=%s", namespace, podName)
+	}
+	if exitCode, ok := containerExitCode(pod, containerName); ok {
+		return exitCode, nil
+	}
+	return -1, fmt.Errorf("container not terminated: namespace=%s podName=%s containerName=%s", namespace, podName, containerName)
+}
+
+func containerExitCode(pod *corev1.Pod, containerName string) (int, bool) {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == containerName && status.State.Terminated != nil {
+			return int(status.State.Terminated.ExitCode), true
+		}
+	}
+	return -1, false
+}
+
+// JobStatus returns the terminal condition of the job, kube might update it after the pod is terminated
+func (client *KubeClient) JobStatus(ctx context.Context, namespace string, name string) (JobStatus, error) {
+
+	job, err := client.BatchApi().Jobs(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return JobActive, errors.Wrapf(err, "error job status: namespace=%s name=%s", namespace, name)
+	}
+	return newJobStatus(job), nil
+}
+
+func newJobStatus(job *batchv1.Job) JobStatus {
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			return JobComplete
+		case batchv1.JobFailed, batchv1.JobFailureTarget:
+			if condition.Reason == "DeadlineExceeded" {
+				return JobDeadlineExceeded
+			}
+			return JobFailed
+		}
+	}
+	return JobActive
 }
 
-func (client *KubeClient) JobDelete(namespace string, name string) error {
+func (client *KubeClient) JobDelete(ctx context.Context, namespace string, name string) error {
 
 	// delete job and all pods
 	backgroundDeletion := metav1.DeletePropagationBackground
-	err := client.BatchApi().Jobs(namespace).Delete(client.ctx, name, metav1.DeleteOptions{
+	err := client.BatchApi().Jobs(namespace).Delete(ctx, name, metav1.DeleteOptions{
 		PropagationPolicy: &backgroundDeletion,
 	})
 	if err != nil {
@@ -640,24 +867,24 @@ func (client *KubeClient) JobDelete(namespace string, name string) error {
 	return nil
 }
 
-func (client *KubeClient) SecretCreate(namespace string, spec *corev1.Secret) error {
+func (client *KubeClient) SecretCreate(ctx context.Context, namespace string, spec *corev1.Secret) error {
 
-	_, err := client.CoreApi().Secrets(namespace).Create(client.ctx, spec, metav1.CreateOptions{})
+	_, err := client.CoreApi().Secrets(namespace).Create(ctx, spec, metav1.CreateOptions{})
 	if err != nil {
 		return errors.Wrapf(err, "error secret create: namespace=%s name=%s", namespace, spec.Name)
 	}
 	return nil
 }
 
-func (client *KubeClient) SecretDelete(namespace string, name string) (bool, error) {
+func (client *KubeClient) SecretDelete(ctx context.Context, namespace string, name string) (bool, error) {
 
-	_, err := client.CoreApi().Secrets(namespace).Get(client.ctx, name, metav1.GetOptions{})
+	_, err := client.CoreApi().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
 		// it means the secret doesn't exist
 		return false, nil
 	}
 
-	if err := client.CoreApi().Secrets(namespace).Delete(client.ctx, name, metav1.DeleteOptions{}); err != nil {
+	if err := client.CoreApi().Secrets(namespace).Delete(ctx, name, metav1.DeleteOptions{}); err != nil {
 		return false, errors.Wrapf(err, "error secret delete: namespace=%s name=%s", namespace, name)
 	}
 	return true, nil
@@ -7,9 +7,13 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/remotecommand"
+
+	"github.com/hckops/hckctl/pkg/client/terminal"
 )
 
 func TestNormalizeKubeConfig(t *testing.T) {
@@ -159,6 +163,25 @@ func TestNewPodInfo(t *testing.T) {
 	assert.Equal(t, expected, result)
 }
 
+func TestContainerExitCode(t *testing.T) {
+	pod := &corev1.Pod{
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "sidecar-share", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+				{Name: "myContainerName", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 2}}},
+			},
+		},
+	}
+	exitCode, ok := containerExitCode(pod, "myContainerName")
+	assert.True(t, ok)
+	assert.Equal(t, 2, exitCode)
+
+	_, ok = containerExitCode(pod, "sidecar-share")
+	assert.False(t, ok)
+	_, ok = containerExitCode(pod, "invalid")
+	assert.False(t, ok)
+}
+
 func TestNewPodInfoErrorReplica(t *testing.T) {
 	pods := &corev1.PodList{
 		Items: []corev1.Pod{
@@ -218,3 +241,26 @@ func TestNewServiceInfo(t *testing.T) {
 
 	assert.Equal(t, serviceInfo, newServiceInfo(service))
 }
+
+func TestTerminalSizeQueue(t *testing.T) {
+	sizeChannel := make(chan terminal.Size, 1)
+	queue := &terminalSizeQueue{sizeChannel: sizeChannel}
+
+	sizeChannel <- terminal.Size{Width: 80, Height: 24}
+	assert.Equal(t, &remotecommand.TerminalSize{Width: 80, Height: 24}, queue.Next())
+
+	close(sizeChannel)
+	assert.Nil(t, queue.Next())
+}
+
+func TestNewJobStatus(t *testing.T) {
+	newJob := func(conditions ...batchv1.JobCondition) *batchv1.Job {
+		return &batchv1.Job{Status: batchv1.JobStatus{Conditions: conditions}}
+	}
+	assert.Equal(t, JobActive, newJobStatus(newJob()))
+	assert.Equal(t, JobActive, newJobStatus(newJob(batchv1.JobCondition{Type: batchv1.JobComplete, Status: corev1.ConditionFalse})))
+	assert.Equal(t, JobComplete, newJobStatus(newJob(batchv1.JobCondition{Type: batchv1.JobComplete, Status: corev1.ConditionTrue})))
+	assert.Equal(t, JobFailed, newJobStatus(newJob(batchv1.JobCondition{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Reason: "BackoffLimitExceeded"})))
+	assert.Equal(t, JobDeadlineExceeded, newJobStatus(newJob(batchv1.JobCondition{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Reason: "DeadlineExceeded"})))
+	assert.Equal(t, JobDeadlineExceeded, newJobStatus(newJob(batchv1.JobCondition{Type: batchv1.JobFailureTarget, Status: corev1.ConditionTrue, Reason: "DeadlineExceeded"})))
+}
@@ -2,9 +2,12 @@ package kubernetes
 
 import (
 	"io"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
+
+	"github.com/hckops/hckctl/pkg/client/terminal"
 )
 
 type ResourcesOpts struct {
@@ -19,12 +22,14 @@ type ResourcesOpts struct {
 type DeploymentCreateOpts struct {
 	Namespace             string
 	Spec                  *appsv1.Deployment
+	OnCreateCallback      func()
 	OnStatusEventCallback func(event string)
 }
 
 type PodPortForwardOpts struct {
 	Namespace             string
 	PodName               string
+	Addresses             []string // defaults to localhost
 	Ports                 []string // format "LOCAL:REMOTE"
 	IsWait                bool
 	OnTunnelStartCallback func()
@@ -40,9 +45,17 @@ type PodExecOpts struct {
 	OutStream      io.Writer
 	ErrStream      io.Writer
 	IsTty          bool
+	Resize         <-chan terminal.Size // optional, defaults to the size of InStream
 	OnExecCallback func()
 }
 
+type PodAttachOpts struct {
+	Namespace     string
+	PodName       string
+	ContainerName string
+	InStream      io.Reader
+}
+
 type PodLogsOpts struct {
 	Namespace     string
 	PodName       string
@@ -50,26 +63,38 @@ type PodLogsOpts struct {
 	OutStream     io.Writer
 }
 
+type PodLogsFilterOpts struct {
+	Namespace     string
+	PodName       string
+	ContainerName string
+	OutStream     io.Writer
+	Follow        bool
+	SinceSeconds  *int64 // nil for all
+	TailLines     *int64 // nil for all
+}
+
 type JobOpts struct {
 	Namespace   string
 	Name        string
 	Annotations map[string]string
 	Labels      map[string]string
 	PodInfo     *PodInfo
+	Stdin       bool          // waits for a single attach, see PodAttachStdin
+	Timeout     time.Duration // optional, the job is terminated by kube when exceeded
 }
 
 type JobCreateOpts struct {
-	Namespace                    string
-	Spec                         *batchv1.Job
-	CaptureInterrupt             bool
-	OnContainerInterruptCallback func(name string)
-	OnStatusEventCallback        func(event string)
+	Namespace             string
+	Spec                  *batchv1.Job
+	OnCreateCallback      func()
+	OnStatusEventCallback func(event string)
 }
 
 type CopyPodOpts struct {
-	Namespace     string
-	PodName       string
-	ContainerName string
-	LocalPath     string
-	RemotePath    string
+	Namespace          string
+	PodName            string
+	ContainerName      string
+	LocalPath          string
+	RemotePath         string
+	OnProgressCallback func(int64)
 }
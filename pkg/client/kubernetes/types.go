@@ -1,7 +1,6 @@
 package kubernetes
 
 import (
-	"context"
 	"time"
 
 	"k8s.io/client-go/kubernetes"
@@ -18,11 +17,20 @@ const (
 )
 
 type KubeClient struct {
-	ctx            context.Context
 	kubeRestConfig *rest.Config
 	kubeClientSet  *kubernetes.Clientset
 }
 
+// JobStatus is derived from the job conditions only
+type JobStatus int
+
+const (
+	JobActive JobStatus = iota // no terminal condition yet
+	JobComplete
+	JobFailed
+	JobDeadlineExceeded
+)
+
 type KubeResource struct {
 	Memory string
 	Cpu    string
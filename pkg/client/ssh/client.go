@@ -1,57 +1,152 @@
 package ssh
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
 	"net"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	gossh "golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
 
 	"github.com/hckops/hckctl/pkg/client/terminal"
 )
 
-func NewSshClient(config *SshClientConfig) (*SshClient, error) {
+// NewSshClient connects to the server, the context cancels only the initial connection
+func NewSshClient(ctx context.Context, config *SshClientConfig) (*SshClient, error) {
 
-	sshConfig := sshClientConfig(config)
-	client, err := gossh.Dial("tcp", config.Address, sshConfig)
+	sshClient, err := dial(ctx, config)
 	if err != nil {
 		return nil, errors.Wrap(err, "error ssh client")
 	}
 
-	return &SshClient{
-		ctx: context.Background(),
-		ssh: client,
-	}, nil
+	// connected is closed while a connection is available
+	connected := make(chan struct{})
+	close(connected)
+
+	// bound to the lifecycle of the client, not of the initial connection
+	clientCtx, cancel := context.WithCancel(context.Background())
+	client := &SshClient{
+		ctx:       clientCtx,
+		cancel:    cancel,
+		config:    config,
+		ssh:       sshClient,
+		connected: connected,
+	}
+	go client.monitor(sshClient)
+	go client.keepAlive()
+	return client, nil
 }
 
-// TODO ssh agent auth
-func sshClientConfig(config *SshClientConfig) *gossh.ClientConfig {
+func sshClientConfig(config *SshClientConfig) (*gossh.ClientConfig, func(), error) {
+
+	hostKeyCallback, err := newHostKeyCallback(config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	authMethods, closeAuth, err := newAuthMethods(config)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	sshConfig := &gossh.ClientConfig{
-		User: config.Username,
-		Auth: []gossh.AuthMethod{
-			gossh.Password(config.Token),
-		},
-		HostKeyCallback: gossh.InsecureIgnoreHostKey(), // TODO remove
+		User:            config.Username,
+		Auth:            authMethods,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         config.dialTimeout(),
+	}
+	return sshConfig, closeAuth, nil
+}
+
+// methods are attempted in order: agent, private key and token
+func newAuthMethods(config *SshClientConfig) ([]gossh.AuthMethod, func(), error) {
+	var authMethods []gossh.AuthMethod
+	closeAuth := func() {}
+
+	if config.UseAgent {
+		socket := os.Getenv(sshAuthSockEnv)
+		if socket == "" {
+			return nil, nil, fmt.Errorf("ssh agent not available: %s is not set", sshAuthSockEnv)
+		}
+		connection, err := net.Dial("unix", socket)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "error connecting to ssh agent: socket=%s", socket)
+		}
+		closeAuth = func() { connection.Close() }
+		authMethods = append(authMethods, gossh.PublicKeysCallback(agent.NewClient(connection).Signers))
 	}
-	return sshConfig
+
+	if config.PrivateKeyPath != "" {
+		signer, err := loadPrivateKey(config.PrivateKeyPath)
+		if err != nil {
+			closeAuth()
+			return nil, nil, err
+		}
+		authMethods = append(authMethods, gossh.PublicKeys(signer))
+	}
+
+	if config.Token != "" {
+		authMethods = append(authMethods, gossh.Password(config.Token))
+	}
+
+	if len(authMethods) == 0 {
+		return nil, nil, errors.New("missing ssh auth: agent, private key or token required")
+	}
+	return authMethods, closeAuth, nil
 }
 
+func loadPrivateKey(path string) (gossh.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading private key: path=%s", path)
+	}
+	signer, err := gossh.ParsePrivateKey(data)
+	var passphraseError *gossh.PassphraseMissingError
+	if errors.As(err, &passphraseError) {
+		return nil, fmt.Errorf("private key is encrypted, add it to the ssh agent instead: path=%s", path)
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "error parsing private key: path=%s", path)
+	}
+	return signer, nil
+}
+
+// Close stops keepalives and reconnections, all the active tunnels are stopped
 func (client *SshClient) Close() error {
+	client.cancel()
+
+	client.mutex.RLock()
+	defer client.mutex.RUnlock()
 	return client.ssh.Close()
 }
 
-func (client *SshClient) SendRequest(protocol string, payload string) (string, error) {
-	// "wantReply" must be true to get a response
-	ok, response, err := client.ssh.SendRequest(protocol, true, []byte(payload))
+func (client *SshClient) SendRequest(ctx context.Context, protocol string, payload string) (string, error) {
+	return client.SendRequestTimeout(ctx, protocol, payload, client.config.requestTimeout())
+}
+
+// SendRequestTimeout is never retried after a reconnection, requests are not guaranteed to be idempotent
+func (client *SshClient) SendRequestTimeout(ctx context.Context, protocol string, payload string, timeout time.Duration) (string, error) {
+	current, err := client.connection(ctx)
+	if err != nil {
+		return "", errors.Wrapf(err, "error ssh connection")
+	}
+
+	// global requests are serialized, keepalives are paused until the reply
+	client.requests.Add(1)
+	client.pending.Add(1)
+	defer client.pending.Add(-1)
+
+	ok, response, err := sendRequest(ctx, current, protocol, []byte(payload), timeout)
 	if !ok {
 		if err != nil {
 			return "", errors.Wrapf(err, "error ssh send request")
 		} else if strings.TrimSpace(string(response)) != "" {
-			return "", fmt.Errorf("error ssh server response %s", response)
+			return "", &RequestError{response: string(response)}
 		} else {
 			return "", errors.New("error ssh invalid request")
 		}
@@ -59,41 +154,185 @@ func (client *SshClient) SendRequest(protocol string, payload string) (string, e
 	return string(response), nil
 }
 
-func (client *SshClient) Exec(opts *SshExecOpts) error {
+func (client *SshClient) Exec(ctx context.Context, opts *SshExecOpts) error {
 
-	session, err := client.ssh.NewSession()
+	session, err := client.newSession(ctx)
 	if err != nil {
-		return errors.Wrapf(err, "error ssh new session")
+		return err
 	}
 	defer session.Close()
 
-	if err := handleStreams(session, opts.OnStreamErrorCallback); err != nil {
+	if err := handleStreams(session, opts); err != nil {
 		return errors.Wrapf(err, "error ssh stream")
 	}
 
-	rawTerminal, err := terminal.NewRawTerminal(os.Stdin)
+	rawTerminal, err := terminal.NewRawTerminal(opts.InStream)
 	if err != nil {
 		return errors.Wrap(err, "error ssh terminal")
 	}
 	defer rawTerminal.Restore()
 
+	if stopResize, err := client.requestPty(session, opts.InStream); err != nil {
+		// e.g. the server doesn't allow terminals, the local one restores the line editing
+		rawTerminal.Restore()
+		opts.onPtyError(err)
+	} else {
+		defer stopResize()
+	}
+
+	// leaves the remote session without waiting for it to exit, a nil channel never receives
+	stopClose := client.closeOnDone(ctx, session, terminal.DetachChannel(opts.InStream))
+	defer stopClose()
+
 	opts.OnStreamStartCallback()
 
+	if err := session.Run(opts.Payload); err != nil && err != io.EOF && !terminal.IsDetached(opts.InStream) {
+		return errors.Wrapf(contextError(ctx, err), "error ssh exec session")
+	}
+	return nil
+}
+
+// Stream exchanges raw data with the remote session, without allocating a terminal
+func (client *SshClient) Stream(ctx context.Context, opts *SshStreamOpts) error {
+
+	session, err := client.newSession(ctx)
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	stopClose := client.closeOnDone(ctx, session, nil)
+	defer stopClose()
+
+	errBuffer := new(bytes.Buffer)
+	session.Stdin = opts.InStream
+	session.Stdout = opts.OutStream
+	session.Stderr = errBuffer
+
 	if err := session.Run(opts.Payload); err != nil && err != io.EOF {
-		return errors.Wrapf(err, "error ssh exec session")
+		return errors.Wrapf(contextError(ctx, err), "error ssh stream session: %s", strings.TrimSpace(errBuffer.String()))
 	}
 	return nil
 }
 
-func handleStreams(session *gossh.Session, onStreamErrorCallback func(error)) error {
+// ExecCommand runs a command without spawning a shell and returns the exit code
+func (client *SshClient) ExecCommand(ctx context.Context, opts *SshCommandOpts) (int, error) {
+
+	session, err := client.newSession(ctx)
+	if err != nil {
+		return -1, err
+	}
+	defer session.Close()
+
+	stopClose := client.closeOnDone(ctx, session, nil)
+	defer stopClose()
+
+	session.Stdin = opts.InStream
+	session.Stdout = opts.OutStream
+	session.Stderr = opts.ErrStream
+
+	if opts.IsTty {
+		// ignore error if stdin is not a terminal e.g. pipe
+		rawTerminal, rawErr := terminal.NewRawTerminal(opts.InStream)
+		if rawErr == nil {
+			defer rawTerminal.Restore()
+		}
+		if stopResize, err := client.requestPty(session, opts.InStream); err != nil {
+			// e.g. the server doesn't allow terminals, the local one restores the line editing
+			if rawErr == nil {
+				rawTerminal.Restore()
+			}
+			opts.onPtyError(err)
+		} else {
+			defer stopResize()
+		}
+	}
+
+	opts.OnStreamStartCallback()
+
+	err = session.Run(opts.Payload)
+	var exitError *gossh.ExitError
+	if err != nil && ctx.Err() != nil {
+		return -1, errors.Wrapf(ctx.Err(), "error ssh exec command")
+	} else if errors.As(err, &exitError) {
+		return exitError.ExitStatus(), nil
+	} else if err != nil && err != io.EOF {
+		return -1, errors.Wrapf(err, "error ssh exec command")
+	}
+	return 0, nil
+}
+
+func (client *SshClient) newSession(ctx context.Context) (*gossh.Session, error) {
+	current, err := client.connection(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error ssh connection")
+	}
+	session, err := current.NewSession()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error ssh new session")
+	}
+	return session, nil
+}
+
+// closeOnDone closes the session when the context is cancelled or the extra channel is closed, until stopped
+func (client *SshClient) closeOnDone(ctx context.Context, session *gossh.Session, done <-chan struct{}) func() {
+	stopChannel := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			session.Close()
+		case <-done:
+			session.Close()
+		case <-client.ctx.Done():
+		case <-stopChannel:
+		}
+	}()
+	return func() { close(stopChannel) }
+}
+
+// contextError prefers the cancellation cause over the error of the closed session
+func contextError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// requestPty allocates a remote terminal with the size of the local one and propagates every change until stopped
+func (client *SshClient) requestPty(session *gossh.Session, in io.Reader) (func(), error) {
+	ctx, cancel := context.WithCancel(client.ctx)
+
+	size := terminal.Size{Width: defaultTerminalWidth, Height: defaultTerminalHeight}
+	sizeChannel := terminal.MonitorSize(ctx, in)
+	if sizeChannel != nil {
+		// the initial size is always sent
+		size = <-sizeChannel
+	}
+	if err := session.RequestPty(terminalType, int(size.Height), int(size.Width), gossh.TerminalModes{}); err != nil {
+		cancel()
+		return nil, errors.Wrap(err, "error ssh request pty")
+	}
+
+	if sizeChannel != nil {
+		go func() {
+			for size := range sizeChannel {
+				// ignore error e.g. the session is already closed
+				session.WindowChange(int(size.Height), int(size.Width))
+			}
+		}()
+	}
+	return cancel, nil
+}
+
+func handleStreams(session *gossh.Session, opts *SshExecOpts) error {
 
 	stdin, err := session.StdinPipe()
 	if err != nil {
 		return errors.Wrap(err, "error opening stdin pipe")
 	}
 	go func() {
-		if _, err := io.Copy(stdin, os.Stdin); err != nil {
-			onStreamErrorCallback(errors.Wrap(err, "error copy stdin local->remote"))
+		if _, err := io.Copy(stdin, opts.InStream); err != nil {
+			opts.OnStreamErrorCallback(errors.Wrap(err, "error copy stdin local->remote"))
 		}
 	}()
 
@@ -102,8 +341,8 @@ func handleStreams(session *gossh.Session, onStreamErrorCallback func(error)) er
 		return errors.Wrap(err, "error opening stdout pipe")
 	}
 	go func() {
-		if _, err := io.Copy(os.Stdout, stdout); err != nil {
-			onStreamErrorCallback(errors.Wrap(err, "error copy stdout remote->local"))
+		if _, err := io.Copy(opts.OutStream, stdout); err != nil {
+			opts.OnStreamErrorCallback(errors.Wrap(err, "error copy stdout remote->local"))
 		}
 	}()
 
@@ -112,51 +351,94 @@ func handleStreams(session *gossh.Session, onStreamErrorCallback func(error)) er
 		return errors.Wrap(err, "error opening stderr pipe")
 	}
 	go func() {
-		if _, err := io.Copy(os.Stderr, stderr); err != nil {
-			onStreamErrorCallback(errors.Wrap(err, "error copy stderr remote->local"))
+		if _, err := io.Copy(opts.ErrStream, stderr); err != nil {
+			opts.OnStreamErrorCallback(errors.Wrap(err, "error copy stderr remote->local"))
 		}
 	}()
 
 	return nil
 }
 
-func (client *SshClient) Tunnel(opts *SshTunnelOpts) {
+// Tunnel blocks until the local listener fails, the context is cancelled or the client is closed, connection errors are not fatal.
+// Remote connections are opened with the active connection, so the tunnel survives a reconnection
+func (client *SshClient) Tunnel(ctx context.Context, opts *SshTunnelOpts) error {
 
 	// starts a local server and forwards traffic to a remote connection
 	listener, err := net.Listen(opts.Network(), opts.LocalAddress())
 	if err != nil {
-		opts.OnTunnelErrorCallback(errors.Wrapf(err, "error ssh creating local tunnel: address=%s", opts.LocalAddress()))
+		return errors.Wrapf(err, "error ssh creating local tunnel: address=%s", opts.LocalAddress())
 	}
 	defer listener.Close()
 
-	copyStream := func(writer, reader net.Conn, label string) {
-		defer writer.Close()
-		defer reader.Close()
+	// accepts connections from now on
+	if opts.OnTunnelListenCallback != nil {
+		opts.OnTunnelListenCallback(listener.Addr().String())
+	}
 
-		opts.OnTunnelStartCallback(label)
-		_, err := io.Copy(writer, reader)
-		if err != nil {
-			opts.OnTunnelErrorCallback(errors.Wrapf(err, "error ssh copying stream: %s", label))
+	// unblocks accept
+	stopChannel := make(chan struct{})
+	defer close(stopChannel)
+	go func() {
+		select {
+		case <-ctx.Done():
+			listener.Close()
+		case <-client.ctx.Done():
+			listener.Close()
+		case <-stopChannel:
 		}
-		opts.OnTunnelStopCallback(label)
-	}
+	}()
 
 	for {
 		// blocks until a connection is opened
 		localConnection, err := listener.Accept()
 		if err != nil {
-			opts.OnTunnelErrorCallback(errors.Wrapf(err, "error ssh opening local tunnel: address=%s", opts.LocalAddress()))
+			if client.ctx.Err() != nil {
+				return client.tunnelCloseError()
+			} else if ctx.Err() != nil {
+				// stopped explicitly
+				return nil
+			}
+			return errors.Wrapf(err, "error ssh opening local tunnel: address=%s", opts.LocalAddress())
 		}
 
-		// forward connections
-		go func() {
-			remoteConnection, err := client.ssh.Dial(opts.Network(), opts.RemoteAddress())
-			if err != nil {
-				opts.OnTunnelErrorCallback(errors.Wrapf(err, "error ssh opening remote tunnel: address=%s", opts.RemoteAddress()))
-			}
+		go client.forward(ctx, localConnection, opts)
+	}
+}
 
-			go copyStream(localConnection, remoteConnection, "remote->local")
-			go copyStream(remoteConnection, localConnection, "local->remote")
-		}()
+// tunnelCloseError returns nil if the client was closed explicitly
+func (client *SshClient) tunnelCloseError() error {
+	client.mutex.RLock()
+	defer client.mutex.RUnlock()
+	return client.err
+}
+
+func (client *SshClient) forward(ctx context.Context, localConnection net.Conn, opts *SshTunnelOpts) {
+
+	copyStream := func(writer, reader net.Conn, label string) {
+		defer writer.Close()
+		defer reader.Close()
+
+		opts.OnTunnelStartCallback(label)
+		// the opposite stream closes both connections when it's done
+		if _, err := io.Copy(writer, reader); err != nil && !errors.Is(err, net.ErrClosed) {
+			opts.OnTunnelErrorCallback(errors.Wrapf(err, "error ssh copying stream: %s", label))
+		}
+		opts.OnTunnelStopCallback(label)
+	}
+
+	current, err := client.connection(ctx)
+	if err != nil {
+		opts.OnTunnelErrorCallback(errors.Wrapf(err, "error ssh connection"))
+		localConnection.Close()
+		return
 	}
+	remoteConnection, err := current.Dial(opts.Network(), opts.RemoteAddress())
+	if err != nil {
+		opts.OnTunnelErrorCallback(errors.Wrapf(err, "error ssh opening remote tunnel: address=%s", opts.RemoteAddress()))
+		localConnection.Close()
+		return
+	}
+
+	go copyStream(localConnection, remoteConnection, "remote->local")
+	go copyStream(remoteConnection, localConnection, "local->remote")
 }
@@ -1,9 +1,14 @@
 package ssh
 
 import (
+	"bytes"
+	"context"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestSshTunnelOptsNetwork(t *testing.T) {
@@ -17,6 +22,14 @@ func TestSshTunnelOptsLocalAddress(t *testing.T) {
 	assert.Equal(t, "0.0.0.0:123", opts.LocalAddress())
 }
 
+func TestSshTunnelOptsLocalAddressHost(t *testing.T) {
+	opts := &SshTunnelOpts{
+		LocalHost: "127.0.0.1",
+		LocalPort: "123",
+	}
+	assert.Equal(t, "127.0.0.1:123", opts.LocalAddress())
+}
+
 func TestSshTunnelOptsRemoteAddress(t *testing.T) {
 	opts := &SshTunnelOpts{
 		RemoteHost: "myHost",
@@ -24,3 +37,47 @@ func TestSshTunnelOptsRemoteAddress(t *testing.T) {
 	}
 	assert.Equal(t, "myHost:456", opts.RemoteAddress())
 }
+
+func TestSshClientConfigDefaults(t *testing.T) {
+	config := &SshClientConfig{}
+	assert.Equal(t, 30*time.Second, config.keepAliveInterval())
+	assert.Equal(t, 30*time.Second, config.requestTimeout())
+	assert.Equal(t, 10*time.Second, config.dialTimeout())
+	assert.Equal(t, 5, config.reconnectAttempts())
+
+	disabled := &SshClientConfig{KeepAliveInterval: -1, ReconnectAttempts: -1}
+	assert.True(t, disabled.keepAliveInterval() < 0)
+	assert.True(t, disabled.reconnectAttempts() < 0)
+}
+
+func TestExecCommandPtyRejected(t *testing.T) {
+	address, fingerprint := newTestServer(t, 0)
+
+	client, err := NewSshClient(context.Background(), &SshClientConfig{
+		Address:     address,
+		Username:    "test",
+		Token:       "test",
+		Fingerprint: fingerprint,
+	})
+	require.NoError(t, err)
+	defer client.Close()
+
+	var ptyErr error
+	out := new(bytes.Buffer)
+	exitCode, err := client.ExecCommand(context.Background(), &SshCommandOpts{
+		Payload:               "hello",
+		InStream:              strings.NewReader(""),
+		OutStream:             out,
+		ErrStream:             new(bytes.Buffer),
+		IsTty:                 true,
+		OnStreamStartCallback: func() {},
+		OnPtyErrorCallback: func(err error) {
+			ptyErr = err
+		},
+	})
+	// runs without a terminal
+	assert.NoError(t, err)
+	assert.Equal(t, 0, exitCode)
+	assert.Equal(t, "hello", out.String())
+	assert.ErrorContains(t, ptyErr, "error ssh request pty")
+}
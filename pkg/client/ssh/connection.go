@@ -0,0 +1,219 @@
+package ssh
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/pkg/errors"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+const (
+	keepAliveRequestType = "keepalive@openssh.com"
+	reconnectBackoff     = 1 * time.Second
+	maxReconnectBackoff  = 30 * time.Second
+)
+
+// dial opens a new connection, the agent is required only during the handshake
+func dial(ctx context.Context, config *SshClientConfig) (*gossh.Client, error) {
+	sshConfig, closeAuth, err := sshClientConfig(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "error ssh client config")
+	}
+	defer closeAuth()
+
+	dialer := &net.Dialer{Timeout: sshConfig.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", config.Address)
+	if err != nil {
+		return nil, errors.Wrap(err, "error ssh dial")
+	}
+
+	// the handshake is not context aware, closing the connection interrupts it
+	stopChannel := make(chan struct{})
+	stoppedChannel := make(chan struct{})
+	go func() {
+		defer close(stoppedChannel)
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-stopChannel:
+		}
+	}()
+
+	clientConn, channels, requests, err := gossh.NewClientConn(conn, config.Address, sshConfig)
+	close(stopChannel)
+	<-stoppedChannel
+	if ctx.Err() != nil {
+		conn.Close()
+		return nil, errors.Wrap(ctx.Err(), "error ssh dial")
+	} else if err != nil {
+		conn.Close()
+		return nil, errors.Wrap(err, "error ssh dial")
+	}
+	return gossh.NewClient(clientConn, channels, requests), nil
+}
+
+// connection returns the active connection, waiting if a reconnection is in progress
+func (client *SshClient) connection(ctx context.Context) (*gossh.Client, error) {
+	for {
+		client.mutex.RLock()
+		current, connected := client.ssh, client.connected
+		select {
+		case <-connected:
+			client.mutex.RUnlock()
+			return current, nil
+		default:
+			client.mutex.RUnlock()
+		}
+
+		select {
+		case <-connected:
+			// reads again the new connection
+		case <-client.ctx.Done():
+			return nil, client.closeError()
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// closeError returns nil if the client was closed explicitly
+func (client *SshClient) closeError() error {
+	client.mutex.RLock()
+	defer client.mutex.RUnlock()
+
+	if client.err != nil {
+		return client.err
+	}
+	return errors.New("error ssh client closed")
+}
+
+// monitor blocks until the connection drops and replaces it, all pending operations are resumed with the new one
+func (client *SshClient) monitor(current *gossh.Client) {
+	for {
+		cause := current.Wait()
+		if client.ctx.Err() != nil {
+			return
+		}
+
+		client.mutex.Lock()
+		client.connected = make(chan struct{})
+		client.mutex.Unlock()
+
+		next, err := client.reconnect(cause)
+
+		client.mutex.Lock()
+		if err != nil {
+			client.err = err
+			client.mutex.Unlock()
+			// stops tunnels and pending operations
+			client.cancel()
+			return
+		}
+		if client.ctx.Err() != nil {
+			client.mutex.Unlock()
+			next.Close()
+			return
+		}
+		client.ssh = next
+		close(client.connected)
+		client.mutex.Unlock()
+
+		current = next
+	}
+}
+
+func (client *SshClient) reconnect(cause error) (*gossh.Client, error) {
+	attempts := client.config.reconnectAttempts()
+	backoff := reconnectBackoff
+
+	for attempt := 1; attempt <= attempts; attempt++ {
+		next, err := dial(client.ctx, client.config)
+		client.config.onReconnect(attempt, err)
+		if err == nil {
+			return next, nil
+		}
+
+		// never retry if the server can't be trusted
+		var hostKeyError *HostKeyError
+		if errors.As(err, &hostKeyError) {
+			return nil, err
+		}
+
+		select {
+		case <-client.ctx.Done():
+			return nil, client.ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxReconnectBackoff)
+	}
+	return nil, fmt.Errorf("error ssh reconnect: attempts=%d cause=%v", attempts, cause)
+}
+
+// keepAlive detects half-open connections e.g. after a network change and forces a reconnection.
+// Global requests are replied in order, a keepalive queued behind a slow request would time out
+// and close a healthy connection: it's skipped while any request is pending
+func (client *SshClient) keepAlive() {
+	interval := client.config.keepAliveInterval()
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-client.ctx.Done():
+			return
+		case <-ticker.C:
+			client.mutex.RLock()
+			current, connected := client.ssh, client.connected
+			client.mutex.RUnlock()
+
+			if client.pending.Load() > 0 {
+				continue
+			}
+			requests := client.requests.Load()
+
+			select {
+			case <-connected:
+				// any reply, even a failure, means the server is alive
+				_, _, err := sendRequest(client.ctx, current, keepAliveRequestType, nil, client.config.requestTimeout())
+				// a request sent in the meantime may be served first, it is responsible for its own timeout
+				if err != nil && client.requests.Load() == requests {
+					current.Close()
+				}
+			default:
+				// reconnection in progress
+			}
+		}
+	}
+}
+
+type requestResult struct {
+	ok       bool
+	response []byte
+	err      error
+}
+
+// sendRequest fails after the timeout or when the context is cancelled, a late reply is discarded
+func sendRequest(ctx context.Context, current *gossh.Client, name string, payload []byte, timeout time.Duration) (bool, []byte, error) {
+	// buffered to never block the sender after the timeout
+	resultChannel := make(chan requestResult, 1)
+	go func() {
+		// "wantReply" must be true to get a response
+		ok, response, err := current.SendRequest(name, true, payload)
+		resultChannel <- requestResult{ok: ok, response: response, err: err}
+	}()
+
+	select {
+	case result := <-resultChannel:
+		return result.ok, result.response, result.err
+	case <-time.After(timeout):
+		return false, nil, fmt.Errorf("timeout after %s", timeout)
+	case <-ctx.Done():
+		return false, nil, ctx.Err()
+	}
+}
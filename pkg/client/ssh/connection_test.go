@@ -0,0 +1,152 @@
+package ssh
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+const slowRequestType = "hck-test-slow"
+
+// newTestServer replies to the global requests one at a time, like the api server,
+// and echoes the payload of the exec sessions always rejecting the terminals
+func newTestServer(t *testing.T, slowDelay time.Duration) (string, string) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := gossh.NewSignerFromKey(privateKey)
+	require.NoError(t, err)
+
+	config := &gossh.ServerConfig{
+		PasswordCallback: func(gossh.ConnMetadata, []byte) (*gossh.Permissions, error) {
+			return nil, nil
+		},
+	}
+	config.AddHostKey(signer)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			connection, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				serverConn, channels, requests, err := gossh.NewServerConn(connection, config)
+				if err != nil {
+					return
+				}
+				defer serverConn.Close()
+				go func() {
+					for newChannel := range channels {
+						if newChannel.ChannelType() != "session" {
+							newChannel.Reject(gossh.Prohibited, "not supported")
+							continue
+						}
+						channel, channelRequests, err := newChannel.Accept()
+						if err != nil {
+							continue
+						}
+						go handleTestSession(channel, channelRequests)
+					}
+				}()
+				for request := range requests {
+					if request.Type == slowRequestType {
+						time.Sleep(slowDelay)
+					}
+					request.Reply(request.Type == slowRequestType, nil)
+				}
+			}()
+		}
+	}()
+	return listener.Addr().String(), gossh.FingerprintSHA256(signer.PublicKey())
+}
+
+func handleTestSession(channel gossh.Channel, requests <-chan *gossh.Request) {
+	defer channel.Close()
+	for request := range requests {
+		switch request.Type {
+		case "exec":
+			var payload struct{ Command string }
+			gossh.Unmarshal(request.Payload, &payload)
+			request.Reply(true, nil)
+			channel.Write([]byte(payload.Command))
+			channel.SendRequest("exit-status", false, gossh.Marshal(struct{ Status uint32 }{0}))
+			return
+		default:
+			// e.g. pty-req
+			request.Reply(false, nil)
+		}
+	}
+}
+
+func TestKeepAliveSlowRequest(t *testing.T) {
+	address, fingerprint := newTestServer(t, 500*time.Millisecond)
+
+	client, err := NewSshClient(context.Background(), &SshClientConfig{
+		Address:           address,
+		Username:          "test",
+		Token:             "test",
+		Fingerprint:       fingerprint,
+		KeepAliveInterval: 20 * time.Millisecond,
+		RequestTimeout:    50 * time.Millisecond,
+		ReconnectAttempts: -1,
+	})
+	require.NoError(t, err)
+	defer client.Close()
+
+	// the keepalives never close the connection while the request is pending
+	_, err = client.SendRequestTimeout(context.Background(), slowRequestType, "", 5*time.Second)
+	assert.NoError(t, err)
+
+	// the keepalives resume after the reply
+	time.Sleep(100 * time.Millisecond)
+	_, err = client.SendRequestTimeout(context.Background(), slowRequestType, "", 5*time.Second)
+	assert.NoError(t, err)
+}
+
+func TestNewSshClientCancel(t *testing.T) {
+	// accepts the connection but never completes the handshake
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	errorChannel := make(chan error, 1)
+	go func() {
+		_, err := NewSshClient(ctx, &SshClientConfig{
+			Address:     listener.Addr().String(),
+			Username:    "test",
+			Token:       "test",
+			Fingerprint: "SHA256:test",
+		})
+		errorChannel <- err
+	}()
+
+	select {
+	case err := <-errorChannel:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(2 * time.Second):
+		t.Fatal("client not cancelled")
+	}
+}
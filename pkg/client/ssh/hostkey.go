@@ -0,0 +1,102 @@
+package ssh
+
+import (
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/pkg/errors"
+	gossh "golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/hckops/hckctl/pkg/util"
+)
+
+// HostKeyError is returned when the server presents a key different from the trusted one
+type HostKeyError struct {
+	Host           string
+	Expected       string
+	Actual         string
+	KnownHostsPath string // empty if the fingerprint is pinned
+}
+
+func (e *HostKeyError) Error() string {
+	message := fmt.Sprintf("host key changed for %s: expected=%s actual=%s, possible man-in-the-middle attack", e.Host, e.Expected, e.Actual)
+	if e.KnownHostsPath != "" {
+		return fmt.Sprintf("%s, remove the entry from %s only if the server key was rotated", message, e.KnownHostsPath)
+	}
+	return fmt.Sprintf("%s, update the pinned fingerprint only if the server key was rotated", message)
+}
+
+// a pinned fingerprint takes precedence over the known hosts file
+func newHostKeyCallback(config *SshClientConfig) (gossh.HostKeyCallback, error) {
+	if config.Fingerprint != "" {
+		return pinnedHostKeyCallback(config.Fingerprint), nil
+	}
+	if config.KnownHostsPath == "" {
+		return nil, errors.New("missing host key verification: known hosts path or fingerprint required")
+	}
+	return knownHostsCallback(config.KnownHostsPath, config.onTrustHost)
+}
+
+func pinnedHostKeyCallback(fingerprint string) gossh.HostKeyCallback {
+	return func(hostname string, remote net.Addr, key gossh.PublicKey) error {
+		if actual := gossh.FingerprintSHA256(key); actual != fingerprint {
+			return &HostKeyError{Host: hostname, Expected: fingerprint, Actual: actual}
+		}
+		return nil
+	}
+}
+
+// knownHostsCallback trusts unknown hosts on first use and fails if a known host key changes,
+// the fingerprint of a new host is always reported to allow a manual verification
+func knownHostsCallback(path string, onTrustHost func(string, string)) (gossh.HostKeyCallback, error) {
+	if err := util.CreateBaseDir(path); err != nil {
+		return nil, errors.Wrapf(err, "error creating known hosts dir: path=%s", path)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDONLY, 0600)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error opening known hosts: path=%s", path)
+	}
+	file.Close()
+
+	callback, err := knownhosts.New(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error parsing known hosts: path=%s", path)
+	}
+
+	return func(hostname string, remote net.Addr, key gossh.PublicKey) error {
+		err := callback(hostname, remote, key)
+
+		var keyError *knownhosts.KeyError
+		if errors.As(err, &keyError) {
+			if len(keyError.Want) == 0 {
+				if err := appendKnownHost(path, hostname, key); err != nil {
+					return err
+				}
+				onTrustHost(hostname, gossh.FingerprintSHA256(key))
+				return nil
+			}
+			return &HostKeyError{
+				Host:           hostname,
+				Expected:       gossh.FingerprintSHA256(keyError.Want[0].Key),
+				Actual:         gossh.FingerprintSHA256(key),
+				KnownHostsPath: path,
+			}
+		}
+		return err
+	}, nil
+}
+
+func appendKnownHost(path string, hostname string, key gossh.PublicKey) error {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.Wrapf(err, "error opening known hosts: path=%s", path)
+	}
+	defer file.Close()
+
+	if _, err := fmt.Fprintln(file, knownhosts.Line([]string{hostname}, key)); err != nil {
+		return errors.Wrapf(err, "error adding known host: host=%s", hostname)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package ssh
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+func newTestPublicKey(t *testing.T) gossh.PublicKey {
+	publicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	sshKey, err := gossh.NewPublicKey(publicKey)
+	require.NoError(t, err)
+	return sshKey
+}
+
+func TestNewHostKeyCallbackMissing(t *testing.T) {
+	_, err := newHostKeyCallback(&SshClientConfig{})
+	assert.EqualError(t, err, "missing host key verification: known hosts path or fingerprint required")
+}
+
+func TestPinnedHostKeyCallback(t *testing.T) {
+	key := newTestPublicKey(t)
+	otherKey := newTestPublicKey(t)
+	remote := &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 2222}
+
+	callback, err := newHostKeyCallback(&SshClientConfig{Fingerprint: gossh.FingerprintSHA256(key), KnownHostsPath: "/invalid"})
+	require.NoError(t, err)
+
+	assert.NoError(t, callback("127.0.0.1:2222", remote, key))
+
+	var hostKeyError *HostKeyError
+	err = callback("127.0.0.1:2222", remote, otherKey)
+	require.ErrorAs(t, err, &hostKeyError)
+	assert.Equal(t, gossh.FingerprintSHA256(otherKey), hostKeyError.Actual)
+	assert.Contains(t, err.Error(), "update the pinned fingerprint")
+}
+
+func TestKnownHostsCallback(t *testing.T) {
+	key := newTestPublicKey(t)
+	otherKey := newTestPublicKey(t)
+	remote := &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 2222}
+	knownHostsPath := filepath.Join(t.TempDir(), "config", "known_hosts")
+
+	// trust on first use
+	var trusted []string
+	onTrustHost := func(host string, fingerprint string) {
+		trusted = append(trusted, host, fingerprint)
+	}
+	firstCallback, err := newHostKeyCallback(&SshClientConfig{KnownHostsPath: knownHostsPath, OnTrustHostCallback: onTrustHost})
+	require.NoError(t, err)
+	assert.NoError(t, firstCallback("127.0.0.1:2222", remote, key))
+	assert.Equal(t, []string{"127.0.0.1:2222", gossh.FingerprintSHA256(key)}, trusted)
+
+	data, err := os.ReadFile(knownHostsPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "[127.0.0.1]:2222 ssh-ed25519 ")
+
+	// reloads the persisted entry
+	callback, err := newHostKeyCallback(&SshClientConfig{KnownHostsPath: knownHostsPath, OnTrustHostCallback: onTrustHost})
+	require.NoError(t, err)
+	assert.NoError(t, callback("127.0.0.1:2222", remote, key))
+	assert.Len(t, trusted, 2)
+
+	var hostKeyError *HostKeyError
+	err = callback("127.0.0.1:2222", remote, otherKey)
+	require.ErrorAs(t, err, &hostKeyError)
+	assert.Equal(t, gossh.FingerprintSHA256(key), hostKeyError.Expected)
+	assert.Equal(t, gossh.FingerprintSHA256(otherKey), hostKeyError.Actual)
+	assert.Equal(t, knownHostsPath, hostKeyError.KnownHostsPath)
+}
+
+func TestNewAuthMethods(t *testing.T) {
+	_, _, err := newAuthMethods(&SshClientConfig{})
+	assert.EqualError(t, err, "missing ssh auth: agent, private key or token required")
+
+	_, _, err = newAuthMethods(&SshClientConfig{PrivateKeyPath: filepath.Join(t.TempDir(), "invalid")})
+	assert.ErrorContains(t, err, "error reading private key")
+
+	t.Setenv(sshAuthSockEnv, "")
+	_, _, err = newAuthMethods(&SshClientConfig{UseAgent: true})
+	assert.EqualError(t, err, "ssh agent not available: SSH_AUTH_SOCK is not set")
+
+	authMethods, closeAuth, err := newAuthMethods(&SshClientConfig{Token: "myToken"})
+	require.NoError(t, err)
+	closeAuth()
+	assert.Equal(t, 1, len(authMethods))
+}
@@ -3,28 +3,117 @@ package ssh
 import (
 	"context"
 	"fmt"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	gossh "golang.org/x/crypto/ssh"
 )
 
 type SshClient struct {
-	ctx context.Context
-	ssh *gossh.Client
+	ctx       context.Context // cancelled when the client is closed
+	cancel    context.CancelFunc
+	config    *SshClientConfig
+	mutex     sync.RWMutex
+	ssh       *gossh.Client
+	connected chan struct{} // closed while the connection is available
+	err       error         // reconnection failure
+	pending   atomic.Int32  // global requests waiting for a reply
+	requests  atomic.Uint64 // global requests sent, keepalives excluded
 }
 
+const (
+	sshAuthSockEnv           = "SSH_AUTH_SOCK"
+	defaultKeepAliveInterval = 30 * time.Second
+	defaultRequestTimeout    = 30 * time.Second
+	defaultDialTimeout       = 10 * time.Second
+	defaultReconnectAttempts = 5
+	terminalType             = "xterm"
+	defaultTerminalWidth     = 80
+	defaultTerminalHeight    = 24
+)
+
 type SshClientConfig struct {
-	Address  string
-	Username string
-	Token    string
+	Address        string
+	Username       string
+	Token          string // password auth, optional
+	PrivateKeyPath string // optional
+	UseAgent       bool
+	KnownHostsPath string // trust on first use
+	Fingerprint    string // pinned SHA256 host key, overrides known hosts
+
+	KeepAliveInterval   time.Duration // defaults to 30s, negative disables keepalives
+	RequestTimeout      time.Duration // defaults to 30s, also used for keepalives
+	DialTimeout         time.Duration // defaults to 10s
+	ReconnectAttempts   int           // defaults to 5, negative disables reconnections
+	OnReconnectCallback func(attempt int, err error)
+	OnTrustHostCallback func(host string, fingerprint string) // a new host is added to the known hosts
+}
+
+func (c *SshClientConfig) keepAliveInterval() time.Duration {
+	if c.KeepAliveInterval == 0 {
+		return defaultKeepAliveInterval
+	}
+	return c.KeepAliveInterval
+}
+
+func (c *SshClientConfig) requestTimeout() time.Duration {
+	if c.RequestTimeout <= 0 {
+		return defaultRequestTimeout
+	}
+	return c.RequestTimeout
+}
+
+func (c *SshClientConfig) dialTimeout() time.Duration {
+	if c.DialTimeout <= 0 {
+		return defaultDialTimeout
+	}
+	return c.DialTimeout
+}
+
+func (c *SshClientConfig) reconnectAttempts() int {
+	if c.ReconnectAttempts == 0 {
+		return defaultReconnectAttempts
+	}
+	return c.ReconnectAttempts
+}
+
+func (c *SshClientConfig) onReconnect(attempt int, err error) {
+	if c.OnReconnectCallback != nil {
+		c.OnReconnectCallback(attempt, err)
+	}
+}
+
+func (c *SshClientConfig) onTrustHost(host string, fingerprint string) {
+	if c.OnTrustHostCallback != nil {
+		c.OnTrustHostCallback(host, fingerprint)
+	}
+}
+
+// RequestError is returned when the server rejects a request with a payload
+type RequestError struct {
+	response string
+}
+
+func (e *RequestError) Error() string {
+	return fmt.Sprintf("error ssh server response %s", e.response)
+}
+
+func (e *RequestError) Response() string {
+	return e.response
 }
 
 type SshTunnelOpts struct {
-	LocalPort             string
-	RemoteHost            string
-	RemotePort            string
-	OnTunnelStartCallback func(string)
-	OnTunnelStopCallback  func(string)
-	OnTunnelErrorCallback func(error)
+	LocalHost              string // defaults to all interfaces
+	LocalPort              string
+	RemoteHost             string
+	RemotePort             string
+	OnTunnelListenCallback func(address string) // optional, invoked once the local listener is bound
+	OnTunnelStartCallback  func(string)
+	OnTunnelStopCallback   func(string)
+	OnTunnelErrorCallback  func(error)
 }
 
 func (t *SshTunnelOpts) Network() string {
@@ -32,7 +121,11 @@ func (t *SshTunnelOpts) Network() string {
 }
 
 func (t *SshTunnelOpts) LocalAddress() string {
-	return fmt.Sprintf("0.0.0.0:%s", t.LocalPort)
+	host := t.LocalHost
+	if host == "" {
+		host = "0.0.0.0"
+	}
+	return net.JoinHostPort(host, t.LocalPort)
 }
 func (t *SshTunnelOpts) RemoteAddress() string {
 	return fmt.Sprintf("%s:%s", t.RemoteHost, t.RemotePort)
@@ -40,6 +133,38 @@ func (t *SshTunnelOpts) RemoteAddress() string {
 
 type SshExecOpts struct {
 	Payload               string
+	InStream              io.Reader
+	OutStream             io.Writer
+	ErrStream             io.Writer
 	OnStreamStartCallback func()
 	OnStreamErrorCallback func(error)
+	OnPtyErrorCallback    func(error) // optional, the session runs without a terminal
+}
+
+func (o *SshExecOpts) onPtyError(err error) {
+	if o.OnPtyErrorCallback != nil {
+		o.OnPtyErrorCallback(err)
+	}
+}
+
+type SshStreamOpts struct {
+	Payload   string
+	InStream  io.Reader
+	OutStream io.Writer
+}
+
+type SshCommandOpts struct {
+	Payload               string
+	InStream              io.Reader
+	OutStream             io.Writer
+	ErrStream             io.Writer
+	IsTty                 bool
+	OnStreamStartCallback func()
+	OnPtyErrorCallback    func(error) // optional, the command runs without a terminal
+}
+
+func (o *SshCommandOpts) onPtyError(err error) {
+	if o.OnPtyErrorCallback != nil {
+		o.OnPtyErrorCallback(err)
+	}
 }
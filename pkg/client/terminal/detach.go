@@ -0,0 +1,93 @@
+package terminal
+
+import (
+	"errors"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/moby/term"
+)
+
+const (
+	DefaultDetachKeys = "ctrl-p,ctrl-q"
+)
+
+// Detachable is implemented by the input streams which allow to leave a session without closing it
+type Detachable interface {
+	Detached() <-chan struct{}
+}
+
+// DetachReader stops reading when the detach keys are typed, the input is closed with EOF
+type DetachReader struct {
+	reader   io.ReadCloser
+	proxy    io.Reader
+	detached chan struct{}
+	once     sync.Once
+}
+
+// NewDetachReader uses the default keys if empty e.g. "ctrl-p,ctrl-q", see https://docs.docker.com/engine/reference/commandline/attach/#detach-keys
+func NewDetachReader(in io.ReadCloser, keys string) (*DetachReader, error) {
+	if keys == "" {
+		keys = DefaultDetachKeys
+	}
+	escapeKeys, err := term.ToBytes(keys)
+	if err != nil {
+		return nil, err
+	}
+	if len(escapeKeys) == 0 {
+		return nil, errors.New("empty detach keys")
+	}
+	return &DetachReader{
+		reader:   in,
+		proxy:    term.NewEscapeProxy(in, escapeKeys),
+		detached: make(chan struct{}),
+	}, nil
+}
+
+func (r *DetachReader) Read(p []byte) (int, error) {
+	n, err := r.proxy.Read(p)
+	var escapeError term.EscapeError
+	if errors.As(err, &escapeError) {
+		r.once.Do(func() { close(r.detached) })
+		return n, io.EOF
+	}
+	return n, err
+}
+
+func (r *DetachReader) Close() error {
+	return r.reader.Close()
+}
+
+// File allows to detect the underlying terminal
+func (r *DetachReader) File() *os.File {
+	file, _ := Unwrap[io.Reader](r.reader).(*os.File)
+	return file
+}
+
+// Detached is closed when the detach keys are typed
+func (r *DetachReader) Detached() <-chan struct{} {
+	return r.detached
+}
+
+// DetachChannel returns nil if the stream doesn't support detaching
+func DetachChannel(stream any) <-chan struct{} {
+	if detachable, ok := stream.(Detachable); ok {
+		return detachable.Detached()
+	}
+	return nil
+}
+
+// IsDetached returns true if the detach keys were typed
+func IsDetached(stream any) bool {
+	detached := DetachChannel(stream)
+	if detached == nil {
+		return false
+	}
+	select {
+	case <-detached:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,41 @@
+package terminal
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetachReader(t *testing.T) {
+	// ctrl-p ctrl-q
+	reader, err := NewDetachReader(io.NopCloser(strings.NewReader("ls\n\x10\x11exit\n")), "")
+	assert.NoError(t, err)
+	assert.False(t, IsDetached(reader))
+
+	value, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.Equal(t, "ls\n", string(value))
+	assert.True(t, IsDetached(reader))
+}
+
+func TestDetachReaderPartialKeys(t *testing.T) {
+	reader, err := NewDetachReader(io.NopCloser(strings.NewReader("a\x10b")), "ctrl-p,ctrl-q")
+	assert.NoError(t, err)
+
+	value, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.Equal(t, "a\x10b", string(value))
+	assert.False(t, IsDetached(reader))
+}
+
+func TestDetachReaderInvalidKeys(t *testing.T) {
+	_, err := NewDetachReader(io.NopCloser(strings.NewReader("")), "ctrl-foo")
+	assert.Error(t, err)
+}
+
+func TestIsDetachedNotSupported(t *testing.T) {
+	assert.Nil(t, DetachChannel(strings.NewReader("")))
+	assert.False(t, IsDetached(strings.NewReader("")))
+}
@@ -0,0 +1,37 @@
+package terminal
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/moby/term"
+	"github.com/pkg/errors"
+)
+
+// ReadPassword prints the prompt and reads a line from the terminal without echo
+func ReadPassword(in *os.File, out io.Writer, prompt string) ([]byte, error) {
+	fd, isTerminal := term.GetFdInfo(in)
+	if !isTerminal {
+		return nil, errors.New("error invalid terminal")
+	}
+	state, err := term.SaveState(fd)
+	if err != nil {
+		return nil, errors.Wrap(err, "error terminal state")
+	}
+	if err := term.DisableEcho(fd, state); err != nil {
+		return nil, errors.Wrap(err, "error terminal echo")
+	}
+	defer term.RestoreTerminal(fd, state)
+
+	fmt.Fprint(out, prompt)
+	line, err := bufio.NewReader(in).ReadString('\n')
+	// the newline is not echoed
+	fmt.Fprintln(out)
+	if err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "error reading password")
+	}
+	return []byte(strings.TrimRight(line, "\r\n")), nil
+}
@@ -0,0 +1,93 @@
+package terminal
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/signal"
+	"runtime"
+	"syscall"
+	"time"
+
+	"github.com/moby/term"
+)
+
+const (
+	// SIGWINCH has the same value on linux and darwin, but it's not defined on windows
+	sigwinch = syscall.Signal(0x1c)
+	// windows doesn't notify resize events, the console size is polled instead
+	resizePollInterval = 250 * time.Millisecond
+)
+
+type Size struct {
+	Width  uint16
+	Height uint16
+}
+
+// GetSize returns false if the stream is not a terminal
+func GetSize(in io.Reader) (Size, bool) {
+	fd, isTerminal := term.GetFdInfo(Unwrap(in))
+	if !isTerminal {
+		return Size{}, false
+	}
+	winsize, err := term.GetWinsize(fd)
+	if err != nil || winsize.Width == 0 || winsize.Height == 0 {
+		return Size{}, false
+	}
+	return Size{Width: winsize.Width, Height: winsize.Height}, true
+}
+
+// MonitorSize sends the initial size of the terminal and then every change, until the context is done.
+// Returns nil if the stream is not a terminal
+func MonitorSize(ctx context.Context, in io.Reader) <-chan Size {
+	initialSize, ok := GetSize(in)
+	if !ok {
+		return nil
+	}
+
+	sizeChannel := make(chan Size, 1)
+	sizeChannel <- initialSize
+
+	go func() {
+		defer close(sizeChannel)
+
+		resizeChannel := make(chan os.Signal, 1)
+		var pollChannel <-chan time.Time
+		if runtime.GOOS == "windows" {
+			ticker := time.NewTicker(resizePollInterval)
+			defer ticker.Stop()
+			pollChannel = ticker.C
+		} else {
+			signal.Notify(resizeChannel, sigwinch)
+			defer signal.Stop(resizeChannel)
+		}
+
+		lastSize := initialSize
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-resizeChannel:
+			case <-pollChannel:
+			}
+			if size, ok := GetSize(in); ok && size != lastSize {
+				lastSize = size
+				select {
+				case sizeChannel <- size:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return sizeChannel
+}
+
+// ResizeSource returns the explicit resize events e.g. forwarded by a remote client,
+// otherwise it monitors the local terminal
+func ResizeSource(ctx context.Context, in io.Reader, resize <-chan Size) <-chan Size {
+	if resize != nil {
+		return resize
+	}
+	return MonitorSize(ctx, in)
+}
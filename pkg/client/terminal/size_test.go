@@ -0,0 +1,46 @@
+package terminal
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSizeNotTerminal(t *testing.T) {
+	_, ok := GetSize(new(bytes.Buffer))
+	assert.False(t, ok)
+}
+
+func TestResizeSource(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	assert.Nil(t, ResizeSource(ctx, new(bytes.Buffer), nil))
+
+	resize := make(chan Size)
+	assert.Equal(t, (<-chan Size)(resize), ResizeSource(ctx, new(bytes.Buffer), resize))
+}
+
+type testFileStream struct {
+	io.Reader
+	file *os.File
+}
+
+func (s *testFileStream) File() *os.File {
+	return s.file
+}
+
+func TestUnwrap(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	assert.Equal(t, io.Reader(buffer), Unwrap[io.Reader](buffer))
+
+	wrapped := &testFileStream{Reader: buffer, file: os.Stdin}
+	assert.Equal(t, io.Reader(os.Stdin), Unwrap[io.Reader](wrapped))
+
+	notFile := &testFileStream{Reader: buffer}
+	assert.Equal(t, io.Reader(notFile), Unwrap[io.Reader](notFile))
+}
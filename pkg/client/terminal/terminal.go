@@ -3,11 +3,29 @@ package terminal
 import (
 	"fmt"
 	"io"
+	"os"
 
 	"github.com/moby/term"
 	"github.com/pkg/errors"
 )
 
+// FileStream is implemented by the streams wrapping a file e.g. a recorder, to detect the underlying terminal
+type FileStream interface {
+	File() *os.File
+}
+
+// Unwrap returns the underlying file of a wrapped stream, otherwise the stream itself
+func Unwrap[T any](stream T) T {
+	if wrapper, ok := any(stream).(FileStream); ok {
+		if file := wrapper.File(); file != nil {
+			if unwrapped, ok := any(file).(T); ok {
+				return unwrapped
+			}
+		}
+	}
+	return stream
+}
+
 type RawTerminal struct {
 	fileDescriptor uintptr
 	previousState  *term.State
@@ -15,7 +33,7 @@ type RawTerminal struct {
 
 func NewRawTerminal(in io.Reader) (*RawTerminal, error) {
 
-	if fd, isTerminal := term.GetFdInfo(in); isTerminal {
+	if fd, isTerminal := term.GetFdInfo(Unwrap(in)); isTerminal {
 		previousState, err := term.SetRawTerminal(fd)
 		if err != nil {
 			return nil, errors.Wrap(err, "error raw terminal")
@@ -1,6 +1,7 @@
 package docker
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -40,11 +41,11 @@ func (common *DockerCommonClient) GetClient() *docker.DockerClient {
 
 func (common *DockerCommonClient) Close() error {
 	common.eventBus.Publish(newCloseDockerClientEvent())
-	common.eventBus.Close()
+	common.eventBus.Flush()
 	return common.client.Close()
 }
 
-func (common *DockerCommonClient) PullImageOffline(imageName string, onImagePullCallback func()) error {
+func (common *DockerCommonClient) PullImageOffline(ctx context.Context, imageName string, onImagePullCallback func()) error {
 
 	// TODO add support at least for linux/arm64
 	// temporary solution to force the architecture, ideally:
@@ -58,7 +59,7 @@ func (common *DockerCommonClient) PullImageOffline(imageName string, onImagePull
 		OnImagePullCallback: onImagePullCallback,
 	}
 	common.eventBus.Publish(newImagePullDockerEvent(imageName, imagePullOpts.PlatformString()))
-	if err := common.client.ImagePull(imagePullOpts); err != nil {
+	if err := common.client.ImagePull(ctx, imagePullOpts); err != nil {
 		// ignore error and try to use an existing image if exists
 		if common.clientOpts.IgnoreImagePullError {
 			common.eventBus.Publish(newImagePullIgnoreDockerEvent(imageName))
@@ -78,7 +79,7 @@ func (common *DockerCommonClient) PullImageOffline(imageName string, onImagePull
 			common.eventBus.Publish(newImageRemoveIgnoreDockerEvent(imageId, err))
 		},
 	}
-	if err := common.client.ImageRemoveDangling(imageRemoveOpts); err != nil {
+	if err := common.client.ImageRemoveDangling(ctx, imageRemoveOpts); err != nil {
 		return err
 	}
 
@@ -89,10 +90,10 @@ func sidecarLabel() string {
 	return fmt.Sprintf("%s=%s", commonModel.LabelSchemaKind, schema.KindSidecarV1.String())
 }
 
-func (common *DockerCommonClient) SidecarList(containerName string) ([]commonModel.SidecarInfo, error) {
+func (common *DockerCommonClient) SidecarList(ctx context.Context, containerName string) ([]commonModel.SidecarInfo, error) {
 
 	// filter by prefix and label
-	containers, err := common.client.ContainerList(commonModel.SidecarPrefixName, sidecarLabel())
+	containers, err := common.client.ContainerList(ctx, commonModel.SidecarPrefixName, sidecarLabel())
 	if err != nil {
 		return nil, err
 	}
@@ -110,13 +111,29 @@ func (common *DockerCommonClient) SidecarList(containerName string) ([]commonMod
 	return sidecars, nil
 }
 
+// Rollback removes the resources created before a failure or an interrupt, it's a noop if committed
+func (common *DockerCommonClient) Rollback(ctx context.Context, rollback *util.Rollback) {
+	if rollback.Len() == 0 {
+		return
+	}
+	common.eventBus.Publish(newRollbackDockerEvent())
+	if err := rollback.Run(ctx); err != nil {
+		common.eventBus.Publish(newRollbackErrorDockerEvent(err))
+	}
+}
+
+func (common *DockerCommonClient) SidecarRemove(ctx context.Context, containerId string) error {
+	common.eventBus.Publish(newSidecarVpnRemoveDockerEvent(containerId))
+	return common.client.ContainerRemove(ctx, containerId)
+}
+
 func buildSidecarVpnName(containerName string) string {
 	// expect valid name always
 	tokens := strings.Split(containerName, "-")
 	return fmt.Sprintf("%svpn-%s", commonModel.SidecarPrefixName, tokens[len(tokens)-1])
 }
 
-func (common *DockerCommonClient) SidecarVpnInject(opts *commonModel.SidecarVpnInjectOpts, portConfig *docker.ContainerPortConfigOpts) (string, error) {
+func (common *DockerCommonClient) SidecarVpnInject(ctx context.Context, opts *commonModel.SidecarVpnInjectOpts, portConfig *docker.ContainerPortConfigOpts) (string, error) {
 
 	// sidecarName
 	containerName := buildSidecarVpnName(opts.Name)
@@ -127,7 +144,11 @@ func (common *DockerCommonClient) SidecarVpnInject(opts *commonModel.SidecarVpnI
 	// base directory "/usr/share" must exist
 	vpnConfigPath := "/usr/share/client.ovpn"
 
-	if err := common.PullImageOffline(imageName, func() {
+	// the sidecar is removed if it fails to start
+	rollback := util.NewRollback()
+	defer common.Rollback(ctx, rollback)
+
+	if err := common.PullImageOffline(ctx, imageName, func() {
 		common.eventBus.Publish(newSidecarVpnConnectDockerEvent(opts.NetworkVpn.Name))
 		common.eventBus.Publish(newSidecarVpnConnectDockerLoaderEvent(opts.NetworkVpn.Name))
 	}); err != nil {
@@ -154,15 +175,20 @@ func (common *DockerCommonClient) SidecarVpnInject(opts *commonModel.SidecarVpnI
 	}
 
 	containerOpts := &docker.ContainerCreateOpts{
-		ContainerName:    containerName,
-		ContainerConfig:  containerConfig,
-		HostConfig:       hostConfig,
-		Platform:         docker.DefaultPlatform(),
-		WaitStatus:       false,
-		CaptureInterrupt: false, // edge case: killing this while creating will leave an orphan sidecar container
+		ContainerName:   containerName,
+		ContainerConfig: containerConfig,
+		HostConfig:      hostConfig,
+		Platform:        docker.DefaultPlatform(),
+		WaitStatus:      false,
 		OnContainerCreateCallback: func(containerId string) error {
-			// upload openvpn config file
-			return common.client.CopyFileToContainer(containerId, opts.NetworkVpn.LocalPath, vpnConfigPath)
+			rollback.Add("sidecar-vpn", func(ctx context.Context) error {
+				return common.SidecarRemove(ctx, containerId)
+			})
+			// upload openvpn config file, secrets are never written locally
+			if opts.NetworkVpn.IsSecret {
+				return common.client.CopyContentToContainer(ctx, containerId, []byte(opts.NetworkVpn.ConfigValue), vpnConfigPath)
+			}
+			return common.client.CopyFileToContainer(ctx, containerId, opts.NetworkVpn.LocalPath, vpnConfigPath)
 		},
 		OnContainerStatusCallback: func(status string) {
 			common.eventBus.Publish(newSidecarVpnCreateStatusDockerEvent(status))
@@ -170,7 +196,7 @@ func (common *DockerCommonClient) SidecarVpnInject(opts *commonModel.SidecarVpnI
 		OnContainerStartCallback: func() {},
 	}
 	// sidecarId
-	containerId, err := common.client.ContainerCreate(containerOpts)
+	containerId, err := common.client.ContainerCreate(ctx, containerOpts)
 	if err != nil {
 		return "", err
 	}
@@ -178,5 +204,6 @@ func (common *DockerCommonClient) SidecarVpnInject(opts *commonModel.SidecarVpnI
 	// block to give time to connect
 	util.Sleep(3)
 
+	rollback.Commit()
 	return containerId, nil
 }
@@ -8,8 +8,9 @@ import (
 )
 
 type dockerCommonEvent struct {
-	kind  event.EventKind
-	value string
+	kind    event.EventKind
+	value   string
+	payload any
 }
 
 func (e *dockerCommonEvent) Source() string {
@@ -24,6 +25,10 @@ func (e *dockerCommonEvent) String() string {
 	return e.value
 }
 
+func (e *dockerCommonEvent) Payload() any {
+	return e.payload
+}
+
 func newInitDockerClientEvent() *dockerCommonEvent {
 	return &dockerCommonEvent{kind: event.LogDebug, value: "init docker client"}
 }
@@ -49,7 +54,8 @@ func newImageRemoveIgnoreDockerEvent(imageId string, err error) *dockerCommonEve
 }
 
 func newSidecarVpnCreateDockerEvent(containerName string, containerId string) *dockerCommonEvent {
-	return &dockerCommonEvent{kind: event.LogInfo, value: fmt.Sprintf("sidecar-vpn create: containerName=%s containerId=%s", containerName, containerId)}
+	return &dockerCommonEvent{kind: event.LogInfo, value: fmt.Sprintf("sidecar-vpn create: containerName=%s containerId=%s", containerName, containerId),
+		payload: event.ResourcePayload{Action: event.ResourceCreate, Type: "sidecar-vpn", Name: containerName, Id: containerId}}
 }
 
 func newSidecarVpnCreateStatusDockerEvent(status string) *dockerCommonEvent {
@@ -63,3 +69,16 @@ func newSidecarVpnConnectDockerEvent(vpnName string) *dockerCommonEvent {
 func newSidecarVpnConnectDockerLoaderEvent(vpnName string) *dockerCommonEvent {
 	return &dockerCommonEvent{kind: event.LoaderUpdate, value: fmt.Sprintf("connecting to %s", vpnName)}
 }
+
+func newSidecarVpnRemoveDockerEvent(containerId string) *dockerCommonEvent {
+	return &dockerCommonEvent{kind: event.LogInfo, value: fmt.Sprintf("sidecar-vpn remove: containerId=%s", containerId),
+		payload: event.ResourcePayload{Action: event.ResourceDelete, Type: "sidecar-vpn", Id: containerId}}
+}
+
+func newRollbackDockerEvent() *dockerCommonEvent {
+	return &dockerCommonEvent{kind: event.LogWarning, value: "rollback partially created resources"}
+}
+
+func newRollbackErrorDockerEvent(err error) *dockerCommonEvent {
+	return &dockerCommonEvent{kind: event.LogError, value: fmt.Sprintf("rollback error: %v", err)}
+}
@@ -20,6 +20,7 @@ const (
 	sidecarVpnSecretPath   = "openvpn/client.ovpn"
 	sidecarVpnSecretKey    = "openvpn-config"
 	sidecarShareVolume     = "sidecar-share-volume"
+	sidecarOutputVolume    = "sidecar-output-volume"
 )
 
 func buildSidecarVpnSecretName(podName string) string {
@@ -153,18 +154,26 @@ func buildSidecarShareLock(remoteDir string) string {
 	return filepath.Join(remoteDir, ".wait")
 }
 
-func buildSidecarShareContainer(remoteDir string) corev1.Container {
-	return corev1.Container{
-		Name:  buildSidecarShareContainerName(),
-		Image: commonModel.SidecarShareImageName, // only requirement is the "tar" binary
-		Stdin: true,
-		VolumeMounts: []corev1.VolumeMount{
-			{
-				Name:      sidecarShareVolume,
-				MountPath: remoteDir,
-			},
+func buildSidecarShareContainer(shareDir *commonModel.ShareDirInfo) corev1.Container {
+	volumeMounts := []corev1.VolumeMount{
+		{
+			Name:      sidecarShareVolume,
+			MountPath: shareDir.RemotePath,
 		},
 	}
+	if shareDir.OutputDir {
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      sidecarOutputVolume,
+			MountPath: commonModel.SidecarShareOutputDir,
+		})
+	}
+
+	return corev1.Container{
+		Name:         buildSidecarShareContainerName(),
+		Image:        commonModel.SidecarShareImageName, // only requirement is the "tar" binary
+		Stdin:        true,
+		VolumeMounts: volumeMounts,
+	}
 }
 
 func injectSidecarShare(podSpec *corev1.PodSpec, mainContainerName string, shareDir *commonModel.ShareDirInfo) {
@@ -219,13 +228,24 @@ func injectSidecarShare(podSpec *corev1.PodSpec, mainContainerName string, share
 					ReadOnly:  true,
 				},
 			)
+
+			// mount writable output volume to main container, collected by the sidecar on completion
+			if shareDir.OutputDir {
+				podSpec.Containers[index].VolumeMounts = append(
+					podSpec.Containers[index].VolumeMounts,
+					corev1.VolumeMount{
+						Name:      sidecarOutputVolume,
+						MountPath: commonModel.SidecarShareOutputDir,
+					},
+				)
+			}
 		}
 	}
 
 	// inject sidecar
 	podSpec.Containers = append(
 		podSpec.Containers, // current containers
-		buildSidecarShareContainer(shareDir.RemotePath),
+		buildSidecarShareContainer(shareDir),
 	)
 
 	// inject shared volume between main container and sidecar
@@ -238,4 +258,16 @@ func injectSidecarShare(podSpec *corev1.PodSpec, mainContainerName string, share
 			},
 		},
 	)
+
+	if shareDir.OutputDir {
+		podSpec.Volumes = append(
+			podSpec.Volumes,
+			corev1.Volume{
+				Name: sidecarOutputVolume,
+				VolumeSource: corev1.VolumeSource{
+					EmptyDir: &corev1.EmptyDirVolumeSource{},
+				},
+			},
+		)
+	}
 }
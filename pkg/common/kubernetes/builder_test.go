@@ -349,3 +349,54 @@ status: {}
 
 	assert.YAMLEqf(t, expected, kubernetes.ObjectToYaml(actual), "unexpected pod")
 }
+
+func TestInjectSidecarShareOutputDir(t *testing.T) {
+
+	expected := `
+apiVersion: v1
+kind: Pod
+metadata:
+  creationTimestamp: null
+spec:
+  containers:
+  - args:
+    - foo
+    - bar
+    image: my-image
+    name: my-name
+    resources: {}
+    volumeMounts:
+    - mountPath: /tmp/foo
+      name: sidecar-share-volume
+      readOnly: true
+    - mountPath: /hck/output
+      name: sidecar-output-volume
+  - image: busybox
+    name: sidecar-share
+    resources: {}
+    stdin: true
+    volumeMounts:
+    - mountPath: /tmp/foo
+      name: sidecar-share-volume
+    - mountPath: /hck/output
+      name: sidecar-output-volume
+  volumes:
+  - hostPath:
+      path: my-path
+    name: my-volume
+  - emptyDir: {}
+    name: sidecar-share-volume
+  - emptyDir: {}
+    name: sidecar-output-volume
+status: {}
+`
+
+	containerName := "my-name"
+	shareDir := &model.ShareDirInfo{RemotePath: "/tmp/foo", OutputDir: true}
+	actual := newPodSpecTest(containerName)
+	injectSidecarShare(&actual.Spec, containerName, shareDir)
+	// fix model
+	actual.TypeMeta = metav1.TypeMeta{Kind: "Pod", APIVersion: "v1"}
+
+	assert.YAMLEqf(t, expected, kubernetes.ObjectToYaml(actual), "unexpected pod")
+}
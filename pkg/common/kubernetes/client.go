@@ -1,6 +1,7 @@
 package kubernetes
 
 import (
+	"context"
 	"io"
 	"os"
 
@@ -10,6 +11,7 @@ import (
 	"github.com/hckops/hckctl/pkg/client/kubernetes"
 	commonModel "github.com/hckops/hckctl/pkg/common/model"
 	"github.com/hckops/hckctl/pkg/event"
+	"github.com/hckops/hckctl/pkg/util"
 )
 
 type KubeCommonClient struct {
@@ -39,14 +41,25 @@ func (common *KubeCommonClient) GetClient() *kubernetes.KubeClient {
 
 func (common *KubeCommonClient) Close() error {
 	common.eventBus.Publish(newCloseKubeClientEvent())
-	common.eventBus.Close()
+	common.eventBus.Flush()
 	return common.client.Close()
 }
 
-func (common *KubeCommonClient) SidecarVpnDelete(namespace string, mainContainerName string) error {
+// Rollback deletes the resources created before a failure or an interrupt, it's a noop if committed
+func (common *KubeCommonClient) Rollback(ctx context.Context, rollback *util.Rollback) {
+	if rollback.Len() == 0 {
+		return
+	}
+	common.eventBus.Publish(newRollbackKubeEvent())
+	if err := rollback.Run(ctx); err != nil {
+		common.eventBus.Publish(newRollbackErrorKubeEvent(err))
+	}
+}
+
+func (common *KubeCommonClient) SidecarVpnDelete(ctx context.Context, namespace string, mainContainerName string) error {
 	// delete secret
 	name := buildSidecarVpnSecretName(mainContainerName)
-	if ok, err := common.client.SecretDelete(namespace, name); err != nil {
+	if ok, err := common.client.SecretDelete(ctx, namespace, name); err != nil {
 		return err
 	} else if ok {
 		common.eventBus.Publish(newSecretDeleteKubeEvent(namespace, name))
@@ -54,12 +67,12 @@ func (common *KubeCommonClient) SidecarVpnDelete(namespace string, mainContainer
 	return nil
 }
 
-func (common *KubeCommonClient) SidecarVpnInject(namespace string, opts *commonModel.SidecarVpnInjectOpts, podSpec *corev1.PodSpec) error {
+func (common *KubeCommonClient) SidecarVpnInject(ctx context.Context, namespace string, opts *commonModel.SidecarVpnInjectOpts, podSpec *corev1.PodSpec) error {
 
 	// create secret
 	secret := buildSidecarVpnSecret(namespace, opts.Name, opts.NetworkVpn.ConfigValue)
 	common.eventBus.Publish(newSecretCreateKubeEvent(namespace, secret.Name))
-	if err := common.client.SecretCreate(namespace, secret); err != nil {
+	if err := common.client.SecretCreate(ctx, namespace, secret); err != nil {
 		return err
 	}
 
@@ -77,19 +90,20 @@ func (common *KubeCommonClient) SidecarShareInject(opts *commonModel.SidecarShar
 	return nil
 }
 
-func (common *KubeCommonClient) SidecarShareUpload(opts *commonModel.SidecarShareUploadOpts) error {
+func (common *KubeCommonClient) SidecarShareUpload(ctx context.Context, opts *commonModel.SidecarShareUploadOpts) error {
 	common.eventBus.Publish(newSidecarShareUploadKubeEvent(opts.ShareDir.LocalPath, opts.ShareDir.RemotePath))
 	common.eventBus.Publish(newSidecarShareUploadKubeLoaderEvent())
 
 	sidecarContainerName := buildSidecarShareContainerName()
 	copyOpts := &kubernetes.CopyPodOpts{
-		Namespace:     opts.Namespace,
-		PodName:       opts.PodName,
-		ContainerName: sidecarContainerName,
-		LocalPath:     opts.ShareDir.LocalPath,
-		RemotePath:    opts.ShareDir.RemotePath,
+		Namespace:          opts.Namespace,
+		PodName:            opts.PodName,
+		ContainerName:      sidecarContainerName,
+		LocalPath:          opts.ShareDir.LocalPath,
+		RemotePath:         opts.ShareDir.RemotePath,
+		OnProgressCallback: func(int64) {},
 	}
-	if err := common.client.CopyToPod(copyOpts); err != nil {
+	if err := common.client.CopyToPod(ctx, copyOpts); err != nil {
 		return err
 	}
 
@@ -106,10 +120,25 @@ func (common *KubeCommonClient) SidecarShareUpload(opts *commonModel.SidecarShar
 			IsTty:          false,
 			OnExecCallback: func() {},
 		}
-		if err := common.client.PodExecCommand(execDeleteLock); err != nil {
+		if err := common.client.PodExecCommand(ctx, execDeleteLock); err != nil {
 			return errors.Wrapf(err, "error delete lock")
 		}
 	}
 
 	return nil
 }
+
+func (common *KubeCommonClient) SidecarShareDownload(ctx context.Context, opts *commonModel.SidecarShareDownloadOpts) error {
+	common.eventBus.Publish(newSidecarShareDownloadKubeEvent(commonModel.SidecarShareOutputDir, opts.LocalPath))
+	common.eventBus.Publish(newSidecarShareDownloadKubeLoaderEvent())
+
+	copyOpts := &kubernetes.CopyPodOpts{
+		Namespace:          opts.Namespace,
+		PodName:            opts.PodName,
+		ContainerName:      buildSidecarShareContainerName(),
+		LocalPath:          opts.LocalPath,
+		RemotePath:         commonModel.SidecarShareOutputDir,
+		OnProgressCallback: func(int64) {},
+	}
+	return common.client.CopyFromPod(ctx, copyOpts)
+}
@@ -8,8 +8,9 @@ import (
 )
 
 type kubeCommonEvent struct {
-	kind  event.EventKind
-	value string
+	kind    event.EventKind
+	value   string
+	payload any
 }
 
 func (e *kubeCommonEvent) Source() string {
@@ -24,6 +25,10 @@ func (e *kubeCommonEvent) String() string {
 	return e.value
 }
 
+func (e *kubeCommonEvent) Payload() any {
+	return e.payload
+}
+
 func newInitKubeClientEvent() *kubeCommonEvent {
 	return &kubeCommonEvent{kind: event.LogDebug, value: "init kube client"}
 }
@@ -33,11 +38,13 @@ func newCloseKubeClientEvent() *kubeCommonEvent {
 }
 
 func newSecretCreateKubeEvent(namespace string, name string) *kubeCommonEvent {
-	return &kubeCommonEvent{kind: event.LogInfo, value: fmt.Sprintf("secret create: namespace=%s name=%s", namespace, name)}
+	return &kubeCommonEvent{kind: event.LogInfo, value: fmt.Sprintf("secret create: namespace=%s name=%s", namespace, name),
+		payload: event.ResourcePayload{Action: event.ResourceCreate, Type: "secret", Name: name, Namespace: namespace}}
 }
 
 func newSecretDeleteKubeEvent(namespace string, name string) *kubeCommonEvent {
-	return &kubeCommonEvent{kind: event.LogInfo, value: fmt.Sprintf("secret delete: namespace=%s name=%s", namespace, name)}
+	return &kubeCommonEvent{kind: event.LogInfo, value: fmt.Sprintf("secret delete: namespace=%s name=%s", namespace, name),
+		payload: event.ResourcePayload{Action: event.ResourceDelete, Type: "secret", Name: name, Namespace: namespace}}
 }
 
 func newSidecarVpnConnectKubeEvent(vpnName string) *kubeCommonEvent {
@@ -55,3 +62,19 @@ func newSidecarShareUploadKubeEvent(localPath string, remotePath string) *kubeCo
 func newSidecarShareUploadKubeLoaderEvent() *kubeCommonEvent {
 	return &kubeCommonEvent{kind: event.LoaderUpdate, value: fmt.Sprintf("uploading shared folder")}
 }
+
+func newSidecarShareDownloadKubeEvent(remotePath string, localPath string) *kubeCommonEvent {
+	return &kubeCommonEvent{kind: event.LogInfo, value: fmt.Sprintf("sidecar-share download: remotePath=%s localPath=%s", remotePath, localPath)}
+}
+
+func newSidecarShareDownloadKubeLoaderEvent() *kubeCommonEvent {
+	return &kubeCommonEvent{kind: event.LoaderUpdate, value: fmt.Sprintf("downloading output folder")}
+}
+
+func newRollbackKubeEvent() *kubeCommonEvent {
+	return &kubeCommonEvent{kind: event.LogWarning, value: "rollback partially created resources"}
+}
+
+func newRollbackErrorKubeEvent(err error) *kubeCommonEvent {
+	return &kubeCommonEvent{kind: event.LogError, value: fmt.Sprintf("rollback error: %v", err)}
+}
@@ -10,4 +10,6 @@ const (
 	SidecarVpnPrivilegedImageName = "hckops/alpine-openvpn-privileged:latest"
 	SidecarShareImageName         = "busybox"
 	SidecarShareDir               = "/hck/share"
+	SidecarShareOutputDir         = "/hck/output"
+	ShareOutputDirName            = "output"
 )
@@ -3,6 +3,8 @@ package model
 import (
 	"io"
 	"os"
+
+	"github.com/hckops/hckctl/pkg/client/terminal"
 )
 
 type DockerOptions struct {
@@ -17,10 +19,14 @@ type KubeOptions struct {
 }
 
 type CloudOptions struct {
-	Version  string
-	Address  string
-	Username string
-	Token    string
+	Version        string
+	Address        string
+	Username       string
+	Token          string
+	PrivateKeyPath string
+	UseAgent       bool
+	KnownHostsPath string
+	Fingerprint    string
 }
 
 type StreamOptions struct {
@@ -28,6 +34,8 @@ type StreamOptions struct {
 	Out   io.Writer
 	Err   io.Writer
 	IsTty bool // tty is false for ssh tunnel or logs
+	// optional terminal size changes e.g. forwarded by a remote client, defaults to the size of In if it's a terminal
+	Resize <-chan terminal.Size
 }
 
 func NewStdStreamOpts(tty bool) *StreamOptions {
@@ -54,3 +62,9 @@ type SidecarShareUploadOpts struct {
 	PodName   string
 	ShareDir  *ShareDirInfo
 }
+
+type SidecarShareDownloadOpts struct {
+	Namespace string
+	PodName   string
+	LocalPath string
+}
@@ -2,6 +2,7 @@ package model
 
 import (
 	"fmt"
+	"path/filepath"
 )
 
 type DockerProviderInfo struct {
@@ -60,10 +61,16 @@ type NetworkVpnInfo struct {
 	LocalPath   string
 	ConfigValue string
 	Privileged  bool
+	IsSecret    bool // the config is resolved from the secret store, LocalPath is only a reference
 }
 
 type ShareDirInfo struct {
 	LocalPath  string
 	RemotePath string
 	LockDir    bool
+	OutputDir  bool // writable remote directory collected locally on completion
+}
+
+func (info *ShareDirInfo) LocalOutputPath(name string) string {
+	return filepath.Join(info.LocalPath, ShareOutputDirName, name)
 }
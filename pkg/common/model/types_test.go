@@ -22,3 +22,10 @@ func TestImageVersion(t *testing.T) {
 	image.Version = "my-version"
 	assert.Equal(t, "my-version", image.ResolveVersion())
 }
+
+func TestShareDirLocalOutputPath(t *testing.T) {
+	shareDir := ShareDirInfo{
+		LocalPath: "/tmp/share",
+	}
+	assert.Equal(t, "/tmp/share/output/my-task", shareDir.LocalOutputPath("my-task"))
+}
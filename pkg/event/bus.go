@@ -3,6 +3,7 @@ package event
 import (
 	"fmt"
 	"sync"
+	"time"
 )
 
 type EventKind uint8
@@ -29,40 +30,203 @@ type Event interface {
 	fmt.Stringer
 }
 
+// max number of events retained while nobody is subscribed
+const backlogSize = 256
+
+// EventBus delivers the events to each subscriber in the same order they are published,
+// every subscriber is independent and a slow callback never blocks the publisher
 type EventBus struct {
-	eventChan chan Event
-	wg        sync.WaitGroup
+	mutex       sync.Mutex
+	subscribers []*subscriber
+	backlog     []published
+	closed      bool
+	now         func() time.Time
+}
+
+// published retains when the event occurred, the delivery is asynchronous
+type published struct {
+	event Event
+	time  time.Time
 }
 
 func NewEventBus() *EventBus {
-	return &EventBus{
-		eventChan: make(chan Event),
-	}
+	return &EventBus{now: time.Now}
 }
 
+// Publish never blocks, the events are ignored after the bus is closed.
+// The events published before any subscription are replayed to each new subscriber until the next event is delivered
 func (bus *EventBus) Publish(event Event) {
-	bus.wg.Add(1)
-	go func() {
-		bus.eventChan <- event
-		bus.wg.Done()
-	}()
-}
-
-func (bus *EventBus) Subscribe(callback func(event Event)) {
-	go func() {
-		for {
-			select {
-			case event := <-bus.eventChan:
-				callback(event)
-			}
+	bus.mutex.Lock()
+	defer bus.mutex.Unlock()
+
+	if bus.closed {
+		return
+	}
+	value := published{event: event, time: bus.now()}
+	if len(bus.subscribers) == 0 {
+		if len(bus.backlog) < backlogSize {
+			bus.backlog = append(bus.backlog, value)
+		}
+		return
+	}
+	bus.backlog = nil
+	for _, s := range bus.subscribers {
+		s.push(value)
+	}
+}
+
+// Subscribe invokes the callback sequentially for each event which matches all the filters
+func (bus *EventBus) Subscribe(callback func(event Event), filters ...Filter) *Subscription {
+	return bus.SubscribeTime(func(event Event, _ time.Time) {
+		callback(event)
+	}, filters...)
+}
+
+// SubscribeTime is like Subscribe, the callback receives also the time when the event was published
+func (bus *EventBus) SubscribeTime(callback func(event Event, time time.Time), filters ...Filter) *Subscription {
+	s := newSubscriber(callback, filters)
+
+	bus.mutex.Lock()
+	defer bus.mutex.Unlock()
+
+	if !bus.closed {
+		for _, value := range bus.backlog {
+			s.push(value)
 		}
-	}()
+		bus.subscribers = append(bus.subscribers, s)
+	}
+	return &Subscription{bus: bus, subscriber: s}
 }
 
+// Drain discards all the events
 func (bus *EventBus) Drain() {
 	bus.Subscribe(func(event Event) {})
 }
 
+// Flush blocks until all the published events are delivered, it must not be invoked by a callback
+func (bus *EventBus) Flush() {
+	bus.mutex.Lock()
+	subscribers := append([]*subscriber{}, bus.subscribers...)
+	bus.mutex.Unlock()
+
+	for _, s := range subscribers {
+		s.flush()
+	}
+}
+
+// Close delivers the pending events and removes all the subscribers, the bus can't be used anymore
 func (bus *EventBus) Close() {
-	bus.wg.Wait()
+	bus.mutex.Lock()
+	subscribers := bus.subscribers
+	bus.subscribers = nil
+	bus.backlog = nil
+	bus.closed = true
+	bus.mutex.Unlock()
+
+	for _, s := range subscribers {
+		s.close()
+		s.flush()
+	}
+}
+
+func (bus *EventBus) unsubscribe(target *subscriber) {
+	bus.mutex.Lock()
+	defer bus.mutex.Unlock()
+
+	for i, s := range bus.subscribers {
+		if s == target {
+			bus.subscribers = append(bus.subscribers[:i], bus.subscribers[i+1:]...)
+			break
+		}
+	}
+}
+
+type Subscription struct {
+	bus        *EventBus
+	subscriber *subscriber
+}
+
+// Unsubscribe stops receiving new events, the pending ones are still delivered.
+// It's safe to invoke it multiple times or from the callback
+func (s *Subscription) Unsubscribe() {
+	s.bus.unsubscribe(s.subscriber)
+	s.subscriber.close()
+}
+
+// subscriber delivers the events with a single goroutine at a time, which exits as soon as the queue is empty
+type subscriber struct {
+	callback func(event Event, time time.Time)
+	filters  []Filter
+	mutex    sync.Mutex
+	idle     *sync.Cond
+	queue    []published
+	running  bool
+	closed   bool
+}
+
+func newSubscriber(callback func(event Event, time time.Time), filters []Filter) *subscriber {
+	s := &subscriber{callback: callback, filters: filters}
+	s.idle = sync.NewCond(&s.mutex)
+	return s
+}
+
+func (s *subscriber) matches(event Event) bool {
+	for _, filter := range s.filters {
+		if !filter(event) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *subscriber) push(value published) {
+	if !s.matches(value.event) {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.closed {
+		return
+	}
+	s.queue = append(s.queue, value)
+	if !s.running {
+		s.running = true
+		go s.run()
+	}
+}
+
+func (s *subscriber) run() {
+	s.mutex.Lock()
+	for len(s.queue) > 0 {
+		values := s.queue
+		s.queue = nil
+		s.mutex.Unlock()
+
+		for _, value := range values {
+			s.callback(value.event, value.time)
+		}
+
+		s.mutex.Lock()
+	}
+	s.running = false
+	s.idle.Broadcast()
+	s.mutex.Unlock()
+}
+
+func (s *subscriber) flush() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for s.running {
+		s.idle.Wait()
+	}
+}
+
+func (s *subscriber) close() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.closed = true
 }
@@ -1,11 +1,47 @@
 package event
 
 import (
+	"fmt"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
 
+type testEvent struct {
+	kind    EventKind
+	source  string
+	value   string
+	payload any
+}
+
+func (e *testEvent) Kind() EventKind { return e.kind }
+func (e *testEvent) Source() string  { return e.source }
+func (e *testEvent) String() string  { return e.value }
+func (e *testEvent) Payload() any    { return e.payload }
+
+func newTestEvent(kind EventKind, source string, value string) *testEvent {
+	return &testEvent{kind: kind, source: source, value: value}
+}
+
+type recorder struct {
+	mutex  sync.Mutex
+	values []string
+}
+
+func (r *recorder) callback(e Event) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.values = append(r.values, e.String())
+}
+
+func (r *recorder) get() []string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]string{}, r.values...)
+}
+
 func TestMethods(t *testing.T) {
 	assert.Equal(t, 7, len(events))
 	assert.Equal(t, "debug", LogDebug.String())
@@ -16,3 +52,163 @@ func TestMethods(t *testing.T) {
 	assert.Equal(t, "update", LoaderUpdate.String())
 	assert.Equal(t, "stop", LoaderStop.String())
 }
+
+func TestEventBusOrder(t *testing.T) {
+	bus := NewEventBus()
+	first := &recorder{}
+	second := &recorder{}
+	bus.Subscribe(first.callback)
+	bus.Subscribe(second.callback)
+
+	var expected []string
+	for i := 0; i < 100; i++ {
+		value := fmt.Sprintf("event-%d", i)
+		expected = append(expected, value)
+		bus.Publish(newTestEvent(LogInfo, "test", value))
+	}
+	bus.Flush()
+
+	assert.Equal(t, expected, first.get())
+	assert.Equal(t, expected, second.get())
+}
+
+func TestEventBusSubscribeTime(t *testing.T) {
+	bus := NewEventBus()
+	publishTime := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	bus.now = func() time.Time {
+		return publishTime
+	}
+
+	// the backlog retains the original time
+	bus.Publish(newTestEvent(LogInfo, "test", "event-0"))
+	var times []time.Time
+	bus.SubscribeTime(func(e Event, published time.Time) {
+		// slow delivery
+		time.Sleep(10 * time.Millisecond)
+		times = append(times, published)
+	})
+	bus.Publish(newTestEvent(LogInfo, "test", "event-1"))
+	bus.Flush()
+
+	assert.Equal(t, []time.Time{publishTime, publishTime}, times)
+}
+
+func TestEventBusFilter(t *testing.T) {
+	bus := NewEventBus()
+	kinds := &recorder{}
+	sources := &recorder{}
+	both := &recorder{}
+	bus.Subscribe(kinds.callback, KindFilter(LogWarning, LogError))
+	bus.Subscribe(sources.callback, SourceFilter("docker"))
+	bus.Subscribe(both.callback, KindFilter(LogInfo), SourceFilter("kube"))
+
+	bus.Publish(newTestEvent(LogInfo, "docker", "a"))
+	bus.Publish(newTestEvent(LogError, "kube", "b"))
+	bus.Publish(newTestEvent(LogInfo, "kube", "c"))
+	bus.Publish(newTestEvent(LogWarning, "docker", "d"))
+	bus.Flush()
+
+	assert.Equal(t, []string{"b", "d"}, kinds.get())
+	assert.Equal(t, []string{"a", "d"}, sources.get())
+	assert.Equal(t, []string{"c"}, both.get())
+}
+
+func TestEventBusUnsubscribe(t *testing.T) {
+	bus := NewEventBus()
+	active := &recorder{}
+	removed := &recorder{}
+	bus.Subscribe(active.callback)
+	subscription := bus.Subscribe(removed.callback)
+
+	bus.Publish(newTestEvent(LogInfo, "test", "before"))
+	subscription.Unsubscribe()
+	subscription.Unsubscribe()
+	bus.Publish(newTestEvent(LogInfo, "test", "after"))
+	bus.Flush()
+
+	assert.Equal(t, []string{"before", "after"}, active.get())
+	// the pending events are delivered
+	assert.Eventually(t, func() bool {
+		return len(removed.get()) == 1
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"before"}, removed.get())
+}
+
+func TestEventBusClose(t *testing.T) {
+	bus := NewEventBus()
+	subscriber := &recorder{}
+	bus.Subscribe(func(e Event) {
+		time.Sleep(time.Millisecond)
+		subscriber.callback(e)
+	})
+
+	bus.Publish(newTestEvent(LogInfo, "test", "a"))
+	bus.Publish(newTestEvent(LogInfo, "test", "b"))
+	bus.Close()
+	// all the pending events are delivered before returning
+	assert.Equal(t, []string{"a", "b"}, subscriber.get())
+
+	bus.Publish(newTestEvent(LogInfo, "test", "ignored"))
+	late := &recorder{}
+	bus.Subscribe(late.callback)
+	bus.Flush()
+	assert.Equal(t, []string{"a", "b"}, subscriber.get())
+	assert.Empty(t, late.get())
+}
+
+func TestEventBusBacklog(t *testing.T) {
+	bus := NewEventBus()
+	bus.Publish(newTestEvent(LogDebug, "test", "init"))
+
+	first := &recorder{}
+	second := &recorder{}
+	bus.Subscribe(first.callback)
+	bus.Subscribe(second.callback)
+	bus.Publish(newTestEvent(LogInfo, "test", "live"))
+
+	// not replayed anymore
+	third := &recorder{}
+	bus.Subscribe(third.callback)
+	bus.Flush()
+
+	assert.Equal(t, []string{"init", "live"}, first.get())
+	assert.Equal(t, []string{"init", "live"}, second.get())
+	assert.Empty(t, third.get())
+}
+
+func TestEventBusPublishFromCallback(t *testing.T) {
+	bus := NewEventBus()
+	subscriber := &recorder{}
+	bus.Subscribe(func(e Event) {
+		subscriber.callback(e)
+		if e.String() == "first" {
+			bus.Publish(newTestEvent(LogInfo, "test", "second"))
+		}
+	})
+
+	bus.Publish(newTestEvent(LogInfo, "test", "first"))
+	bus.Flush()
+
+	assert.Equal(t, []string{"first", "second"}, subscriber.get())
+}
+
+func TestPayloadOf(t *testing.T) {
+	payload := ResourcePayload{Action: ResourceCreate, Type: "container", Name: "box-alpine-abc", Id: "123"}
+	e := &testEvent{kind: LogInfo, source: "docker", payload: payload}
+
+	value, ok := PayloadOf[ResourcePayload](e)
+	assert.True(t, ok)
+	assert.Equal(t, payload, value)
+
+	_, ok = PayloadOf[string](e)
+	assert.False(t, ok)
+
+	_, ok = PayloadOf[ResourcePayload](&noPayloadEvent{})
+	assert.False(t, ok)
+}
+
+type noPayloadEvent struct{}
+
+func (e *noPayloadEvent) Kind() EventKind { return LogInfo }
+func (e *noPayloadEvent) Source() string  { return "test" }
+func (e *noPayloadEvent) String() string  { return "" }
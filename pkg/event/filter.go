@@ -0,0 +1,22 @@
+package event
+
+import (
+	"golang.org/x/exp/slices"
+)
+
+// Filter returns true if the event must be delivered
+type Filter func(event Event) bool
+
+// KindFilter matches any of the given kinds
+func KindFilter(kinds ...EventKind) Filter {
+	return func(event Event) bool {
+		return slices.Contains(kinds, event.Kind())
+	}
+}
+
+// SourceFilter matches any of the given sources e.g. docker, kube, cloud
+func SourceFilter(sources ...string) Filter {
+	return func(event Event) bool {
+		return slices.Contains(sources, event.Source())
+	}
+}
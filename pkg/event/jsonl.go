@@ -0,0 +1,69 @@
+package event
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/hckops/hckctl/pkg/util"
+)
+
+// JsonlSchemaVersion changes only if a field is removed or its meaning changes, new optional fields can be added anytime
+const JsonlSchemaVersion = 1
+
+// JsonlEvent is the stable schema of the event stream, see docs/events.md
+type JsonlEvent struct {
+	Version  int       `json:"version"`
+	Time     time.Time `json:"time"`
+	Kind     string    `json:"kind"`
+	Source   string    `json:"source"`
+	Provider string    `json:"provider,omitempty"`
+	Name     string    `json:"name,omitempty"` // box, task or lab
+	Message  string    `json:"message"`
+	Data     any       `json:"data,omitempty"`
+}
+
+// JsonlAttributes are added to all the events of a subscription
+type JsonlAttributes struct {
+	Provider string
+	Name     string
+}
+
+// JsonlWriter serializes the events as json lines, one event per line
+type JsonlWriter struct {
+	mutex   sync.Mutex
+	encoder *json.Encoder
+}
+
+func NewJsonlWriter(writer io.Writer) *JsonlWriter {
+	return &JsonlWriter{encoder: json.NewEncoder(writer)}
+}
+
+// Subscribe writes all the events published on the bus, the secrets in the messages are masked
+func (w *JsonlWriter) Subscribe(eventBus *EventBus, attributes JsonlAttributes) *Subscription {
+	return eventBus.SubscribeTime(func(e Event, published time.Time) {
+		w.Write(e, published, attributes)
+	})
+}
+
+// Write ignores errors, a broken stream must never interrupt the command
+func (w *JsonlWriter) Write(e Event, published time.Time, attributes JsonlAttributes) {
+	value := &JsonlEvent{
+		Version:  JsonlSchemaVersion,
+		Time:     published.UTC(),
+		Kind:     e.Kind().String(),
+		Source:   e.Source(),
+		Provider: attributes.Provider,
+		Name:     attributes.Name,
+		Message:  util.RedactSecrets(e.String()),
+	}
+	if payloadEvent, ok := e.(PayloadEvent); ok {
+		value.Data = payloadEvent.Payload()
+	}
+
+	// the subscribers of different buses might share the same writer
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.encoder.Encode(value)
+}
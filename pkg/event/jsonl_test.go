@@ -0,0 +1,48 @@
+package event
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJsonlWriter(t *testing.T) {
+	var out bytes.Buffer
+	writer := NewJsonlWriter(&out)
+
+	bus := NewEventBus()
+	bus.now = func() time.Time {
+		return time.Date(2024, 1, 1, 12, 0, 0, 0, time.FixedZone("CET", 3600))
+	}
+	writer.Subscribe(bus, JsonlAttributes{Provider: "docker", Name: "box-alpine-abc"})
+	bus.Publish(newTestEvent(PrintConsole, "docker", "[box-alpine-abc] TTYD_PASSWORD=alpine"))
+	bus.Publish(&testEvent{kind: LogInfo, source: "docker", value: "container create",
+		payload: ResourcePayload{Action: ResourceCreate, Type: "container", Name: "box-alpine-abc", Id: "123"}})
+	bus.Flush()
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Equal(t, `{"version":1,"time":"2024-01-01T11:00:00Z","kind":"console","source":"docker","provider":"docker","name":"box-alpine-abc","message":"[box-alpine-abc] TTYD_PASSWORD=********"}`, lines[0])
+	assert.Equal(t, `{"version":1,"time":"2024-01-01T11:00:00Z","kind":"info","source":"docker","provider":"docker","name":"box-alpine-abc","message":"container create","data":{"action":"create","type":"container","name":"box-alpine-abc","id":"123"}}`, lines[1])
+
+	var value JsonlEvent
+	assert.NoError(t, json.Unmarshal([]byte(lines[1]), &value))
+	assert.Equal(t, JsonlSchemaVersion, value.Version)
+}
+
+func TestJsonlWriterOptionalFields(t *testing.T) {
+	var out bytes.Buffer
+	NewJsonlWriter(&out).Write(&noPayloadEvent{}, time.Now(), JsonlAttributes{})
+
+	var fields map[string]any
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &fields))
+	assert.NotContains(t, fields, "provider")
+	assert.NotContains(t, fields, "name")
+	assert.NotContains(t, fields, "data")
+	assert.Contains(t, fields, "message")
+}
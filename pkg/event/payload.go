@@ -0,0 +1,33 @@
+package event
+
+// PayloadEvent is implemented by the events which carry structured data, the string is meant only for humans
+type PayloadEvent interface {
+	Event
+	Payload() any
+}
+
+// PayloadOf returns the typed payload of an event, if any
+func PayloadOf[T any](event Event) (T, bool) {
+	if payloadEvent, ok := event.(PayloadEvent); ok {
+		value, ok := payloadEvent.Payload().(T)
+		return value, ok
+	}
+	var zero T
+	return zero, false
+}
+
+type ResourceAction string
+
+const (
+	ResourceCreate ResourceAction = "create"
+	ResourceDelete ResourceAction = "delete"
+)
+
+// ResourcePayload describes a resource created or deleted by a provider e.g. a container, a deployment or a job
+type ResourcePayload struct {
+	Action    ResourceAction `json:"action"`
+	Type      string         `json:"type"`
+	Name      string         `json:"name,omitempty"`
+	Id        string         `json:"id,omitempty"`
+	Namespace string         `json:"namespace,omitempty"`
+}
@@ -1,6 +1,8 @@
 package lab
 
 import (
+	"context"
+
 	"github.com/pkg/errors"
 
 	"github.com/hckops/hckctl/pkg/event"
@@ -11,15 +13,18 @@ import (
 type LabClient interface {
 	Provider() model.LabProvider
 	Events() *event.EventBus
-	Create(opts *model.CreateOptions) (*model.LabInfo, error)
+	Create(ctx context.Context, opts *model.CreateOptions) (*model.LabInfo, error)
+	Describe(ctx context.Context, name string) (*model.LabDetails, error)
+	List(ctx context.Context) ([]model.LabInfo, error)
+	Delete(ctx context.Context, names []string) ([]string, error) // deletes all if empty
 }
 
 // TODO generics Box/Lab
-func NewLabClient(opts *model.LabClientOptions) (LabClient, error) {
+func NewLabClient(ctx context.Context, opts *model.LabClientOptions) (LabClient, error) {
 	commonOpts := model.NewCommonLabOpts()
 	switch opts.Provider {
 	case model.Cloud:
-		return cloud.NewCloudLabClient(commonOpts, opts.CloudOpts)
+		return cloud.NewCloudLabClient(ctx, commonOpts, opts.CloudOpts)
 	default:
 		return nil, errors.New("invalid provider")
 	}
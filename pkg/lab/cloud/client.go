@@ -1,6 +1,7 @@
 package cloud
 
 import (
+	"context"
 	"github.com/hckops/hckctl/pkg/client/ssh"
 	commonModel "github.com/hckops/hckctl/pkg/common/model"
 	"github.com/hckops/hckctl/pkg/event"
@@ -13,8 +14,8 @@ type CloudLabClient struct {
 	eventBus   *event.EventBus
 }
 
-func NewCloudLabClient(commonOpts *labModel.CommonLabOptions, cloudOpts *commonModel.CloudOptions) (*CloudLabClient, error) {
-	return newCloudLabClient(commonOpts, cloudOpts)
+func NewCloudLabClient(ctx context.Context, commonOpts *labModel.CommonLabOptions, cloudOpts *commonModel.CloudOptions) (*CloudLabClient, error) {
+	return newCloudLabClient(ctx, commonOpts, cloudOpts)
 }
 
 func (lab *CloudLabClient) Provider() labModel.LabProvider {
@@ -25,6 +26,22 @@ func (lab *CloudLabClient) Events() *event.EventBus {
 	return lab.eventBus
 }
 
-func (lab *CloudLabClient) Create(opts *labModel.CreateOptions) (*labModel.LabInfo, error) {
-	return lab.createLab(opts)
+func (lab *CloudLabClient) Create(ctx context.Context, opts *labModel.CreateOptions) (*labModel.LabInfo, error) {
+	defer lab.close()
+	return lab.createLab(ctx, opts)
+}
+
+func (lab *CloudLabClient) Describe(ctx context.Context, name string) (*labModel.LabDetails, error) {
+	defer lab.close()
+	return lab.describeLab(ctx, name)
+}
+
+func (lab *CloudLabClient) List(ctx context.Context) ([]labModel.LabInfo, error) {
+	defer lab.close()
+	return lab.listLabs(ctx)
+}
+
+func (lab *CloudLabClient) Delete(ctx context.Context, names []string) ([]string, error) {
+	defer lab.close()
+	return lab.deleteLabs(ctx, names)
 }
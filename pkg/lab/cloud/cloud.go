@@ -1,6 +1,9 @@
 package cloud
 
 import (
+	"context"
+	"time"
+
 	"github.com/pkg/errors"
 
 	v1 "github.com/hckops/hckctl/pkg/api/v1"
@@ -9,18 +12,37 @@ import (
 	labModel "github.com/hckops/hckctl/pkg/lab/model"
 )
 
-func newCloudLabClient(commonOpts *labModel.CommonLabOptions, cloudOpts *commonModel.CloudOptions) (*CloudLabClient, error) {
+// creating a lab could require to pull the images
+const createRequestTimeout = 10 * time.Minute
+
+func newCloudLabClient(ctx context.Context, commonOpts *labModel.CommonLabOptions, cloudOpts *commonModel.CloudOptions) (*CloudLabClient, error) {
 	commonOpts.EventBus.Publish(newInitCloudClientEvent())
 
 	clientConfig := &ssh.SshClientConfig{
-		Address:  cloudOpts.Address,
-		Username: cloudOpts.Username,
-		Token:    cloudOpts.Token,
+		Address:        cloudOpts.Address,
+		Username:       cloudOpts.Username,
+		Token:          cloudOpts.Token,
+		PrivateKeyPath: cloudOpts.PrivateKeyPath,
+		UseAgent:       cloudOpts.UseAgent,
+		KnownHostsPath: cloudOpts.KnownHostsPath,
+		Fingerprint:    cloudOpts.Fingerprint,
+		OnReconnectCallback: func(attempt int, err error) {
+			commonOpts.EventBus.Publish(newReconnectCloudClientEvent(attempt, err))
+		},
+		OnTrustHostCallback: func(host string, fingerprint string) {
+			commonOpts.EventBus.Publish(newTrustHostCloudClientEvent(host, fingerprint))
+			commonOpts.EventBus.Publish(newTrustHostCloudClientConsoleEvent(host, fingerprint))
+		},
 	}
-	sshClient, err := ssh.NewSshClient(clientConfig)
+	sshClient, err := ssh.NewSshClient(ctx, clientConfig)
 	if err != nil {
 		return nil, errors.Wrap(err, "error cloud lab")
 	}
+	// detects incompatible servers before any other request
+	if _, err := v1.Negotiate(ctx, cloudOpts.Version, sshClient.SendRequest); err != nil {
+		sshClient.Close()
+		return nil, errors.Wrap(err, "error cloud lab version")
+	}
 
 	return &CloudLabClient{
 		client:     sshClient,
@@ -29,17 +51,23 @@ func newCloudLabClient(commonOpts *labModel.CommonLabOptions, cloudOpts *commonM
 	}, nil
 }
 
-func (lab *CloudLabClient) createLab(opts *labModel.CreateOptions) (*labModel.LabInfo, error) {
+func (lab *CloudLabClient) close() error {
+	lab.eventBus.Publish(newCloseCloudClientEvent())
+	lab.eventBus.Flush()
+	return lab.client.Close()
+}
+
+func (lab *CloudLabClient) createLab(ctx context.Context, opts *labModel.CreateOptions) (*labModel.LabInfo, error) {
 	lab.eventBus.Publish(newApiCreateCloudLoaderEvent(lab.clientOpts.Address, opts.LabTemplate.Name))
 
-	request := v1.NewLabCreateRequest(lab.clientOpts.Version, opts.LabTemplate.Name, opts.Parameters)
+	request := v1.NewLabCreateRequest(lab.clientOpts.Version, opts.LabTemplate.Name, opts.Parameters).WithRequestId(v1.NewRequestId())
 	payload, err := request.Encode()
 	if err != nil {
 		return nil, errors.Wrap(err, "error cloud lab create request")
 	}
-	value, err := lab.client.SendRequest(request.Protocol(), payload)
+	value, err := lab.client.SendRequestTimeout(ctx, request.Protocol(), payload, createRequestTimeout)
 	if err != nil {
-		return nil, errors.Wrap(err, "error cloud lab create")
+		return nil, errors.Wrap(v1.ToError(err), "error cloud lab create")
 	}
 
 	response, err := v1.Decode[v1.LabCreateResponseBody](value)
@@ -51,3 +79,101 @@ func (lab *CloudLabClient) createLab(opts *labModel.CreateOptions) (*labModel.La
 
 	return &labModel.LabInfo{Id: labName, Name: labName}, nil
 }
+
+func (lab *CloudLabClient) describeLab(ctx context.Context, name string) (*labModel.LabDetails, error) {
+	lab.eventBus.Publish(newApiDescribeCloudEvent(name))
+
+	request := v1.NewLabDescribeRequest(lab.clientOpts.Version, name).WithRequestId(v1.NewRequestId())
+	payload, err := request.Encode()
+	if err != nil {
+		return nil, errors.Wrap(err, "error cloud lab describe request")
+	}
+	value, err := lab.client.SendRequest(ctx, request.Protocol(), payload)
+	if err != nil {
+		return nil, errors.Wrap(v1.ToError(err), "error cloud lab describe")
+	}
+
+	response, err := v1.Decode[v1.LabDescribeResponseBody](value)
+	if err != nil {
+		return nil, errors.Wrap(err, "error cloud lab describe response")
+	}
+
+	return toLabDetails(response)
+}
+
+func toLabDetails(response *v1.Message[v1.LabDescribeResponseBody]) (*labModel.LabDetails, error) {
+
+	created, err := time.Parse(time.RFC3339, response.Body.Created)
+	if err != nil {
+		return nil, errors.Wrap(err, "error cloud lab details created")
+	}
+
+	return &labModel.LabDetails{
+		Info: labModel.LabInfo{
+			Id:      response.Body.Id,
+			Name:    response.Body.Name,
+			Healthy: response.Body.Healthy,
+		},
+		TemplateName: response.Body.TemplateName,
+		Box: labModel.LabBoxDetails{
+			Name:         response.Body.Box.Name,
+			Alias:        response.Body.Box.Alias,
+			TemplateName: response.Body.Box.TemplateName,
+			Size:         response.Body.Box.Size,
+			Vpn:          response.Body.Box.Vpn,
+			Ports:        response.Body.Box.Ports,
+			Dumps:        response.Body.Box.Dumps,
+		},
+		Created: created,
+	}, nil
+}
+
+func (lab *CloudLabClient) listLabs(ctx context.Context) ([]labModel.LabInfo, error) {
+
+	request := v1.NewLabListRequest(lab.clientOpts.Version).WithRequestId(v1.NewRequestId())
+	payload, err := request.Encode()
+	if err != nil {
+		return nil, errors.Wrap(err, "error cloud lab list request")
+	}
+	value, err := lab.client.SendRequest(ctx, request.Protocol(), payload)
+	if err != nil {
+		return nil, errors.Wrap(v1.ToError(err), "error cloud lab list")
+	}
+
+	response, err := v1.Decode[v1.LabListResponseBody](value)
+	if err != nil {
+		return nil, errors.Wrap(err, "error cloud lab list response")
+	}
+
+	var result []labModel.LabInfo
+	for index, item := range response.Body.Items {
+		result = append(result, labModel.LabInfo{Id: item.Id, Name: item.Name, Healthy: item.Healthy})
+		lab.eventBus.Publish(newApiListCloudEvent(index, item.Name))
+	}
+	return result, nil
+}
+
+func (lab *CloudLabClient) deleteLabs(ctx context.Context, names []string) ([]string, error) {
+
+	request := v1.NewLabDeleteRequest(lab.clientOpts.Version, names).WithRequestId(v1.NewRequestId())
+	payload, err := request.Encode()
+	if err != nil {
+		return nil, errors.Wrap(err, "error cloud lab delete request")
+	}
+	value, err := lab.client.SendRequest(ctx, request.Protocol(), payload)
+	if err != nil {
+		return nil, errors.Wrap(v1.ToError(err), "error cloud lab delete")
+	}
+
+	response, err := v1.Decode[v1.LabDeleteResponseBody](value)
+	if err != nil {
+		return nil, errors.Wrap(err, "error cloud lab delete response")
+	}
+
+	var result []string
+	for index, name := range response.Body.Names {
+		result = append(result, name)
+		lab.eventBus.Publish(newApiDeleteCloudEvent(index, name))
+	}
+	return result, nil
+}
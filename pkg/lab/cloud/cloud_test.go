@@ -0,0 +1,62 @@
+package cloud
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	v1 "github.com/hckops/hckctl/pkg/api/v1"
+	labModel "github.com/hckops/hckctl/pkg/lab/model"
+)
+
+func TestToLabDetails(t *testing.T) {
+	created := "2042-12-08T10:30:05.265113665Z"
+	createdTime, _ := time.Parse(time.RFC3339, created)
+
+	message := v1.NewLabDescribeResponse("hckadm-0.0.0-info", v1.LabDescribeResponseBody{
+		Id:           "myId",
+		Name:         "lab-ctf-vpn-123",
+		Created:      created,
+		Healthy:      true,
+		TemplateName: "ctf-vpn",
+		Box: v1.LabDescribeBoxInfo{
+			Name:         "box-parrot-123",
+			Alias:        "myAlias",
+			TemplateName: "parrot",
+			Size:         "M",
+			Vpn:          "htb",
+			Ports:        []string{"tty/https://tty.example.com"},
+			Dumps:        []string{"dump-1"},
+		},
+	})
+	expected := &labModel.LabDetails{
+		Info: labModel.LabInfo{
+			Id:      "myId",
+			Name:    "lab-ctf-vpn-123",
+			Healthy: true,
+		},
+		TemplateName: "ctf-vpn",
+		Box: labModel.LabBoxDetails{
+			Name:         "box-parrot-123",
+			Alias:        "myAlias",
+			TemplateName: "parrot",
+			Size:         "M",
+			Vpn:          "htb",
+			Ports:        []string{"tty/https://tty.example.com"},
+			Dumps:        []string{"dump-1"},
+		},
+		Created: createdTime,
+	}
+	result, err := toLabDetails(message)
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, result)
+}
+
+func TestToLabDetailsInvalidCreated(t *testing.T) {
+	message := v1.NewLabDescribeResponse("hckadm-0.0.0-info", v1.LabDescribeResponseBody{Created: "invalid"})
+	_, err := toLabDetails(message)
+
+	assert.ErrorContains(t, err, "error cloud lab details created")
+}
@@ -28,6 +28,25 @@ func newInitCloudClientEvent() *cloudLabEvent {
 	return &cloudLabEvent{kind: event.LogDebug, value: "init cloud client"}
 }
 
+func newCloseCloudClientEvent() *cloudLabEvent {
+	return &cloudLabEvent{kind: event.LogDebug, value: "close cloud client"}
+}
+
+func newReconnectCloudClientEvent(attempt int, err error) *cloudLabEvent {
+	if err != nil {
+		return &cloudLabEvent{kind: event.LogWarning, value: fmt.Sprintf("reconnect cloud client: attempt=%d error=%v", attempt, err)}
+	}
+	return &cloudLabEvent{kind: event.LogInfo, value: fmt.Sprintf("reconnect cloud client: attempt=%d", attempt)}
+}
+
+func newTrustHostCloudClientEvent(host string, fingerprint string) *cloudLabEvent {
+	return &cloudLabEvent{kind: event.LogWarning, value: fmt.Sprintf("trust new host: host=%s fingerprint=%s", host, fingerprint)}
+}
+
+func newTrustHostCloudClientConsoleEvent(host string, fingerprint string) *cloudLabEvent {
+	return &cloudLabEvent{kind: event.PrintConsole, value: fmt.Sprintf("trusting new host %s with fingerprint %s, verify it with the server administrator", host, fingerprint)}
+}
+
 func newApiCreateCloudLoaderEvent(address string, templateName string) *cloudLabEvent {
 	return &cloudLabEvent{kind: event.LoaderUpdate, value: fmt.Sprintf("loading %s/%s", address, templateName)}
 }
@@ -35,3 +54,15 @@ func newApiCreateCloudLoaderEvent(address string, templateName string) *cloudLab
 func newApiCreateCloudEvent(templateName string, labName string) *cloudLabEvent {
 	return &cloudLabEvent{kind: event.LogInfo, value: fmt.Sprintf("api create: templateName=%s labName=%s", templateName, labName)}
 }
+
+func newApiDescribeCloudEvent(labName string) *cloudLabEvent {
+	return &cloudLabEvent{kind: event.LogInfo, value: fmt.Sprintf("api describe: labName=%s", labName)}
+}
+
+func newApiListCloudEvent(index int, labName string) *cloudLabEvent {
+	return &cloudLabEvent{kind: event.LogInfo, value: fmt.Sprintf("api list: (%d) labName=%s", index, labName)}
+}
+
+func newApiDeleteCloudEvent(index int, labName string) *cloudLabEvent {
+	return &cloudLabEvent{kind: event.LogInfo, value: fmt.Sprintf("api delete: (%d) labName=%s", index, labName)}
+}
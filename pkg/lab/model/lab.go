@@ -49,8 +49,14 @@ func (t *BoxTemplate) Merge(original *boxModel.BoxV1) *boxModel.BoxV1 {
 	return original
 }
 
+// Pretty redacts the secrets of the box overrides
 func (lab *LabV1) Pretty() string {
-	value, _ := util.EncodeJsonIndent(lab)
+	redacted := *lab
+	redacted.Box.Template.Env = nil
+	for _, e := range lab.Box.Template.Env {
+		redacted.Box.Template.Env = append(redacted.Box.Template.Env, util.RedactSecrets(e))
+	}
+	value, _ := util.EncodeJsonIndent(redacted)
 	return value
 }
 
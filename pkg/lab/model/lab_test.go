@@ -184,3 +184,21 @@ func TestExpandAliasMissing(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, expected, result)
 }
+
+func TestPrettyRedacted(t *testing.T) {
+	testLab := &LabV1{
+		Box: LabBox{
+			Template: BoxTemplate{
+				Env: []string{
+					"MY_KEY=myValue",
+					"PASSWORD=${password:random}",
+				},
+			},
+		},
+	}
+	pretty := testLab.Pretty()
+
+	assert.Contains(t, pretty, `"MY_KEY=myValue"`)
+	assert.Contains(t, pretty, `"PASSWORD=********"`)
+	assert.Equal(t, "PASSWORD=${password:random}", testLab.Box.Template.Env[1])
+}
@@ -1,7 +1,29 @@
 package model
 
+import (
+	"time"
+)
+
 type LabInfo struct {
 	Id      string
 	Name    string
 	Healthy bool // TODO
 }
+
+type LabDetails struct {
+	Info         LabInfo
+	TemplateName string
+	Box          LabBoxDetails
+	Created      time.Time
+}
+
+// LabBoxDetails is the runtime state of a LabBox
+type LabBoxDetails struct {
+	Name         string
+	Alias        string
+	TemplateName string
+	Size         string
+	Vpn          string
+	Ports        []string // public
+	Dumps        []string // mounted
+}
@@ -93,8 +93,8 @@ func SetFileOutput(filePath string) (func() error, error) {
 		return nil, errors.Wrap(err, "error creating log file")
 	}
 
-	// default
-	SetFormat(TextLogFormat.String(), file)
+	// default, secrets are never written to file
+	SetFormat(TextLogFormat.String(), util.NewRedactWriter(file))
 
 	return closeFileCallback(file), nil
 }
@@ -18,6 +18,9 @@ var taskV1Schema string
 //go:embed dump-v1.json
 var dumpV1Schema string
 
+//go:embed scope-v1.json
+var scopeV1Schema string
+
 type SchemaKind int
 
 const (
@@ -29,6 +32,7 @@ const (
 	KindTaskV1
 	KindFlowV1
 	KindDumpV1
+	KindScopeV1
 )
 
 var kinds = map[SchemaKind]string{
@@ -40,6 +44,7 @@ var kinds = map[SchemaKind]string{
 	KindTaskV1:    "task/v1",
 	KindFlowV1:    "flow/v1",
 	KindDumpV1:    "dump/v1",
+	KindScopeV1:   "scope/v1",
 }
 
 func (s SchemaKind) String() string {
@@ -7,7 +7,7 @@ import (
 )
 
 func TestProviderFlag(t *testing.T) {
-	assert.Equal(t, 8, len(kinds))
+	assert.Equal(t, 9, len(kinds))
 	assert.Equal(t, "config/v1", KindConfigV1.String())
 	assert.Equal(t, "api/v1", KindApiV1.String())
 	assert.Equal(t, "sidecar/v1", KindSidecarV1.String())
@@ -16,4 +16,5 @@ func TestProviderFlag(t *testing.T) {
 	assert.Equal(t, "task/v1", KindTaskV1.String())
 	assert.Equal(t, "flow/v1", KindFlowV1.String())
 	assert.Equal(t, "dump/v1", KindDumpV1.String())
+	assert.Equal(t, "scope/v1", KindScopeV1.String())
 }
@@ -1,6 +1,7 @@
 package schema
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"github.com/pkg/errors"
@@ -48,6 +49,11 @@ func ValidateDumpV1(data string) error {
 	return validateSchema("dump-v1.json", dumpV1Schema, data)
 }
 
+// ValidateScopeV1 is not a template, it's excluded from ValidateAll
+func ValidateScopeV1(data string) error {
+	return validateSchema("scope-v1.json", scopeV1Schema, data)
+}
+
 // returns nil if valid
 func validateSchema(schemaName string, schemaValue string, data string) error {
 	schema, err := jsonschema.CompileString(schemaName, schemaValue)
@@ -59,6 +65,10 @@ func validateSchema(schemaName string, schemaValue string, data string) error {
 	if err := yaml.Unmarshal([]byte(data), &model); err != nil {
 		return fmt.Errorf("yaml error: %v", err)
 	}
+	// unquoted yaml timestamps are decoded as time.Time, which is not a valid json type
+	if model, err = normalizeModel(model); err != nil {
+		return fmt.Errorf("yaml error: %v", err)
+	}
 
 	if err := schema.Validate(model); err != nil {
 		return fmt.Errorf("validation error: %v", err)
@@ -66,3 +76,15 @@ func validateSchema(schemaName string, schemaValue string, data string) error {
 
 	return nil
 }
+
+func normalizeModel(model interface{}) (interface{}, error) {
+	value, err := json.Marshal(model)
+	if err != nil {
+		return nil, err
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(value, &normalized); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
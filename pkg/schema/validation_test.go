@@ -2,6 +2,7 @@ package schema
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -292,3 +293,65 @@ func TestValidTaskV1(t *testing.T) {
 		}`
 	assert.NoError(t, ValidateTaskV1(data))
 }
+
+func TestValidScopeV1(t *testing.T) {
+	data := `
+kind: scope/v1
+name: my-name
+targets:
+  cidrs:
+    - 10.10.10.0/24
+  hostnames:
+    - app.example.com
+  domains:
+    - example.org
+window:
+  start: 2024-01-01T09:00:00Z
+  end: 2024-01-31T18:00:00Z
+`
+	assert.NoError(t, ValidateScopeV1(data))
+}
+
+func TestScopeInvalidTargets(t *testing.T) {
+	data :=
+		`{
+			"kind": "scope/v1",
+			"name": "my-name",
+			"targets": {
+				"urls": ["http://example.com"]
+			}
+		}`
+	assert.ErrorContains(t, ValidateScopeV1(data), "additionalProperties 'urls' not allowed")
+}
+
+func TestBoxSecrets(t *testing.T) {
+	data :=
+		`{
+			"kind": "box/v1",
+			"name": "my-name",
+			"tags": ["my-tag"],
+			"image": {
+				"repository": "hckops/my-image"
+			},
+			"env": ["MY_LICENSE=abc"],
+			"secrets": ["MY_LICENSE"]
+		}`
+	assert.NoError(t, ValidateBoxV1(data))
+
+	invalid := strings.Replace(data, `["MY_LICENSE"]`, `[]`, 1)
+	assert.ErrorContains(t, ValidateBoxV1(invalid), "minimum 1 items required")
+}
+
+func TestTaskEnv(t *testing.T) {
+	data :=
+		`{
+			"kind": "task/v1",
+			"name": "my-name",
+			"tags": ["my-tag"],
+			"env": ["SHODAN_API_KEY=${key:secret:shodan}"]
+		}`
+	assert.NoError(t, ValidateTaskV1(data))
+
+	invalid := strings.Replace(data, `SHODAN_API_KEY=`, `SHODAN API KEY=`, 1)
+	assert.ErrorContains(t, ValidateTaskV1(invalid), "does not match pattern")
+}
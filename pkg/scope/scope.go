@@ -0,0 +1,229 @@
+package scope
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+
+	"github.com/hckops/hckctl/pkg/schema"
+)
+
+const (
+	ScopeFileName = ".hck-scope.yml"
+)
+
+// ScopeV1 defines the authorized targets of an engagement, anything not explicitly listed is out of scope
+type ScopeV1 struct {
+	Kind    string
+	Name    string
+	Targets ScopeTargets
+	Window  ScopeWindow
+}
+
+type ScopeTargets struct {
+	Cidrs     []string
+	Hostnames []string
+	Domains   []string
+}
+
+// ScopeWindow bounds are optional
+type ScopeWindow struct {
+	Start *time.Time
+	End   *time.Time
+}
+
+// LoadScope reads and validates a scope file
+func LoadScope(path string) (*ScopeV1, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading scope: path=%s", path)
+	}
+	if err := schema.ValidateScopeV1(string(data)); err != nil {
+		return nil, errors.Wrapf(err, "invalid scope: path=%s", path)
+	}
+
+	var scope ScopeV1
+	if err := yaml.Unmarshal(data, &scope); err != nil {
+		return nil, errors.Wrapf(err, "error decoding scope: path=%s", path)
+	}
+	if _, err := scope.networks(); err != nil {
+		return nil, errors.Wrapf(err, "invalid scope: path=%s", path)
+	}
+	if scope.Window.Start != nil && scope.Window.End != nil && !scope.Window.End.After(*scope.Window.Start) {
+		return nil, fmt.Errorf("invalid scope window: path=%s start=%s end=%s",
+			path, scope.Window.Start.Format(time.RFC3339), scope.Window.End.Format(time.RFC3339))
+	}
+	return &scope, nil
+}
+
+// FindScopeFile looks for a scope file in the given directory and all its parents
+func FindScopeFile(dir string) (string, bool) {
+	current, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+	for {
+		path := filepath.Join(current, ScopeFileName)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, true
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return "", false
+		}
+		current = parent
+	}
+}
+
+// ValidateWindow returns an error if the time is outside the engagement window
+func (scope *ScopeV1) ValidateWindow(now time.Time) error {
+	if scope.Window.Start != nil && now.Before(*scope.Window.Start) {
+		return fmt.Errorf("engagement %s not started: start=%s", scope.Name, scope.Window.Start.Format(time.RFC3339))
+	}
+	if scope.Window.End != nil && now.After(*scope.Window.End) {
+		return fmt.Errorf("engagement %s ended: end=%s", scope.Name, scope.Window.End.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// ScopeError lists the targets which are not authorized and the values which look like targets but don't parse
+type ScopeError struct {
+	Name         string
+	Targets      []string
+	Unrecognised []string
+}
+
+func (e *ScopeError) Error() string {
+	var reasons []string
+	if len(e.Targets) > 0 {
+		reasons = append(reasons, fmt.Sprintf("targets out of scope of engagement %s: [%s]", e.Name, strings.Join(e.Targets, ",")))
+	}
+	if len(e.Unrecognised) > 0 {
+		reasons = append(reasons, fmt.Sprintf("unrecognised targets for engagement %s: [%s]", e.Name, strings.Join(e.Unrecognised, ",")))
+	}
+	return strings.Join(reasons, ", ")
+}
+
+// ValidateTargets extracts all the addresses, networks, ranges and hostnames from the values
+// and returns a ScopeError if any of them is not authorized or unrecognised
+func (scope *ScopeV1) ValidateTargets(values []string) error {
+	return scope.ValidateInputs(values, nil)
+}
+
+// ValidateInputs validates the values like ValidateTargets, then the inputs sorted by name.
+// The inputs which identify a target e.g. "address" are parsed strictly: every token must be a target
+// and single labels e.g. "intranet" are hostnames
+func (scope *ScopeV1) ValidateInputs(values []string, inputs map[string]string) error {
+	networks, err := scope.networks()
+	if err != nil {
+		return err
+	}
+
+	var targets []Target
+	for _, value := range values {
+		targets = append(targets, extractTargets(value, false)...)
+	}
+	var names []string
+	for name := range inputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		targets = append(targets, extractTargets(inputs[name], IsTargetInput(name))...)
+	}
+
+	var outOfScope []string
+	var unrecognised []string
+	visited := map[string]bool{}
+	for _, target := range targets {
+		if visited[target.Value] {
+			continue
+		}
+		visited[target.Value] = true
+
+		if target.Kind == InvalidTarget {
+			unrecognised = append(unrecognised, target.Value)
+		} else if !scope.isAuthorized(target, networks) {
+			outOfScope = append(outOfScope, target.Value)
+		}
+	}
+	if len(outOfScope) > 0 || len(unrecognised) > 0 {
+		return &ScopeError{Name: scope.Name, Targets: outOfScope, Unrecognised: unrecognised}
+	}
+	return nil
+}
+
+func (scope *ScopeV1) isAuthorized(target Target, networks []*net.IPNet) bool {
+	switch target.Kind {
+	case AddressTarget:
+		ip := net.ParseIP(target.Value)
+		for _, network := range networks {
+			if network.Contains(ip) {
+				return true
+			}
+		}
+	case RangeTarget:
+		// networks are contiguous, a range is authorized if a single network contains both its bounds
+		first, last, _ := parseAddressRange(target.Value)
+		for _, network := range networks {
+			if network.Contains(first) && network.Contains(last) {
+				return true
+			}
+		}
+	case NetworkTarget:
+		_, targetNetwork, _ := net.ParseCIDR(target.Value)
+		targetOnes, targetBits := targetNetwork.Mask.Size()
+		for _, network := range networks {
+			ones, bits := network.Mask.Size()
+			if bits == targetBits && ones <= targetOnes && network.Contains(targetNetwork.IP) {
+				return true
+			}
+		}
+	case HostnameTarget:
+		for _, hostname := range scope.Targets.Hostnames {
+			if normalizeHostname(hostname) == target.Value {
+				return true
+			}
+		}
+		for _, domain := range scope.Targets.Domains {
+			domain = normalizeHostname(domain)
+			if target.Value == domain || strings.HasSuffix(target.Value, "."+domain) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// networks accepts both networks and single addresses
+func (scope *ScopeV1) networks() ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	for _, value := range scope.Targets.Cidrs {
+		if ip := net.ParseIP(value); ip != nil {
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = 8 * net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid scope cidr %s", value)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+func normalizeHostname(value string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(value)), ".")
+}
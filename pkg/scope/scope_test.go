@@ -0,0 +1,137 @@
+package scope
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testScope = `
+kind: scope/v1
+name: my-engagement
+targets:
+  cidrs:
+    - 10.10.10.0/24
+    - 192.168.1.5
+  hostnames:
+    - app.example.com
+  domains:
+    - example.org
+window:
+  start: 2024-01-01T09:00:00Z
+  end: 2024-01-31T18:00:00Z
+`
+
+func writeScope(t *testing.T, dir string, value string) string {
+	path := filepath.Join(dir, ScopeFileName)
+	assert.NoError(t, os.WriteFile(path, []byte(value), 0600))
+	return path
+}
+
+func TestLoadScope(t *testing.T) {
+	scope, err := LoadScope(writeScope(t, t.TempDir(), testScope))
+	assert.NoError(t, err)
+
+	start := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 1, 31, 18, 0, 0, 0, time.UTC)
+	assert.Equal(t, "scope/v1", scope.Kind)
+	assert.Equal(t, "my-engagement", scope.Name)
+	assert.Equal(t, ScopeTargets{
+		Cidrs:     []string{"10.10.10.0/24", "192.168.1.5"},
+		Hostnames: []string{"app.example.com"},
+		Domains:   []string{"example.org"},
+	}, scope.Targets)
+	assert.True(t, start.Equal(*scope.Window.Start))
+	assert.True(t, end.Equal(*scope.Window.End))
+}
+
+func TestLoadScopeInvalid(t *testing.T) {
+	_, err := LoadScope(writeScope(t, t.TempDir(), "kind: scope/v1\nname: my-engagement\ntargets:\n  cidrs:\n    - 10.10.10.0/99\n"))
+	assert.ErrorContains(t, err, "invalid scope cidr 10.10.10.0/99")
+
+	_, err = LoadScope(writeScope(t, t.TempDir(), "kind: scope/v1\nname: my-engagement\nwindow:\n  start: 2024-02-01T00:00:00Z\n  end: 2024-01-01T00:00:00Z\n"))
+	assert.ErrorContains(t, err, "invalid scope window")
+
+	_, err = LoadScope(writeScope(t, t.TempDir(), "kind: box/v1\nname: my-engagement\n"))
+	assert.ErrorContains(t, err, "invalid scope")
+}
+
+func TestFindScopeFile(t *testing.T) {
+	dir := t.TempDir()
+	nested := filepath.Join(dir, "a", "b")
+	assert.NoError(t, os.MkdirAll(nested, 0755))
+
+	_, found := FindScopeFile(nested)
+	assert.False(t, found)
+
+	expected := writeScope(t, dir, testScope)
+	path, found := FindScopeFile(nested)
+	assert.True(t, found)
+	assert.Equal(t, expected, path)
+}
+
+func TestValidateWindow(t *testing.T) {
+	scope, err := LoadScope(writeScope(t, t.TempDir(), testScope))
+	assert.NoError(t, err)
+
+	assert.NoError(t, scope.ValidateWindow(time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)))
+	assert.EqualError(t, scope.ValidateWindow(time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC)),
+		"engagement my-engagement not started: start=2024-01-01T09:00:00Z")
+	assert.EqualError(t, scope.ValidateWindow(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)),
+		"engagement my-engagement ended: end=2024-01-31T18:00:00Z")
+
+	assert.NoError(t, (&ScopeV1{}).ValidateWindow(time.Now()))
+}
+
+func TestValidateTargets(t *testing.T) {
+	scope, err := LoadScope(writeScope(t, t.TempDir(), testScope))
+	assert.NoError(t, err)
+
+	assert.NoError(t, scope.ValidateTargets([]string{
+		"-sV", "10.10.10.1", "10.10.10.128/25", "192.168.1.5:443",
+		"https://app.example.com/login", "example.org", "api.example.org", "/tmp/wordlist.txt",
+		"10.10.10.1-254", "10.10.10.*", "192.168.1.5-5",
+	}))
+
+	err = scope.ValidateTargets([]string{
+		"10.10.11.1", "10.10.0.0/16", "192.168.1.6", "example.com", "www.app.example.com", "notexample.org", "10.10.11.1",
+	})
+	var scopeErr *ScopeError
+	assert.ErrorAs(t, err, &scopeErr)
+	assert.Equal(t, []string{"10.10.11.1", "10.10.0.0/16", "192.168.1.6", "example.com", "www.app.example.com", "notexample.org"}, scopeErr.Targets)
+	assert.EqualError(t, err, "targets out of scope of engagement my-engagement: "+
+		"[10.10.11.1,10.10.0.0/16,192.168.1.6,example.com,www.app.example.com,notexample.org]")
+}
+
+func TestValidateTargetsRange(t *testing.T) {
+	scope, err := LoadScope(writeScope(t, t.TempDir(), testScope))
+	assert.NoError(t, err)
+
+	err = scope.ValidateTargets([]string{"10.10.10-11.1", "10.10.*.1", "192.168.1.4-5", "10.10.10.1-300", "10.10.10"})
+	var scopeErr *ScopeError
+	assert.ErrorAs(t, err, &scopeErr)
+	assert.Equal(t, []string{"10.10.10-11.1", "10.10.*.1", "192.168.1.4-5"}, scopeErr.Targets)
+	assert.Equal(t, []string{"10.10.10.1-300", "10.10.10"}, scopeErr.Unrecognised)
+	assert.EqualError(t, err, "targets out of scope of engagement my-engagement: [10.10.10-11.1,10.10.*.1,192.168.1.4-5], "+
+		"unrecognised targets for engagement my-engagement: [10.10.10.1-300,10.10.10]")
+}
+
+func TestValidateInputs(t *testing.T) {
+	scope, err := LoadScope(writeScope(t, t.TempDir(), testScope))
+	assert.NoError(t, err)
+
+	assert.NoError(t, scope.ValidateInputs([]string{"nmap", "-sV"}, map[string]string{
+		"address": "10.10.10.1-254", "wordlist": "common", "url": "https://app.example.com",
+	}))
+
+	err = scope.ValidateInputs([]string{"nmap", "intranet"}, map[string]string{
+		"wordlist": "common", "target": "intranet", "address": "10.10.10.1 -p",
+	})
+	var scopeErr *ScopeError
+	assert.ErrorAs(t, err, &scopeErr)
+	assert.Equal(t, []string{"intranet"}, scopeErr.Targets)
+	assert.Equal(t, []string{"-p"}, scopeErr.Unrecognised)
+}
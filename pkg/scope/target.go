@@ -0,0 +1,223 @@
+package scope
+
+import (
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type TargetKind uint
+
+const (
+	AddressTarget TargetKind = iota
+	NetworkTarget
+	RangeTarget // nmap style ipv4 octet ranges and wildcards e.g. 10.10.10.1-254 or 10.10.*.1
+	HostnameTarget
+	InvalidTarget // looks like an address but it doesn't parse, never authorized
+)
+
+type Target struct {
+	Kind  TargetKind
+	Value string
+}
+
+// inputs which always contain targets, their values are parsed strictly
+var targetInputs = map[string]bool{
+	"address": true, "domain": true, "host": true, "hostname": true, "ip": true, "rhost": true, "rhosts": true,
+	"target": true, "targets": true, "url": true,
+}
+
+// last labels which are more likely files than domains e.g. wordlist.txt
+var fileExtensions = map[string]bool{
+	"bin": true, "cfg": true, "conf": true, "csv": true, "dat": true, "db": true, "gz": true, "html": true,
+	"ini": true, "jar": true, "js": true, "json": true, "key": true, "log": true, "lst": true, "md": true,
+	"nse": true, "out": true, "ovpn": true, "pcap": true, "pem": true, "php": true, "py": true, "rb": true,
+	"sh": true, "sql": true, "tar": true, "tgz": true, "txt": true, "xml": true, "yaml": true, "yml": true,
+	"zip": true,
+}
+
+// ExtractTargets returns the addresses, networks, ranges and hostnames found in a command argument e.g.
+// "10.10.10.1", "10.10.10.0/24", "http://example.com:8080/path", "--target=example.com,10.0.0.1", "user@host.local:22"
+func ExtractTargets(value string) []Target {
+	return extractTargets(value, false)
+}
+
+// extractTargets in strict mode refuses every token which is not a target and accepts single labels as hostnames
+func extractTargets(value string, strict bool) []Target {
+	var targets []Target
+	for _, token := range strings.FieldsFunc(value, func(r rune) bool {
+		return unicode.IsSpace(r) || r == ',' || r == ';' || r == '"' || r == '\''
+	}) {
+		if target, ok := parseTarget(token, strict); ok {
+			targets = append(targets, target)
+		}
+	}
+	return targets
+}
+
+// IsTargetInput returns true if the input name identifies a target e.g. "address"
+func IsTargetInput(name string) bool {
+	return targetInputs[strings.ToLower(name)]
+}
+
+func parseTarget(value string, strict bool) (Target, bool) {
+	invalid := func() (Target, bool) {
+		return Target{Kind: InvalidTarget, Value: value}, strict
+	}
+
+	token := value
+	// flag value e.g. --url=http://example.com
+	if index := strings.Index(token, "="); index >= 0 && !strings.Contains(token[:index], "://") {
+		token = token[index+1:]
+	}
+	if token == "" || strings.HasPrefix(token, "-") {
+		return invalid()
+	}
+
+	if strings.Contains(token, "://") {
+		if parsed, err := url.Parse(token); err == nil {
+			token = parsed.Hostname()
+		} else {
+			return invalid()
+		}
+	} else {
+		// credentials e.g. user@example.com
+		if index := strings.LastIndex(token, "@"); index >= 0 {
+			token = token[index+1:]
+		}
+		if _, network, err := net.ParseCIDR(token); err == nil {
+			return Target{Kind: NetworkTarget, Value: network.String()}, true
+		}
+		// path e.g. example.com/admin
+		if index := strings.Index(token, "/"); index >= 0 {
+			// typo e.g. 10.10.10.0/33
+			if isAddressPattern(token[:index]) && isDigits(token[index+1:]) {
+				return Target{Kind: InvalidTarget, Value: token}, true
+			}
+			token = token[:index]
+		}
+		if host, _, err := net.SplitHostPort(token); err == nil {
+			token = host
+		}
+	}
+
+	token = strings.Trim(token, "[]")
+	if ip := net.ParseIP(token); ip != nil {
+		return Target{Kind: AddressTarget, Value: ip.String()}, true
+	}
+	if isAddressPattern(token) {
+		if _, _, ok := parseAddressRange(token); ok {
+			return Target{Kind: RangeTarget, Value: token}, true
+		}
+		return Target{Kind: InvalidTarget, Value: token}, true
+	}
+	if hostname := normalizeHostname(token); isHostname(hostname) || (strict && isLabel(hostname)) {
+		return Target{Kind: HostnameTarget, Value: hostname}, true
+	}
+	return invalid()
+}
+
+// isHostname requires at least one dot and an alphabetic top-level domain, single labels are ambiguous
+func isHostname(value string) bool {
+	labels := strings.Split(value, ".")
+	if len(labels) < 2 {
+		return false
+	}
+	for _, label := range labels {
+		if label == "" || len(label) > 63 || strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return false
+		}
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+				return false
+			}
+		}
+	}
+	topLevel := labels[len(labels)-1]
+	if len(topLevel) < 2 || fileExtensions[topLevel] {
+		return false
+	}
+	for _, r := range topLevel {
+		if r < 'a' || r > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+// isAddressPattern matches anything shaped like an ipv4 address or range e.g. 10.10.10.1-254, 10.10.10.*, 10.10.10
+func isAddressPattern(value string) bool {
+	parts := strings.Split(value, ".")
+	if len(parts) < 3 {
+		return false
+	}
+	for _, part := range parts {
+		if part == "" || strings.Trim(part, "0123456789-*") != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// parseAddressRange returns the first and the last address of an nmap style ipv4 range
+func parseAddressRange(value string) (net.IP, net.IP, bool) {
+	parts := strings.Split(value, ".")
+	if len(parts) != net.IPv4len {
+		return nil, nil, false
+	}
+	first := make(net.IP, net.IPv4len)
+	last := make(net.IP, net.IPv4len)
+	for index, part := range parts {
+		low, high, ok := parseOctetRange(part)
+		if !ok {
+			return nil, nil, false
+		}
+		first[index], last[index] = low, high
+	}
+	return first, last, true
+}
+
+// parseOctetRange supports "*", "n", "n-m", "-m" and "n-"
+func parseOctetRange(value string) (byte, byte, bool) {
+	if value == "*" {
+		return 0, 255, true
+	}
+	lowValue, highValue, isRange := strings.Cut(value, "-")
+	if !isRange {
+		highValue = lowValue
+	}
+	if lowValue == "" && isRange {
+		lowValue = "0"
+	}
+	if highValue == "" && isRange {
+		highValue = "255"
+	}
+	low, err := strconv.ParseUint(lowValue, 10, 8)
+	if err != nil {
+		return 0, 0, false
+	}
+	high, err := strconv.ParseUint(highValue, 10, 8)
+	if err != nil || low > high {
+		return 0, 0, false
+	}
+	return byte(low), byte(high), true
+}
+
+// isLabel is a single label hostname e.g. intranet, numbers are excluded
+func isLabel(value string) bool {
+	if value == "" || len(value) > 63 || strings.HasPrefix(value, "-") || strings.HasSuffix(value, "-") || isDigits(value) {
+		return false
+	}
+	for _, r := range value {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+func isDigits(value string) bool {
+	return value != "" && strings.Trim(value, "0123456789") == ""
+}
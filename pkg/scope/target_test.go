@@ -0,0 +1,63 @@
+package scope
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractTargets(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected []Target
+	}{
+		{"10.10.10.1", []Target{{AddressTarget, "10.10.10.1"}}},
+		{"10.10.10.1:8080", []Target{{AddressTarget, "10.10.10.1"}}},
+		{"10.10.10.0/24", []Target{{NetworkTarget, "10.10.10.0/24"}}},
+		{"[::1]:22", []Target{{AddressTarget, "::1"}}},
+		{"http://Example.com:8080/path?a=b", []Target{{HostnameTarget, "example.com"}}},
+		{"--url=https://app.example.com", []Target{{HostnameTarget, "app.example.com"}}},
+		{"--target=example.com,10.0.0.1", []Target{{HostnameTarget, "example.com"}, {AddressTarget, "10.0.0.1"}}},
+		{"root@host.example.com:22", []Target{{HostnameTarget, "host.example.com"}}},
+		{"example.com/admin", []Target{{HostnameTarget, "example.com"}}},
+		{"10.10.10.1-254", []Target{{RangeTarget, "10.10.10.1-254"}}},
+		{"10.10.10.*", []Target{{RangeTarget, "10.10.10.*"}}},
+		{"--target=10.10-11.0.-100:80", []Target{{RangeTarget, "10.10-11.0.-100"}}},
+		{"10.10.10.256", []Target{{InvalidTarget, "10.10.10.256"}}},
+		{"10.10.10.9-1", []Target{{InvalidTarget, "10.10.10.9-1"}}},
+		{"10.10.10", []Target{{InvalidTarget, "10.10.10"}}},
+		{"10.10.10.0/33", []Target{{InvalidTarget, "10.10.10.0/33"}}},
+		{"intranet", nil},
+		{"-sV", nil},
+		{"/usr/share/wordlists/common.txt", nil},
+		{"wordlist.txt", nil},
+		{"localhost", nil},
+		{"1.5", nil},
+		{"", nil},
+	}
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.expected, ExtractTargets(testCase.value), testCase.value)
+	}
+}
+
+func TestExtractTargetsStrict(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected []Target
+	}{
+		{"intranet", []Target{{HostnameTarget, "intranet"}}},
+		{"Intranet., 10.10.10.1", []Target{{HostnameTarget, "intranet"}, {AddressTarget, "10.10.10.1"}}},
+		{"http://intranet:8080", []Target{{HostnameTarget, "intranet"}}},
+		{"80", []Target{{InvalidTarget, "80"}}},
+		{"-sV", []Target{{InvalidTarget, "-sV"}}},
+	}
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.expected, extractTargets(testCase.value, true), testCase.value)
+	}
+}
+
+func TestIsTargetInput(t *testing.T) {
+	assert.True(t, IsTargetInput("address"))
+	assert.True(t, IsTargetInput("RHOSTS"))
+	assert.False(t, IsTargetInput("wordlist"))
+}
@@ -0,0 +1,43 @@
+package secret
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	ReferencePrefix = "secret:"
+)
+
+var nameRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Lookup returns the value of a secret by name
+type Lookup func(name string) (string, error)
+
+// ParseReference returns the name of the secret e.g. "secret:htb-vpn"
+func ParseReference(value string) (string, bool) {
+	if !strings.HasPrefix(value, ReferencePrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(value, ReferencePrefix), true
+}
+
+// Resolve returns the value unchanged if it's not a reference, the lookup is invoked only for references
+func Resolve(value string, lookup Lookup) (string, error) {
+	name, ok := ParseReference(value)
+	if !ok {
+		return value, nil
+	}
+	if lookup == nil {
+		return "", fmt.Errorf("unable to resolve secret %s", name)
+	}
+	return lookup(name)
+}
+
+func ValidateName(name string) error {
+	if !nameRegex.MatchString(name) {
+		return fmt.Errorf("invalid secret name %s", name)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package secret
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseReference(t *testing.T) {
+	name, ok := ParseReference("secret:htb-vpn")
+	assert.True(t, ok)
+	assert.Equal(t, "htb-vpn", name)
+
+	_, ok = ParseReference("/home/user/htb.ovpn")
+	assert.False(t, ok)
+}
+
+func TestResolve(t *testing.T) {
+	invoked := false
+	lookup := func(name string) (string, error) {
+		invoked = true
+		if name == "token" {
+			return "my-token", nil
+		}
+		return "", errors.New("not found")
+	}
+
+	value, err := Resolve("plain", lookup)
+	assert.NoError(t, err)
+	assert.Equal(t, "plain", value)
+	assert.False(t, invoked)
+
+	value, err = Resolve("secret:token", lookup)
+	assert.NoError(t, err)
+	assert.Equal(t, "my-token", value)
+
+	_, err = Resolve("secret:other", lookup)
+	assert.EqualError(t, err, "not found")
+
+	_, err = Resolve("secret:token", nil)
+	assert.EqualError(t, err, "unable to resolve secret token")
+}
@@ -0,0 +1,247 @@
+package secret
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/hckops/hckctl/pkg/util"
+)
+
+const (
+	storeVersion = 1
+	kdfName      = "scrypt"
+	saltSize     = 16
+	checkValue   = "hckctl"
+)
+
+// scrypt cost, same defaults of the age passphrase recipients with a lower work factor
+var defaultKdfParams = kdfParams{Name: kdfName, LogN: 15, R: 8, P: 1}
+
+// upper bounds of the scrypt cost read from the file, about 1GB of memory
+const (
+	maxKdfLogN = 20
+	maxKdfR    = 8
+	maxKdfP    = 4
+)
+
+var (
+	ErrInvalidPassphrase = errors.New("invalid passphrase")
+	ErrSecretNotFound    = errors.New("secret not found")
+)
+
+type kdfParams struct {
+	Name string `json:"name"`
+	Salt []byte `json:"salt"`
+	LogN int    `json:"logN"`
+	R    int    `json:"r"`
+	P    int    `json:"p"`
+}
+
+type sealedValue struct {
+	Nonce   []byte    `json:"nonce"`
+	Data    []byte    `json:"data"`
+	Updated time.Time `json:"updated"`
+}
+
+type storeFile struct {
+	Version int                    `json:"version"`
+	Kdf     kdfParams              `json:"kdf"`
+	Check   sealedValue            `json:"check"` // detects a wrong passphrase
+	Secrets map[string]sealedValue `json:"secrets"`
+}
+
+// Store keeps each secret encrypted with XChaCha20-Poly1305, the key is derived from a passphrase with scrypt.
+// The names are not encrypted, they are bound to the values as additional data
+type Store struct {
+	path string
+	key  []byte
+	file *storeFile
+}
+
+// OpenStore unlocks an existing store or initializes a new one, which is written only on the first change
+func OpenStore(path string, passphrase []byte) (*Store, error) {
+	if len(passphrase) == 0 {
+		return nil, errors.New("empty passphrase")
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return newStore(path, passphrase)
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "error reading secret store: path=%s", path)
+	}
+
+	var file storeFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, errors.Wrapf(err, "invalid secret store: path=%s", path)
+	}
+	if file.Version != storeVersion || file.Kdf.Name != kdfName {
+		return nil, fmt.Errorf("unsupported secret store: version=%d kdf=%s", file.Version, file.Kdf.Name)
+	}
+	key, err := deriveKey(passphrase, file.Kdf)
+	if err != nil {
+		return nil, err
+	}
+	if value, err := open(key, file.Check, ""); err != nil || value != checkValue {
+		return nil, ErrInvalidPassphrase
+	}
+	if file.Secrets == nil {
+		file.Secrets = map[string]sealedValue{}
+	}
+	return &Store{path: path, key: key, file: &file}, nil
+}
+
+func newStore(path string, passphrase []byte) (*Store, error) {
+	params := defaultKdfParams
+	params.Salt = make([]byte, saltSize)
+	if _, err := rand.Read(params.Salt); err != nil {
+		return nil, errors.Wrap(err, "error generating salt")
+	}
+	key, err := deriveKey(passphrase, params)
+	if err != nil {
+		return nil, err
+	}
+	check, err := seal(key, checkValue, "")
+	if err != nil {
+		return nil, err
+	}
+	file := &storeFile{
+		Version: storeVersion,
+		Kdf:     params,
+		Check:   check,
+		Secrets: map[string]sealedValue{},
+	}
+	return &Store{path: path, key: key, file: file}, nil
+}
+
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Names returns all the secret names sorted
+func (s *Store) Names() []string {
+	var names []string
+	for name := range s.file.Secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *Store) Get(name string) (string, error) {
+	sealed, ok := s.file.Secrets[name]
+	if !ok {
+		return "", errors.Wrapf(ErrSecretNotFound, "name=%s", name)
+	}
+	value, err := open(s.key, sealed, name)
+	if err != nil {
+		return "", errors.Wrapf(err, "error decrypting secret: name=%s", name)
+	}
+	return value, nil
+}
+
+// Lookup resolves the references with this store
+func (s *Store) Lookup(name string) (string, error) {
+	return s.Get(name)
+}
+
+func (s *Store) Set(name string, value string) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+	sealed, err := seal(s.key, value, name)
+	if err != nil {
+		return err
+	}
+	s.file.Secrets[name] = sealed
+	return s.save()
+}
+
+func (s *Store) Remove(name string) error {
+	if _, ok := s.file.Secrets[name]; !ok {
+		return errors.Wrapf(ErrSecretNotFound, "name=%s", name)
+	}
+	delete(s.file.Secrets, name)
+	return s.save()
+}
+
+// save replaces the file atomically
+func (s *Store) save() error {
+	if err := util.CreateBaseDir(s.path); err != nil {
+		return errors.Wrapf(err, "error creating secret store dir: path=%s", s.path)
+	}
+	data, err := json.MarshalIndent(s.file, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "error encoding secret store")
+	}
+	temp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return errors.Wrapf(err, "error writing secret store: path=%s", s.path)
+	}
+	defer os.Remove(temp.Name())
+
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		return errors.Wrapf(err, "error writing secret store: path=%s", s.path)
+	}
+	if err := temp.Chmod(0600); err != nil {
+		temp.Close()
+		return errors.Wrapf(err, "error writing secret store: path=%s", s.path)
+	}
+	if err := temp.Close(); err != nil {
+		return errors.Wrapf(err, "error writing secret store: path=%s", s.path)
+	}
+	return os.Rename(temp.Name(), s.path)
+}
+
+// deriveKey refuses unbounded parameters, a tampered file could exhaust memory or cpu
+func deriveKey(passphrase []byte, params kdfParams) ([]byte, error) {
+	if params.LogN < 1 || params.LogN > maxKdfLogN || params.R < 1 || params.R > maxKdfR || params.P < 1 || params.P > maxKdfP {
+		return nil, fmt.Errorf("invalid kdf params: logN=%d r=%d p=%d", params.LogN, params.R, params.P)
+	}
+	key, err := scrypt.Key(passphrase, params.Salt, 1<<params.LogN, params.R, params.P, chacha20poly1305.KeySize)
+	if err != nil {
+		return nil, errors.Wrap(err, "error deriving key")
+	}
+	return key, nil
+}
+
+func seal(key []byte, value string, name string) (sealedValue, error) {
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return sealedValue{}, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return sealedValue{}, errors.Wrap(err, "error generating nonce")
+	}
+	return sealedValue{
+		Nonce:   nonce,
+		Data:    aead.Seal(nil, nonce, []byte(value), []byte(name)),
+		Updated: time.Now().UTC(),
+	}, nil
+}
+
+func open(key []byte, sealed sealedValue, name string) (string, error) {
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed.Nonce) != aead.NonceSize() {
+		return "", errors.New("invalid nonce")
+	}
+	value, err := aead.Open(nil, sealed.Nonce, sealed.Data, []byte(name))
+	if err != nil {
+		return "", err
+	}
+	return string(value), nil
+}
@@ -0,0 +1,84 @@
+package secret
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.json")
+
+	store, err := OpenStore(path, []byte("passphrase"))
+	assert.NoError(t, err)
+	assert.Empty(t, store.Names())
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+
+	assert.NoError(t, store.Set("htb-token", "my-token"))
+	assert.NoError(t, store.Set("htb-vpn", "client\nremote 1.2.3.4 1337"))
+	assert.ErrorContains(t, store.Set("invalid name", "value"), "invalid secret name invalid name")
+
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	if filepath.Separator == '/' {
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	}
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "my-token")
+
+	reopened, err := OpenStore(path, []byte("passphrase"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"htb-token", "htb-vpn"}, reopened.Names())
+	value, err := reopened.Get("htb-vpn")
+	assert.NoError(t, err)
+	assert.Equal(t, "client\nremote 1.2.3.4 1337", value)
+
+	assert.NoError(t, reopened.Remove("htb-token"))
+	assert.ErrorIs(t, reopened.Remove("htb-token"), ErrSecretNotFound)
+	_, err = reopened.Get("htb-token")
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+	assert.Equal(t, []string{"htb-vpn"}, reopened.Names())
+}
+
+func TestStoreInvalidPassphrase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.json")
+
+	store, err := OpenStore(path, []byte("passphrase"))
+	assert.NoError(t, err)
+	assert.NoError(t, store.Set("token", "value"))
+
+	_, err = OpenStore(path, []byte("wrong"))
+	assert.ErrorIs(t, err, ErrInvalidPassphrase)
+	_, err = OpenStore(path, []byte{})
+	assert.EqualError(t, err, "empty passphrase")
+}
+
+func TestStoreSwappedValues(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.json")
+
+	store, err := OpenStore(path, []byte("passphrase"))
+	assert.NoError(t, err)
+	assert.NoError(t, store.Set("first", "value-1"))
+	assert.NoError(t, store.Set("second", "value-2"))
+
+	// the name is authenticated with the value
+	store.file.Secrets["first"], store.file.Secrets["second"] = store.file.Secrets["second"], store.file.Secrets["first"]
+	_, err = store.Get("first")
+	assert.ErrorContains(t, err, "error decrypting secret: name=first")
+}
+
+func TestDeriveKeyInvalidParams(t *testing.T) {
+	params := defaultKdfParams
+	params.LogN = 40
+	_, err := deriveKey([]byte("passphrase"), params)
+	assert.EqualError(t, err, "invalid kdf params: logN=40 r=8 p=1")
+
+	params = defaultKdfParams
+	params.R = 1 << 20
+	_, err = deriveKey([]byte("passphrase"), params)
+	assert.ErrorContains(t, err, "invalid kdf params")
+}
@@ -0,0 +1,57 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// see https://docs.asciinema.org/manual/asciicast/v2
+const (
+	asciicastVersion = 2
+	FileExtension    = ".cast"
+)
+
+type EventKind string
+
+const (
+	OutputEvent EventKind = "o"
+	InputEvent  EventKind = "i"
+	ResizeEvent EventKind = "r"
+)
+
+type Header struct {
+	Version   int               `json:"version"`
+	Width     uint16            `json:"width"`
+	Height    uint16            `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// Event is encoded as an array e.g. [0.248848, "o", "hello"]
+type Event struct {
+	Time float64 // seconds since the start of the recording
+	Kind EventKind
+	Data string
+}
+
+func (e Event) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]interface{}{e.Time, e.Kind, e.Data})
+}
+
+func (e *Event) UnmarshalJSON(data []byte) error {
+	var values []json.RawMessage
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	if len(values) != 3 {
+		return fmt.Errorf("invalid asciicast event: fields=%d", len(values))
+	}
+	if err := json.Unmarshal(values[0], &e.Time); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(values[1], &e.Kind); err != nil {
+		return err
+	}
+	return json.Unmarshal(values[2], &e.Data)
+}
@@ -0,0 +1,238 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+
+	"github.com/hckops/hckctl/pkg/client/terminal"
+	commonModel "github.com/hckops/hckctl/pkg/common/model"
+	"github.com/hckops/hckctl/pkg/util"
+)
+
+const (
+	defaultWidth  = 80
+	defaultHeight = 24
+	idTimeFormat  = "20060102-150405"
+)
+
+// Recorder writes the streams of a session in asciicast v2 format, every event is written immediately
+// so that the recording is preserved even if the process is interrupted
+type Recorder struct {
+	id      string
+	path    string
+	start   time.Time
+	mutex   sync.Mutex
+	encoder *json.Encoder
+	file    *os.File
+	cancel  context.CancelFunc
+}
+
+// NewRecorder creates a new recording in the given directory, the size is detected from the input terminal
+func NewRecorder(dir string, title string, in io.Reader) (*Recorder, error) {
+	if err := util.CreateDir(dir); err != nil {
+		return nil, errors.Wrapf(err, "error creating session dir: path=%s", dir)
+	}
+
+	start := time.Now()
+	id := newSessionId(start, title)
+	path := filepath.Join(dir, id+FileExtension)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error creating session: path=%s", path)
+	}
+
+	size, ok := terminal.GetSize(in)
+	if !ok {
+		size = terminal.Size{Width: defaultWidth, Height: defaultHeight}
+	}
+	header := &Header{
+		Version:   asciicastVersion,
+		Width:     size.Width,
+		Height:    size.Height,
+		Timestamp: start.Unix(),
+		Title:     title,
+		Env:       map[string]string{"TERM": os.Getenv("TERM"), "SHELL": os.Getenv("SHELL")},
+	}
+	encoder := json.NewEncoder(file)
+	if err := encoder.Encode(header); err != nil {
+		file.Close()
+		return nil, errors.Wrapf(err, "error writing session header: path=%s", path)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	recorder := &Recorder{
+		id:      id,
+		path:    path,
+		start:   start,
+		encoder: encoder,
+		file:    file,
+		cancel:  cancel,
+	}
+	go recorder.recordResize(ctx, in, size)
+	return recorder, nil
+}
+
+// e.g. 20240101-120000-base-parrot-x7k2p, sortable by creation time.
+// The random suffix avoids collisions between recordings started in the same second
+func newSessionId(start time.Time, title string) string {
+	name := strings.Trim(strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		return '-'
+	}, title), "-")
+	suffix := util.RandomAlphanumeric(5)
+	if name == "" {
+		return fmt.Sprintf("%s-%s", start.Format(idTimeFormat), suffix)
+	}
+	return fmt.Sprintf("%s-%s-%s", start.Format(idTimeFormat), name, suffix)
+}
+
+func (r *Recorder) Id() string {
+	return r.id
+}
+
+func (r *Recorder) Path() string {
+	return r.path
+}
+
+// Record returns new stream options which tee all the streams into the recording
+func (r *Recorder) Record(streamOpts *commonModel.StreamOptions) *commonModel.StreamOptions {
+	recordOpts := &commonModel.StreamOptions{
+		IsTty:  streamOpts.IsTty,
+		Resize: streamOpts.Resize,
+	}
+	if streamOpts.In != nil {
+		recordOpts.In = &recordReader{reader: streamOpts.In, stream: r.newStream(InputEvent)}
+	}
+	if streamOpts.Out != nil {
+		recordOpts.Out = &recordWriter{writer: streamOpts.Out, stream: r.newStream(OutputEvent)}
+	}
+	if streamOpts.Err != nil {
+		recordOpts.Err = &recordWriter{writer: streamOpts.Err, stream: r.newStream(OutputEvent)}
+	}
+	return recordOpts
+}
+
+// Close stops the recording, the streams are still usable but not recorded anymore
+func (r *Recorder) Close() error {
+	r.cancel()
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+func (r *Recorder) recordResize(ctx context.Context, in io.Reader, initialSize terminal.Size) {
+	sizeChannel := terminal.MonitorSize(ctx, in)
+	if sizeChannel == nil {
+		return
+	}
+	for size := range sizeChannel {
+		if size != initialSize {
+			r.write(ResizeEvent, fmt.Sprintf("%dx%d", size.Width, size.Height))
+		}
+	}
+}
+
+// write ignores errors, a broken recording must never interrupt the session
+func (r *Recorder) write(kind EventKind, data string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.file == nil {
+		return
+	}
+	r.encoder.Encode(Event{Time: time.Since(r.start).Seconds(), Kind: kind, Data: data})
+}
+
+func (r *Recorder) newStream(kind EventKind) *recordStream {
+	return &recordStream{recorder: r, kind: kind}
+}
+
+// recordStream buffers incomplete utf-8 sequences split across reads or writes
+type recordStream struct {
+	recorder *Recorder
+	kind     EventKind
+	mutex    sync.Mutex
+	pending  []byte
+}
+
+func (s *recordStream) record(data []byte) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	value := append(s.pending, data...)
+	// splits on the last complete rune
+	end := len(value)
+	for i := len(value) - 1; i >= 0 && i >= len(value)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(value[i]) {
+			if !utf8.FullRune(value[i:]) {
+				end = i
+			}
+			break
+		}
+	}
+	s.pending = append([]byte{}, value[end:]...)
+	if end > 0 {
+		s.recorder.write(s.kind, string(value[:end]))
+	}
+}
+
+type recordReader struct {
+	reader io.ReadCloser
+	stream *recordStream
+}
+
+func (r *recordReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		r.stream.record(p[:n])
+	}
+	return n, err
+}
+
+func (r *recordReader) Close() error {
+	return r.reader.Close()
+}
+
+// File allows to detect the underlying terminal
+func (r *recordReader) File() *os.File {
+	file, _ := terminal.Unwrap[io.Reader](r.reader).(*os.File)
+	return file
+}
+
+type recordWriter struct {
+	writer io.Writer
+	stream *recordStream
+}
+
+func (w *recordWriter) Write(p []byte) (int, error) {
+	n, err := w.writer.Write(p)
+	if n > 0 {
+		w.stream.record(p[:n])
+	}
+	return n, err
+}
+
+// File allows to detect the underlying terminal
+func (w *recordWriter) File() *os.File {
+	file, _ := terminal.Unwrap(w.writer).(*os.File)
+	return file
+}
@@ -0,0 +1,74 @@
+package session
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type ReplayOptions struct {
+	Speed   float64       // defaults to 1
+	MaxIdle time.Duration // zero to preserve the original pauses
+	// internal
+	sleep func(time.Duration)
+}
+
+func (opts *ReplayOptions) speed() float64 {
+	if opts.Speed <= 0 {
+		return 1
+	}
+	return opts.Speed
+}
+
+// Replay writes the output events with the original timing, input events are never printed
+func Replay(in io.Reader, out io.Writer, opts *ReplayOptions) error {
+	sleep := opts.sleep
+	if sleep == nil {
+		sleep = time.Sleep
+	}
+
+	reader := bufio.NewReader(in)
+	line, err := reader.ReadBytes('\n')
+	if err != nil {
+		return errors.Wrap(err, "error reading asciicast header")
+	}
+	if _, err := decodeHeader(line); err != nil {
+		return err
+	}
+
+	var previous float64
+	for {
+		line, err := reader.ReadBytes('\n')
+		if strings.TrimSpace(string(line)) != "" {
+			var event Event
+			if decodeErr := json.Unmarshal(line, &event); decodeErr != nil {
+				return errors.Wrap(decodeErr, "error decoding asciicast event")
+			}
+
+			delay := time.Duration((event.Time - previous) / opts.speed() * float64(time.Second))
+			if opts.MaxIdle > 0 && delay > opts.MaxIdle {
+				delay = opts.MaxIdle
+			}
+			if delay > 0 {
+				sleep(delay)
+			}
+			previous = event.Time
+
+			if event.Kind == OutputEvent {
+				if _, writeErr := io.WriteString(out, event.Data); writeErr != nil {
+					return errors.Wrap(writeErr, "error writing asciicast event")
+				}
+			}
+		}
+
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return errors.Wrap(err, "error reading asciicast event")
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package session
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type SessionInfo struct {
+	Id      string
+	Path    string
+	Title   string
+	Width   uint16
+	Height  uint16
+	Created time.Time
+}
+
+// ListSessions returns all the recordings sorted by creation time, invalid files are ignored
+func ListSessions(dir string) ([]SessionInfo, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return []SessionInfo{}, nil
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "error reading session dir: path=%s", dir)
+	}
+
+	var sessions []SessionInfo
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != FileExtension {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		header, err := ReadHeader(path)
+		if err != nil {
+			continue
+		}
+		sessions = append(sessions, SessionInfo{
+			Id:      strings.TrimSuffix(entry.Name(), FileExtension),
+			Path:    path,
+			Title:   header.Title,
+			Width:   header.Width,
+			Height:  header.Height,
+			Created: time.Unix(header.Timestamp, 0),
+		})
+	}
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].Created.Before(sessions[j].Created)
+	})
+	return sessions, nil
+}
+
+// FindSession returns the path of a recording, the id can't reference files outside the directory
+func FindSession(dir string, id string) (string, error) {
+	if id == "" || id != filepath.Base(id) || strings.ContainsAny(id, `/\`) {
+		return "", fmt.Errorf("invalid session id %s", id)
+	}
+	path := filepath.Join(dir, strings.TrimSuffix(id, FileExtension)+FileExtension)
+	if _, err := os.Stat(path); err != nil {
+		return "", errors.Wrapf(err, "session not found: id=%s", id)
+	}
+	return path, nil
+}
+
+// ReadHeader returns the header of a recording
+func ReadHeader(path string) (*Header, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	line, err := bufio.NewReader(file).ReadBytes('\n')
+	if err != nil {
+		return nil, err
+	}
+	return decodeHeader(line)
+}
+
+func decodeHeader(line []byte) (*Header, error) {
+	var header Header
+	if err := json.Unmarshal(line, &header); err != nil {
+		return nil, errors.Wrap(err, "invalid asciicast header")
+	}
+	if header.Version != asciicastVersion {
+		return nil, fmt.Errorf("unsupported asciicast version %d", header.Version)
+	}
+	return &header, nil
+}
@@ -0,0 +1,154 @@
+package session
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	commonModel "github.com/hckops/hckctl/pkg/common/model"
+)
+
+func TestEventJson(t *testing.T) {
+	value, err := Event{Time: 1.5, Kind: OutputEvent, Data: "hello\r\n"}.MarshalJSON()
+	assert.NoError(t, err)
+	assert.Equal(t, `[1.5,"o","hello\r\n"]`, string(value))
+
+	var event Event
+	assert.NoError(t, event.UnmarshalJSON(value))
+	assert.Equal(t, Event{Time: 1.5, Kind: OutputEvent, Data: "hello\r\n"}, event)
+
+	assert.EqualError(t, event.UnmarshalJSON([]byte(`[1.5,"o"]`)), "invalid asciicast event: fields=2")
+}
+
+func TestNewSessionId(t *testing.T) {
+	start := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	assert.Regexp(t, "^20240102-150405-base-parrot-[a-z0-9]{5}$", newSessionId(start, "base/parrot"))
+	assert.Regexp(t, "^20240102-150405-box-alpine-123-[a-z0-9]{5}$", newSessionId(start, "box-alpine-123"))
+	assert.Regexp(t, "^20240102-150405-[a-z0-9]{5}$", newSessionId(start, "../"))
+	// same second and title
+	assert.NotEqual(t, newSessionId(start, "base/parrot"), newSessionId(start, "base/parrot"))
+}
+
+func TestRecorderSameSecond(t *testing.T) {
+	dir := t.TempDir()
+	first, err := NewRecorder(dir, "base/alpine", new(bytes.Buffer))
+	require.NoError(t, err)
+	defer first.Close()
+	second, err := NewRecorder(dir, "base/alpine", new(bytes.Buffer))
+	require.NoError(t, err)
+	defer second.Close()
+
+	assert.NotEqual(t, first.Path(), second.Path())
+}
+
+func TestRecorder(t *testing.T) {
+	dir := t.TempDir()
+	recorder, err := NewRecorder(dir, "base/alpine", new(bytes.Buffer))
+	require.NoError(t, err)
+
+	out := new(bytes.Buffer)
+	streamOpts := recorder.Record(&commonModel.StreamOptions{
+		In:    io.NopCloser(strings.NewReader("ls\n")),
+		Out:   out,
+		Err:   out,
+		IsTty: true,
+	})
+	assert.True(t, streamOpts.IsTty)
+
+	input, err := io.ReadAll(streamOpts.In)
+	assert.NoError(t, err)
+	assert.Equal(t, "ls\n", string(input))
+
+	// the euro sign is split across writes
+	euro := []byte("€")
+	streamOpts.Out.Write([]byte("cost "))
+	streamOpts.Out.Write(euro[:1])
+	streamOpts.Out.Write(euro[1:])
+	streamOpts.Err.Write([]byte("error"))
+	assert.Equal(t, "cost €error", out.String())
+
+	assert.NoError(t, recorder.Close())
+	// ignored after close
+	streamOpts.Out.Write([]byte("closed"))
+	assert.NoError(t, recorder.Close())
+
+	value, err := os.ReadFile(recorder.Path())
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(value)), "\n")
+	require.Len(t, lines, 5)
+	assert.Contains(t, lines[0], `"version":2,"width":80,"height":24`)
+	assert.Contains(t, lines[0], `"title":"base/alpine"`)
+	assert.Contains(t, lines[1], `"i","ls\n"]`)
+	assert.Contains(t, lines[2], `"o","cost "]`)
+	assert.Contains(t, lines[3], `"o","€"]`)
+	assert.Contains(t, lines[4], `"o","error"]`)
+
+	sessions, err := ListSessions(dir)
+	assert.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, recorder.Id(), sessions[0].Id)
+	assert.Equal(t, "base/alpine", sessions[0].Title)
+}
+
+func TestListSessions(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "b.cast"), []byte(`{"version":2,"width":80,"height":24,"timestamp":200}`+"\n"), 0600)
+	os.WriteFile(filepath.Join(dir, "a.cast"), []byte(`{"version":2,"width":80,"height":24,"timestamp":100}`+"\n"), 0600)
+	os.WriteFile(filepath.Join(dir, "invalid.cast"), []byte(`{"version":1}`+"\n"), 0600)
+	os.WriteFile(filepath.Join(dir, "other.txt"), []byte("other"), 0600)
+
+	sessions, err := ListSessions(dir)
+	assert.NoError(t, err)
+	require.Len(t, sessions, 2)
+	assert.Equal(t, "a", sessions[0].Id)
+	assert.Equal(t, "b", sessions[1].Id)
+
+	missing, err := ListSessions(filepath.Join(dir, "missing"))
+	assert.NoError(t, err)
+	assert.Empty(t, missing)
+}
+
+func TestFindSession(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "abc.cast"), []byte("{}"), 0600)
+
+	path, err := FindSession(dir, "abc")
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "abc.cast"), path)
+
+	_, err = FindSession(dir, "../abc")
+	assert.EqualError(t, err, "invalid session id ../abc")
+	_, err = FindSession(dir, "xyz")
+	assert.ErrorContains(t, err, "session not found: id=xyz")
+}
+
+func TestReplay(t *testing.T) {
+	recording := strings.Join([]string{
+		`{"version":2,"width":80,"height":24,"timestamp":100}`,
+		`[0.5,"o","hello "]`,
+		`[1.0,"i","typed"]`,
+		`[11.0,"r","100x50"]`,
+		`[12.0,"o","world"]`,
+	}, "\n")
+
+	var delays []time.Duration
+	out := new(bytes.Buffer)
+	err := Replay(strings.NewReader(recording), out, &ReplayOptions{
+		Speed:   2,
+		MaxIdle: 2 * time.Second,
+		sleep:   func(d time.Duration) { delays = append(delays, d) },
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "hello world", out.String())
+	assert.Equal(t, []time.Duration{250 * time.Millisecond, 250 * time.Millisecond, 2 * time.Second, 500 * time.Millisecond}, delays)
+
+	err = Replay(strings.NewReader(`{"version":1}`+"\n"), out, &ReplayOptions{})
+	assert.EqualError(t, err, "unsupported asciicast version 1")
+}
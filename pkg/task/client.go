@@ -1,9 +1,12 @@
 package task
 
 import (
+	"context"
+
 	"github.com/pkg/errors"
 
 	"github.com/hckops/hckctl/pkg/event"
+	"github.com/hckops/hckctl/pkg/task/cloud"
 	"github.com/hckops/hckctl/pkg/task/docker"
 	"github.com/hckops/hckctl/pkg/task/kubernetes"
 	"github.com/hckops/hckctl/pkg/task/model"
@@ -12,16 +15,19 @@ import (
 type TaskClient interface {
 	Provider() model.TaskProvider
 	Events() *event.EventBus
-	Run(opts *model.RunOptions) error
+	// Run blocks until the task completes and returns its exit code, -1 if unknown
+	Run(ctx context.Context, opts *model.RunOptions) (int, error)
 }
 
-func NewTaskClient(opts *model.TaskClientOptions) (TaskClient, error) {
+func NewTaskClient(ctx context.Context, opts *model.TaskClientOptions) (TaskClient, error) {
 	commonOpts := model.NewCommonTaskOpts()
 	switch opts.Provider {
 	case model.Docker:
 		return docker.NewDockerTaskClient(commonOpts, opts.DockerOpts)
 	case model.Kubernetes:
 		return kubernetes.NewKubeTaskClient(commonOpts, opts.KubeOpts)
+	case model.Cloud:
+		return cloud.NewCloudTaskClient(ctx, commonOpts, opts.CloudOpts)
 	default:
 		return nil, errors.New("invalid provider")
 	}
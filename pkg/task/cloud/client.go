@@ -0,0 +1,39 @@
+package cloud
+
+import (
+	"context"
+
+	"github.com/hckops/hckctl/pkg/client/ssh"
+	commonModel "github.com/hckops/hckctl/pkg/common/model"
+	"github.com/hckops/hckctl/pkg/event"
+	taskModel "github.com/hckops/hckctl/pkg/task/model"
+)
+
+type CloudTaskClient struct {
+	client     *ssh.SshClient
+	clientOpts *commonModel.CloudOptions
+	eventBus   *event.EventBus
+}
+
+func NewCloudTaskClient(ctx context.Context, commonOpts *taskModel.CommonTaskOptions, cloudOpts *commonModel.CloudOptions) (*CloudTaskClient, error) {
+	return newCloudTaskClient(ctx, commonOpts, cloudOpts)
+}
+
+func (task *CloudTaskClient) Provider() taskModel.TaskProvider {
+	return taskModel.Cloud
+}
+
+func (task *CloudTaskClient) Events() *event.EventBus {
+	return task.eventBus
+}
+
+func (task *CloudTaskClient) Run(ctx context.Context, opts *taskModel.RunOptions) (int, error) {
+	defer task.close()
+	return task.runTask(ctx, opts)
+}
+
+// List returns the remote tasks, it's available only with the cloud provider
+func (task *CloudTaskClient) List(ctx context.Context) ([]taskModel.TaskInfo, error) {
+	defer task.close()
+	return task.listTasks(ctx)
+}
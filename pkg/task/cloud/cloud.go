@@ -0,0 +1,160 @@
+package cloud
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+
+	v1 "github.com/hckops/hckctl/pkg/api/v1"
+	"github.com/hckops/hckctl/pkg/client/ssh"
+	commonModel "github.com/hckops/hckctl/pkg/common/model"
+	taskModel "github.com/hckops/hckctl/pkg/task/model"
+	"github.com/hckops/hckctl/pkg/util"
+)
+
+// the server could pull the images before starting the task
+const createRequestTimeout = 10 * time.Minute
+
+func newCloudTaskClient(ctx context.Context, commonOpts *taskModel.CommonTaskOptions, cloudOpts *commonModel.CloudOptions) (*CloudTaskClient, error) {
+	commonOpts.EventBus.Publish(newInitCloudClientEvent())
+
+	clientConfig := &ssh.SshClientConfig{
+		Address:        cloudOpts.Address,
+		Username:       cloudOpts.Username,
+		Token:          cloudOpts.Token,
+		PrivateKeyPath: cloudOpts.PrivateKeyPath,
+		UseAgent:       cloudOpts.UseAgent,
+		KnownHostsPath: cloudOpts.KnownHostsPath,
+		Fingerprint:    cloudOpts.Fingerprint,
+		OnReconnectCallback: func(attempt int, err error) {
+			commonOpts.EventBus.Publish(newReconnectCloudClientEvent(attempt, err))
+		},
+		OnTrustHostCallback: func(host string, fingerprint string) {
+			commonOpts.EventBus.Publish(newTrustHostCloudClientEvent(host, fingerprint))
+			commonOpts.EventBus.Publish(newTrustHostCloudClientConsoleEvent(host, fingerprint))
+		},
+	}
+	sshClient, err := ssh.NewSshClient(ctx, clientConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "error cloud task")
+	}
+	// detects incompatible servers before any other request
+	if _, err := v1.Negotiate(ctx, cloudOpts.Version, sshClient.SendRequest); err != nil {
+		sshClient.Close()
+		return nil, errors.Wrap(err, "error cloud task version")
+	}
+
+	return &CloudTaskClient{
+		client:     sshClient,
+		clientOpts: cloudOpts,
+		eventBus:   commonOpts.EventBus,
+	}, nil
+}
+
+func (task *CloudTaskClient) close() error {
+	task.eventBus.Publish(newCloseCloudClientEvent())
+	task.eventBus.Flush()
+	return task.client.Close()
+}
+
+func (task *CloudTaskClient) runTask(ctx context.Context, opts *taskModel.RunOptions) (int, error) {
+	// the server resolves the template by name, custom templates and local share dir are not supported
+	if err := validateTemplate(opts.Labels); err != nil {
+		return -1, err
+	}
+	task.eventBus.Publish(newApiRunCloudLoaderEvent(task.clientOpts.Address, opts.Template.Name))
+
+	request := v1.NewTaskRunRequest(task.clientOpts.Version, opts.Template.Name, opts.Arguments).WithRequestId(v1.NewRequestId())
+	payload, err := request.Encode()
+	if err != nil {
+		return -1, errors.Wrap(err, "error cloud run request")
+	}
+	value, err := task.client.SendRequestTimeout(ctx, request.Protocol(), payload, createRequestTimeout)
+	if err != nil {
+		return -1, errors.Wrap(v1.ToError(err), "error cloud run")
+	}
+
+	response, err := v1.Decode[v1.TaskRunResponseBody](value)
+	if err != nil {
+		return -1, errors.Wrap(err, "error cloud run response")
+	}
+	taskName := response.Body.Name
+	task.eventBus.Publish(newApiRunCloudEvent(opts.Template.Name, taskName))
+
+	logFileName := opts.GenerateLogFileName(taskModel.Cloud, taskName)
+	exitCode, err := task.logsTask(ctx, taskName, opts.StreamOpts, logFileName)
+	if err != nil {
+		return -1, err
+	}
+	task.eventBus.Publish(newApiLogsCloudConsoleEvent(logFileName))
+	return exitCode, nil
+}
+
+// validateTemplate refuses local templates, the server would resolve a git template with the same name
+func validateTemplate(labels commonModel.Labels) error {
+	if labels.ToGitTemplateInfo() == nil {
+		return errors.New("only git templates are supported by the cloud provider")
+	}
+	return nil
+}
+
+func (task *CloudTaskClient) logsTask(ctx context.Context, taskName string, streamOpts *commonModel.StreamOptions, logFileName string) (int, error) {
+	task.eventBus.Publish(newApiLogsCloudEvent(taskName, logFileName))
+
+	session := v1.NewTaskLogsSession(task.clientOpts.Version, taskName).WithRequestId(v1.NewRequestId())
+	payload, err := session.Encode()
+	if err != nil {
+		return -1, errors.Wrap(err, "error cloud logs session")
+	}
+
+	logFile, err := util.OpenFile(logFileName)
+	if err != nil {
+		return -1, errors.Wrap(err, "error cloud log file")
+	}
+	defer logFile.Close()
+
+	commandOpts := &ssh.SshCommandOpts{
+		Payload:   payload,
+		OutStream: io.MultiWriter(streamOpts.Out, logFile),
+		ErrStream: streamOpts.Err,
+		IsTty:     false,
+		OnStreamStartCallback: func() {
+			// stop loader
+			task.eventBus.Publish(newApiStopCloudLoaderEvent())
+		},
+	}
+	// blocks until the task completes
+	exitCode, err := task.client.ExecCommand(ctx, commandOpts)
+	if err != nil {
+		return -1, errors.Wrap(err, "error cloud logs")
+	}
+	task.eventBus.Publish(newApiLogsExitCodeCloudEvent(taskName, exitCode))
+	return exitCode, nil
+}
+
+func (task *CloudTaskClient) listTasks(ctx context.Context) ([]taskModel.TaskInfo, error) {
+
+	request := v1.NewTaskListRequest(task.clientOpts.Version).WithRequestId(v1.NewRequestId())
+	payload, err := request.Encode()
+	if err != nil {
+		return nil, errors.Wrap(err, "error cloud task list request")
+	}
+	value, err := task.client.SendRequest(ctx, request.Protocol(), payload)
+	if err != nil {
+		return nil, errors.Wrap(v1.ToError(err), "error cloud task list")
+	}
+
+	response, err := v1.Decode[v1.TaskListResponseBody](value)
+	if err != nil {
+		return nil, errors.Wrap(err, "error cloud task list response")
+	}
+
+	var result []taskModel.TaskInfo
+	for index, item := range response.Body.Items {
+		result = append(result, taskModel.TaskInfo{Name: item.Name, Status: item.Status})
+		task.eventBus.Publish(newApiListCloudEvent(index, item.Name, item.Status))
+	}
+	return result, nil
+}
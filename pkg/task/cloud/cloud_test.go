@@ -0,0 +1,17 @@
+package cloud
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	taskModel "github.com/hckops/hckctl/pkg/task/model"
+)
+
+func TestValidateTemplate(t *testing.T) {
+	gitLabels := taskModel.NewTaskLabels().AddDefaultGit("https://github.com/hckops/megalopolis", "main", "megalopolis")
+	assert.NoError(t, validateTemplate(gitLabels))
+
+	localLabels := taskModel.NewTaskLabels().AddDefaultLocal()
+	assert.EqualError(t, validateTemplate(localLabels), "only git templates are supported by the cloud provider")
+}
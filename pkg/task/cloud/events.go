@@ -0,0 +1,76 @@
+package cloud
+
+import (
+	"fmt"
+
+	"github.com/hckops/hckctl/pkg/event"
+	"github.com/hckops/hckctl/pkg/task/model"
+)
+
+type cloudTaskEvent struct {
+	kind  event.EventKind
+	value string
+}
+
+func (e *cloudTaskEvent) Source() string {
+	return model.Cloud.String()
+}
+
+func (e *cloudTaskEvent) Kind() event.EventKind {
+	return e.kind
+}
+
+func (e *cloudTaskEvent) String() string {
+	return e.value
+}
+
+func newInitCloudClientEvent() *cloudTaskEvent {
+	return &cloudTaskEvent{kind: event.LogDebug, value: "init cloud client"}
+}
+
+func newCloseCloudClientEvent() *cloudTaskEvent {
+	return &cloudTaskEvent{kind: event.LogDebug, value: "close cloud client"}
+}
+
+func newReconnectCloudClientEvent(attempt int, err error) *cloudTaskEvent {
+	if err != nil {
+		return &cloudTaskEvent{kind: event.LogWarning, value: fmt.Sprintf("reconnect cloud client: attempt=%d error=%v", attempt, err)}
+	}
+	return &cloudTaskEvent{kind: event.LogInfo, value: fmt.Sprintf("reconnect cloud client: attempt=%d", attempt)}
+}
+
+func newTrustHostCloudClientEvent(host string, fingerprint string) *cloudTaskEvent {
+	return &cloudTaskEvent{kind: event.LogWarning, value: fmt.Sprintf("trust new host: host=%s fingerprint=%s", host, fingerprint)}
+}
+
+func newTrustHostCloudClientConsoleEvent(host string, fingerprint string) *cloudTaskEvent {
+	return &cloudTaskEvent{kind: event.PrintConsole, value: fmt.Sprintf("trusting new host %s with fingerprint %s, verify it with the server administrator", host, fingerprint)}
+}
+
+func newApiRunCloudLoaderEvent(address string, templateName string) *cloudTaskEvent {
+	return &cloudTaskEvent{kind: event.LoaderUpdate, value: fmt.Sprintf("running %s/%s", address, templateName)}
+}
+
+func newApiRunCloudEvent(templateName string, taskName string) *cloudTaskEvent {
+	return &cloudTaskEvent{kind: event.LogInfo, value: fmt.Sprintf("api run: templateName=%s taskName=%s", templateName, taskName)}
+}
+
+func newApiStopCloudLoaderEvent() *cloudTaskEvent {
+	return &cloudTaskEvent{kind: event.LoaderStop, value: "waiting"}
+}
+
+func newApiLogsCloudEvent(taskName string, logFileName string) *cloudTaskEvent {
+	return &cloudTaskEvent{kind: event.LogInfo, value: fmt.Sprintf("api logs: taskName=%s logFileName=%s", taskName, logFileName)}
+}
+
+func newApiLogsExitCodeCloudEvent(taskName string, exitCode int) *cloudTaskEvent {
+	return &cloudTaskEvent{kind: event.LogInfo, value: fmt.Sprintf("api logs exit code: taskName=%s exitCode=%d", taskName, exitCode)}
+}
+
+func newApiLogsCloudConsoleEvent(logFileName string) *cloudTaskEvent {
+	return &cloudTaskEvent{kind: event.PrintConsole, value: fmt.Sprintf("\noutput file: %s", logFileName)}
+}
+
+func newApiListCloudEvent(index int, taskName string, status string) *cloudTaskEvent {
+	return &cloudTaskEvent{kind: event.LogInfo, value: fmt.Sprintf("api list: (%d) taskName=%s status=%s", index, taskName, status)}
+}
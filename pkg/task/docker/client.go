@@ -1,6 +1,7 @@
 package docker
 
 import (
+	"context"
 	"github.com/hckops/hckctl/pkg/client/docker"
 	commonDocker "github.com/hckops/hckctl/pkg/common/docker"
 	commonModel "github.com/hckops/hckctl/pkg/common/model"
@@ -27,7 +28,7 @@ func (task *DockerTaskClient) Events() *event.EventBus {
 	return task.eventBus
 }
 
-func (task *DockerTaskClient) Run(opts *taskModel.RunOptions) error {
+func (task *DockerTaskClient) Run(ctx context.Context, opts *taskModel.RunOptions) (int, error) {
 	defer task.close()
-	return task.runTask(opts)
+	return task.runTask(ctx, opts)
 }
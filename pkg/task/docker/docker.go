@@ -1,12 +1,16 @@
 package docker
 
 import (
+	"context"
+	"sync/atomic"
+
 	"github.com/pkg/errors"
 
 	"github.com/hckops/hckctl/pkg/client/docker"
 	commonDocker "github.com/hckops/hckctl/pkg/common/docker"
 	commonModel "github.com/hckops/hckctl/pkg/common/model"
 	taskModel "github.com/hckops/hckctl/pkg/task/model"
+	"github.com/hckops/hckctl/pkg/util"
 )
 
 func newDockerTaskClient(commonOpts *taskModel.CommonTaskOptions, dockerOpts *commonModel.DockerOptions) (*DockerTaskClient, error) {
@@ -28,14 +32,19 @@ func (task *DockerTaskClient) close() error {
 	return task.dockerCommon.Close()
 }
 
-func (task *DockerTaskClient) runTask(opts *taskModel.RunOptions) error {
+func (task *DockerTaskClient) runTask(ctx context.Context, opts *taskModel.RunOptions) (int, error) {
+	// temporary containers are removed also when interrupted
+	cleanupCtx := context.WithoutCancel(ctx)
+	// removes the container and the sidecar in reverse order on failure
+	rollback := util.NewRollback()
+	defer task.dockerCommon.Rollback(ctx, rollback)
 
 	// pull image
 	imageName := opts.Template.Image.Name()
-	if err := task.dockerCommon.PullImageOffline(imageName, func() {
+	if err := task.dockerCommon.PullImageOffline(ctx, imageName, func() {
 		task.eventBus.Publish(newImagePullDockerLoaderEvent(imageName))
 	}); err != nil {
-		return err
+		return -1, err
 	}
 
 	// taskName
@@ -48,21 +57,27 @@ func (task *DockerTaskClient) runTask(opts *taskModel.RunOptions) error {
 			Name:       containerName,
 			NetworkVpn: opts.CommonInfo.NetworkVpn,
 		}
-		if sidecarContainerId, err := task.dockerCommon.SidecarVpnInject(sidecarOpts, &docker.ContainerPortConfigOpts{}); err != nil {
-			return err
+		if sidecarContainerId, err := task.dockerCommon.SidecarVpnInject(ctx, sidecarOpts, &docker.ContainerPortConfigOpts{}); err != nil {
+			return -1, err
 		} else {
 			networkMode = docker.ContainerNetworkMode(sidecarContainerId)
-			// remove sidecar on exit
-			defer task.client.ContainerRemove(sidecarContainerId)
+			rollback.Add("sidecar-vpn", func(ctx context.Context) error {
+				return task.dockerCommon.SidecarRemove(ctx, sidecarContainerId)
+			})
 		}
 	} else {
 		networkMode = docker.DefaultNetworkMode()
 	}
 
+	containerEnv := []docker.ContainerEnv{}
+	for _, env := range opts.Env {
+		containerEnv = append(containerEnv, docker.ContainerEnv{Key: env.Key, Value: env.Value})
+	}
+
 	containerConfig, err := docker.BuildContainerConfig(&docker.ContainerConfigOpts{
 		ImageName:  imageName,
 		Hostname:   "", // vpn NetworkMode conflicts with Hostname containerName
-		Env:        []docker.ContainerEnv{},
+		Env:        containerEnv,
 		Ports:      []docker.ContainerPort{},
 		Labels:     opts.Labels,
 		Tty:        opts.StreamOpts.IsTty,
@@ -70,7 +85,7 @@ func (task *DockerTaskClient) runTask(opts *taskModel.RunOptions) error {
 		Cmd:        opts.Arguments,
 	})
 	if err != nil {
-		return err
+		return -1, err
 	}
 
 	hostConfig, err := docker.BuildHostConfig(&docker.ContainerHostConfigOpts{
@@ -84,18 +99,20 @@ func (task *DockerTaskClient) runTask(opts *taskModel.RunOptions) error {
 		},
 	})
 	if err != nil {
-		return err
+		return -1, err
 	}
 
 	networkName := task.clientOpts.NetworkName
-	networkId, err := task.client.NetworkUpsert(networkName)
+	networkId, err := task.client.NetworkUpsert(ctx, networkName)
 	if err != nil {
-		return err
+		return -1, err
 	}
 	task.eventBus.Publish(newNetworkUpsertDockerEvent(networkName, networkId))
 	task.eventBus.Publish(newContainerCreateDockerLoaderEvent())
 
 	logFileName := opts.GenerateLogFileName(taskModel.Docker, containerName)
+	var timedOut atomic.Bool
+	exitCode := -1
 	containerOpts := &docker.ContainerCreateOpts{
 		ContainerName:    containerName,
 		ContainerConfig:  containerConfig,
@@ -103,15 +120,35 @@ func (task *DockerTaskClient) runTask(opts *taskModel.RunOptions) error {
 		NetworkingConfig: docker.BuildNetworkingConfig(networkName, networkId), // all on the same network
 		Platform:         docker.DefaultPlatform(),
 		WaitStatus:       true, // block
-		CaptureInterrupt: true,
-		OnContainerInterruptCallback: func(containerId string) {
-			// returns control to runTask, it will correctly invoke defer to remove the sidecar
-			// unless it's interrupted while the sidecar is being created
-			task.eventBus.Publish(newContainerRemoveDockerEvent(containerId))
-			task.client.ContainerRemove(containerId)
+		OnContainerCreateCallback: func(containerId string) error {
+			rollback.Add("container", func(ctx context.Context) error {
+				task.eventBus.Publish(newContainerRemoveDockerEvent(containerId))
+				return task.client.ContainerRemove(ctx, containerId)
+			})
+			// attaches before starting to avoid losing the input
+			if opts.StreamOpts.In == nil {
+				return nil
+			}
+			task.eventBus.Publish(newContainerAttachDockerEvent(containerId))
+			return task.client.ContainerAttachStdin(ctx, containerId, opts.StreamOpts.In, func(err error) {
+				task.eventBus.Publish(newContainerAttachErrorDockerEvent(containerId, err))
+			})
 		},
-		OnContainerCreateCallback: func(string) error { return nil },
 		OnContainerWaitCallback: func(containerId string) error {
+			// killing the container ends the logs stream and returns control, the partial logs are preserved
+			if opts.Timeout > 0 {
+				timeoutCtx, cancel := context.WithTimeout(ctx, opts.Timeout)
+				defer cancel()
+				go func() {
+					<-timeoutCtx.Done()
+					if errors.Is(timeoutCtx.Err(), context.DeadlineExceeded) {
+						timedOut.Store(true)
+						task.eventBus.Publish(newContainerTimeoutDockerEvent(containerId, opts.Timeout))
+						task.client.ContainerKill(cleanupCtx, containerId)
+					}
+				}()
+			}
+
 			task.eventBus.Publish(newVolumeMountDockerEvent(containerId, opts.CommonInfo.ShareDir.LocalPath, opts.CommonInfo.ShareDir.RemotePath))
 
 			// stop loader
@@ -125,22 +162,30 @@ func (task *DockerTaskClient) runTask(opts *taskModel.RunOptions) error {
 				ContainerId: containerId,
 				OutStream:   opts.StreamOpts.Out,
 			}
-			return task.client.ContainerLogsTee(logsOpts, logFileName)
+			return task.client.ContainerLogsTee(ctx, logsOpts, logFileName)
 		},
 		OnContainerStatusCallback: func(status string) {
 			task.eventBus.Publish(newContainerCreateStatusDockerEvent(status))
 		},
+		OnContainerExitCallback: func(code int) {
+			exitCode = code
+		},
 		OnContainerStartCallback: func() {},
 	}
 	// taskId
-	containerId, err := task.client.ContainerCreate(containerOpts)
+	containerId, err := task.client.ContainerCreate(ctx, containerOpts)
 	if err != nil {
-		return err
+		return -1, err
 	}
 	task.eventBus.Publish(newContainerCreateDockerEvent(opts.Template.Name, containerName, containerId))
 	task.eventBus.Publish(newContainerLogDockerConsoleEvent(logFileName))
 
-	// remove temporary container
-	task.eventBus.Publish(newContainerRemoveDockerEvent(containerId))
-	return task.client.ContainerRemove(containerId)
+	// remove temporary containers
+	if err := rollback.Run(ctx); err != nil {
+		return -1, err
+	}
+	if timedOut.Load() {
+		return -1, &taskModel.TimeoutError{Timeout: opts.Timeout, LogFileName: logFileName}
+	}
+	return exitCode, nil
 }
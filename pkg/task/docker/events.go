@@ -2,14 +2,16 @@ package docker
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/hckops/hckctl/pkg/event"
 	"github.com/hckops/hckctl/pkg/task/model"
 )
 
 type dockerTaskEvent struct {
-	kind  event.EventKind
-	value string
+	kind    event.EventKind
+	value   string
+	payload any
 }
 
 func (e *dockerTaskEvent) Source() string {
@@ -24,6 +26,10 @@ func (e *dockerTaskEvent) String() string {
 	return e.value
 }
 
+func (e *dockerTaskEvent) Payload() any {
+	return e.payload
+}
+
 func newImagePullDockerLoaderEvent(imageName string) *dockerTaskEvent {
 	return &dockerTaskEvent{kind: event.LoaderUpdate, value: fmt.Sprintf("pulling image %s", imageName)}
 }
@@ -41,7 +47,16 @@ func newContainerCreateStatusDockerEvent(status string) *dockerTaskEvent {
 }
 
 func newContainerCreateDockerEvent(templateName string, containerName string, containerId string) *dockerTaskEvent {
-	return &dockerTaskEvent{kind: event.LogInfo, value: fmt.Sprintf("container create: templateName=%s containerName=%s containerId=%s", templateName, containerName, containerId)}
+	return &dockerTaskEvent{kind: event.LogInfo, value: fmt.Sprintf("container create: templateName=%s containerName=%s containerId=%s", templateName, containerName, containerId),
+		payload: event.ResourcePayload{Action: event.ResourceCreate, Type: "container", Name: containerName, Id: containerId}}
+}
+
+func newContainerAttachDockerEvent(containerId string) *dockerTaskEvent {
+	return &dockerTaskEvent{kind: event.LogInfo, value: fmt.Sprintf("container attach stdin: containerId=%s", containerId)}
+}
+
+func newContainerAttachErrorDockerEvent(containerId string, err error) *dockerTaskEvent {
+	return &dockerTaskEvent{kind: event.LogWarning, value: fmt.Sprintf("container attach stdin: containerId=%s error=%v", containerId, err)}
 }
 
 func newContainerLogDockerEvent(logFileName string) *dockerTaskEvent {
@@ -60,6 +75,11 @@ func newContainerWaitDockerLoaderEvent() *dockerTaskEvent {
 	return &dockerTaskEvent{kind: event.LoaderStop, value: "waiting"}
 }
 
+func newContainerTimeoutDockerEvent(containerId string, timeout time.Duration) *dockerTaskEvent {
+	return &dockerTaskEvent{kind: event.LogWarning, value: fmt.Sprintf("container timeout: containerId=%s timeout=%s", containerId, timeout)}
+}
+
 func newContainerRemoveDockerEvent(containerId string) *dockerTaskEvent {
-	return &dockerTaskEvent{kind: event.LogInfo, value: fmt.Sprintf("container remove: containerId=%s", containerId)}
+	return &dockerTaskEvent{kind: event.LogInfo, value: fmt.Sprintf("container remove: containerId=%s", containerId),
+		payload: event.ResourcePayload{Action: event.ResourceDelete, Type: "container", Id: containerId}}
 }
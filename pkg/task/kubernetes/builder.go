@@ -0,0 +1,39 @@
+package kubernetes
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	taskModel "github.com/hckops/hckctl/pkg/task/model"
+	"github.com/hckops/hckctl/pkg/util"
+)
+
+func buildEnvSecretName(jobName string) string {
+	return fmt.Sprintf("%s-env-secret", util.ToLowerKebabCase(jobName))
+}
+
+func buildEnvSecret(namespace string, jobName string, envs []taskModel.TaskEnv) *corev1.Secret {
+	data := map[string][]byte{}
+	for _, env := range envs {
+		data[env.Key] = []byte(env.Value)
+	}
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      buildEnvSecretName(jobName),
+			Namespace: namespace,
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: data,
+	}
+}
+
+// injectEnvSecret exposes all the keys of the secret as env of the main container, the values are never part of the job spec
+func injectEnvSecret(podSpec *corev1.PodSpec, jobName string) {
+	podSpec.Containers[0].EnvFrom = append(podSpec.Containers[0].EnvFrom, corev1.EnvFromSource{
+		SecretRef: &corev1.SecretEnvSource{
+			LocalObjectReference: corev1.LocalObjectReference{Name: buildEnvSecretName(jobName)},
+		},
+	})
+}
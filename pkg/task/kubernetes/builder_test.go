@@ -0,0 +1,52 @@
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/hckops/hckctl/pkg/client/kubernetes"
+	taskModel "github.com/hckops/hckctl/pkg/task/model"
+)
+
+func TestBuildEnvSecret(t *testing.T) {
+
+	expected := `
+apiVersion: v1
+data:
+  API_KEY: bXktdmFsdWU=
+  LIMIT: MTA=
+kind: Secret
+metadata:
+  creationTimestamp: null
+  name: task-my-name-abcde-env-secret
+  namespace: my-namespace
+type: Opaque
+`
+
+	actual := buildEnvSecret("my-namespace", "task-my-name-abcde", []taskModel.TaskEnv{
+		{Key: "API_KEY", Value: "my-value"},
+		{Key: "LIMIT", Value: "10"},
+	})
+	// fix model
+	actual.TypeMeta = metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"}
+
+	assert.YAMLEqf(t, expected, kubernetes.ObjectToYaml(actual), "unexpected secret")
+}
+
+func TestInjectEnvSecret(t *testing.T) {
+	podSpec := &corev1.PodSpec{
+		Containers: []corev1.Container{{Name: "my-container"}},
+	}
+	injectEnvSecret(podSpec, "task-my-name-abcde")
+
+	expected := []corev1.EnvFromSource{{
+		SecretRef: &corev1.SecretEnvSource{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "task-my-name-abcde-env-secret"},
+		},
+	}}
+	assert.Equal(t, expected, podSpec.Containers[0].EnvFrom)
+	assert.Empty(t, podSpec.Containers[0].Env)
+}
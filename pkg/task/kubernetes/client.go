@@ -1,6 +1,7 @@
 package kubernetes
 
 import (
+	"context"
 	"github.com/hckops/hckctl/pkg/client/kubernetes"
 	commonKube "github.com/hckops/hckctl/pkg/common/kubernetes"
 	commonModel "github.com/hckops/hckctl/pkg/common/model"
@@ -27,7 +28,7 @@ func (task *KubeTaskClient) Events() *event.EventBus {
 	return task.eventBus
 }
 
-func (task *KubeTaskClient) Run(opts *taskModel.RunOptions) error {
+func (task *KubeTaskClient) Run(ctx context.Context, opts *taskModel.RunOptions) (int, error) {
 	defer task.close()
-	return task.runTask(opts)
+	return task.runTask(ctx, opts)
 }
@@ -2,14 +2,16 @@ package kubernetes
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/hckops/hckctl/pkg/event"
 	"github.com/hckops/hckctl/pkg/task/model"
 )
 
 type kubeTaskEvent struct {
-	kind  event.EventKind
-	value string
+	kind    event.EventKind
+	value   string
+	payload any
 }
 
 func (e *kubeTaskEvent) Source() string {
@@ -24,20 +26,44 @@ func (e *kubeTaskEvent) String() string {
 	return e.value
 }
 
+func (e *kubeTaskEvent) Payload() any {
+	return e.payload
+}
+
 func newNamespaceApplyKubeEvent(namespace string) *kubeTaskEvent {
 	return &kubeTaskEvent{kind: event.LogInfo, value: fmt.Sprintf("namespace apply: namespace=%s", namespace)}
 }
 
+func newSecretCreateKubeEvent(namespace string, name string, size int) *kubeTaskEvent {
+	return &kubeTaskEvent{kind: event.LogInfo, value: fmt.Sprintf("secret create: namespace=%s name=%s keys=%d", namespace, name, size),
+		payload: event.ResourcePayload{Action: event.ResourceCreate, Type: "secret", Name: name, Namespace: namespace}}
+}
+
+func newSecretDeleteKubeEvent(namespace string, name string) *kubeTaskEvent {
+	return &kubeTaskEvent{kind: event.LogInfo, value: fmt.Sprintf("secret delete: namespace=%s name=%s", namespace, name),
+		payload: event.ResourcePayload{Action: event.ResourceDelete, Type: "secret", Name: name, Namespace: namespace}}
+}
+
 func newJobCreateStatusKubeEvent(status string) *kubeTaskEvent {
 	return &kubeTaskEvent{kind: event.LogDebug, value: status}
 }
 
 func newJobCreateKubeEvent(namespace string, name string) *kubeTaskEvent {
-	return &kubeTaskEvent{kind: event.LogInfo, value: fmt.Sprintf("job create: namespace=%s name=%s", namespace, name)}
+	return &kubeTaskEvent{kind: event.LogInfo, value: fmt.Sprintf("job create: namespace=%s name=%s", namespace, name),
+		payload: event.ResourcePayload{Action: event.ResourceCreate, Type: "job", Name: name, Namespace: namespace}}
+}
+
+func newJobStatusErrorKubeEvent(namespace string, name string, attempt int, err error) *kubeTaskEvent {
+	return &kubeTaskEvent{kind: event.LogWarning, value: fmt.Sprintf("error job status: namespace=%s name=%s attempt=%d error=%v", namespace, name, attempt, err)}
+}
+
+func newJobTimeoutKubeEvent(namespace string, name string, timeout time.Duration) *kubeTaskEvent {
+	return &kubeTaskEvent{kind: event.LogWarning, value: fmt.Sprintf("job timeout: namespace=%s name=%s timeout=%s", namespace, name, timeout)}
 }
 
 func newJobDeleteKubeEvent(namespace string, name string) *kubeTaskEvent {
-	return &kubeTaskEvent{kind: event.LogInfo, value: fmt.Sprintf("job delete: namespace=%s name=%s", namespace, name)}
+	return &kubeTaskEvent{kind: event.LogInfo, value: fmt.Sprintf("job delete: namespace=%s name=%s", namespace, name),
+		payload: event.ResourcePayload{Action: event.ResourceDelete, Type: "job", Name: name, Namespace: namespace}}
 }
 
 func newJobDeleteKubeConsoleEvent() *kubeTaskEvent {
@@ -48,14 +74,34 @@ func newPodNameKubeEvent(namespace string, name string, containerName string) *k
 	return &kubeTaskEvent{kind: event.LogInfo, value: fmt.Sprintf("found unique pod: namespace=%s name=%s containerName=%s", namespace, name, containerName)}
 }
 
+func newPodAttachKubeEvent(namespace string, name string, containerName string) *kubeTaskEvent {
+	return &kubeTaskEvent{kind: event.LogInfo, value: fmt.Sprintf("pod attach stdin: namespace=%s name=%s containerName=%s", namespace, name, containerName)}
+}
+
+func newPodAttachErrorKubeEvent(namespace string, name string, err error) *kubeTaskEvent {
+	return &kubeTaskEvent{kind: event.LogWarning, value: fmt.Sprintf("pod attach stdin: namespace=%s name=%s error=%v", namespace, name, err)}
+}
+
 func newPodLogKubeEvent(logFileName string) *kubeTaskEvent {
 	return &kubeTaskEvent{kind: event.LogInfo, value: fmt.Sprintf("pod log: logFileName=%s", logFileName)}
 }
 
+func newPodExitCodeKubeEvent(namespace string, podName string, exitCode int) *kubeTaskEvent {
+	return &kubeTaskEvent{kind: event.LogInfo, value: fmt.Sprintf("pod exit code: namespace=%s podName=%s exitCode=%d", namespace, podName, exitCode)}
+}
+
+func newPodExitCodeErrorKubeEvent(namespace string, podName string, attempt int, err error) *kubeTaskEvent {
+	return &kubeTaskEvent{kind: event.LogWarning, value: fmt.Sprintf("error pod exit code: namespace=%s podName=%s attempt=%d error=%v", namespace, podName, attempt, err)}
+}
+
 func newPodLogKubeConsoleEvent(logFileName string) *kubeTaskEvent {
 	return &kubeTaskEvent{kind: event.PrintConsole, value: fmt.Sprintf("\noutput file: %s", logFileName)}
 }
 
+func newPodOutputKubeConsoleEvent(outputPath string) *kubeTaskEvent {
+	return &kubeTaskEvent{kind: event.PrintConsole, value: fmt.Sprintf("output dir: %s", outputPath)}
+}
+
 func newContainerWaitKubeLoaderEvent() *kubeTaskEvent {
 	return &kubeTaskEvent{kind: event.LoaderStop, value: "waiting"}
 }
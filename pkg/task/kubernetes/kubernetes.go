@@ -1,6 +1,9 @@
 package kubernetes
 
 import (
+	"context"
+	"time"
+
 	"github.com/pkg/errors"
 
 	"github.com/hckops/hckctl/pkg/client/kubernetes"
@@ -11,6 +14,13 @@ import (
 	"github.com/hckops/hckctl/pkg/util"
 )
 
+const (
+	exitCodeAttempts  = 5
+	exitCodeBackoff   = 1 * time.Second
+	jobStatusAttempts = 3
+	jobStatusBackoff  = 1 * time.Second
+)
+
 func newKubeTaskClient(commonOpts *taskModel.CommonTaskOptions, kubeOpts *commonModel.KubeOptions) (*KubeTaskClient, error) {
 
 	kubeCommonClient, err := commonKube.NewKubeCommonClient(kubeOpts, commonOpts.EventBus)
@@ -30,12 +40,15 @@ func (task *KubeTaskClient) close() error {
 	return task.kubeCommon.Close()
 }
 
-func (task *KubeTaskClient) runTask(opts *taskModel.RunOptions) error {
+func (task *KubeTaskClient) runTask(ctx context.Context, opts *taskModel.RunOptions) (int, error) {
 	namespace := task.clientOpts.Namespace
+	// temporary resources are deleted in reverse order also when failed or interrupted, the namespace is shared
+	rollback := util.NewRollback()
+	defer task.kubeCommon.Rollback(ctx, rollback)
 
 	// create namespace
-	if err := task.client.NamespaceApply(namespace); err != nil {
-		return err
+	if err := task.client.NamespaceApply(ctx, namespace); err != nil {
+		return -1, err
 	}
 	task.eventBus.Publish(newNamespaceApplyKubeEvent(namespace))
 
@@ -60,8 +73,23 @@ func (task *KubeTaskClient) runTask(opts *taskModel.RunOptions) error {
 				Cpu:    "1000m",
 			},
 		},
+		Stdin:   opts.StreamOpts.In != nil,
+		Timeout: opts.Timeout,
 	})
 
+	// create env secret before any sidecar is injected
+	if len(opts.Env) > 0 {
+		secret := buildEnvSecret(namespace, jobName, opts.Env)
+		task.eventBus.Publish(newSecretCreateKubeEvent(namespace, secret.Name, len(opts.Env)))
+		if err := task.client.SecretCreate(ctx, namespace, secret); err != nil {
+			return -1, err
+		}
+		rollback.Add("env-secret", func(ctx context.Context) error {
+			return task.deleteEnvSecret(ctx, namespace, jobName)
+		})
+		injectEnvSecret(&jobSpec.Spec.Template.Spec, jobName)
+	}
+
 	// inject sidecar-volume
 	if opts.CommonInfo.ShareDir != nil {
 		sidecarOpts := &commonModel.SidecarShareInjectOpts{
@@ -69,7 +97,7 @@ func (task *KubeTaskClient) runTask(opts *taskModel.RunOptions) error {
 			ShareDir:          opts.CommonInfo.ShareDir,
 		}
 		if err := task.kubeCommon.SidecarShareInject(sidecarOpts, &jobSpec.Spec.Template.Spec); err != nil {
-			return err
+			return -1, err
 		}
 	}
 
@@ -79,34 +107,38 @@ func (task *KubeTaskClient) runTask(opts *taskModel.RunOptions) error {
 			Name:       jobName,
 			NetworkVpn: opts.CommonInfo.NetworkVpn,
 		}
-		if err := task.kubeCommon.SidecarVpnInject(namespace, sidecarOpts, &jobSpec.Spec.Template.Spec); err != nil {
-			return err
+		if err := task.kubeCommon.SidecarVpnInject(ctx, namespace, sidecarOpts, &jobSpec.Spec.Template.Spec); err != nil {
+			return -1, err
 		}
-		defer task.kubeCommon.SidecarVpnDelete(namespace, jobName)
+		rollback.Add("sidecar-vpn", func(ctx context.Context) error {
+			return task.kubeCommon.SidecarVpnDelete(ctx, namespace, jobName)
+		})
 	}
 
 	jobOpts := &kubernetes.JobCreateOpts{
-		Namespace:        namespace,
-		Spec:             jobSpec,
-		CaptureInterrupt: true,
-		OnContainerInterruptCallback: func(name string) {
-			// ignore error when interrupted: it will attempt to delete the job twice
-			task.eventBus.Publish(newJobDeleteKubeConsoleEvent())
-			task.client.JobDelete(namespace, name)
+		Namespace: namespace,
+		Spec:      jobSpec,
+		OnCreateCallback: func() {
+			rollback.Add("job", func(undoCtx context.Context) error {
+				if ctx.Err() != nil {
+					task.eventBus.Publish(newJobDeleteKubeConsoleEvent())
+				}
+				task.eventBus.Publish(newJobDeleteKubeEvent(namespace, jobName))
+				return task.client.JobDelete(undoCtx, namespace, jobName)
+			})
 		},
 		OnStatusEventCallback: func(event string) {
 			task.eventBus.Publish(newJobCreateStatusKubeEvent(event))
 		},
 	}
-	err := task.client.JobCreate(jobOpts)
-	if err != nil {
-		return err
+	if err := task.client.JobCreate(ctx, jobOpts); err != nil {
+		return -1, err
 	}
 	task.eventBus.Publish(newJobCreateKubeEvent(namespace, jobName))
 
-	podInfo, err := task.client.JobDescribe(namespace, jobName)
+	podInfo, err := task.client.JobDescribe(ctx, namespace, jobName)
 	if err != nil {
-		return err
+		return -1, err
 	}
 	task.eventBus.Publish(newPodNameKubeEvent(namespace, podInfo.PodName, podInfo.ContainerName))
 
@@ -117,9 +149,25 @@ func (task *KubeTaskClient) runTask(opts *taskModel.RunOptions) error {
 			PodName:   podInfo.PodName,
 			ShareDir:  opts.CommonInfo.ShareDir,
 		}
-		if err := task.kubeCommon.SidecarShareUpload(sidecarOpts); err != nil {
-			return err
+		if err := task.kubeCommon.SidecarShareUpload(ctx, sidecarOpts); err != nil {
+			return -1, err
+		}
+	}
+
+	// the container blocks reading until attached, the logs are streamed concurrently
+	if opts.StreamOpts.In != nil {
+		attachOpts := &kubernetes.PodAttachOpts{
+			Namespace:     namespace,
+			PodName:       podInfo.PodName,
+			ContainerName: podInfo.ContainerName,
+			InStream:      opts.StreamOpts.In,
 		}
+		task.eventBus.Publish(newPodAttachKubeEvent(namespace, podInfo.PodName, podInfo.ContainerName))
+		go func() {
+			if err := task.client.PodAttachStdin(ctx, attachOpts); err != nil {
+				task.eventBus.Publish(newPodAttachErrorKubeEvent(namespace, podInfo.PodName, err))
+			}
+		}()
 	}
 
 	// stop loader
@@ -133,12 +181,100 @@ func (task *KubeTaskClient) runTask(opts *taskModel.RunOptions) error {
 		OutStream:     opts.StreamOpts.Out,
 	}
 	task.eventBus.Publish(newPodLogKubeEvent(logFileName))
-	// blocks and tail logs
-	if err := task.client.PodLogsTee(logOpts, logFileName); err != nil {
-		return err
+	// blocks and tail logs, the stream is closed when the deadline is exceeded
+	logsErr := task.client.PodLogsTee(ctx, logOpts, logFileName)
+	if task.isTimeout(ctx, namespace, jobName, opts.Timeout) {
+		task.eventBus.Publish(newJobTimeoutKubeEvent(namespace, jobName, opts.Timeout))
+		// the pod is terminated, the output dir can't be collected
+		if err := rollback.Run(ctx); err != nil {
+			return -1, err
+		}
+		return -1, &taskModel.TimeoutError{Timeout: opts.Timeout, LogFileName: logFileName}
+	} else if logsErr != nil {
+		return -1, logsErr
 	}
 
 	task.eventBus.Publish(newPodLogKubeConsoleEvent(logFileName))
-	task.eventBus.Publish(newJobDeleteKubeEvent(namespace, jobName))
-	return task.client.JobDelete(namespace, jobName)
+	exitCode := task.waitExitCode(ctx, namespace, podInfo.PodName, podInfo.ContainerName)
+
+	// collect output directory before the job is deleted
+	if opts.CommonInfo.ShareDir != nil && opts.CommonInfo.ShareDir.OutputDir {
+		outputPath := opts.CommonInfo.ShareDir.LocalOutputPath(jobName)
+		sidecarOpts := &commonModel.SidecarShareDownloadOpts{
+			Namespace: namespace,
+			PodName:   podInfo.PodName,
+			LocalPath: outputPath,
+		}
+		if err := task.kubeCommon.SidecarShareDownload(ctx, sidecarOpts); err != nil {
+			return -1, err
+		}
+		// skip empty output
+		if !util.PathNotExist(outputPath) {
+			task.eventBus.Publish(newPodOutputKubeConsoleEvent(outputPath))
+		}
+	}
+
+	// delete temporary resources
+	if err := rollback.Run(ctx); err != nil {
+		return -1, err
+	}
+	return exitCode, nil
+}
+
+// waitExitCode retries briefly, the container status is updated after the end of the logs stream
+func (task *KubeTaskClient) waitExitCode(ctx context.Context, namespace string, podName string, containerName string) int {
+	for attempt := 1; attempt <= exitCodeAttempts; attempt++ {
+		exitCode, err := task.client.PodExitCode(ctx, namespace, podName, containerName)
+		if err == nil {
+			task.eventBus.Publish(newPodExitCodeKubeEvent(namespace, podName, exitCode))
+			return exitCode
+		}
+		task.eventBus.Publish(newPodExitCodeErrorKubeEvent(namespace, podName, attempt, err))
+		if attempt == exitCodeAttempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return -1
+		case <-time.After(exitCodeBackoff):
+		}
+	}
+	return -1
+}
+
+// isTimeout relies only on the job conditions, kube might set them after the logs stream is closed.
+// The job is still active if the main container exited before the sidecars, which is never a timeout
+func (task *KubeTaskClient) isTimeout(ctx context.Context, namespace string, jobName string, timeout time.Duration) bool {
+	if timeout <= 0 {
+		return false
+	}
+	for attempt := 1; attempt <= jobStatusAttempts; attempt++ {
+		status, err := task.client.JobStatus(ctx, namespace, jobName)
+		if err != nil {
+			task.eventBus.Publish(newJobStatusErrorKubeEvent(namespace, jobName, attempt, err))
+		} else if status != kubernetes.JobActive {
+			return status == kubernetes.JobDeadlineExceeded
+		}
+		if attempt == jobStatusAttempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(jobStatusBackoff):
+		}
+	}
+	return false
+}
+
+func (task *KubeTaskClient) deleteEnvSecret(ctx context.Context, namespace string, jobName string) error {
+	name := buildEnvSecretName(jobName)
+	if ok, err := task.client.SecretDelete(ctx, namespace, name); err != nil {
+		return err
+	} else if ok {
+		task.eventBus.Publish(newSecretDeleteKubeEvent(namespace, name))
+	}
+	return nil
 }
@@ -14,6 +14,7 @@ type TaskClientOptions struct {
 	Provider   TaskProvider
 	DockerOpts *commonModel.DockerOptions
 	KubeOpts   *commonModel.KubeOptions
+	CloudOpts  *commonModel.CloudOptions
 }
 
 type CommonTaskOptions struct {
@@ -32,6 +33,8 @@ type RunOptions struct {
 	CommonInfo commonModel.CommonInfo
 	StreamOpts *commonModel.StreamOptions
 	Arguments  []string
+	Env        []TaskEnv     // expanded and resolved
+	Timeout    time.Duration // zero to wait forever
 	LogDir     string
 }
 
@@ -7,6 +7,8 @@ import (
 	"github.com/pkg/errors"
 
 	commonModel "github.com/hckops/hckctl/pkg/common/model"
+	"github.com/hckops/hckctl/pkg/scope"
+	"github.com/hckops/hckctl/pkg/secret"
 	"github.com/hckops/hckctl/pkg/util"
 )
 
@@ -22,15 +24,23 @@ type TaskV1 struct {
 	Name     string
 	Tags     []string
 	Image    commonModel.Image
+	Env      []string `json:"Env,omitempty" yaml:",omitempty"` // values are expanded with the inputs
 	Commands []TaskCommand
 }
 
+type TaskEnv struct {
+	Key   string
+	Value string
+}
+
 type TaskCommand struct {
 	Name      string
 	Arguments []string
+	Timeout   string `json:"Timeout,omitempty" yaml:",omitempty"` // duration e.g. "30m", overridden by the flag
 }
 
-func (command *TaskCommand) ExpandCommandArguments(parameters commonModel.Parameters) ([]string, error) {
+// ExpandCommandArguments validates both the inputs and the expanded arguments against the engagement scope, if not nil
+func (command *TaskCommand) ExpandCommandArguments(parameters commonModel.Parameters, targetScope *scope.ScopeV1) ([]string, error) {
 	var expandedArguments []string
 
 	for _, argument := range command.Arguments {
@@ -43,9 +53,59 @@ func (command *TaskCommand) ExpandCommandArguments(parameters commonModel.Parame
 			expandedArguments = append(expandedArguments, expanded)
 		}
 	}
+
+	if targetScope != nil {
+		// unused inputs are validated too
+		if err := targetScope.ValidateInputs(expandedArguments, parameters); err != nil {
+			return nil, err
+		}
+	}
 	return expandedArguments, nil
 }
 
+// ExpandEnv expands the template values with the inputs, the overrides "KEY=VALUE" replace or extend them as they are.
+// The "secret:<name>" values are resolved last, the lookup is invoked only if referenced
+func (task *TaskV1) ExpandEnv(parameters commonModel.Parameters, overrides []string, lookup secret.Lookup) ([]TaskEnv, error) {
+	var envs []TaskEnv
+	indexes := map[string]int{}
+	upsert := func(key, value string) {
+		if index, ok := indexes[key]; ok {
+			envs[index].Value = value
+		} else {
+			indexes[key] = len(envs)
+			envs = append(envs, TaskEnv{Key: key, Value: value})
+		}
+	}
+
+	for _, e := range task.Env {
+		key, value, err := util.SplitKeyValue(e)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid env %s", e)
+		}
+		expanded, err := util.Expand(value, parameters)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to expand env %s", key)
+		}
+		upsert(key, expanded)
+	}
+	for _, e := range overrides {
+		key, value, err := util.SplitKeyValue(e)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid env %s", e)
+		}
+		upsert(key, value)
+	}
+
+	for index, env := range envs {
+		value, err := secret.Resolve(env.Value, lookup)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid secret env %s", env.Key)
+		}
+		envs[index].Value = value
+	}
+	return envs, nil
+}
+
 func (task *TaskV1) GenerateName() string {
 	return fmt.Sprintf("%s%s-%s", tagPrefixName, util.ToLowerKebabCase(task.Name), util.RandomAlphanumeric(5))
 }
@@ -77,7 +137,17 @@ func (task *TaskV1) LoadCommand(name string) (TaskCommand, error) {
 	return TaskCommand{}, fmt.Errorf("%s command not found", name)
 }
 
+// Pretty redacts the env values by naming convention e.g. *_TOKEN
 func (task *TaskV1) Pretty() string {
-	value, _ := util.EncodeJsonIndent(task)
+	redacted := *task
+	redacted.Env = nil
+	for _, e := range task.Env {
+		if key, _, err := util.SplitKeyValue(e); err == nil && util.IsSecretKey(key) {
+			redacted.Env = append(redacted.Env, fmt.Sprintf("%s=%s", key, util.RedactedValue))
+		} else {
+			redacted.Env = append(redacted.Env, e)
+		}
+	}
+	value, _ := util.EncodeJsonIndent(redacted)
 	return value
 }
@@ -1,12 +1,14 @@
 package model
 
 import (
+	"errors"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 
 	commonModel "github.com/hckops/hckctl/pkg/common/model"
+	"github.com/hckops/hckctl/pkg/scope"
 )
 
 func TestGenerateName(t *testing.T) {
@@ -98,6 +100,60 @@ func TestPretty(t *testing.T) {
 	assert.Equal(t, json, task.Pretty())
 }
 
+func TestPrettyRedacted(t *testing.T) {
+	task := &TaskV1{
+		Kind: "task/v1",
+		Name: "shodan",
+		Env:  []string{"SHODAN_API_KEY=abc", "SHODAN_LIMIT=${limit:10}"},
+	}
+	assert.Contains(t, task.Pretty(), `"SHODAN_API_KEY=********"`)
+	assert.Contains(t, task.Pretty(), `"SHODAN_LIMIT=${limit:10}"`)
+	assert.NotContains(t, task.Pretty(), "abc")
+}
+
+func TestExpandEnv(t *testing.T) {
+	task := &TaskV1{
+		Env: []string{
+			"API_KEY=${key:secret:shodan}",
+			"LIMIT=${limit:10}",
+			"MODE=passive",
+		},
+	}
+	parameters := commonModel.Parameters{
+		"limit": "100",
+	}
+	lookup := func(name string) (string, error) {
+		if name == "shodan" {
+			return "my-key", nil
+		}
+		return "", errors.New("not found")
+	}
+	expected := []TaskEnv{
+		{Key: "API_KEY", Value: "my-key"},
+		{Key: "LIMIT", Value: "100"},
+		{Key: "MODE", Value: "active"},
+		{Key: "EXTRA", Value: "a=b"},
+	}
+	envs, err := task.ExpandEnv(parameters, []string{"MODE=active", "EXTRA=a=b"}, lookup)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, envs)
+
+	_, err = task.ExpandEnv(commonModel.Parameters{"key": "secret:missing"}, nil, lookup)
+	assert.EqualError(t, err, "invalid secret env API_KEY: not found")
+
+	_, err = task.ExpandEnv(parameters, []string{"INVALID"}, lookup)
+	assert.EqualError(t, err, "invalid env INVALID: invalid key-value pair")
+
+	_, err = (&TaskV1{Env: []string{"TARGET=${address}"}}).ExpandEnv(parameters, nil, lookup)
+	assert.EqualError(t, err, "unable to expand env TARGET: address required")
+}
+
+func TestExpandEnvEmpty(t *testing.T) {
+	envs, err := (&TaskV1{}).ExpandEnv(commonModel.Parameters{}, nil, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, envs)
+}
+
 func TestExpandCommandArguments(t *testing.T) {
 	command := TaskCommand{Arguments: []string{
 		" -a ",
@@ -114,9 +170,42 @@ func TestExpandCommandArguments(t *testing.T) {
 	expected := []string{
 		"-a", "-b", "bbb", "-c", "CCC", "-d", "AAA", "-e", "f", "--g", "HHH", "-l", "LLL:MMM:NNN",
 	}
-	expanded, err := command.ExpandCommandArguments(parameters)
+	expanded, err := command.ExpandCommandArguments(parameters, nil)
 
 	assert.Len(t, expanded, 13)
 	assert.Equal(t, expected, expanded)
 	assert.Nil(t, err)
 }
+
+func TestExpandCommandArgumentsScope(t *testing.T) {
+	command := TaskCommand{Arguments: []string{
+		"nmap -sV ${address:10.10.10.1}",
+	}}
+	targetScope := &scope.ScopeV1{
+		Name: "my-engagement",
+		Targets: scope.ScopeTargets{
+			Cidrs: []string{"10.10.10.0/24"},
+		},
+	}
+
+	expanded, err := command.ExpandCommandArguments(commonModel.Parameters{}, targetScope)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"nmap", "-sV", "10.10.10.1"}, expanded)
+
+	_, err = command.ExpandCommandArguments(commonModel.Parameters{"address": "10.10.11.1"}, targetScope)
+	assert.EqualError(t, err, "targets out of scope of engagement my-engagement: [10.10.11.1]")
+
+	// unused inputs
+	_, err = command.ExpandCommandArguments(commonModel.Parameters{"proxy": "example.com"}, targetScope)
+	assert.EqualError(t, err, "targets out of scope of engagement my-engagement: [example.com]")
+
+	// ranges, single labels and typos
+	_, err = command.ExpandCommandArguments(commonModel.Parameters{"address": "10.10.10.1-254"}, targetScope)
+	assert.NoError(t, err)
+	_, err = command.ExpandCommandArguments(commonModel.Parameters{"address": "10.10.*.1"}, targetScope)
+	assert.EqualError(t, err, "targets out of scope of engagement my-engagement: [10.10.*.1]")
+	_, err = command.ExpandCommandArguments(commonModel.Parameters{"address": "intranet"}, targetScope)
+	assert.EqualError(t, err, "targets out of scope of engagement my-engagement: [intranet]")
+	_, err = command.ExpandCommandArguments(commonModel.Parameters{"address": "10.10.10.256"}, targetScope)
+	assert.EqualError(t, err, "unrecognised targets for engagement my-engagement: [10.10.10.256]")
+}
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// TimeoutError is returned when a task is killed after the timeout, the logs collected so far are preserved
+type TimeoutError struct {
+	Timeout     time.Duration
+	LogFileName string
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("task timed out after %s", e.Timeout)
+}
+
+// ParseTimeout returns zero if the command doesn't define a timeout e.g. "30m"
+func (command *TaskCommand) ParseTimeout() (time.Duration, error) {
+	if strings.TrimSpace(command.Timeout) == "" {
+		return 0, nil
+	}
+	timeout, err := time.ParseDuration(command.Timeout)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid timeout %s", command.Timeout)
+	}
+	if timeout <= 0 {
+		return 0, fmt.Errorf("invalid timeout %s", command.Timeout)
+	}
+	return timeout, nil
+}
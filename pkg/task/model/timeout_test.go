@@ -0,0 +1,34 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTimeout(t *testing.T) {
+	timeout, err := (&TaskCommand{Timeout: "30m"}).ParseTimeout()
+	assert.NoError(t, err)
+	assert.Equal(t, 30*time.Minute, timeout)
+
+	none, err := (&TaskCommand{}).ParseTimeout()
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), none)
+
+	_, err = (&TaskCommand{Timeout: "abc"}).ParseTimeout()
+	assert.ErrorContains(t, err, "invalid timeout abc")
+
+	_, err = (&TaskCommand{Timeout: "-1s"}).ParseTimeout()
+	assert.EqualError(t, err, "invalid timeout -1s")
+}
+
+func TestTimeoutError(t *testing.T) {
+	err := fmt.Errorf("error task: %w", &TimeoutError{Timeout: 90 * time.Second})
+
+	var timeoutErr *TimeoutError
+	assert.True(t, errors.As(err, &timeoutErr))
+	assert.EqualError(t, timeoutErr, "task timed out after 1m30s")
+}
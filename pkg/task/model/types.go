@@ -0,0 +1,6 @@
+package model
+
+type TaskInfo struct {
+	Name   string
+	Status string
+}
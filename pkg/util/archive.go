@@ -0,0 +1,149 @@
+package util
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// CreateTar archives a file or a directory, all entries are nested under the given prefix
+func CreateTar(srcPath string, prefix string, writer io.Writer) error {
+	tarWriter := tar.NewWriter(writer)
+
+	err := filepath.Walk(srcPath, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		// ignore links and special files
+		if !info.IsDir() && !info.Mode().IsRegular() {
+			return nil
+		}
+
+		relativePath, err := filepath.Rel(srcPath, filePath)
+		if err != nil {
+			return err
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = path.Join(prefix, filepath.ToSlash(relativePath))
+		if info.IsDir() {
+			header.Name += "/"
+		}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+
+		if info.Mode().IsRegular() {
+			file, err := os.Open(filePath)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			if _, err := io.Copy(tarWriter, file); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.Wrapf(err, "error archive create")
+	}
+	return tarWriter.Close()
+}
+
+// ExtractTar restores the entries nested under the given prefix into destPath, which is either a file or a directory.
+// Directories are created lazily and only regular files are restored
+func ExtractTar(reader io.Reader, prefix string, destPath string) error {
+	basePath := filepath.Clean(destPath)
+	tarReader := tar.NewReader(reader)
+
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.Wrapf(err, "error archive read")
+		}
+
+		// removes any leading "/", "./" and "../"
+		name := path.Clean("/" + header.Name)[1:]
+		relativePath, found := strings.CutPrefix(name, prefix)
+		if !found || (relativePath != "" && !strings.HasPrefix(relativePath, "/")) {
+			// ignore entries outside the prefix
+			continue
+		}
+
+		targetPath := filepath.Join(basePath, filepath.FromSlash(relativePath))
+		if targetPath != basePath && !strings.HasPrefix(targetPath, basePath+string(os.PathSeparator)) {
+			return fmt.Errorf("error archive invalid path=%s", header.Name)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if targetPath == basePath {
+				continue
+			}
+			if err := os.MkdirAll(targetPath, os.FileMode(header.Mode)|0700); err != nil {
+				return errors.Wrapf(err, "error archive directory")
+			}
+		case tar.TypeReg:
+			if err := extractTarFile(tarReader, targetPath, os.FileMode(header.Mode)); err != nil {
+				return err
+			}
+		default:
+			// ignore links and special files
+		}
+	}
+	return nil
+}
+
+func extractTarFile(reader io.Reader, targetPath string, mode os.FileMode) error {
+	if err := CreateBaseDir(targetPath); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(targetPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode.Perm())
+	if err != nil {
+		return errors.Wrapf(err, "error archive file")
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(file, reader); err != nil {
+		return errors.Wrapf(err, "error archive copy")
+	}
+	// umask is ignored
+	return os.Chmod(targetPath, mode.Perm())
+}
+
+type progressReader struct {
+	reader             io.Reader
+	total              int64
+	reported           int64
+	onProgressCallback func(int64)
+}
+
+const progressStep = 1 << 20 // 1 MiB
+
+// NewProgressReader invokes the callback with the total bytes read, at most once per MiB and at the end of the stream
+func NewProgressReader(reader io.Reader, onProgressCallback func(int64)) io.Reader {
+	return &progressReader{reader: reader, onProgressCallback: onProgressCallback}
+}
+
+func (p *progressReader) Read(data []byte) (int, error) {
+	n, err := p.reader.Read(data)
+	p.total += int64(n)
+	if p.total-p.reported >= progressStep || (err == io.EOF && p.total != p.reported) {
+		p.reported = p.total
+		p.onProgressCallback(p.total)
+	}
+	return n, err
+}
@@ -0,0 +1,104 @@
+package util
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArchiveDirectory(t *testing.T) {
+	srcDir := filepath.Join(t.TempDir(), "src")
+	assert.NoError(t, os.MkdirAll(filepath.Join(srcDir, "foo"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(srcDir, "foo", "bar.sh"), []byte("hello"), 0750))
+
+	buffer := new(bytes.Buffer)
+	assert.NoError(t, CreateTar(srcDir, "output", buffer))
+
+	destDir := filepath.Join(t.TempDir(), "dest")
+	assert.NoError(t, ExtractTar(buffer, "output", destDir))
+
+	data, err := os.ReadFile(filepath.Join(destDir, "foo", "bar.sh"))
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+	info, err := os.Stat(filepath.Join(destDir, "foo", "bar.sh"))
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0750), info.Mode().Perm())
+}
+
+func TestArchiveFile(t *testing.T) {
+	srcFile := filepath.Join(t.TempDir(), "hosts")
+	assert.NoError(t, os.WriteFile(srcFile, []byte("127.0.0.1"), 0644))
+
+	buffer := new(bytes.Buffer)
+	assert.NoError(t, CreateTar(srcFile, "hosts", buffer))
+
+	destFile := filepath.Join(t.TempDir(), "my-hosts")
+	assert.NoError(t, ExtractTar(buffer, "hosts", destFile))
+
+	data, err := os.ReadFile(destFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "127.0.0.1", string(data))
+}
+
+func TestExtractTarFile(t *testing.T) {
+	// e.g. "tar -cf - -C /tmp loot.sh" in a remote container
+	buffer := new(bytes.Buffer)
+	writer := tar.NewWriter(buffer)
+	assert.NoError(t, writer.WriteHeader(&tar.Header{Name: "loot.sh", Typeflag: tar.TypeReg, Mode: 0777, Size: 5}))
+	_, err := writer.Write([]byte("hello"))
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Close())
+
+	destFile := filepath.Join(t.TempDir(), "dest", "my-loot.sh")
+	assert.NoError(t, ExtractTar(buffer, "loot.sh", destFile))
+
+	data, err := os.ReadFile(destFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+	// the umask is ignored
+	info, err := os.Stat(destFile)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0777), info.Mode().Perm())
+}
+
+func TestExtractTarEmpty(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	writer := tar.NewWriter(buffer)
+	assert.NoError(t, writer.WriteHeader(&tar.Header{Name: "output/", Typeflag: tar.TypeDir, Mode: 0755}))
+	assert.NoError(t, writer.Close())
+
+	destDir := filepath.Join(t.TempDir(), "dest")
+	assert.NoError(t, ExtractTar(buffer, "output", destDir))
+	assert.True(t, PathNotExist(destDir))
+}
+
+func TestExtractTarIgnored(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	writer := tar.NewWriter(buffer)
+	assert.NoError(t, writer.WriteHeader(&tar.Header{Name: "output/link", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"}))
+	assert.NoError(t, writer.WriteHeader(&tar.Header{Name: "../../outside", Typeflag: tar.TypeReg, Mode: 0644}))
+	assert.NoError(t, writer.WriteHeader(&tar.Header{Name: "outputs/other", Typeflag: tar.TypeReg, Mode: 0644}))
+	assert.NoError(t, writer.Close())
+
+	destDir := filepath.Join(t.TempDir(), "dest")
+	assert.NoError(t, ExtractTar(buffer, "output", destDir))
+	assert.True(t, PathNotExist(destDir))
+}
+
+func TestProgressReader(t *testing.T) {
+	var progress []int64
+	reader := NewProgressReader(strings.NewReader(strings.Repeat("a", progressStep+10)), func(total int64) {
+		progress = append(progress, total)
+	})
+
+	_, err := io.Copy(io.Discard, reader)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{progressStep + 10}, progress[len(progress)-1:])
+	assert.LessOrEqual(t, len(progress), 2)
+}
@@ -34,3 +34,12 @@ func isPortOpen(port string) error {
 
 	return nil
 }
+
+// CheckLocalAddress verifies that the given address is available to listen on, without retrying
+func CheckLocalAddress(host string, port string) error {
+	listener, err := net.Listen("tcp", net.JoinHostPort(host, port))
+	if err != nil {
+		return fmt.Errorf("unable to listen on %s: %v", net.JoinHostPort(host, port), err)
+	}
+	return listener.Close()
+}
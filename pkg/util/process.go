@@ -0,0 +1,19 @@
+package util
+
+import (
+	"os"
+	"runtime"
+	"syscall"
+)
+
+func IsProcessAlive(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	// on windows FindProcess fails if the process doesn't exist and signals are not supported
+	if runtime.GOOS == "windows" {
+		return true
+	}
+	return process.Signal(syscall.Signal(0)) == nil
+}
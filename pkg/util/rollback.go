@@ -0,0 +1,52 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+type rollbackAction struct {
+	name string
+	undo func(ctx context.Context) error
+}
+
+// Rollback collects the undo actions of the resources created by a multi-step operation
+type Rollback struct {
+	actions []rollbackAction
+}
+
+func NewRollback() *Rollback {
+	return &Rollback{}
+}
+
+// Add registers the action which deletes a resource, it must be invoked as soon as the resource might exist
+func (r *Rollback) Add(name string, undo func(ctx context.Context) error) {
+	r.actions = append(r.actions, rollbackAction{name: name, undo: undo})
+}
+
+// Len returns the number of pending actions
+func (r *Rollback) Len() int {
+	return len(r.actions)
+}
+
+// Commit discards all the actions, the operation completed successfully
+func (r *Rollback) Commit() {
+	r.actions = nil
+}
+
+// Run invokes all the actions in reverse order and discards them, the context is never cancelled
+// to cleanup also when interrupted. It attempts all the actions and returns the combined errors
+func (r *Rollback) Run(ctx context.Context) error {
+	undoCtx := context.WithoutCancel(ctx)
+
+	var errs []error
+	for i := len(r.actions) - 1; i >= 0; i-- {
+		action := r.actions[i]
+		if err := action.undo(undoCtx); err != nil {
+			errs = append(errs, fmt.Errorf("error rollback %s: %w", action.name, err))
+		}
+	}
+	r.actions = nil
+	return errors.Join(errs...)
+}
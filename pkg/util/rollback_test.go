@@ -0,0 +1,49 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRollback(t *testing.T) {
+	var undone []string
+	newUndo := func(name string, err error) func(context.Context) error {
+		return func(ctx context.Context) error {
+			assert.NoError(t, ctx.Err())
+			undone = append(undone, name)
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	rollback := NewRollback()
+	rollback.Add("first", newUndo("first", nil))
+	rollback.Add("second", newUndo("second", errors.New("boom")))
+	rollback.Add("third", newUndo("third", nil))
+
+	err := rollback.Run(ctx)
+	assert.EqualError(t, err, "error rollback second: boom")
+	assert.Equal(t, []string{"third", "second", "first"}, undone)
+
+	// the actions are invoked only once
+	assert.NoError(t, rollback.Run(ctx))
+	assert.Len(t, undone, 3)
+}
+
+func TestRollbackCommit(t *testing.T) {
+	rollback := NewRollback()
+	rollback.Add("resource", func(ctx context.Context) error {
+		t.Fatal("unexpected rollback")
+		return nil
+	})
+	assert.Equal(t, 1, rollback.Len())
+	rollback.Commit()
+	assert.Equal(t, 0, rollback.Len())
+
+	assert.NoError(t, rollback.Run(context.Background()))
+}
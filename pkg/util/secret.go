@@ -0,0 +1,57 @@
+package util
+
+import (
+	"io"
+	"regexp"
+	"strings"
+)
+
+const (
+	RedactedValue = "********"
+)
+
+// compared ignoring case and separators e.g. TTYD_PASSWORD, api-key, secretKey
+var secretKeyWords = []string{
+	"password", "passwd", "passphrase", "secret", "token", "apikey", "privatekey", "accesskey", "credential",
+}
+
+// matches key=value, key: value and "key": "value", the value is a ${placeholder} or stops at quotes and separators
+var secretKeyValueRegex = regexp.MustCompile(`([A-Za-z0-9_.\-]+)("?\s*[=:]\s*"?)(\$\{[^}]*\}|[^\s,;&"'\\\]\})]+)`)
+
+// IsSecretKey returns true if the key is a secret by naming convention
+func IsSecretKey(key string) bool {
+	compact := strings.NewReplacer("_", "", "-", "", ".", "").Replace(strings.ToLower(key))
+	for _, word := range secretKeyWords {
+		if strings.Contains(compact, word) {
+			return true
+		}
+	}
+	return compact == "pass" || compact == "pwd"
+}
+
+// RedactSecrets masks the values of all the secret keys in a free text
+func RedactSecrets(text string) string {
+	return secretKeyValueRegex.ReplaceAllStringFunc(text, func(match string) string {
+		groups := secretKeyValueRegex.FindStringSubmatch(match)
+		if !IsSecretKey(groups[1]) {
+			return match
+		}
+		return groups[1] + groups[2] + RedactedValue
+	})
+}
+
+type redactWriter struct {
+	writer io.Writer
+}
+
+// NewRedactWriter masks the secrets of each write, it expects complete lines e.g. log events
+func NewRedactWriter(writer io.Writer) io.Writer {
+	return &redactWriter{writer: writer}
+}
+
+func (w *redactWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(w.writer, RedactSecrets(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
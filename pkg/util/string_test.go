@@ -19,3 +19,9 @@ func TestBase64(t *testing.T) {
 	assert.True(t, ok)
 	assert.Equal(t, value, decoded)
 }
+
+func TestFormatBytes(t *testing.T) {
+	assert.Equal(t, "512 B", FormatBytes(512))
+	assert.Equal(t, "1.5 KiB", FormatBytes(1536))
+	assert.Equal(t, "2.0 MiB", FormatBytes(2*1024*1024))
+}